@@ -0,0 +1,10 @@
+package main
+
+import (
+	"api-doc-generator/internal/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Execute())
+}
@@ -0,0 +1,334 @@
+// apifox-mock 模拟 Apifox 开放 API 的 OpenAPI 导入接口，
+// 用于在本地和 CI 中跑通完整的同步流程，无需真实的 Apifox 账号
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"api-doc-generator/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importRequest 与 sync.ApifoxImportRequest 结构一致
+type importRequest struct {
+	Input   json.RawMessage `json:"input"`
+	Options struct {
+		EndpointOverwriteBehavior string `json:"endpointOverwriteBehavior"`
+		SchemaOverwriteBehavior   string `json:"schemaOverwriteBehavior"`
+		TargetBranchID            int64  `json:"targetBranchId"`
+		TargetEndpointFolderID    int64  `json:"targetEndpointFolderId"`
+	} `json:"options"`
+}
+
+// counters Apifox 导入结果中的计数
+type counters struct {
+	EndpointCreated int `json:"endpointCreated"`
+	EndpointUpdated int `json:"endpointUpdated"`
+	EndpointFailed  int `json:"endpointFailed"`
+	EndpointIgnored int `json:"endpointIgnored"`
+	SchemaCreated   int `json:"schemaCreated"`
+	SchemaUpdated   int `json:"schemaUpdated"`
+	SchemaFailed    int `json:"schemaFailed"`
+	SchemaIgnored   int `json:"schemaIgnored"`
+}
+
+// project 记录某个项目（分支）已导入的接口和数据结构，用于区分新建和更新
+type project struct {
+	Endpoints map[string]bool `json:"endpoints"`
+	Schemas   map[string]bool `json:"schemas"`
+	Imports   int             `json:"imports"`
+}
+
+type mockServer struct {
+	token       string
+	latency     time.Duration
+	failRate    float64
+	failFirst   int
+	failStatus  int
+	failProject map[string]bool
+
+	mu       sync.Mutex
+	requests int
+	projects map[string]*project
+}
+
+var overwriteBehaviors = map[string]bool{
+	"OVERWRITE_EXISTING": true,
+	"AUTO_MERGE":         true,
+	"KEEP_EXISTING":      true,
+	"CREATE_NEW":         true,
+}
+
+func main() {
+	port := flag.String("port", "4010", "监听端口")
+	token := flag.String("token", "", "要求的 API 令牌，为空时接受任意令牌")
+	latency := flag.Duration("latency", 0, "每个导入请求的响应延迟，例如 500ms")
+	failRate := flag.Float64("fail-rate", 0, "导入请求随机失败的比例（0-1）")
+	failFirst := flag.Int("fail-first", 0, "前 N 个导入请求失败")
+	failStatus := flag.Int("fail-status", http.StatusInternalServerError, "模拟失败时返回的 HTTP 状态码")
+	failProjects := flag.String("fail-projects", "", "总是导入失败的项目 ID（逗号分隔）")
+	flag.Parse()
+
+	s := &mockServer{
+		token:       *token,
+		latency:     *latency,
+		failRate:    *failRate,
+		failFirst:   *failFirst,
+		failStatus:  *failStatus,
+		failProject: make(map[string]bool),
+		projects:    make(map[string]*project),
+	}
+	for _, id := range strings.Split(*failProjects, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			s.failProject[id] = true
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Logger(), gin.Recovery())
+	r.POST("/v1/projects/:id/import-openapi", s.handleImport)
+	r.POST("/v1/projects/:id/export-openapi", s.handleExport)
+	// 查看模拟项目的状态，便于在 CI 中断言同步结果
+	r.GET("/_mock/projects/:id", s.handleInspect)
+	r.DELETE("/_mock/projects", s.handleReset)
+
+	log.Printf("🧪 Apifox mock listening on :%s", *port)
+	log.Printf("🔧 Set the project Apifox BaseURL to http://localhost:%s", *port)
+	if err := r.Run(":" + *port); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+func (s *mockServer) handleImport(c *gin.Context) {
+	projectID := c.Param("id")
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+
+	if msg := s.checkHeaders(c); msg != "" {
+		apiError(c, http.StatusUnauthorized, msg)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	var req importRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		apiError(c, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if msg := validateOptions(&req); msg != "" {
+		apiError(c, http.StatusBadRequest, msg)
+		return
+	}
+	spec, err := loadInput(req.Input)
+	if err != nil {
+		apiError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if s.shouldFail(projectID) {
+		log.Printf("💥 Simulated failure for project %s", projectID)
+		apiError(c, s.failStatus, "simulated failure")
+		return
+	}
+
+	key := projectID
+	if req.Options.TargetBranchID != 0 {
+		key = fmt.Sprintf("%s/branches/%d", projectID, req.Options.TargetBranchID)
+	}
+	result := s.record(key, spec)
+	log.Printf("✅ Imported into %s: %d endpoint(s) created, %d updated, %d schema(s) created, %d updated",
+		key, result.EndpointCreated, result.EndpointUpdated, result.SchemaCreated, result.SchemaUpdated)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"counters": result,
+			"errors":   []interface{}{},
+		},
+	})
+}
+
+// handleExport 导出项目已导入的接口，apidoc init 用它校验令牌
+func (s *mockServer) handleExport(c *gin.Context) {
+	if msg := s.checkHeaders(c); msg != "" {
+		apiError(c, http.StatusUnauthorized, msg)
+		return
+	}
+	projectID := c.Param("id")
+	if s.failProject[projectID] {
+		apiError(c, http.StatusForbidden, "no access to project "+projectID)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spec := openapi.NewSpec()
+	spec.Info.Title = "Apifox mock project " + projectID
+	if p, ok := s.projects[projectID]; ok {
+		for endpoint := range p.Endpoints {
+			method, path, _ := strings.Cut(endpoint, " ")
+			spec.AddPath(path, method, &openapi.Operation{Responses: map[string]openapi.Response{}})
+		}
+	}
+	c.JSON(http.StatusOK, spec)
+}
+
+// checkHeaders 校验鉴权和 API 版本请求头，返回错误信息
+func (s *mockServer) checkHeaders(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") || strings.TrimPrefix(auth, "Bearer ") == "" {
+		return "missing bearer token"
+	}
+	if s.token != "" && strings.TrimPrefix(auth, "Bearer ") != s.token {
+		return "invalid token"
+	}
+	if c.GetHeader("X-Apifox-Api-Version") == "" {
+		return "missing X-Apifox-Api-Version header"
+	}
+	return ""
+}
+
+// validateOptions 校验导入选项，返回错误信息
+func validateOptions(req *importRequest) string {
+	if len(req.Input) == 0 {
+		return "input is required"
+	}
+	if !overwriteBehaviors[req.Options.EndpointOverwriteBehavior] {
+		return "invalid options.endpointOverwriteBehavior: " + req.Options.EndpointOverwriteBehavior
+	}
+	if !overwriteBehaviors[req.Options.SchemaOverwriteBehavior] {
+		return "invalid options.schemaOverwriteBehavior: " + req.Options.SchemaOverwriteBehavior
+	}
+	return ""
+}
+
+// loadInput 解析 input：文档内容字符串，或 {"url": "..."} 形式的文档地址
+func loadInput(input json.RawMessage) (*openapi.Spec, error) {
+	var content string
+	if err := json.Unmarshal(input, &content); err != nil {
+		var byURL struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(input, &byURL); err != nil || byURL.URL == "" {
+			return nil, fmt.Errorf("input must be the spec content or {\"url\": ...}")
+		}
+		resp, err := http.Get(byURL.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %v", byURL.URL, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch %s: HTTP %d", byURL.URL, resp.StatusCode)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %v", byURL.URL, err)
+		}
+		content = string(data)
+	}
+
+	var spec openapi.Spec
+	if err := json.Unmarshal([]byte(content), &spec); err != nil {
+		return nil, fmt.Errorf("input is not a valid OpenAPI document: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version: %q", spec.OpenAPI)
+	}
+	return &spec, nil
+}
+
+// shouldFail 按配置决定本次请求是否模拟失败
+func (s *mockServer) shouldFail(projectID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.failProject[projectID] || s.requests <= s.failFirst {
+		return true
+	}
+	return s.failRate > 0 && rand.Float64() < s.failRate
+}
+
+// record 保存导入内容，返回与 Apifox 相同格式的计数
+func (s *mockServer) record(key string, spec *openapi.Spec) counters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[key]
+	if !ok {
+		p = &project{Endpoints: make(map[string]bool), Schemas: make(map[string]bool)}
+		s.projects[key] = p
+	}
+	p.Imports++
+
+	var result counters
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		endpoint := method + " " + path
+		if p.Endpoints[endpoint] {
+			result.EndpointUpdated++
+		} else {
+			result.EndpointCreated++
+			p.Endpoints[endpoint] = true
+		}
+	})
+	if spec.Components != nil {
+		for name := range spec.Components.Schemas {
+			if p.Schemas[name] {
+				result.SchemaUpdated++
+			} else {
+				result.SchemaCreated++
+				p.Schemas[name] = true
+			}
+		}
+	}
+	return result
+}
+
+func (s *mockServer) handleInspect(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := c.Param("id")
+	if branch := c.Query("branch"); branch != "" {
+		key += "/branches/" + branch
+	}
+	p, ok := s.projects[key]
+	if !ok {
+		apiError(c, http.StatusNotFound, "project has not been imported: "+key)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (s *mockServer) handleReset(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.projects = make(map[string]*project)
+	s.requests = 0
+	c.JSON(http.StatusOK, gin.H{"message": "Mock state reset"})
+}
+
+// apiError 返回与 Apifox 相同格式的错误
+func apiError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{
+		"success":      false,
+		"errorCode":    http.StatusText(status),
+		"errorMessage": message,
+	})
+}
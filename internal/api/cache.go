@@ -0,0 +1,106 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinSize is the smallest response worth compressing
+const gzipMinSize = 1024
+
+// compressibleTypes are the content types Gzip compresses
+var compressibleTypes = []string{"application/json", "application/yaml", "application/javascript", "text/"}
+
+// Gzip compresses JSON, YAML and text responses for clients that accept gzip.
+// Partial (Range) responses, HEAD requests and small bodies are sent as is.
+// A strong ETag is weakened, since the compressed bytes differ from the
+// representation it was computed for.
+func Gzip() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" ||
+			!strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+		w := &gzipWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Header("Vary", "Accept-Encoding")
+		c.Next()
+		if w.gz != nil {
+			w.gz.Close()
+		}
+	}
+}
+
+// gzipWriter decides on the first write of the body whether to compress the
+// response. Gin sends the headers with the first write, so they can still be
+// changed then; handlers may set a status more than once before.
+type gzipWriter struct {
+	gin.ResponseWriter
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	w.decide(w.Status())
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) decide(status int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	header := w.Header()
+	if status != http.StatusOK || header.Get("Content-Encoding") != "" || !compressible(header.Get("Content-Type")) {
+		return
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < gzipMinSize {
+		return
+	}
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		header.Set("ETag", "W/"+etag)
+	}
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+func compressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StaticETag sets ETag and Cache-Control on files served from root under
+// prefix, so pollers revalidate and get 304 Not Modified while a file is
+// unchanged. The ETag is derived from the size and modification time, and
+// the file server compares it with If-None-Match; If-Modified-Since is
+// handled by the file server itself.
+func StaticETag(prefix, root string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Request.URL.Path, prefix)
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(filepath.Clean("/"+name)))); err == nil && !info.IsDir() {
+			c.Header("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+			c.Header("Cache-Control", "no-cache")
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,112 @@
+// Package api holds the conventions shared by the service's HTTP endpoints:
+// the error envelope, the registered error codes and request IDs.
+package api
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code is a registered, stable error code. Clients should branch on Name,
+// never on the message text.
+type Code struct {
+	Name    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"` // default message
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = make(map[string]Code)
+)
+
+// RegisterCode adds an error code to the catalog served at /api/v1/errors.
+// Registering the same name twice is a programming error.
+func RegisterCode(name string, status int, message string) Code {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	if _, exists := codes[name]; exists {
+		panic("api: duplicate error code " + name)
+	}
+	code := Code{Name: name, Status: status, Message: message}
+	codes[name] = code
+	return code
+}
+
+// Codes returns all registered error codes, sorted by name
+func Codes() []Code {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	list := make([]Code, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, code)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Error codes returned by the service
+var (
+	CodeInvalidRequest     = RegisterCode("INVALID_REQUEST", http.StatusBadRequest, "The request is malformed or has invalid parameters")
+	CodeInvalidSignature   = RegisterCode("INVALID_SIGNATURE", http.StatusUnauthorized, "The webhook signature or token is invalid")
+	CodeUnauthorized       = RegisterCode("UNAUTHORIZED", http.StatusUnauthorized, "An API key is required; send it as Authorization: Bearer <key> or X-API-Key")
+	CodeForbidden          = RegisterCode("FORBIDDEN", http.StatusForbidden, "The API key's team has no access to this resource")
+	CodeNotFound           = RegisterCode("NOT_FOUND", http.StatusNotFound, "The requested resource does not exist")
+	CodeMethodNotAllowed   = RegisterCode("METHOD_NOT_ALLOWED", http.StatusMethodNotAllowed, "The method is not allowed for this resource")
+	CodeProjectNotFound    = RegisterCode("PROJECT_NOT_FOUND", http.StatusNotFound, "The project is not registered")
+	CodeConfigReloadFailed = RegisterCode("CONFIG_RELOAD_FAILED", http.StatusInternalServerError, "Project configs could not be reloaded")
+	CodeProjectExists      = RegisterCode("PROJECT_EXISTS", http.StatusConflict, "A project with this name is already registered")
+	CodeRepoRegistered     = RegisterCode("REPO_REGISTERED", http.StatusConflict, "The repository is already registered by another project")
+	CodeConfigReadOnly     = RegisterCode("CONFIG_READ_ONLY", http.StatusConflict, "Project configs are managed in the configs repository")
+	CodeSpecNotSynced      = RegisterCode("SPEC_NOT_SYNCED", http.StatusConflict, "The project has no synced document yet")
+	CodeBreakingChanges    = RegisterCode("BREAKING_CHANGES", http.StatusConflict, "The change breaks the API and sync.fail_on_breaking is set")
+	CodeLintFailed         = RegisterCode("LINT_FAILED", http.StatusConflict, "The document violates lint rules with error severity")
+	CodePayloadTooLarge    = RegisterCode("PAYLOAD_TOO_LARGE", http.StatusRequestEntityTooLarge, "The request body exceeds the size limit")
+	CodeRateLimited        = RegisterCode("RATE_LIMITED", http.StatusTooManyRequests, "Too many requests; retry after the time in the Retry-After header")
+	CodeAnalysisFailed     = RegisterCode("ANALYSIS_FAILED", http.StatusUnprocessableEntity, "The repository could not be checked out or analyzed")
+	CodeSyncFailed         = RegisterCode("SYNC_FAILED", http.StatusBadGateway, "Syncing to a target failed")
+	CodeUpstreamFailed     = RegisterCode("UPSTREAM_FAILED", http.StatusBadGateway, "The try-it upstream could not be reached")
+	CodeInternal           = RegisterCode("INTERNAL_ERROR", http.StatusInternalServerError, "An unexpected error occurred")
+)
+
+// ErrorResponse is the body of every error returned by the service
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// Error writes the error envelope with the code's HTTP status and aborts the
+// request. An empty message uses the code's default message.
+func Error(c *gin.Context, code Code, message string, details interface{}) {
+	if message == "" {
+		message = code.Message
+	}
+	c.AbortWithStatusJSON(code.Status, ErrorResponse{
+		Code:      code.Name,
+		Message:   message,
+		Details:   details,
+		RequestID: RequestIDFrom(c),
+	})
+}
+
+// NotFound answers unknown routes with the error envelope
+func NotFound(c *gin.Context) {
+	Error(c, CodeNotFound, "", gin.H{"path": c.Request.URL.Path})
+}
+
+// MethodNotAllowed answers known routes called with another method
+func MethodNotAllowed(c *gin.Context) {
+	Error(c, CodeMethodNotAllowed, "", gin.H{"method": c.Request.Method})
+}
+
+// Recovery turns panics in handlers into INTERNAL_ERROR responses
+func Recovery(c *gin.Context, recovered interface{}) {
+	log.Printf("❌ Panic serving %s %s (request %s): %v", c.Request.Method, c.Request.URL.Path, RequestIDFrom(c), recovered)
+	Error(c, CodeInternal, "", nil)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit rejects requests whose body is larger than maxBytes. The body is
+// read up front, so handlers that verify signatures never see a truncated
+// payload. A limit of 0 or less disables the check.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			Error(c, CodePayloadTooLarge, fmt.Sprintf("request body of %d bytes exceeds the limit of %d bytes", c.Request.ContentLength, maxBytes), nil)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		if err != nil {
+			Error(c, CodeInvalidRequest, err.Error(), nil)
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			Error(c, CodePayloadTooLarge, fmt.Sprintf("request body exceeds the limit of %d bytes", maxBytes), nil)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// RateLimiter is a token bucket per key, e.g. per client IP. Each key may
+// send perMinute requests in a burst, refilled evenly over a minute.
+type RateLimiter struct {
+	perMinute int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing perMinute requests per key, or nil
+// when perMinute is 0 or less. A nil limiter allows everything.
+func NewRateLimiter(perMinute int) *RateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{perMinute: perMinute, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token for key. When none is left, it returns false and the
+// time until the next token is available.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	now := time.Now()
+	capacity := float64(l.perMinute)
+	perSecond := capacity / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled completely, so the map does not
+// grow with every client ever seen
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit rejects requests of a client IP that exceeds the limiter
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CheckRate(c, limiter, c.ClientIP()) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckRate takes a token for key and writes a RATE_LIMITED error with a
+// Retry-After header when none is left. It reports whether the request may
+// continue.
+func CheckRate(c *gin.Context, limiter *RateLimiter, key string) bool {
+	ok, wait := limiter.Allow(key)
+	if ok {
+		return true
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	Error(c, CodeRateLimited, fmt.Sprintf("too many requests from %s, retry in %ds", key, seconds), nil)
+	return false
+}
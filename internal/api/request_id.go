@@ -0,0 +1,41 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions. An ID sent by
+// the client (or a proxy) is kept so logs can be correlated across services.
+const RequestIDHeader = "X-Request-ID"
+
+const requestIDKey = "request_id"
+
+// RequestID assigns every request an ID, returned in the response header and
+// in error responses
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID assigned by the RequestID middleware
+func RequestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key when the Authorization header is used for
+// something else
+const APIKeyHeader = "X-API-Key"
+
+const tenantKey = "tenant"
+
+// Tenant is the team an API key belongs to
+type Tenant struct {
+	Name  string
+	Admin bool // admins see the projects of every team
+}
+
+// CanAccess reports whether the tenant may see projects of team. A nil tenant
+// means authentication is disabled, so everything is accessible.
+func (t *Tenant) CanAccess(team string) bool {
+	return t == nil || t.Admin || t.Name == team
+}
+
+// Authenticate resolves the API key of each request to its tenant, sent as
+// "Authorization: Bearer <key>" or in X-API-Key. Requests without a known
+// key are rejected. With no keys, authentication is disabled and requests pass
+// without a tenant.
+func Authenticate(keys map[string]*Tenant) gin.HandlerFunc {
+	// Keys are looked up by hash, so the comparison time does not depend on
+	// how much of a guessed key matches
+	hashed := make(map[[sha256.Size]byte]*Tenant, len(keys))
+	for key, tenant := range keys {
+		hashed[sha256.Sum256([]byte(key))] = tenant
+	}
+	return func(c *gin.Context) {
+		if len(hashed) == 0 {
+			c.Next()
+			return
+		}
+		key := c.GetHeader(APIKeyHeader)
+		if auth := c.GetHeader("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if key == "" {
+			Error(c, CodeUnauthorized, "", nil)
+			return
+		}
+		tenant, ok := hashed[sha256.Sum256([]byte(key))]
+		if !ok {
+			Error(c, CodeUnauthorized, "the API key is not valid", nil)
+			return
+		}
+		c.Set(tenantKey, tenant)
+		c.Next()
+	}
+}
+
+// AllowCORS lets pages on any origin call the routes after it, such as the
+// mock server called from a frontend dev server. Browsers send preflight
+// requests without the API key, so they are answered here, before
+// Authenticate, with the method and headers the page asked for.
+func AllowCORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", c.GetHeader("Access-Control-Request-Method"))
+			c.Header("Access-Control-Allow-Headers", c.GetHeader("Access-Control-Request-Headers"))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// TenantFrom returns the tenant set by Authenticate, or nil when
+// authentication is disabled
+func TenantFrom(c *gin.Context) *Tenant {
+	if tenant, ok := c.Get(tenantKey); ok {
+		return tenant.(*Tenant)
+	}
+	return nil
+}
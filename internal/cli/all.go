@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// projectRun 一个项目的同步结果
+type projectRun struct {
+	Project    string        `json:"project"`
+	OK         bool          `json:"ok"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+	LogFile    string        `json:"log_file,omitempty"`
+	Error      string        `json:"error,omitempty"` // 失败时输出中的最后一条错误
+}
+
+// runAll 同步配置目录中的所有项目，最多 concurrency 个项目同时运行，打印汇总表，有项目失败时返回错误。
+// 每个项目在单独的子进程中执行 apidoc sync（附带 args），输出写入 {data_dir}/output/{项目名}/sync.log，
+// 一个项目失败退出不影响其他项目。聚合项目在其他项目完成后运行，以合并它们刚同步的文档
+func runAll(configManager *config.ProjectConfigManager, concurrency int, args []string) error {
+	names, err := configManager.ListProjects()
+	if err != nil {
+		return fmt.Errorf("获取项目列表失败: %w", err)
+	}
+	if len(names) == 0 {
+		output.printf("配置目录 %s 中没有项目\n", configManager.ConfigDir)
+		return nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("无法定位 apidoc 可执行文件: %w", err)
+	}
+
+	var services, aggregates, runs []*projectRun
+	for _, name := range names {
+		run := &projectRun{Project: name}
+		runs = append(runs, run)
+		projectConfig, err := configManager.LoadProjectConfig(name)
+		switch {
+		case err != nil:
+			run.Error = "加载配置失败: " + err.Error()
+		case projectConfig.IsAggregate():
+			aggregates = append(aggregates, run)
+		default:
+			services = append(services, run)
+		}
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	output.printf("🔄 同步 %d 个项目（并发 %d）\n\n", len(names), concurrency)
+	for _, batch := range [][]*projectRun{services, aggregates} {
+		slots := make(chan struct{}, concurrency)
+		done := make(chan *projectRun)
+		for _, run := range batch {
+			go func(run *projectRun) {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				runProject(executable, configManager.ConfigDir, run, args)
+				done <- run
+			}(run)
+		}
+		for range batch {
+			run := <-done
+			if run.OK {
+				output.printf("  ✓ %s（%s）\n", run.Project, run.Duration.Round(time.Millisecond))
+			} else {
+				output.printf("  ❌ %s（%s）: %s\n", run.Project, run.Duration.Round(time.Millisecond), run.Error)
+			}
+		}
+	}
+
+	printRunSummary(runs)
+	output.result.Projects = runs
+	failed := 0
+	for _, run := range runs {
+		if !run.OK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个项目同步失败", failed)
+	}
+	return nil
+}
+
+// runProject 在子进程中同步一个项目，输出保存到项目的日志文件
+func runProject(executable, configDir string, run *projectRun, args []string) {
+	started := time.Now()
+	cmdArgs := append([]string{"sync", run.Project, "--config-dir", configDir}, args...)
+	output, err := exec.Command(executable, cmdArgs...).CombinedOutput()
+	run.Duration = time.Since(started)
+	run.DurationMS = run.Duration.Milliseconds()
+	run.OK = err == nil
+
+	run.LogFile = filepath.Join(outputDir(run.Project), "sync.log")
+	if mkErr := os.MkdirAll(filepath.Dir(run.LogFile), 0755); mkErr == nil {
+		if writeErr := os.WriteFile(run.LogFile, output, 0644); writeErr != nil {
+			run.LogFile = ""
+		}
+	} else {
+		run.LogFile = ""
+	}
+	if err != nil {
+		run.Error = lastError(output)
+		if run.Error == "" {
+			run.Error = err.Error()
+		}
+	}
+}
+
+// passThroughArgs 返回命令行中显式设置、需要传给每个项目的选项（--all 时使用）
+func passThroughArgs(flags *pflag.FlagSet) []string {
+	skip := map[string]bool{"all": true, "concurrency": true, "config-dir": true, "output": true}
+	var args []string
+	flags.Visit(func(f *pflag.Flag) {
+		if !skip[f.Name] {
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+	return args
+}
+
+// lastError 返回输出中最后一条错误（以 ❌ 开头的行），去掉日志时间前缀
+func lastError(output []byte) string {
+	lines := bytes.Split(bytes.TrimSpace(output), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := string(lines[i])
+		if idx := strings.Index(line, "❌"); idx >= 0 {
+			return strings.TrimSpace(strings.TrimPrefix(line[idx:], "❌"))
+		}
+	}
+	return ""
+}
+
+// printRunSummary 打印各项目同步结果的汇总表
+func printRunSummary(runs []*projectRun) {
+	width := len("PROJECT")
+	for _, run := range runs {
+		if len(run.Project) > width {
+			width = len(run.Project)
+		}
+	}
+	succeeded := 0
+	output.println()
+	output.printf("=== 同步汇总 ===\n")
+	output.printf("%-*s  %-4s  %10s  %s\n", width, "PROJECT", "OK", "DURATION", "LOG / ERROR")
+	for _, run := range runs {
+		status, detail := "✗", run.Error
+		if run.OK {
+			status, detail = "✓", run.LogFile
+			succeeded++
+		} else if run.LogFile != "" {
+			detail = run.LogFile + ": " + run.Error
+		}
+		output.printf("%-*s  %-4s  %10s  %s\n", width, run.Project, status, run.Duration.Round(time.Millisecond), detail)
+	}
+	output.println()
+	output.printf("成功 %d 个，失败 %d 个\n", succeeded, len(runs)-succeeded)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "管理项目配置的加密",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "gen-key",
+		Short: "生成新的配置加密主密钥",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := config.GenerateMasterKey()
+			if err != nil {
+				return fmt.Errorf("生成主密钥失败: %w", err)
+			}
+			fmt.Println(key)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "encrypt",
+		Short: "使用 CONFIG_MASTER_KEY 加密配置目录中所有项目配置的敏感字段（令牌、API Key）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := encryptConfigDir(configDir); err != nil {
+				return fmt.Errorf("加密配置失败: %w", err)
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+// encryptConfigDir 加密配置目录下所有项目配置中尚未加密的敏感字段，已加密的字段保持不变
+func encryptConfigDir(dir string) error {
+	key, err := config.LoadMasterKey()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("未设置 %s 或 %s，可使用 apidoc config gen-key 生成", config.MasterKeyEnv, config.MasterKeyFileEnv)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		encrypted, count, err := config.EncryptConfigJSON(data, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if count == 0 {
+			fmt.Printf("  - %s: 无需加密\n", filepath.Base(file))
+			continue
+		}
+		if err := os.WriteFile(file, encrypted, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ %s: 已加密 %d 个字段\n", filepath.Base(file), count)
+		total += count
+	}
+	fmt.Printf("共加密 %d 个字段\n", total)
+	return nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newDiffCommand() *cobra.Command {
+	var branch string
+	cmd := &cobra.Command{
+		Use:               "diff <项目名>",
+		Short:             "生成文档并与上次同步的文档对比，不保存也不同步",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configManager := config.NewProjectConfigManager(configDir)
+			output.result.Project = args[0]
+			output.result.Command = "diff"
+			projectConfig, err := configManager.LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+			return runDiff(configManager, projectConfig, branch)
+		},
+	}
+	addBranchFlag(cmd, &branch)
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// runDiff 生成文档并与上次同步的文档（sync.BaselinePath）对比，
+// 列出新增、删除和变更的接口，不保存也不同步
+func runDiff(configManager *config.ProjectConfigManager, projectConfig *config.ProjectConfig, gitBranch string) error {
+	spec, err := buildSpec(configManager, projectConfig)
+	if err != nil {
+		return fmt.Errorf("解析失败: %w", err)
+	}
+
+	apifoxCfg := projectConfig.Apifox.ForGitBranch(resolveGitBranch(projectConfig, gitBranch))
+	lastSpec, lastPath, err := sync.LoadBaseline(apifoxCfg)
+	if err != nil {
+		return fmt.Errorf("读取上次同步的文档失败: %w", err)
+	}
+	output.println()
+	if lastSpec == nil {
+		output.printf("没有同步过的文档（%s），所有接口都是新增\n", sync.BaselinePath(apifoxCfg))
+	} else {
+		output.printf("与上次同步的文档对比: %s\n", lastPath)
+	}
+
+	report := diff.Compare(lastSpec, spec)
+	output.setSpec(spec)
+	output.setDiff(report, lastPath)
+	output.printf("变更: %s\n", report.Summary())
+	if !report.HasChanges() {
+		return nil
+	}
+
+	endpoints := report.Endpoints()
+	printEndpoints := func(title, symbol string, locations []string) {
+		if len(locations) == 0 {
+			return
+		}
+		output.println()
+		output.printf("%s（%d）:\n", title, len(locations))
+		for _, location := range locations {
+			output.printf("  %s %s\n", symbol, location)
+		}
+	}
+	printEndpoints("新增接口", "+", endpoints.Added)
+	printEndpoints("删除接口", "-", endpoints.Removed)
+
+	if len(endpoints.Changed) > 0 {
+		output.println()
+		output.printf("变更接口（%d）:\n", len(endpoints.Changed))
+		for _, endpoint := range endpoints.Changed {
+			output.printf("  ~ %s\n", endpoint)
+			for _, c := range report.Changes {
+				if c.Category != diff.CategoryEndpoint && !strings.HasPrefix(c.Location, "schema ") && c.EndpointLocation() == endpoint {
+					output.printf("      %s\n", c)
+				}
+			}
+		}
+	}
+
+	var schemaChanges []diff.Change
+	for _, c := range report.Changes {
+		if strings.HasPrefix(c.Location, "schema ") {
+			schemaChanges = append(schemaChanges, c)
+		}
+	}
+	if len(schemaChanges) > 0 {
+		output.println()
+		output.printf("数据结构变更（%d）:\n", len(schemaChanges))
+		for _, c := range schemaChanges {
+			output.printf("  %s\n", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/pkg/ast"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newExportCommand() *cobra.Command {
+	var format, file, engine string
+	var asyncAPI bool
+	cmd := &cobra.Command{
+		Use:   "export <项目名>",
+		Short: "生成项目的 OpenAPI 文档并写到文件或标准输出，不同步",
+		Long: `生成项目的 OpenAPI 文档（应用覆盖文件和扩展字段），写到 --file 指定的文件，
+未指定时写到标准输出，解析过程的提示写到标准错误。聚合项目合并各成员最近一次同步的文档。
+--asyncapi 改为导出服务生产、消费的消息通道（@Publishes、@Consumes 注释与 parser.messages）的 AsyncAPI 文档。
+--engine 只导出 parser.engine_mode 为 split 的项目中一个 gin.Engine 的文档，参数为 parser.engine_specs 中的文档名或 engine 变量名。`,
+		Example: `  apidoc export user-service > openapi.json
+  apidoc export user-service --format yaml --file openapi.yaml
+  apidoc export user-service --asyncapi --file asyncapi.json
+  apidoc export user-service --engine admin --file admin.json`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "yaml" {
+				return fmt.Errorf("不支持的格式: %s（json 或 yaml）", format)
+			}
+			if engine != "" && asyncAPI {
+				return fmt.Errorf("--engine 与 --asyncapi 不能同时使用")
+			}
+			configManager := config.NewProjectConfigManager(configDir)
+			projectConfig, err := configManager.LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+
+			// 写到标准输出时，解析过程的提示写到标准错误
+			if file == "" {
+				output = &cliOutput{text: cmd.ErrOrStderr()}
+			}
+			spec, err := buildSpec(configManager, projectConfig)
+			if err != nil {
+				return fmt.Errorf("解析失败: %w", err)
+			}
+			if engine != "" {
+				if projectConfig.Parser.EngineMode != config.EngineModeSplit {
+					return fmt.Errorf("--engine 需要 parser.engine_mode 为 split")
+				}
+				specs := ast.SplitEngines(spec, projectConfig.Parser.EngineSpecs)
+				if specs == nil {
+					return fmt.Errorf("项目的接口不来自多个 gin.Engine")
+				}
+				if specs[engine] == nil {
+					names := make([]string, 0, len(specs))
+					for name := range specs {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					return fmt.Errorf("没有名为 %s 的文档，可选: %s", engine, strings.Join(names, ", "))
+				}
+				spec = specs[engine]
+			}
+
+			var data []byte
+			if asyncAPI {
+				doc := spec.AsyncAPI()
+				if doc == nil {
+					return fmt.Errorf("项目没有声明消息通道（@Publishes、@Consumes 注释或 parser.messages）")
+				}
+				if format == "yaml" {
+					data, err = doc.ToYAML()
+				} else {
+					data, err = json.MarshalIndent(doc, "", "  ")
+					data = append(data, '\n')
+				}
+			} else if format == "yaml" {
+				data, err = spec.ToYAML()
+			} else {
+				data, err = json.MarshalIndent(spec, "", "  ")
+				data = append(data, '\n')
+			}
+			if err != nil {
+				return fmt.Errorf("序列化失败: %w", err)
+			}
+
+			if file == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return err
+			}
+			if asyncAPI {
+				fmt.Fprintf(cmd.OutOrStdout(), "✓ 已导出 %d 个消息通道: %s\n", len(spec.AsyncAPI().Channels), file)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ 已导出 %d 个 API 端点、%d 个数据结构: %s\n", countEndpoints(spec), len(spec.Components.Schemas), file)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "文档格式：json 或 yaml")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "写入的文件，默认写到标准输出")
+	cmd.Flags().BoolVar(&asyncAPI, "asyncapi", false, "导出消息通道的 AsyncAPI 文档")
+	cmd.Flags().StringVar(&engine, "engine", "", "只导出一个 gin.Engine 的文档（parser.engine_mode 为 split）")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
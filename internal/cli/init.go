@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/sync"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [项目名]",
+		Short: "交互式创建项目配置",
+		Long: `依次询问项目名称、仓库地址、本地路径、语言框架和 Apifox 项目，
+校验 Apifox 令牌可以访问该项目后保存到配置目录。`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName := ""
+			if len(args) > 0 {
+				projectName = args[0]
+			}
+			if err := runInit(config.NewProjectConfigManager(configDir), projectName, cmd.InOrStdin()); err != nil {
+				return fmt.Errorf("创建配置失败: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// prompter 从标准输入逐行读取回答
+type prompter struct {
+	reader *bufio.Reader
+}
+
+// ask 显示问题并读取一行回答，直接回车时返回默认值
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", fmt.Errorf("读取输入失败: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// askRequired 读取必填项，check 不为 nil 时校验回答，不通过则重新提问
+func (p *prompter) askRequired(question, def string, check func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			fmt.Println("  ⚠️  不能为空")
+			continue
+		}
+		if check != nil {
+			if err := check(answer); err != nil {
+				fmt.Printf("  ⚠️  %v\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// confirm 读取是/否回答
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := p.ask(question+" ("+hint+")", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// runInit 交互式创建项目配置：依次询问仓库地址、解析框架和 Apifox 项目，
+// 校验 Apifox 令牌可以访问该项目后保存到配置目录
+func runInit(configManager *config.ProjectConfigManager, projectName string, in io.Reader) error {
+	p := &prompter{reader: bufio.NewReader(in)}
+	fmt.Println("🛠  创建项目配置（直接回车使用方括号中的默认值）")
+	fmt.Println()
+
+	name, err := p.askRequired("项目名称", projectName, config.ValidateProjectName)
+	if err != nil {
+		return err
+	}
+	if configManager.IsYAML(name) {
+		return fmt.Errorf("项目 %s 由 %s 定义，请直接编辑该文件", name, configManager.ConfigPath(name))
+	}
+	if configManager.Exists(name) {
+		overwrite, err := p.confirm(fmt.Sprintf("项目 %s 已存在，覆盖现有配置？", name), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("已取消，未修改 %s", configManager.ConfigPath(name))
+		}
+	}
+
+	cfg := &config.ProjectConfig{ProjectName: name}
+	if cfg.Description, err = p.ask("项目描述", ""); err != nil {
+		return err
+	}
+	if cfg.RepoURL, err = p.ask("Git 仓库地址（留空则只分析本地目录）", ""); err != nil {
+		return err
+	}
+	if cfg.LocalPath, err = p.askRequired("本地代码路径", "", nil); err != nil {
+		return err
+	}
+
+	languages := newParserRegistry().List()
+	cfg.Parser.Language, err = p.ask(fmt.Sprintf("语言框架（%s，留空自动识别）", strings.Join(languages, "、")), "")
+	if err != nil {
+		return err
+	}
+	for cfg.Parser.Language != "" && !containsString(languages, cfg.Parser.Language) {
+		fmt.Printf("  ⚠️  不支持的语言框架: %s\n", cfg.Parser.Language)
+		if cfg.Parser.Language, err = p.ask("语言框架（留空自动识别）", ""); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+	checkID := func(id string) error {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return fmt.Errorf("应为数字 ID: %s", id)
+		}
+		return nil
+	}
+	if cfg.Apifox.ProjectID, err = p.askRequired("Apifox 项目 ID", "", checkID); err != nil {
+		return err
+	}
+	if cfg.Apifox.BaseURL, err = p.ask("Apifox API 地址", "https://api.apifox.com"); err != nil {
+		return err
+	}
+	checkMode := func(mode string) error {
+		if mode != "string" && mode != "url" {
+			return fmt.Errorf("应为 string 或 url: %s", mode)
+		}
+		return nil
+	}
+	if cfg.Apifox.SyncMode, err = p.askRequired("同步方式（string 或 url）", "string", checkMode); err != nil {
+		return err
+	}
+
+	// 令牌默认取环境变量 APIFOX_TOKEN，校验不通过时重新输入。
+	// 使用环境变量时配置中保存 env://APIFOX_TOKEN 引用，不写入令牌本身
+	token := os.Getenv("APIFOX_TOKEN")
+	fromEnv := false
+	for {
+		question := "Apifox 令牌"
+		if token != "" {
+			question = "Apifox 令牌（回车使用环境变量 APIFOX_TOKEN）"
+		}
+		answer, err := p.ask(question, "")
+		if err != nil {
+			return err
+		}
+		fromEnv = answer == "" && token != "" && token == os.Getenv("APIFOX_TOKEN")
+		if answer != "" {
+			token = answer
+		}
+		if token == "" {
+			fmt.Println("  ⚠️  不能为空")
+			continue
+		}
+		cfg.Apifox.Token = token
+
+		fmt.Printf("正在校验令牌...\n")
+		err = sync.CheckApifoxToken(&cfg.Apifox)
+		if err == nil {
+			fmt.Printf("✓ 令牌可以访问 Apifox 项目 %s\n", cfg.Apifox.ProjectID)
+			break
+		}
+		fmt.Printf("❌ 令牌校验失败: %v\n", err)
+		retry, err := p.confirm("重新输入令牌？", true)
+		if err != nil {
+			return err
+		}
+		if !retry {
+			return fmt.Errorf("令牌校验未通过，未保存配置")
+		}
+		token = ""
+	}
+	if fromEnv {
+		cfg.Apifox.Token = "env://APIFOX_TOKEN"
+	}
+
+	if err := configManager.SaveProjectConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Printf("✓ 已保存: %s\n", configManager.ConfigPath(name))
+	fmt.Printf("  试运行: apidoc sync %s --config-dir %s --dry-run\n", name, configManager.ConfigDir)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出配置目录中的所有项目",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configManager := config.NewProjectConfigManager(configDir)
+			projects, err := configManager.ListProjects()
+			if err != nil {
+				return fmt.Errorf("获取项目列表失败: %w", err)
+			}
+
+			fmt.Println("📋 可用的项目配置:")
+			fmt.Println()
+			for i, project := range projects {
+				fmt.Printf("  %d. %s\n", i+1, project)
+
+				// 尝试获取项目信息
+				if info, err := configManager.GetProjectInfo(project); err == nil {
+					if desc, ok := info["description"].(string); ok && desc != "" {
+						fmt.Printf("     描述: %s\n", desc)
+					}
+				}
+			}
+			fmt.Println()
+			fmt.Printf("共 %d 个项目\n", len(projects))
+			fmt.Println()
+			fmt.Println("使用方法: apidoc sync <项目名>")
+			return nil
+		},
+	}
+}
+
+func newInfoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "info <项目名>",
+		Short:             "显示项目详细信息",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectConfig, err := config.NewProjectConfigManager(configDir).LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+
+			fmt.Printf("=== %s 项目信息 ===\n", projectConfig.ProjectName)
+			fmt.Println()
+			fmt.Printf("项目名称: %s\n", projectConfig.ProjectName)
+			fmt.Printf("项目描述: %s\n", projectConfig.Description)
+			fmt.Printf("仓库地址: %s\n", projectConfig.RepoURL)
+			fmt.Printf("本地路径: %s\n", projectConfig.LocalPath)
+			fmt.Println()
+			fmt.Printf("语言框架: %s\n", languageLabel(projectConfig.Parser.Language))
+			fmt.Printf("跳过前缀: %v\n", projectConfig.Parser.SkipPrefix)
+			fmt.Println()
+			fmt.Printf("Apifox 项目ID: %s\n", projectConfig.Apifox.ProjectID)
+			fmt.Printf("Apifox API: %s\n", projectConfig.Apifox.BaseURL)
+			fmt.Printf("同步模式: %s\n", projectConfig.Apifox.SyncMode)
+			fmt.Println()
+			return nil
+		},
+	}
+}
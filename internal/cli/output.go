@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
+	"api-doc-generator/internal/sync"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// 输出格式
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// 结果状态
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+)
+
+// cliResult --output json 时在标准输出打印的结果
+type cliResult struct {
+	Project  string                  `json:"project,omitempty"`
+	Command  string                  `json:"command"` // sync、dry-run、diff、validate 或 all
+	Status   string                  `json:"status"`  // success 或 failed
+	Error    string                  `json:"error,omitempty"`
+	Spec     *specSummary            `json:"spec,omitempty"`
+	Analysis *openapi.AnalysisReport `json:"analysis,omitempty"` // 含分析警告
+	Diff     *diffResult             `json:"diff,omitempty"`
+	Lint     *lint.Report            `json:"lint,omitempty"`
+	Problems []string                `json:"problems,omitempty"` // validate 未通过的检查
+	Targets  []targetResult          `json:"targets,omitempty"`
+	Files    map[string]string       `json:"files,omitempty"`    // 保存的文件，如 openapi、diff
+	Projects []*projectRun           `json:"projects,omitempty"` // sync --all 时各项目的结果
+}
+
+// specSummary 生成的文档的规模和类型覆盖率
+type specSummary struct {
+	Endpoints       int     `json:"endpoints"`
+	Schemas         int     `json:"schemas"`
+	CoveragePercent float64 `json:"coverage_percent"`
+}
+
+// diffResult 与上次同步的文档相比的变更
+type diffResult struct {
+	Against   string               `json:"against,omitempty"` // 对比的文档，没有上次同步的文档时为空
+	Summary   string               `json:"summary"`
+	Breaking  int                  `json:"breaking"`
+	Endpoints diff.EndpointChanges `json:"endpoints"`
+	Changes   []diff.Change        `json:"changes"`
+}
+
+// targetResult 一个同步目标的结果
+type targetResult struct {
+	Target string           `json:"target"`
+	Result *sync.SyncResult `json:"result,omitempty"` // 没有导入明细的目标为空
+}
+
+// cliOutput 按 --output 输出结果。JSON 模式下，人类可读的输出（printf、println）写到命令的标准错误，
+// 运行结束（包括失败退出）时在命令的标准输出打印一个 cliResult，供 CI 解析
+type cliOutput struct {
+	json   bool
+	text   io.Writer // 人类可读的输出，为空时写到标准输出
+	stdout io.Writer // cliResult
+	result cliResult
+}
+
+// output 当前命令的输出，支持 --output 的命令解析参数后创建
+var output = &cliOutput{}
+
+// addOutputFlag 为命令添加 --output 选项，执行命令前按选项创建 output
+func addOutputFlag(cmd *cobra.Command) {
+	format := cmd.Flags().String("output", outputText, "输出格式：text 或 json。json 时在标准输出打印结构化结果（数量、变更、同步结果、警告），其余输出写到标准错误")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
+		output, err = newCLIOutput(*format, cmd)
+		return err
+	}
+}
+
+func newCLIOutput(format string, cmd *cobra.Command) (*cliOutput, error) {
+	o := &cliOutput{text: cmd.OutOrStdout(), stdout: cmd.OutOrStdout()}
+	switch format {
+	case "", outputText:
+	case outputJSON:
+		o.json = true
+		o.text = cmd.ErrOrStderr()
+	default:
+		return nil, fmt.Errorf("不支持的输出格式: %s（text 或 json）", format)
+	}
+	return o, nil
+}
+
+// writer 返回人类可读的输出写到的位置
+func (o *cliOutput) writer() io.Writer {
+	if o.text == nil {
+		return os.Stdout
+	}
+	return o.text
+}
+
+func (o *cliOutput) printf(format string, args ...interface{}) {
+	fmt.Fprintf(o.writer(), format, args...)
+}
+
+func (o *cliOutput) println(args ...interface{}) {
+	fmt.Fprintln(o.writer(), args...)
+}
+
+// setSpec 记录生成的文档的规模和分析报告
+func (o *cliOutput) setSpec(spec *openapi.Spec) {
+	o.result.Spec = &specSummary{
+		Endpoints:       countEndpoints(spec),
+		Schemas:         len(spec.Components.Schemas),
+		CoveragePercent: spec.Coverage().Percent(),
+	}
+	o.result.Analysis = spec.AnalysisReport()
+}
+
+// setDiff 记录与 against 对比的变更
+func (o *cliOutput) setDiff(report *diff.Report, against string) {
+	changes := report.Changes
+	if changes == nil {
+		changes = []diff.Change{}
+	}
+	o.result.Diff = &diffResult{
+		Against:   against,
+		Summary:   report.Summary(),
+		Breaking:  len(report.Breaking()),
+		Endpoints: report.Endpoints(),
+		Changes:   changes,
+	}
+}
+
+// addFile 记录保存的文件
+func (o *cliOutput) addFile(kind, path string) {
+	if o.result.Files == nil {
+		o.result.Files = make(map[string]string)
+	}
+	o.result.Files[kind] = path
+}
+
+// finish 结束运行，JSON 模式下输出结果：err 不为空时为失败，否则为成功
+func (o *cliOutput) finish(err error) {
+	if !o.json {
+		return
+	}
+	o.result.Status = statusSuccess
+	if err != nil {
+		o.result.Status = statusFailed
+		o.result.Error = err.Error()
+	}
+	o.printResult()
+}
+
+func (o *cliOutput) printResult() {
+	encoder := json.NewEncoder(o.stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(o.result)
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/parser"
+	"api-doc-generator/internal/parser/gin"
+	"api-doc-generator/internal/parser/graphql"
+	"api-doc-generator/internal/sync"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// analyzeProject 按项目配置选择解析器解析项目，并应用覆盖文件
+func analyzeProject(projectConfig *config.ProjectConfig) (*openapi.Spec, error) {
+	// 检查项目路径
+	if _, err := os.Stat(projectConfig.LocalPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("项目路径不存在: %s", projectConfig.LocalPath)
+	}
+
+	// 创建解析器，未指定语言时根据项目文件自动识别
+	registry := newParserRegistry()
+
+	language := projectConfig.Parser.Language
+	if language == "" {
+		detection, err := registry.Detect(projectConfig.LocalPath, projectConfig.Parser.ExcludeDetectors)
+		if err != nil {
+			return nil, fmt.Errorf("无法识别项目语言: %w", err)
+		}
+		language = detection.Language
+		output.printf("识别为 %s（置信度 %.0f%%: %s）\n", language, detection.Confidence*100, detection.Reason)
+	}
+
+	// 覆盖文件可能添加只被 webhook、回调引用的 schema，未引用的 schema 在应用覆盖文件后再裁剪
+	parserCfg := projectConfig.Parser
+	parserCfg.KeepAllSchemas = true
+	p, err := registry.GetWithConfig(language, parserCfg)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的语言: %s", language)
+	}
+
+	spec, err := p.Analyze(projectConfig.LocalPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := sync.DescribeOperations(spec, projectConfig, projectConfig.LocalPath); err != nil {
+		return nil, err
+	}
+
+	// 应用覆盖文件
+	overlayPath := projectOverlayPath(projectConfig)
+	overlay, err := openapi.LoadOverlay(overlayPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载覆盖文件失败: %w", err)
+	}
+	if overlay != nil {
+		spec.ApplyOverlay(overlay)
+		output.printf("已应用覆盖文件: %s\n", overlayPath)
+	}
+	spec.ApplyExtensions(projectConfig.Extensions)
+	if !projectConfig.Parser.KeepAllSchemas {
+		spec.PruneSchemas()
+	}
+
+	return spec, nil
+}
+
+// buildSpec 生成项目的文档：聚合项目合并各项目最近一次同步的文档，其他项目解析源码
+func buildSpec(configManager *config.ProjectConfigManager, projectConfig *config.ProjectConfig) (*openapi.Spec, error) {
+	if projectConfig.IsAggregate() {
+		return sync.BuildAggregateSpec(configManager, projectConfig)
+	}
+	return analyzeProject(projectConfig)
+}
+
+// resolveGitBranch 返回选择 Apifox 分支使用的 Git 分支：命令行指定的分支，
+// 未指定时为本地仓库的当前分支
+func resolveGitBranch(projectConfig *config.ProjectConfig, branch string) string {
+	if branch == "" && !projectConfig.IsAggregate() {
+		if head, err := git.NewClient("").HeadInfo(projectConfig.LocalPath); err == nil {
+			branch = head.Branch
+		}
+	}
+	return branch
+}
+
+// projectOverlayPath 返回项目覆盖文件的路径，相对路径基于 local_path
+func projectOverlayPath(projectConfig *config.ProjectConfig) string {
+	overlayPath := projectConfig.Overlay
+	if overlayPath == "" {
+		overlayPath = openapi.DefaultOverlayFile
+	}
+	if !filepath.IsAbs(overlayPath) {
+		overlayPath = filepath.Join(projectConfig.LocalPath, overlayPath)
+	}
+	return overlayPath
+}
+
+// mergeProjectSpecs 解析多个项目并合并为一份文档，路径以各项目的 path_prefix（默认 /项目名）划分
+func mergeProjectSpecs(configManager *config.ProjectConfigManager, projectNames []string) (*openapi.Spec, error) {
+	var specs []*openapi.Spec
+	for _, name := range projectNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		projectConfig, err := configManager.LoadProjectConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		output.printf("  - 解析 %s (%s)\n", name, projectConfig.LocalPath)
+		spec, err := analyzeProject(projectConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		spec.PrefixPaths(projectConfig.EffectivePathPrefix())
+		specs = append(specs, spec)
+	}
+
+	merged, err := openapi.Merge(specs...)
+	if err != nil {
+		return nil, err
+	}
+	merged.Info.Title = "Aggregated API Documentation"
+	merged.Info.Description = "Merged from: " + strings.Join(projectNames, ", ")
+	return merged, nil
+}
+
+// saveSpec 保存 OpenAPI 规范到 {data_dir}/output/{name}/openapi.json，返回文件路径和大小
+func saveSpec(name string, spec *openapi.Spec) (string, int, error) {
+	outputFile, err := saveJSON(name, "openapi.json", spec)
+	if err != nil {
+		return "", 0, err
+	}
+	info, err := os.Stat(outputFile)
+	if err != nil {
+		return "", 0, err
+	}
+	return outputFile, int(info.Size()), nil
+}
+
+// saveJSON 将 v 以 JSON 格式保存到 {data_dir}/output/{name}/{file}，返回文件路径
+func saveJSON(name, file string, v interface{}) (string, error) {
+	dir := outputDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	outputFile := filepath.Join(dir, file)
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("JSON 序列化失败: %w", err)
+	}
+
+	if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
+		return "", err
+	}
+	return outputFile, nil
+}
+
+// warningLocation 返回分析警告所属的接口或文件
+func warningLocation(w openapi.AnalysisWarning) string {
+	switch {
+	case w.Path != "":
+		return w.Method + " " + w.Path
+	case w.File != "":
+		return w.File
+	default:
+		return "-"
+	}
+}
+
+// newParserRegistry 创建注册了所有内置解析器的注册表
+func newParserRegistry() *parser.Registry {
+	registry := parser.NewRegistry()
+	registry.Register("go-gin", gin.NewGinParser())
+	registry.Register("graphql", graphql.NewGraphQLParser())
+	return registry
+}
+
+// countEndpoints 统计端点数量
+func countEndpoints(spec *openapi.Spec) int {
+	return spec.OperationCount()
+}
+
+// languageLabel 显示配置的解析语言，未配置时为自动识别
+func languageLabel(language string) string {
+	if language == "" {
+		return "自动识别"
+	}
+	return language
+}
@@ -0,0 +1,101 @@
+// Package cli 实现 apidoc 命令行。serve、sync、validate、diff、export 等子命令共用
+// --config-dir 配置目录、帮助和 shell 补全（apidoc completion bash|zsh|fish|powershell）
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// configDir --config-dir 指定的项目配置目录
+var configDir string
+
+// dataDir 服务配置的数据目录（DATA_DIR），命令行生成的文件保存在其下的 output/ 中
+var dataDir = ".temp"
+
+// Execute 解析命令行参数并执行子命令，返回进程的退出状态。
+// 命令失败时在标准错误打印错误，--output json 时在标准输出打印失败的结果
+func Execute() int {
+	err := newRootCommand().Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+	output.finish(err)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "apidoc",
+		Short: "从源码生成 OpenAPI 文档并同步到 Apifox 等平台",
+		Long: `apidoc 解析 Go/Gin 项目源码生成 OpenAPI 文档，同步到 Apifox、Postman 等平台。
+
+项目配置保存在 --config-dir 目录中，每个项目一个 JSON 或 YAML 文件；
+服务配置从 config.yaml 和环境变量读取。`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			applyDataDir(cmd)
+			return nil
+		},
+	}
+	root.PersistentFlags().StringVar(&configDir, "config-dir", ".temp/configs", "项目配置目录，默认为服务配置的 projects.config_dir")
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return fmt.Errorf("%w（运行 %s --help 查看用法）", err, cmd.CommandPath())
+	})
+
+	root.AddCommand(
+		newServeCommand(),
+		newSyncCommand(),
+		newValidateCommand(),
+		newDiffCommand(),
+		newExportCommand(),
+		newWatchCommand(),
+		newVerifyCommand(),
+		newInitCommand(),
+		newListCommand(),
+		newInfoCommand(),
+		newConfigCommand(),
+		newVersionCommand(),
+	)
+	return root
+}
+
+// applyDataDir 按服务配置使用数据目录：产物写入 {data_dir}/artifacts，未指定 --config-dir 时
+// 使用服务配置的项目配置目录。服务配置加载失败时保留默认目录，需要服务配置的命令会报告错误
+func applyDataDir(cmd *cobra.Command) {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	dataDir = cfg.DataDir
+	sync.ArtifactsDir = cfg.ArtifactsDir()
+	if !cmd.Flags().Changed("config-dir") {
+		configDir = cfg.Projects.ConfigDir
+	}
+}
+
+// outputDir 返回命令行为 name（项目名或 merged）生成的文件的保存目录：{data_dir}/output/{name}
+func outputDir(name string) string {
+	return filepath.Join(dataDir, "output", name)
+}
+
+// completeProjects 补全配置目录中的项目名
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projects, err := config.NewProjectConfigManager(configDir).ListProjects()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return projects, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/server"
+	"api-doc-generator/internal/version"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	var port, configFile string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "启动文档生成服务（Webhook、手动触发和项目配置 API）",
+		Long: `启动 HTTP 服务，接收代码仓库的 Webhook 并在推送后生成和同步文档。
+服务配置从 --config 指定的 YAML 文件（默认为 CONFIG_FILE 或当前目录下的 config.yaml）
+和环境变量读取，环境变量优先，见 config.example.yaml 和 .env.example。
+配置目录中的项目配置变化时自动重新加载。按 Ctrl+C 或发送 SIGTERM 优雅退出。`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg *config.Config
+			var err error
+			if configFile != "" {
+				cfg, err = config.LoadFile(configFile)
+			} else {
+				cfg, err = config.Load()
+			}
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+			if port != "" {
+				cfg.Server.Port = port
+			}
+			return server.Run(cfg)
+		},
+	}
+	cmd.Flags().StringVar(&port, "port", "", "监听端口，覆盖环境变量 SERVER_PORT")
+	cmd.Flags().StringVar(&configFile, "config", "", "服务配置文件（YAML），默认为 CONFIG_FILE 或当前目录下的 config.yaml")
+	return cmd
+}
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "显示版本和构建信息，配置了 UPDATE_FEED_URL 时检查新版本",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Printf("apidoc %s\n", version.Get())
+			cfg, err := config.Load()
+			if err != nil {
+				return nil
+			}
+			status := version.NewChecker(cfg.Server.UpdateFeedURL, time.Hour).Check()
+			switch {
+			case status == nil:
+			case status.Error != "":
+				fmt.Printf("Update check failed: %s\n", status.Error)
+			case status.Available:
+				fmt.Printf("Update available: %s %s\n", status.Latest.Version, status.Latest.URL)
+			default:
+				fmt.Println("Up to date")
+			}
+			return nil
+		},
+	}
+}
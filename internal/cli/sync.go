@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
+	"api-doc-generator/internal/storage"
+	"api-doc-generator/internal/sync"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// syncOptions apidoc sync 的选项
+type syncOptions struct {
+	Save          bool    // 保存 OpenAPI 规范到文件
+	DryRun        bool    // 试运行：保存规范和变更列表，不同步
+	AllowBreaking bool    // 存在破坏性变更时仍然同步
+	Coverage      bool    // 显示各接口的文档覆盖情况
+	BodyLimit     int64   // 网关请求/响应体大小限制（字节）
+	MaxUntyped    float64 // 未推断出类型的接口占比上限，负数时使用 sync.max_untyped_percent
+	Branch        string  // 选择 Apifox 分支使用的 Git 分支
+	Merge         string  // 合并多个项目的文档（逗号分隔的项目名）
+}
+
+func newSyncCommand() *cobra.Command {
+	opts := syncOptions{}
+	var all bool
+	var concurrency int
+	cmd := &cobra.Command{
+		Use:   "sync [项目名]",
+		Short: "解析项目并同步文档到 Apifox 等同步目标",
+		Long: `解析项目源码生成 OpenAPI 文档，通过完整性检查、API 规范检查和破坏性变更检查后
+同步到项目配置的同步目标。聚合项目合并各成员最近一次同步的文档。
+
+--all 同步配置目录中的所有项目；--merge 不指定项目时只合并并保存文档。`,
+		Example: `  apidoc sync user-service
+  apidoc sync user-service --dry-run
+  apidoc sync --all --concurrency 4
+  apidoc sync --merge user-service,order-service`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configManager := config.NewProjectConfigManager(configDir)
+
+			// 同步所有项目，供定时任务统一刷新
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("--all 不能与项目名同时使用")
+				}
+				output.result.Command = "all"
+				return runAll(configManager, concurrency, passThroughArgs(cmd.Flags()))
+			}
+
+			// 仅合并不同步：保存聚合文档后退出
+			if opts.Merge != "" && len(args) == 0 {
+				return runMerge(configManager, opts.Merge)
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("必须指定项目名称，可用 apidoc list 查看所有项目")
+			}
+			return runSync(configManager, args[0], opts)
+		},
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.Save, "save", false, "保存 OpenAPI 规范到文件")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "试运行：解析并保存 OpenAPI 规范和变更列表，不同步到任何目标；检查未通过时以非零状态退出")
+	flags.BoolVar(&opts.AllowBreaking, "allow-breaking", false, "存在破坏性变更时仍然同步（覆盖 sync.fail_on_breaking）")
+	flags.BoolVar(&opts.Coverage, "coverage", false, "显示各接口的文档覆盖情况、请求/响应体最大体积估算和分析警告")
+	flags.Int64Var(&opts.BodyLimit, "body-limit", 1<<20, "网关请求/响应体大小限制（字节），用于标记可能超限的接口")
+	addMaxUntypedFlag(cmd, &opts.MaxUntyped)
+	addBranchFlag(cmd, &opts.Branch)
+	flags.StringVar(&opts.Merge, "merge", "", "合并多个项目的文档（逗号分隔的项目名），按各项目 path_prefix 划分路径")
+	flags.BoolVar(&all, "all", false, "同步配置目录中的所有项目并打印汇总表，其他选项（如 --dry-run）对每个项目生效；任一项目失败时以非零状态退出")
+	flags.IntVar(&concurrency, "concurrency", 4, "--all 时同时同步的项目数")
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// addMaxUntypedFlag 添加 --max-untyped 选项
+func addMaxUntypedFlag(cmd *cobra.Command, maxUntyped *float64) {
+	cmd.Flags().Float64Var(maxUntyped, "max-untyped", -1, "请求/响应体未推断出类型的接口占比上限（百分比），超过时以非零状态退出；默认使用 sync.max_untyped_percent")
+}
+
+// addBranchFlag 添加 --branch 选项
+func addBranchFlag(cmd *cobra.Command, branch *string) {
+	cmd.Flags().StringVar(branch, "branch", "", "按 Git 分支选择 Apifox 分支（apifox.GitBranches），默认为本地仓库当前分支")
+}
+
+// runMerge 解析并合并多个项目的文档，保存到 {data_dir}/output/merged/openapi.json
+func runMerge(configManager *config.ProjectConfigManager, projects string) error {
+	spec, err := mergeProjectSpecs(configManager, strings.Split(projects, ","))
+	if err != nil {
+		return fmt.Errorf("合并失败: %w", err)
+	}
+	outputFile, size, err := saveSpec("merged", spec)
+	if err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+	output.printf("✓ 已合并 %d 个 API 端点、%d 个数据结构\n", countEndpoints(spec), len(spec.Components.Schemas))
+	output.printf("  文件路径: %s\n", outputFile)
+	output.printf("  文件大小: %d bytes\n", size)
+	return nil
+}
+
+// runSync 解析项目并同步到各同步目标，试运行时只保存文档和变更列表
+func runSync(configManager *config.ProjectConfigManager, projectName string, opts syncOptions) error {
+	// 加载项目配置
+	output.result.Project = projectName
+	output.result.Command = "sync"
+	if opts.DryRun {
+		output.result.Command = "dry-run"
+	}
+	output.printf("📖 加载项目配置: %s\n", projectName)
+	projectConfig, err := configManager.LoadProjectConfig(projectName)
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	output.printf("✓ 配置加载成功\n")
+	output.println()
+	maxUntyped := opts.MaxUntyped
+
+	// 步骤 1: 解析项目
+	output.printf("=== 步骤 1: 解析项目 ===\n")
+	output.printf("项目路径: %s\n", projectConfig.LocalPath)
+	output.printf("解析语言: %s\n", languageLabel(projectConfig.Parser.Language))
+	output.println()
+
+	// 解析项目
+	output.println("正在解析代码...")
+	var spec *openapi.Spec
+	if opts.Merge != "" {
+		spec, err = mergeProjectSpecs(configManager, strings.Split(opts.Merge, ","))
+	} else if projectConfig.IsAggregate() {
+		// 聚合项目不解析源码，合并各项目最近一次同步的文档
+		output.printf("聚合项目: %s\n", strings.Join(projectConfig.Aggregate.Projects, ", "))
+		spec, err = sync.BuildAggregateSpec(configManager, projectConfig)
+	} else {
+		spec, err = analyzeProject(projectConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("解析失败: %w", err)
+	}
+
+	output.setSpec(spec)
+	output.printf("✓ 解析完成\n")
+	output.printf("  - 发现 %d 个 API 端点\n", countEndpoints(spec))
+	output.printf("  - 发现 %d 个数据结构\n", len(spec.Components.Schemas))
+
+	// 文档覆盖率与体积估算：标出可能超过网关限制的接口
+	coverage := spec.Coverage()
+	output.printf("  - 类型覆盖率 %.0f%%\n", coverage.Percent())
+	// 列表只在 --coverage 时输出，其余情况只给出数量
+	if oversized := coverage.Oversized(opts.BodyLimit); len(oversized) > 0 {
+		output.printf("  - %d 个接口的请求/响应体上限超过 %d 字节", len(oversized), opts.BodyLimit)
+		if !opts.Coverage {
+			output.println("（--coverage 查看）")
+		} else {
+			output.println(":")
+			for _, c := range oversized {
+				output.printf("      %s %s\n", c.Method, c.Path)
+			}
+		}
+	}
+	// 分析报告：哪些接口的类型是完整推断的，哪些是猜测的
+	analysis := spec.AnalysisReport()
+	output.printf("  - 完整推断 %d 个接口，猜测 %d 个，未推断类型 %d 个，分析警告 %d 条\n",
+		analysis.Inferred, analysis.Guessed, analysis.Untyped, len(analysis.Warnings))
+	if opts.Coverage {
+		output.println()
+		output.printf("%s", coverage.String())
+		if len(analysis.Warnings) > 0 {
+			output.println()
+			output.println("分析警告:")
+			for _, w := range analysis.Warnings {
+				output.printf("  [%s] %s\n", w.Kind, warningLocation(w))
+				output.printf("      %s\n", w.Message)
+			}
+		}
+	}
+	output.println()
+
+	// 步骤 2: 保存到文件（可选，试运行时总是保存）
+	if opts.DryRun {
+		opts.Save = true
+	}
+	if opts.Save {
+		output.printf("=== 步骤 2: 保存 OpenAPI 规范 ===\n")
+		outputFile, size, err := saveSpec(projectName, spec)
+		if err != nil {
+			return fmt.Errorf("保存文件失败: %w", err)
+		}
+
+		coverageFile, err := saveJSON(projectName, "coverage.json", coverage)
+		if err != nil {
+			return fmt.Errorf("保存覆盖率报告失败: %w", err)
+		}
+
+		analysisFile, err := saveJSON(projectName, "analysis.json", analysis)
+		if err != nil {
+			return fmt.Errorf("保存分析报告失败: %w", err)
+		}
+
+		output.addFile("openapi", outputFile)
+		output.addFile("coverage", coverageFile)
+		output.addFile("analysis", analysisFile)
+		output.printf("✓ OpenAPI 规范已保存\n")
+		output.printf("  文件路径: %s\n", outputFile)
+		output.printf("  文件大小: %d bytes\n", size)
+		output.printf("  覆盖率报告: %s\n", coverageFile)
+		output.printf("  分析报告: %s\n", analysisFile)
+		output.println()
+	}
+
+	// 文档完整性检查：未推断出类型的接口过多时中止（CI 中以非零状态退出）
+	if maxUntyped < 0 && projectConfig.Sync.MaxUntypedPercent != nil {
+		maxUntyped = *projectConfig.Sync.MaxUntypedPercent
+	}
+	if maxUntyped >= 0 {
+		if err := coverage.CheckCompleteness(maxUntyped); err != nil {
+			output.println("❌ 以下接口的请求/响应体未推断出类型:")
+			for _, c := range coverage.Untyped() {
+				output.printf("  %s %s\n", c.Method, c.Path)
+			}
+			output.println()
+			return fmt.Errorf("文档完整性检查未通过: %w", err)
+		}
+		output.printf("✓ 文档完整性检查通过（未推断类型的接口占比 %.0f%%，上限 %.0f%%）\n\n", 100-coverage.Percent(), maxUntyped)
+	}
+
+	// API 规范检查：error 级别的违规中止同步（CI 中以非零状态退出）
+	if projectConfig.Lint != nil {
+		lintReport := lint.Run(spec, projectConfig.Lint)
+		output.result.Lint = lintReport
+		if opts.Save {
+			if lintFile, err := saveJSON(projectName, "lint.json", lintReport); err == nil {
+				output.addFile("lint", lintFile)
+				output.printf("规范检查报告: %s\n", lintFile)
+			}
+		}
+		if len(lintReport.Violations) > 0 {
+			output.println(lintReport.String())
+		}
+		if lintReport.HasErrors() {
+			output.println()
+			return fmt.Errorf("API 规范检查未通过: %s", lintReport.Summary())
+		}
+		output.printf("✓ API 规范检查通过（%s）\n\n", lintReport.Summary())
+	}
+
+	// 步骤 3: 同步到各目标（试运行时只与上次同步的文档对比）
+	stage := "同步到 " + strings.Join(projectConfig.Targets(), ", ")
+	if opts.DryRun {
+		stage = "与上次同步的文档对比（试运行，不同步）"
+	}
+	output.printf("=== 步骤 %d: %s ===\n", func() int {
+		if opts.Save {
+			return 3
+		}
+		return 2
+	}(), stage)
+	// 按 Git 分支选择导入的 Apifox 分支和目录
+	branch := resolveGitBranch(projectConfig, opts.Branch)
+	apifoxCfg := projectConfig.Apifox.ForGitBranch(branch)
+	if projectConfig.HasTarget(config.TargetApifox) {
+		output.printf("Apifox 项目ID: %s\n", apifoxCfg.ProjectID)
+		output.printf("同步模式: %s\n", apifoxCfg.SyncMode)
+		if apifoxCfg.Branch != "" {
+			output.printf("Apifox 分支: %s（Git 分支 %s）\n", apifoxCfg.Branch, branch)
+		}
+		if apifoxCfg.APIFolder != "" {
+			output.printf("接口目录: %s\n", apifoxCfg.APIFolder)
+		}
+	}
+	if projectConfig.HasTarget(config.TargetPostman) {
+		output.printf("Postman Workspace: %s\n", projectConfig.Postman.WorkspaceID)
+	}
+	output.println()
+
+	// 与上次同步的文档对比，生成变更日志
+	targets := projectConfig.Targets()
+	var breakingErr error
+	var report *diff.Report
+	lastSpec, lastPath, err := sync.LoadBaseline(apifoxCfg)
+	if err != nil {
+		output.printf("⚠️  读取上次同步的文档失败: %v\n", err)
+	} else if lastSpec != nil {
+		report = diff.Compare(lastSpec, spec)
+		output.setDiff(report, lastPath)
+		output.printf("与上次同步相比 (%s): %s\n", lastPath, report.Summary())
+		if opts.DryRun && report.HasChanges() {
+			output.println(report.String())
+		}
+		output.println()
+
+		if report.HasBreaking() && projectConfig.Sync.FailOnBreaking && !opts.AllowBreaking {
+			output.println("❌ 检测到破坏性变更:")
+			for _, change := range report.Breaking() {
+				output.printf("  %s\n", change)
+			}
+			output.println()
+			breakingErr = fmt.Errorf("检测到 %d 个破坏性变更，确认变更后使用 --allow-breaking 重新执行", len(report.Breaking()))
+			// 配置了预发项目时仍先同步到预发，由检查项拦截正式发布，其余目标不同步
+			if !opts.DryRun {
+				if targets = sync.StagingTargets(targets, projectConfig); targets == nil {
+					return errors.New("已中止同步，确认变更后使用 --allow-breaking 重新执行")
+				}
+				output.println("⚠️  只同步到预发项目，其余同步目标已跳过")
+			}
+		}
+	}
+
+	// 试运行：保存变更列表后结束，不同步到任何目标
+	if opts.DryRun {
+		if report == nil {
+			output.println("没有上次同步的文档，无法生成变更列表")
+		} else {
+			diffFile, err := saveJSON(projectName, "diff.json", report)
+			if err != nil {
+				return fmt.Errorf("保存变更列表失败: %w", err)
+			}
+			output.addFile("diff", diffFile)
+			output.printf("✓ 变更列表已保存: %s\n", diffFile)
+		}
+		if breakingErr != nil {
+			return fmt.Errorf("试运行结束：正式同步将被中止，%v", breakingErr)
+		}
+		output.println("✓ 试运行结束，未同步到任何目标")
+		return nil
+	}
+
+	// 创建服务器配置（用于文档 URL 生成）
+	serverCfg := &config.ServerConfig{
+		PublicURL: "http://localhost:8080",
+	}
+
+	// 对象存储与服务端一样通过 STORAGE_* 环境变量配置，未启用时文档只保存在本地
+	envCfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("加载环境配置失败: %w", err)
+	}
+	store, err := storage.New(envCfg.Storage)
+	if err != nil {
+		return fmt.Errorf("存储配置错误: %w", err)
+	}
+
+	// 执行同步：先构建所有目标，配置有误时在推送任何内容前失败
+	commitMsg := fmt.Sprintf("%s 项目文档同步", projectConfig.ProjectName)
+	syncers, err := sync.DefaultRegistry().Resolve(targets, &sync.Context{
+		Project:  projectConfig,
+		Apifox:   apifoxCfg,
+		Server:   serverCfg,
+		RepoPath: projectConfig.LocalPath,
+		Gates:    []sync.Gate{{Name: "breaking-changes", Check: func(*openapi.Spec) error { return breakingErr }}},
+		Storage:  store,
+		Output:   output.writer(),
+	})
+	if err != nil {
+		return fmt.Errorf("同步目标配置错误: %w", err)
+	}
+	results := make([]*sync.SyncResult, len(syncers))
+	for i, syncer := range syncers {
+		output.printf("正在同步到 %s...\n", targets[i])
+		result, err := syncer.Sync(spec, commitMsg)
+		if err != nil {
+			return fmt.Errorf("同步到 %s 失败: %w", targets[i], err)
+		}
+		results[i] = result
+		output.result.Targets = append(output.result.Targets, targetResult{Target: targets[i], Result: result})
+	}
+
+	// 下次同步与本次的文档对比，与同步到哪些目标无关
+	if err := sync.SaveBaseline(apifoxCfg, spec); err != nil {
+		output.printf("⚠️  保存对比基准失败: %v\n", err)
+	}
+
+	output.println()
+	output.println("✓ 同步成功!")
+	output.println()
+	output.printf("=== 同步摘要 ===\n")
+	output.printf("项目名称: %s\n", projectConfig.ProjectName)
+	output.printf("API 端点: %d 个\n", countEndpoints(spec))
+	output.printf("数据结构: %d 个\n", len(spec.Components.Schemas))
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		output.printf("%s 导入: 新建 %d、更新 %d、失败 %d 个接口；新建 %d、更新 %d、失败 %d 个数据结构\n",
+			targets[i], result.Created, result.Updated, result.Failed,
+			result.SchemasCreated, result.SchemasUpdated, result.SchemasFailed)
+		for _, msg := range result.Errors {
+			output.printf("  ⚠️  %s\n", msg)
+		}
+	}
+	output.println()
+	if projectConfig.HasTarget(config.TargetApifox) {
+		output.printf("📱 在 Apifox 中查看:\n")
+		output.printf("   https://app.apifox.com/project/%s\n", projectConfig.Apifox.ProjectID)
+		output.println()
+	}
+	return nil
+}
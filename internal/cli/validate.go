@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi/lint"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newValidateCommand() *cobra.Command {
+	var maxUntyped float64
+	cmd := &cobra.Command{
+		Use:   "validate <项目名>",
+		Short: "校验项目配置、仓库和生成的文档，不保存也不同步",
+		Long: `检查本地路径和仓库、解析源码、校验生成的 OpenAPI 文档，并执行完整性检查和
+API 规范检查，适合合并前的 CI 检查。有检查未通过时以非零状态退出。`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configManager := config.NewProjectConfigManager(configDir)
+			output.result.Project = args[0]
+			output.result.Command = "validate"
+			projectConfig, err := configManager.LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+			return runValidate(configManager, projectConfig, maxUntyped)
+		},
+	}
+	addMaxUntypedFlag(cmd, &maxUntyped)
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// runValidate 检查项目能否生成可导入的文档：本地路径和仓库、源码解析、OpenAPI 文档校验、
+// 完整性检查和 API 规范检查。只打印报告，不保存也不同步，有检查未通过时返回错误。
+// maxUntyped 为负数时使用 sync.max_untyped_percent
+func runValidate(configManager *config.ProjectConfigManager, projectConfig *config.ProjectConfig, maxUntyped float64) error {
+	ok := true
+	pass := func(format string, args ...interface{}) {
+		output.printf("  ✓ "+format+"\n", args...)
+	}
+	fail := func(format string, args ...interface{}) {
+		ok = false
+		output.result.Problems = append(output.result.Problems, fmt.Sprintf(format, args...))
+		output.printf("  ❌ "+format+"\n", args...)
+	}
+	finish := func() error {
+		output.println()
+		if !ok {
+			return errors.New("校验未通过")
+		}
+		output.println("✓ 校验通过")
+		return nil
+	}
+
+	output.printf("=== 校验项目: %s ===\n", projectConfig.ProjectName)
+	output.println()
+
+	// 1. 本地路径与代码仓库
+	output.println("[1/4] 代码仓库")
+	if projectConfig.IsAggregate() {
+		pass("聚合项目，合并 %s 最近一次同步的文档", strings.Join(projectConfig.Aggregate.Projects, ", "))
+	} else {
+		if _, err := os.Stat(projectConfig.LocalPath); err != nil {
+			fail("本地路径不可用: %v", err)
+		} else {
+			pass("本地路径: %s", projectConfig.LocalPath)
+			if head, err := git.NewClient("").HeadInfo(projectConfig.LocalPath); err == nil {
+				pass("当前提交: %s（分支 %s）", head.ShortHash, head.Branch)
+			} else {
+				output.println("  - 本地路径不是 Git 仓库")
+			}
+		}
+		if projectConfig.RepoURL != "" {
+			auth := git.Auth{Token: projectConfig.GitAuth.Token, TokenUser: projectConfig.GitAuth.TokenUser, SSHKey: projectConfig.GitAuth.SSHKey}
+			if err := git.NewClient("").WithAuth(auth).CheckRemote(projectConfig.RepoURL); err != nil {
+				fail("仓库无法访问: %v", err)
+			} else {
+				pass("仓库可访问: %s", projectConfig.RepoURL)
+			}
+		}
+		if !ok {
+			return finish()
+		}
+	}
+	output.println()
+
+	// 2. 解析源码
+	output.println("[2/4] 解析")
+	spec, err := buildSpec(configManager, projectConfig)
+	if err != nil {
+		fail("解析失败: %v", err)
+		return finish()
+	}
+	pass("发现 %d 个 API 端点、%d 个数据结构", countEndpoints(spec), len(spec.Components.Schemas))
+	output.setSpec(spec)
+	analysis := output.result.Analysis
+	output.printf("  - %s\n", analysis.Summary())
+	for _, w := range analysis.Warnings {
+		output.printf("      [%s] %s: %s\n", w.Kind, warningLocation(w), w.Message)
+	}
+	output.println()
+
+	// 3. OpenAPI 文档校验与完整性检查
+	output.println("[3/4] 文档校验")
+	if err := spec.Validate(); err != nil {
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			fail("%s", problem)
+		}
+	} else {
+		pass("OpenAPI 文档有效")
+	}
+	if maxUntyped < 0 && projectConfig.Sync.MaxUntypedPercent != nil {
+		maxUntyped = *projectConfig.Sync.MaxUntypedPercent
+	}
+	coverage := spec.Coverage()
+	if maxUntyped >= 0 {
+		if err := coverage.CheckCompleteness(maxUntyped); err != nil {
+			fail("文档完整性检查未通过: %v", err)
+			for _, c := range coverage.Untyped() {
+				output.printf("      %s %s\n", c.Method, c.Path)
+			}
+		} else {
+			pass("文档完整性检查通过（未推断类型的接口占比 %.0f%%，上限 %.0f%%）", 100-coverage.Percent(), maxUntyped)
+		}
+	} else {
+		output.printf("  - 类型覆盖率 %.0f%%，未配置完整性检查\n", coverage.Percent())
+	}
+	output.println()
+
+	// 4. API 规范检查
+	output.println("[4/4] API 规范检查")
+	if projectConfig.Lint == nil {
+		output.println("  - 未配置 lint，跳过")
+	} else {
+		report := lint.Run(spec, projectConfig.Lint)
+		output.result.Lint = report
+		for _, v := range report.Violations {
+			output.printf("      %s\n", v)
+		}
+		if report.HasErrors() {
+			fail("API 规范检查未通过: %s", report.Summary())
+		} else {
+			pass("API 规范检查通过（%s）", report.Summary())
+		}
+	}
+	return finish()
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/contract"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newVerifyCommand() *cobra.Command {
+	var baseURL string
+	cmd := &cobra.Command{
+		Use:   "verify <项目名>",
+		Short: "契约测试：按最近一次同步的文档请求被测环境的接口",
+		Long: `按最近一次同步的文档请求 verify.base_url 的接口，检查响应与文档是否一致。
+有接口不一致时以非零状态退出。`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectConfig, err := config.NewProjectConfigManager(configDir).LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+			return runVerify(projectConfig, baseURL)
+		},
+	}
+	cmd.Flags().StringVar(&baseURL, "url", "", "被测环境地址，覆盖 verify.base_url")
+	return cmd
+}
+
+// runVerify 按文档请求被测环境的接口并打印结果，有接口与文档不一致时返回错误。
+// 使用最近一次同步的文档，从未同步过时解析当前源码
+func runVerify(projectConfig *config.ProjectConfig, baseURL string) error {
+	verifyCfg := projectConfig.Verify.WithBaseURL(baseURL)
+
+	spec, specPath, err := sync.LoadLastSyncedSpec(&projectConfig.Apifox)
+	if err != nil {
+		return fmt.Errorf("读取上次同步的文档失败: %w", err)
+	}
+	if spec == nil {
+		fmt.Println("项目尚未同步过文档，解析当前源码...")
+		if spec, err = analyzeProject(projectConfig); err != nil {
+			return fmt.Errorf("解析失败: %w", err)
+		}
+	} else {
+		fmt.Printf("文档: %s\n", specPath)
+	}
+
+	fmt.Printf("=== 契约测试: %s ===\n", verifyCfg.BaseURL)
+	report, err := contract.New(verifyCfg).Verify(spec)
+	if err != nil {
+		return fmt.Errorf("契约测试失败: %w", err)
+	}
+	for _, result := range report.Results {
+		switch result.Outcome {
+		case contract.Passed:
+			fmt.Printf("  ✓ %s %s → %d (%s)\n", result.Method, result.Path, result.Status, result.Duration)
+		case contract.Failed:
+			fmt.Printf("  ❌ %s %s", result.Method, result.Path)
+			if result.Status != 0 {
+				fmt.Printf(" → %d", result.Status)
+			}
+			fmt.Println()
+			for _, problem := range result.Problems {
+				fmt.Printf("      %s\n", problem)
+			}
+		default:
+			fmt.Printf("  - %s %s: %s\n", result.Method, result.Path, strings.Join(result.Problems, "; "))
+		}
+	}
+	fmt.Println()
+	fmt.Printf("通过 %d，失败 %d，跳过 %d\n", report.Passed, report.Failed, report.Skipped)
+	if !report.OK() {
+		return fmt.Errorf("契约测试未通过: %d 个接口与文档不一致", report.Failed)
+	}
+	return nil
+}
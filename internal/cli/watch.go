@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
+	"api-doc-generator/internal/sync"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// watchDebounce 文件变化后等待的时间，保存多个文件或格式化时只重新生成一次
+const watchDebounce = 300 * time.Millisecond
+
+func newWatchCommand() *cobra.Command {
+	var syncTargets bool
+	var serveAddr string
+	cmd := &cobra.Command{
+		Use:   "watch <项目名>",
+		Short: "监听项目源码，文件变化时重新生成文档并显示变更",
+		Long: `监听项目的 local_path，.go 文件、go.mod 或覆盖文件变化时重新生成文档，
+打印与上一次生成相比的变更并保存到 {data_dir}/output/{项目名}/openapi.json。按 Ctrl+C 退出。
+--sync 时文档变化后同步到项目的同步目标，与 sync 命令一样先经过文档完整性、API 规范和破坏性变更检查。`,
+		Example:           `  apidoc watch user-service --serve :8090`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectConfig, err := config.NewProjectConfigManager(configDir).LoadProjectConfig(args[0])
+			if err != nil {
+				return fmt.Errorf("加载配置失败: %w", err)
+			}
+			return runWatch(projectConfig, syncTargets, serveAddr)
+		},
+	}
+	cmd.Flags().BoolVar(&syncTargets, "sync", false, "文档变化后同步到项目的同步目标（经过与 sync 命令相同的检查）")
+	cmd.Flags().StringVar(&serveAddr, "serve", "", "在该地址提供最新文档页面，例如 :8090")
+	return cmd
+}
+
+// specWatcher 监听项目源码，文件变化时重新生成文档
+type specWatcher struct {
+	project     *config.ProjectConfig
+	syncTargets bool // 文档变化时同步到项目的同步目标
+	overlay     string
+	current     atomic.Pointer[openapi.Spec] // 最近一次生成的文档，--serve 时对外提供
+}
+
+// runWatch 监听项目的 local_path，.go 文件、go.mod 或覆盖文件变化时重新生成文档并保存到
+// {data_dir}/output/{项目名}/openapi.json，打印与上一次生成相比的变更。syncTargets 为 true 时
+// 文档变化后同步到项目的同步目标；serveAddr 不为空时在该地址提供最新文档的页面。按 Ctrl+C 退出
+func runWatch(projectConfig *config.ProjectConfig, syncTargets bool, serveAddr string) error {
+	if projectConfig.IsAggregate() {
+		return fmt.Errorf("聚合项目没有源码，不支持 watch")
+	}
+	root, err := filepath.Abs(projectConfig.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建文件监听失败: %w", err)
+	}
+	defer fsWatcher.Close()
+	if err := watchTree(fsWatcher, root); err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", root, err)
+	}
+
+	w := &specWatcher{project: projectConfig, syncTargets: syncTargets, overlay: projectOverlayPath(projectConfig)}
+	if overlay, err := filepath.Abs(w.overlay); err == nil {
+		w.overlay = overlay
+	}
+	w.regenerate(nil)
+
+	if serveAddr != "" {
+		go w.serve(serveAddr)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	fmt.Printf("👀 正在监听 %s，按 Ctrl+C 退出\n", root)
+
+	var timer <-chan time.Time
+	changed := make(map[string]bool)
+	for {
+		select {
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchTree(fsWatcher, event.Name); err != nil {
+						fmt.Printf("⚠️  监听 %s 失败: %v\n", event.Name, err)
+					}
+					continue
+				}
+			}
+			if event.Has(fsnotify.Chmod) || !w.triggers(event.Name) {
+				continue
+			}
+			changed[event.Name] = true
+			timer = time.After(watchDebounce)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("⚠️  文件监听错误: %v\n", err)
+		case <-timer:
+			timer = nil
+			files := make([]string, 0, len(changed))
+			for file := range changed {
+				if rel, err := filepath.Rel(root, file); err == nil {
+					file = rel
+				}
+				files = append(files, file)
+			}
+			changed = make(map[string]bool)
+			w.regenerate(files)
+		case <-interrupt:
+			fmt.Println()
+			fmt.Println("已停止监听")
+			return nil
+		}
+	}
+}
+
+// watchTree 监听 dir 及其子目录，跳过隐藏目录、vendor、node_modules 和 testdata
+func watchTree(fsWatcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+			return filepath.SkipDir
+		}
+		return fsWatcher.Add(path)
+	})
+}
+
+// triggers 判断文件变化是否需要重新生成文档
+func (w *specWatcher) triggers(path string) bool {
+	base := filepath.Base(path)
+	if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") {
+		return false // 编辑器的临时文件
+	}
+	return strings.HasSuffix(base, ".go") || base == "go.mod" || path == w.overlay
+}
+
+// regenerate 重新生成文档，解析失败时保留上一次的文档继续监听
+func (w *specWatcher) regenerate(files []string) {
+	fmt.Println()
+	if len(files) > 0 {
+		fmt.Printf("=== [%s] 文件变化: %s ===\n", time.Now().Format("15:04:05"), strings.Join(files, ", "))
+	} else {
+		fmt.Printf("=== [%s] 生成文档 ===\n", time.Now().Format("15:04:05"))
+	}
+
+	started := time.Now()
+	spec, err := analyzeProject(w.project)
+	if err != nil {
+		fmt.Printf("❌ 解析失败: %v\n", err)
+		return
+	}
+	analysis := spec.AnalysisReport()
+	fmt.Printf("✓ 生成完成（%s）: %d 个 API 端点、%d 个数据结构，%s\n",
+		time.Since(started).Round(time.Millisecond), countEndpoints(spec), len(spec.Components.Schemas), analysis.Summary())
+
+	previous := w.current.Load()
+	if previous != nil {
+		report := diff.Compare(previous, spec)
+		if !report.HasChanges() {
+			fmt.Println("文档没有变化")
+			return
+		}
+		fmt.Printf("变更: %s\n", report.Summary())
+		fmt.Println(report.String())
+	}
+	w.current.Store(spec)
+
+	outputFile, _, err := saveSpec(w.project.ProjectName, spec)
+	if err != nil {
+		fmt.Printf("❌ 保存文件失败: %v\n", err)
+	} else {
+		fmt.Printf("已保存: %s\n", outputFile)
+	}
+
+	if w.syncTargets {
+		w.sync(spec)
+	}
+}
+
+// sync 将文档同步到项目的同步目标，失败时只打印错误。与 sync 命令一样，文档完整性检查、
+// API 规范检查未通过或检测到破坏性变更（sync.fail_on_breaking）时不同步；配置了预发项目时
+// 破坏性变更仍同步到预发，由检查项拦截正式发布，其余目标不同步
+func (w *specWatcher) sync(spec *openapi.Spec) {
+	if limit := w.project.Sync.MaxUntypedPercent; limit != nil {
+		if err := spec.Coverage().CheckCompleteness(*limit); err != nil {
+			fmt.Printf("❌ 文档完整性检查未通过，未同步: %v\n", err)
+			return
+		}
+	}
+	if w.project.Lint != nil {
+		if lintReport := lint.Run(spec, w.project.Lint); lintReport.HasErrors() {
+			fmt.Println(lintReport.String())
+			fmt.Printf("❌ API 规范检查未通过，未同步: %s\n", lintReport.Summary())
+			return
+		}
+	}
+
+	branch := resolveGitBranch(w.project, "")
+	apifoxCfg := w.project.Apifox.ForGitBranch(branch)
+	targets := w.project.Targets()
+	var breakingErr error
+	if lastSpec, _, err := sync.LoadBaseline(apifoxCfg); err != nil {
+		fmt.Printf("⚠️  读取上次同步的文档失败: %v\n", err)
+	} else if lastSpec != nil {
+		if report := diff.Compare(lastSpec, spec); report.HasBreaking() && w.project.Sync.FailOnBreaking {
+			breakingErr = fmt.Errorf("检测到 %d 个破坏性变更，确认变更后使用 sync --allow-breaking 同步", len(report.Breaking()))
+			if targets = sync.StagingTargets(targets, w.project); targets == nil {
+				fmt.Printf("❌ %v，未同步\n", breakingErr)
+				return
+			}
+		}
+	}
+
+	syncers, err := sync.DefaultRegistry().Resolve(targets, &sync.Context{
+		Project:  w.project,
+		Apifox:   apifoxCfg,
+		Server:   &config.ServerConfig{PublicURL: "http://localhost:8080"},
+		RepoPath: w.project.LocalPath,
+		Gates:    []sync.Gate{{Name: "breaking-changes", Check: func(*openapi.Spec) error { return breakingErr }}},
+	})
+	if err != nil {
+		fmt.Printf("❌ 同步目标配置错误: %v\n", err)
+		return
+	}
+	commitMsg := fmt.Sprintf("%s 项目文档同步（watch）", w.project.ProjectName)
+	failed := false
+	for i, syncer := range syncers {
+		if _, err := syncer.Sync(spec, commitMsg); err != nil {
+			fmt.Printf("❌ 同步到 %s 失败: %v\n", targets[i], err)
+			failed = true
+			continue
+		}
+		fmt.Printf("✓ 已同步到 %s\n", targets[i])
+	}
+	// 全部目标同步成功后才作为下次对比的基准
+	if !failed {
+		if err := sync.SaveBaseline(apifoxCfg, spec); err != nil {
+			fmt.Printf("⚠️  保存对比基准失败: %v\n", err)
+		}
+	}
+}
+
+// serve 在 addr 提供最新文档：/ 为 HTML 页面（html.renderer），/openapi.json 为文档本身，
+// 刷新页面即可看到重新生成的结果
+func (w *specWatcher) serve(addr string) {
+	renderer := w.project.HTML.Renderer
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(rw).Encode(w.currentSpec())
+	})
+	mux.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.Header().Set("Cache-Control", "no-store")
+		if err := sync.RenderHTML(rw, renderer, w.currentSpec(), ""); err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	fmt.Printf("📖 文档页面: http://%s/\n", displayAddr(addr))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Printf("❌ 文档页面服务启动失败: %v\n", err)
+	}
+}
+
+// currentSpec 返回最近一次生成的文档，尚未生成成功时为空文档
+func (w *specWatcher) currentSpec() *openapi.Spec {
+	if spec := w.current.Load(); spec != nil {
+		return spec
+	}
+	return openapi.NewSpec()
+}
+
+// displayAddr 将 :8090 形式的监听地址显示为 localhost:8090
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
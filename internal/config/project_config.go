@@ -1,33 +1,349 @@
 package config
 
 import (
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/lint"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 )
 
 // ProjectConfig 项目级别的配置
 type ProjectConfig struct {
-	ProjectName string        `json:"project_name"`
-	RepoURL     string        `json:"repo_url"`
-	LocalPath   string        `json:"local_path"`
-	Description string        `json:"description"`
-	Apifox      ApifoxConfig  `json:"apifox"`
-	Parser      ParserConfig  `json:"parser"`
+	ProjectName string           `json:"project_name"`
+	RepoURL     string           `json:"repo_url"`
+	GitAuth     GitAuthConfig    `json:"git_auth"` // 私有仓库的克隆凭据
+	LocalPath   string           `json:"local_path"`
+	Description string           `json:"description"`
+	Team        string           `json:"team"` // 所属团队，产物保存在数据目录的 artifacts/teams/{team}/ 下
+	Apifox      ApifoxConfig     `json:"apifox"`
+	Parser      ParserConfig     `json:"parser"`
+	Overlay     string           `json:"overlay"`     // 覆盖文件路径，相对 local_path，默认 openapi-overrides.yaml
+	PathPrefix  string           `json:"path_prefix"` // 与其他项目合并文档时的路径前缀，默认 /项目名
+	Sync        SyncConfig       `json:"sync"`
+	SyncTargets []string         `json:"sync_targets"` // 同步目标：apifox、postman、swaggerhub、html、git、export、asyncapi、engines，默认只同步到 apifox
+	Postman     PostmanConfig    `json:"postman"`
+	SwaggerHub  SwaggerHubConfig `json:"swaggerhub"`
+	HTML        HTMLConfig       `json:"html"`
+	GitPush     GitPushConfig    `json:"git_push"`
+	Exports     []ExportConfig   `json:"exports"`   // 自定义模板导出，同步目标为 export
+	Verify      VerifyConfig     `json:"verify"`    // 契约测试（verify）的被测环境
+	Type        string           `json:"type"`      // service（默认）或 aggregate
+	Aggregate   AggregateConfig  `json:"aggregate"` // type 为 aggregate 时合并的项目
+	Staging     *ApifoxConfig    `json:"staging"`   // 预发 Apifox 项目，配置后先同步到预发、检查通过再同步到 apifox
+	Callbacks   []CallbackConfig `json:"callbacks"` // 运行结束后接收结果的回调地址
+	// Notifications 运行结束后发送同步摘要的 Slack、钉钉、飞书机器人
+	Notifications []NotificationConfig `json:"notifications"`
+	// SparseCheckout 克隆 repo_url 时只检出匹配的文件（如 ["**/*.go"]），go.mod、go.sum 和覆盖文件总会检出
+	SparseCheckout []string `json:"sparse_checkout"`
+	// Extensions 注入文档的自定义扩展字段（如 x-api-owner、x-service-tier），
+	// 可以设置在文档、tag 或按路径/方法/tag 匹配的接口上
+	Extensions *openapi.ExtensionRules `json:"extensions"`
+	// Lint 对生成的文档执行 API 规范检查，error 级别的违规会中止同步；未配置时不检查
+	Lint *lint.Config `json:"lint"`
+	// DescriptionTemplate 接口 description 的 Go 模板，在应用覆盖文件之前渲染，可用字段见
+	// openapi.DescriptionData（Handler、SourceFile、Team、ShortCommit 等）；为空时保留生成的描述
+	DescriptionTemplate string `json:"description_template"`
+
+	// secretRefs 从密钥引用解析出的值 → 引用，保存和通过 API 返回配置时写回引用
+	secretRefs map[string]string
+}
+
+// AggregateConfig 聚合项目配置：将多个已登记项目最近一次同步的文档合并为网关级文档，
+// 各项目的路径按其 path_prefix 划分
+type AggregateConfig struct {
+	Projects []string `json:"projects"`
+}
+
+// 项目类型
+const (
+	ProjectTypeService   = "service"
+	ProjectTypeAggregate = "aggregate"
+)
+
+// IsAggregate 判断是否为聚合项目
+func (c *ProjectConfig) IsAggregate() bool {
+	return c.Type == ProjectTypeAggregate
+}
+
+// IncludesProject 判断聚合项目是否包含指定项目
+func (c *ProjectConfig) IncludesProject(name string) bool {
+	for _, member := range c.Aggregate.Projects {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePathPrefix 返回与其他项目合并时使用的路径前缀，默认 /项目名
+func (c *ProjectConfig) EffectivePathPrefix() string {
+	if c.PathPrefix != "" {
+		return c.PathPrefix
+	}
+	return "/" + c.ProjectName
+}
+
+// 内置的同步目标，其他目标可以通过 sync.Registry 注册
+const (
+	TargetApifox     = "apifox"
+	TargetPostman    = "postman"
+	TargetSwaggerHub = "swaggerhub"
+	TargetHTML       = "html"
+	TargetGit        = "git"
+	TargetExport     = "export"
+	TargetAsyncAPI   = "asyncapi"
+	TargetEngines    = "engines"
+)
+
+// Targets 返回项目的同步目标，未配置时默认为 apifox
+func (c *ProjectConfig) Targets() []string {
+	if len(c.SyncTargets) == 0 {
+		return []string{TargetApifox}
+	}
+	return c.SyncTargets
+}
+
+// HasTarget 判断项目是否同步到指定目标
+func (c *ProjectConfig) HasTarget(target string) bool {
+	for _, t := range c.Targets() {
+		if t == target {
+			return true
+		}
+	}
+	return false
 }
 
 // ParserConfig 解析器配置
 type ParserConfig struct {
-	Language   string   `json:"language"`
-	SkipPaths  []string `json:"skip_paths"`
-	SkipPrefix []string `json:"skip_prefix"`
+	Language         string   `json:"language" yaml:"language"` // 指定解析器，为空时根据项目文件自动识别
+	SkipPaths        []string `json:"skip_paths" yaml:"skip_paths"`
+	SkipPrefix       []string `json:"skip_prefix" yaml:"skip_prefix"`
+	ResolveExternal  bool     `json:"resolve_external" yaml:"resolve_external"`   // 下载依赖模块，解析项目外部定义的类型
+	GoProxy          string   `json:"goproxy" yaml:"goproxy"`                     // 解析外部类型时使用的 GOPROXY
+	GoPrivate        string   `json:"goprivate" yaml:"goprivate"`                 // 解析外部类型时使用的 GOPRIVATE
+	KeepAllSchemas   bool     `json:"keep_all_schemas" yaml:"keep_all_schemas"`   // 保留所有结构体，不裁剪接口未引用的 schema
+	ExcludeDetectors []string `json:"exclude_detectors" yaml:"exclude_detectors"` // 自动识别语言时跳过的检测器（解析器名称）
+	// TypeMappings 自定义类型 -> OpenAPI schema，键为 import 路径.类型、包名.类型或类型名，
+	// 例如 "types.Money": {"type": "object", "properties": {...}}
+	TypeMappings map[string]map[string]interface{} `json:"type_mappings" yaml:"type_mappings"`
+	// EngineMode 项目创建了多个 gin.Engine（如对外 API 和管理后台）时的处理方式：
+	// sections（默认）按 engine 划分 tag 目录，merge 与单个 engine 一样直接合并，
+	// split 每个 engine 另外生成一份文档（见 engines 同步目标与 export --engine）
+	EngineMode string `json:"engine_mode" yaml:"engine_mode"`
+	// EngineSpecs split 模式下 engine 变量名 -> 文档名，如 {"r": "public", "adminEngine": "admin"}；
+	// 未列出的 engine 以变量名命名，多个 engine 可以合并到同一份文档
+	EngineSpecs map[string]string `json:"engine_specs" yaml:"engine_specs"`
+	// Mode 分析方式：ast（默认）只解析语法树；deep 通过 go/packages 加载完整类型信息，
+	// 准确解析类型别名、接口实现、跨包类型与泛型，需要 go 工具链并能下载依赖
+	Mode string `json:"mode" yaml:"mode"`
+	// MaxFiles 分析的 Go 文件数上限，超出时分析失败；0 使用默认值，负数表示不限制
+	MaxFiles int `json:"max_files" yaml:"max_files"`
+	// MaxFileSize 单个文件的大小上限（字节），更大的文件（通常是生成的代码）不解析；0 使用默认值，负数表示不限制
+	MaxFileSize int64 `json:"max_file_size" yaml:"max_file_size"`
+	// SourceLinks 在每个接口上添加 x-source-file / x-source-line，指向 handler 的定义
+	SourceLinks bool `json:"source_links" yaml:"source_links"`
+	// IncludeStatic 将 r.Static、r.StaticFS、r.StaticFile 挂载的静态文件作为 GET 接口写入文档，默认不写入。
+	// NoRoute / NoMethod 的 handler 总是不写入文档
+	IncludeStatic bool `json:"include_static" yaml:"include_static"`
+	// FoldPathCase 只有大小写不同的路径（/Users 与 /users）合并为一个，以先出现的写法为准并记录警告。
+	// gin 的路由区分大小写，默认不合并
+	FoldPathCase bool `json:"fold_path_case" yaml:"fold_path_case"`
+	// BasePath 服务部署在网关路径下时（如 /api/payments）加在所有接口路径前的前缀，
+	// tag 仍按去掉前缀的路径推断
+	BasePath string `json:"base_path" yaml:"base_path"`
+	// VersionTags 同一资源出现在多个 API 版本下（/api/v1/users 与 /api/v2/users）时，
+	// 在 tag 后加上版本（Users v1、Users v2），Apifox 中按版本分目录
+	VersionTags bool `json:"version_tags" yaml:"version_tags"`
+	// SummaryTemplate 接口 summary 的 Go 模板，可用字段 Handler、Words、Sentence、Verb、Object、
+	// Method、Path、Tag，tr 函数按 Translations 翻译，例如 {{tr .Verb}}{{tr .Object}}；为空时使用 Words
+	SummaryTemplate string `json:"summary_template" yaml:"summary_template"`
+	// Translations tag 与 summary 的翻译表，如 {"Users": "用户", "Get User": "获取用户"}
+	Translations map[string]string `json:"translations" yaml:"translations"`
+	// Envelope 中间件包装 JSON 响应的统一结构，未配置时为 {code, message, data}
+	Envelope *EnvelopeConfig `json:"envelope" yaml:"envelope"`
+	// Errors 所有接口统一附加的错误响应，未配置时为不带响应体的 400/404/500
+	Errors *ErrorCatalogConfig `json:"errors" yaml:"errors"`
+	// Pagination 分页约定，配置后识别读取分页参数的 GET 接口，统一分页参数并包装列表响应
+	Pagination *PaginationConfig `json:"pagination" yaml:"pagination"`
+	// Messages 服务生产或消费的消息（Kafka topic、RabbitMQ 队列等），与代码中的 @Publishes、@Consumes
+	// 注释一起生成 AsyncAPI 文档，同一通道与方向以配置为准
+	Messages []MessageConfig `json:"messages" yaml:"messages"`
+	// GraphQLPath graphql 解析器记录 GraphQL 接口的路径，默认 /graphql
+	GraphQLPath string `json:"graphql_path" yaml:"graphql_path"`
+}
+
+// MessageConfig 一个消息通道及其消息体结构
+type MessageConfig struct {
+	// Channel topic 或队列名，如 user.created
+	Channel string `json:"channel" yaml:"channel"`
+	// Direction publish 服务生产消息，consume 服务消费消息
+	Direction string `json:"direction" yaml:"direction"`
+	// Protocol 消息协议，如 kafka、amqp，可为空
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// Schema 消息体的结构体名称
+	Schema string `json:"schema" yaml:"schema"`
+	// Summary 消息说明
+	Summary string `json:"summary" yaml:"summary"`
+}
+
+// EnvelopeConfig 统一响应结构：handler 返回的数据放在 DataField 字段中，其余字段由 Properties 声明
+type EnvelopeConfig struct {
+	// Disabled 不包装，响应直接使用 handler 返回的类型
+	Disabled bool `json:"disabled" yaml:"disabled"`
+	// DataField 承载数据的字段名，默认 data
+	DataField string `json:"data_field" yaml:"data_field"`
+	// Properties 其余字段的 OpenAPI schema，如 {"code": {"type": "integer"}, "msg": {"type": "string"}}
+	Properties map[string]map[string]interface{} `json:"properties" yaml:"properties"`
+	// Required 必填字段，可以包含 DataField
+	Required []string `json:"required" yaml:"required"`
+}
+
+// Validate 检查统一响应结构并填充默认的数据字段名
+func (c *EnvelopeConfig) Validate() error {
+	if c.Disabled {
+		return nil
+	}
+	if c.DataField == "" {
+		c.DataField = "data"
+	}
+	if _, ok := c.Properties[c.DataField]; ok {
+		return fmt.Errorf("properties 不能包含数据字段 %s", c.DataField)
+	}
+	for _, name := range c.Required {
+		if _, ok := c.Properties[name]; !ok && name != c.DataField {
+			return fmt.Errorf("required 中的字段 %s 未声明", name)
+		}
+	}
+	return nil
+}
+
+// ErrorCatalogConfig 错误响应目录：状态码与描述，响应体统一引用 ErrorResponse 组件
+type ErrorCatalogConfig struct {
+	// Schema ErrorResponse 的 OpenAPI schema，默认 {code, message}
+	Schema map[string]interface{} `json:"schema" yaml:"schema"`
+	// Responses 状态码到描述，如 {"400": "参数错误", "401": "未登录", "500": "服务器内部错误"}
+	Responses map[string]string `json:"responses" yaml:"responses"`
+}
+
+// Validate 检查错误响应目录的状态码
+func (c *ErrorCatalogConfig) Validate() error {
+	if len(c.Responses) == 0 {
+		return fmt.Errorf("responses 不能为空")
+	}
+	for code, description := range c.Responses {
+		status, err := strconv.Atoi(code)
+		if code != "default" && (err != nil || status < 400 || status > 599) {
+			return fmt.Errorf("状态码 %s 不是 4xx/5xx 或 default", code)
+		}
+		if description == "" {
+			return fmt.Errorf("状态码 %s 缺少描述", code)
+		}
+	}
+	return nil
+}
+
+// PaginationConfig 分页约定：列表接口读取的查询参数名与分页响应的字段名
+type PaginationConfig struct {
+	// PageParam 页码参数，默认 page
+	PageParam string `json:"page_param" yaml:"page_param"`
+	// SizeParam 每页数量参数，默认 page_size
+	SizeParam string `json:"size_param" yaml:"size_param"`
+	// OffsetParam 偏移参数，默认 offset
+	OffsetParam string `json:"offset_param" yaml:"offset_param"`
+	// LimitParam 数量参数，默认 limit
+	LimitParam string `json:"limit_param" yaml:"limit_param"`
+	// DefaultSize 每页数量的默认值，默认 20
+	DefaultSize int `json:"default_size" yaml:"default_size"`
+	// MaxSize 每页数量的上限，0 表示不限制
+	MaxSize int `json:"max_size" yaml:"max_size"`
+	// ItemsField 分页响应中列表的字段名，默认 list
+	ItemsField string `json:"items_field" yaml:"items_field"`
+	// TotalField 分页响应中总数的字段名，默认 total
+	TotalField string `json:"total_field" yaml:"total_field"`
+}
+
+// Validate 检查分页约定并填充默认值
+func (c *PaginationConfig) Validate() error {
+	defaults := []struct {
+		field *string
+		value string
+	}{
+		{&c.PageParam, "page"},
+		{&c.SizeParam, "page_size"},
+		{&c.OffsetParam, "offset"},
+		{&c.LimitParam, "limit"},
+		{&c.ItemsField, "list"},
+		{&c.TotalField, "total"},
+	}
+	for _, d := range defaults {
+		if *d.field == "" {
+			*d.field = d.value
+		}
+	}
+	if c.DefaultSize == 0 {
+		c.DefaultSize = 20
+	}
+	if c.DefaultSize < 0 || c.MaxSize < 0 {
+		return fmt.Errorf("default_size 与 max_size 不能为负数")
+	}
+	if c.MaxSize > 0 && c.DefaultSize > c.MaxSize {
+		return fmt.Errorf("default_size %d 超过 max_size %d", c.DefaultSize, c.MaxSize)
+	}
+	if c.ItemsField == c.TotalField {
+		return fmt.Errorf("items_field 与 total_field 不能相同")
+	}
+	return nil
+}
+
+// 解析器资源限制的默认值
+const (
+	DefaultMaxFiles    = 50000
+	DefaultMaxFileSize = 5 << 20
+)
+
+// FileLimits 返回生效的文件数与文件大小上限，0 表示不限制
+func (c ParserConfig) FileLimits() (maxFiles int, maxFileSize int64) {
+	maxFiles, maxFileSize = c.MaxFiles, c.MaxFileSize
+	if maxFiles == 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	if maxFileSize == 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+	if maxFiles < 0 {
+		maxFiles = 0
+	}
+	if maxFileSize < 0 {
+		maxFileSize = 0
+	}
+	return maxFiles, maxFileSize
 }
 
+// 解析器分析方式
+const (
+	ParserModeAST  = "ast"
+	ParserModeDeep = "deep"
+)
+
+// 多 gin.Engine 的处理方式
+const (
+	EngineModeSections = "sections"
+	EngineModeMerge    = "merge"
+	EngineModeSplit    = "split"
+)
+
 // ProjectConfigManager 项目配置管理器
 type ProjectConfigManager struct {
 	ConfigDir string
 	configs   map[string]*ProjectConfig
+	mu        sync.RWMutex
 }
 
 // NewProjectConfigManager 创建项目配置管理器
@@ -44,16 +360,35 @@ func NewProjectConfigManager(configDir string) *ProjectConfigManager {
 // LoadProjectConfig 加载指定项目的配置
 func (m *ProjectConfigManager) LoadProjectConfig(projectName string) (*ProjectConfig, error) {
 	// 检查缓存
-	if cfg, exists := m.configs[projectName]; exists {
-		return cfg, nil
+	m.mu.RLock()
+	cached, exists := m.configs[projectName]
+	m.mu.RUnlock()
+	if exists {
+		return cached, nil
 	}
 
-	// 构建配置文件路径
-	configPath := filepath.Join(m.ConfigDir, projectName+".json")
+	cfg, err := m.readProjectConfig(projectName)
+	if err != nil {
+		return nil, err
+	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("项目配置文件不存在: %s", configPath)
+	// 缓存配置
+	m.mu.Lock()
+	m.configs[projectName] = cfg
+	m.mu.Unlock()
+
+	return cfg, nil
+}
+
+// readProjectConfig 读取、解密并校验项目配置文件，不使用缓存
+func (m *ProjectConfigManager) readProjectConfig(projectName string) (*ProjectConfig, error) {
+	// 查找配置文件（.json、.yaml 或 .yml）
+	configPath, err := m.findConfigFile(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if configPath == "" {
+		return nil, fmt.Errorf("项目配置文件不存在: %s", m.ConfigPath(projectName))
 	}
 
 	// 读取配置文件
@@ -62,36 +397,345 @@ func (m *ProjectConfigManager) LoadProjectConfig(projectName string) (*ProjectCo
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
+	// YAML 配置转换为 JSON，并展开 ${ENV_VAR} 引用
+	if isYAMLFile(configPath) {
+		if data, err = yamlConfigToJSON(data); err != nil {
+			return nil, fmt.Errorf("配置文件 %s 加载失败: %w", configPath, err)
+		}
+	}
+
+	// 解密 enc:v1: 加密的敏感字段
+	data, err = decryptConfigJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("配置文件 %s 解密失败: %w", configPath, err)
+	}
+
+	// 从 Vault 等密钥管理服务读取 vault://、env:// 等引用的密钥
+	data, refs, err := resolveSecretRefsJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("配置文件 %s 密钥读取失败: %w", configPath, err)
+	}
+
+	// 按 JSON Schema 校验，避免拼错的字段被静默忽略
+	if err := ValidateProjectConfigJSON(data); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 校验失败: %w", configPath, err)
+	}
+
 	// 解析 JSON
 	var cfg ProjectConfig
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
+	cfg.secretRefs = refs
 
 	// 验证必填字段
 	if err := m.validateConfig(&cfg); err != nil {
 		return nil, fmt.Errorf("配置验证失败: %w", err)
 	}
 
-	// 缓存配置
-	m.configs[projectName] = &cfg
-
 	return &cfg, nil
 }
 
+// ValidateProjectName 检查项目名能否用作配置文件名
+func ValidateProjectName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project_name 不能为空")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return fmt.Errorf("project_name 只能包含字母、数字、-、_ 和 .: %s", name)
+		}
+	}
+	if name[0] == '.' {
+		return fmt.Errorf("project_name 不能以 . 开头: %s", name)
+	}
+	return nil
+}
+
+// validSpecName 检查 split 模式的文档名能否用作文件名
+func validSpecName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate 验证配置有效性并补全默认值
+func (m *ProjectConfigManager) Validate(cfg *ProjectConfig) error {
+	if err := ValidateProjectName(cfg.ProjectName); err != nil {
+		return err
+	}
+	return m.validateConfig(cfg)
+}
+
+// Exists 判断项目配置文件是否存在
+func (m *ProjectConfigManager) Exists(projectName string) bool {
+	configPath, _ := m.findConfigFile(projectName)
+	return configPath != ""
+}
+
+// ConfigPath 返回项目配置文件的路径，文件不存在时为新建 JSON 配置的路径
+func (m *ProjectConfigManager) ConfigPath(projectName string) string {
+	if configPath, _ := m.findConfigFile(projectName); configPath != "" {
+		return configPath
+	}
+	return filepath.Join(m.ConfigDir, projectName+".json")
+}
+
+// IsYAML 判断项目是否由 YAML 配置文件定义。YAML 配置通过 ${ENV_VAR} 引用令牌，
+// 不能由 SaveProjectConfig 改写，否则展开后的令牌会写入文件
+func (m *ProjectConfigManager) IsYAML(projectName string) bool {
+	configPath, _ := m.findConfigFile(projectName)
+	return isYAMLFile(configPath)
+}
+
+// findConfigFile 查找项目的配置文件，不存在时返回空字符串，同一项目存在多个配置文件时返回错误
+func (m *ProjectConfigManager) findConfigFile(projectName string) (string, error) {
+	var found []string
+	for _, ext := range projectConfigExts {
+		configPath := filepath.Join(m.ConfigDir, projectName+ext)
+		if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+			found = append(found, configPath)
+		}
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("项目 %s 存在多个配置文件: %s", projectName, strings.Join(found, ", "))
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	return found[0], nil
+}
+
 // validateConfig 验证配置有效性
 func (m *ProjectConfigManager) validateConfig(cfg *ProjectConfig) error {
 	if cfg.ProjectName == "" {
 		return fmt.Errorf("project_name 不能为空")
 	}
-	if cfg.LocalPath == "" {
-		return fmt.Errorf("local_path 不能为空")
+	switch cfg.Type {
+	case "", ProjectTypeService:
+		if cfg.LocalPath == "" {
+			return fmt.Errorf("local_path 不能为空")
+		}
+	case ProjectTypeAggregate:
+		if len(cfg.Aggregate.Projects) == 0 {
+			return fmt.Errorf("aggregate.projects 不能为空")
+		}
+		for _, member := range cfg.Aggregate.Projects {
+			if member == cfg.ProjectName {
+				return fmt.Errorf("聚合项目不能包含自身")
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的项目类型: %s", cfg.Type)
+	}
+	if cfg.Type == "" {
+		cfg.Type = ProjectTypeService
+	}
+	if cfg.Team != "" {
+		if err := ValidateTeamName(cfg.Team); err != nil {
+			return fmt.Errorf("team: %w", err)
+		}
+	}
+	cfg.Apifox.Team, cfg.Apifox.Project = cfg.Team, cfg.ProjectName
+	if cfg.Staging != nil {
+		cfg.Staging.Team, cfg.Staging.Project = cfg.Team, cfg.ProjectName
+	}
+	switch cfg.Parser.EngineMode {
+	case "", EngineModeSections, EngineModeMerge, EngineModeSplit:
+	default:
+		return fmt.Errorf("不支持的 parser.engine_mode: %s", cfg.Parser.EngineMode)
+	}
+	if cfg.Parser.EngineMode == "" {
+		cfg.Parser.EngineMode = EngineModeSections
+	}
+	if len(cfg.Parser.EngineSpecs) > 0 && cfg.Parser.EngineMode != EngineModeSplit {
+		return fmt.Errorf("parser.engine_specs 仅在 parser.engine_mode 为 split 时使用")
+	}
+	for engine, name := range cfg.Parser.EngineSpecs {
+		if !validSpecName(name) {
+			return fmt.Errorf("parser.engine_specs.%s 只能包含字母、数字、- 和 _: %q", engine, name)
+		}
+	}
+	switch cfg.Parser.Mode {
+	case "", ParserModeAST, ParserModeDeep:
+	default:
+		return fmt.Errorf("不支持的 parser.mode: %s", cfg.Parser.Mode)
+	}
+	if cfg.Parser.Mode == "" {
+		cfg.Parser.Mode = ParserModeAST
+	}
+	if cfg.Parser.BasePath != "" {
+		if !strings.HasPrefix(cfg.Parser.BasePath, "/") || strings.ContainsAny(cfg.Parser.BasePath, ":{}*?# ") {
+			return fmt.Errorf("parser.base_path 必须以 / 开头且不能包含路径参数: %s", cfg.Parser.BasePath)
+		}
+		cfg.Parser.BasePath = strings.TrimRight(cfg.Parser.BasePath, "/")
+	}
+	if cfg.Parser.Envelope != nil {
+		if err := cfg.Parser.Envelope.Validate(); err != nil {
+			return fmt.Errorf("parser.envelope 无效: %w", err)
+		}
+	}
+	if cfg.Parser.Errors != nil {
+		if err := cfg.Parser.Errors.Validate(); err != nil {
+			return fmt.Errorf("parser.errors 无效: %w", err)
+		}
+	}
+	if cfg.Parser.GraphQLPath != "" && (!strings.HasPrefix(cfg.Parser.GraphQLPath, "/") || strings.ContainsAny(cfg.Parser.GraphQLPath, ":{}*?# ")) {
+		return fmt.Errorf("parser.graphql_path 必须以 / 开头且不能包含路径参数: %s", cfg.Parser.GraphQLPath)
+	}
+	for i, message := range cfg.Parser.Messages {
+		if message.Channel == "" || message.Schema == "" {
+			return fmt.Errorf("parser.messages[%d] 缺少 channel 或 schema", i)
+		}
+		if message.Direction != openapi.MessagePublish && message.Direction != openapi.MessageConsume {
+			return fmt.Errorf("parser.messages[%d] 的 direction 必须是 publish 或 consume: %s", i, message.Direction)
+		}
+	}
+	if cfg.Parser.Pagination != nil {
+		if err := cfg.Parser.Pagination.Validate(); err != nil {
+			return fmt.Errorf("parser.pagination 无效: %w", err)
+		}
+	}
+	if cfg.Parser.SummaryTemplate != "" {
+		identity := func(s string) string { return s }
+		if _, err := template.New("summary").Funcs(template.FuncMap{"tr": identity}).Parse(cfg.Parser.SummaryTemplate); err != nil {
+			return fmt.Errorf("parser.summary_template 无效: %w", err)
+		}
+	}
+	if cfg.DescriptionTemplate != "" {
+		if _, err := openapi.ParseDescriptionTemplate(cfg.DescriptionTemplate); err != nil {
+			return fmt.Errorf("description_template 无效: %w", err)
+		}
+	}
+	if cfg.Extensions != nil {
+		if err := cfg.Extensions.Validate(); err != nil {
+			return fmt.Errorf("extensions 无效: %w", err)
+		}
+	}
+	if cfg.Lint != nil {
+		if err := cfg.Lint.Validate(); err != nil {
+			return fmt.Errorf("lint 配置无效: %w", err)
+		}
+	}
+	if cfg.HasTarget(TargetApifox) {
+		if cfg.Apifox.Token == "" {
+			return fmt.Errorf("apifox.Token 不能为空")
+		}
+		if cfg.Apifox.ProjectID == "" {
+			return fmt.Errorf("apifox.ProjectID 不能为空")
+		}
+	}
+	if err := cfg.Apifox.validateImportTarget("apifox"); err != nil {
+		return err
 	}
-	if cfg.Apifox.Token == "" {
-		return fmt.Errorf("apifox.Token 不能为空")
+	if cfg.Staging != nil {
+		if cfg.Staging.Token == "" {
+			cfg.Staging.Token = cfg.Apifox.Token
+		}
+		if cfg.Staging.ProjectID == "" {
+			return fmt.Errorf("staging.ProjectID 不能为空")
+		}
+		if cfg.Staging.ProjectID == cfg.Apifox.ProjectID {
+			return fmt.Errorf("staging.ProjectID 不能与 apifox.ProjectID 相同")
+		}
+		if cfg.Staging.BaseURL == "" {
+			cfg.Staging.BaseURL = "https://api.apifox.com"
+		}
+		if cfg.Staging.SyncMode == "" {
+			cfg.Staging.SyncMode = "string"
+		}
+		if err := cfg.Staging.validateImportTarget("staging"); err != nil {
+			return err
+		}
+	}
+	if cfg.HasTarget(TargetPostman) && cfg.Postman.APIKey == "" {
+		return fmt.Errorf("postman.api_key 不能为空")
+	}
+	if cfg.HasTarget(TargetSwaggerHub) {
+		if cfg.SwaggerHub.APIKey == "" {
+			return fmt.Errorf("swaggerhub.api_key 不能为空")
+		}
+		if cfg.SwaggerHub.Owner == "" || cfg.SwaggerHub.API == "" {
+			return fmt.Errorf("swaggerhub.owner 和 swaggerhub.api 不能为空")
+		}
+	}
+	if cfg.SwaggerHub.BaseURL == "" {
+		cfg.SwaggerHub.BaseURL = "https://api.swaggerhub.com"
+	}
+	if cfg.HasTarget(TargetGit) && cfg.GitPush.RepoURL == "" {
+		return fmt.Errorf("git_push.repo_url 不能为空")
+	}
+	for _, format := range cfg.GitPush.Formats {
+		if format != "json" && format != "yaml" {
+			return fmt.Errorf("不支持的 git_push.formats: %s", format)
+		}
+	}
+	if cfg.GitPush.Branch == "" {
+		cfg.GitPush.Branch = "main"
+	}
+	if cfg.GitPush.FileName == "" {
+		cfg.GitPush.FileName = "openapi"
 	}
-	if cfg.Apifox.ProjectID == "" {
-		return fmt.Errorf("apifox.ProjectID 不能为空")
+	if len(cfg.GitPush.Formats) == 0 {
+		cfg.GitPush.Formats = []string{"json"}
+	}
+	if cfg.GitPush.AuthorName == "" {
+		cfg.GitPush.AuthorName = "api-doc-generator"
+	}
+	if cfg.GitPush.AuthorEmail == "" {
+		cfg.GitPush.AuthorEmail = "api-doc-generator@localhost"
+	}
+	if cfg.HasTarget(TargetEngines) && cfg.Parser.EngineMode != EngineModeSplit {
+		return fmt.Errorf("engines 同步目标需要 parser.engine_mode 为 split")
+	}
+	if cfg.HasTarget(TargetExport) && len(cfg.Exports) == 0 {
+		return fmt.Errorf("exports 不能为空")
+	}
+	for i, export := range cfg.Exports {
+		if export.Template == "" || export.Output == "" {
+			return fmt.Errorf("exports[%d] 的 template 和 output 不能为空", i)
+		}
+		if !filepath.IsLocal(export.Template) {
+			return fmt.Errorf("exports[%d].template 必须是相对 local_path 的路径且不能包含 ..: %s", i, export.Template)
+		}
+		if !filepath.IsLocal(export.Output) {
+			return fmt.Errorf("exports[%d].output 必须是相对路径且不能包含 ..: %s", i, export.Output)
+		}
+	}
+	for i, callback := range cfg.Callbacks {
+		if !strings.HasPrefix(callback.URL, "http://") && !strings.HasPrefix(callback.URL, "https://") {
+			return fmt.Errorf("callbacks[%d].url 必须是 http(s) 地址: %s", i, callback.URL)
+		}
+	}
+	for i, notification := range cfg.Notifications {
+		switch notification.Type {
+		case NotifySlack, NotifyDingTalk, NotifyFeishu:
+		default:
+			return fmt.Errorf("不支持的 notifications[%d].type: %s", i, notification.Type)
+		}
+		if !strings.HasPrefix(notification.WebhookURL, "https://") && !strings.HasPrefix(notification.WebhookURL, "http://") {
+			return fmt.Errorf("notifications[%d].webhook_url 必须是 http(s) 地址: %s", i, notification.WebhookURL)
+		}
+	}
+	if cfg.HTML.Renderer == "" {
+		cfg.HTML.Renderer = "redoc"
+	}
+	if cfg.HTML.TryIt.Enabled {
+		if cfg.HTML.TryIt.Upstream == "" {
+			cfg.HTML.TryIt.Upstream = cfg.Verify.BaseURL
+		}
+		if !strings.HasPrefix(cfg.HTML.TryIt.Upstream, "http://") && !strings.HasPrefix(cfg.HTML.TryIt.Upstream, "https://") {
+			return fmt.Errorf("html.try_it.upstream 必须是 http(s) 地址（或配置 verify.base_url）: %s", cfg.HTML.TryIt.Upstream)
+		}
+	}
+	if cfg.Postman.BaseURL == "" {
+		cfg.Postman.BaseURL = "https://api.getpostman.com"
 	}
 	if cfg.Apifox.BaseURL == "" {
 		cfg.Apifox.BaseURL = "https://api.apifox.com"
@@ -99,9 +743,6 @@ func (m *ProjectConfigManager) validateConfig(cfg *ProjectConfig) error {
 	if cfg.Apifox.SyncMode == "" {
 		cfg.Apifox.SyncMode = "string"
 	}
-	if cfg.Parser.Language == "" {
-		cfg.Parser.Language = "go-gin"
-	}
 	return nil
 }
 
@@ -119,17 +760,26 @@ func (m *ProjectConfigManager) ListProjects() ([]string, error) {
 	}
 
 	var projects []string
+	seen := make(map[string]bool)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		// 只处理 .json 文件
-		if filepath.Ext(entry.Name()) == ".json" {
-			// 去掉 .json 后缀
-			projectName := entry.Name()[:len(entry.Name())-5]
-			projects = append(projects, projectName)
+		// 只处理 .json、.yaml 和 .yml 文件
+		ext := filepath.Ext(entry.Name())
+		for _, configExt := range projectConfigExts {
+			if ext != configExt {
+				continue
+			}
+			// 去掉扩展名，同名的多个文件只列出一次，加载时报错
+			projectName := strings.TrimSuffix(entry.Name(), ext)
+			if !seen[projectName] {
+				seen[projectName] = true
+				projects = append(projects, projectName)
+			}
 		}
 	}
+	sort.Strings(projects)
 
 	return projects, nil
 }
@@ -137,7 +787,7 @@ func (m *ProjectConfigManager) ListProjects() ([]string, error) {
 // SaveProjectConfig 保存项目配置
 func (m *ProjectConfigManager) SaveProjectConfig(cfg *ProjectConfig) error {
 	// 验证配置
-	if err := m.validateConfig(cfg); err != nil {
+	if err := m.Validate(cfg); err != nil {
 		return fmt.Errorf("配置验证失败: %w", err)
 	}
 
@@ -146,6 +796,11 @@ func (m *ProjectConfigManager) SaveProjectConfig(cfg *ProjectConfig) error {
 		return fmt.Errorf("创建配置目录失败: %w", err)
 	}
 
+	// YAML 配置只能手动编辑
+	if m.IsYAML(cfg.ProjectName) {
+		return fmt.Errorf("项目 %s 由 %s 定义，请直接编辑该文件", cfg.ProjectName, m.ConfigPath(cfg.ProjectName))
+	}
+
 	// 构建配置文件路径
 	configPath := filepath.Join(m.ConfigDir, cfg.ProjectName+".json")
 
@@ -155,13 +810,66 @@ func (m *ProjectConfigManager) SaveProjectConfig(cfg *ProjectConfig) error {
 		return fmt.Errorf("序列化配置失败: %w", err)
 	}
 
+	// 包含密钥引用时先读取密钥，读取失败则不保存；缓存和 cfg 更新为解析后的配置
+	resolvedData, refs, err := resolveSecretRefsJSON(data)
+	if err != nil {
+		return fmt.Errorf("密钥读取失败: %w", err)
+	}
+	if refs != nil {
+		var resolved ProjectConfig
+		if err := json.Unmarshal(resolvedData, &resolved); err != nil {
+			return fmt.Errorf("解析配置失败: %w", err)
+		}
+		if err := m.validateConfig(&resolved); err != nil {
+			return fmt.Errorf("配置验证失败: %w", err)
+		}
+		resolved.secretRefs = refs
+		*cfg = resolved
+	}
+
+	// 配置了主密钥时加密敏感字段（密钥引用不加密）
+	key, err := LoadMasterKey()
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if data, _, err = EncryptConfigJSON(data, key); err != nil {
+			return fmt.Errorf("加密配置失败: %w", err)
+		}
+	}
+
 	// 写入文件
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("写入配置文件失败: %w", err)
 	}
 
 	// 更新缓存
+	m.mu.Lock()
 	m.configs[cfg.ProjectName] = cfg
+	m.mu.Unlock()
+
+	return nil
+}
+
+// DeleteProjectConfig 删除项目配置文件并移出缓存
+func (m *ProjectConfigManager) DeleteProjectConfig(projectName string) error {
+	configPath, err := m.findConfigFile(projectName)
+	if err != nil {
+		return err
+	}
+	if configPath == "" {
+		configPath = m.ConfigPath(projectName)
+	}
+	if err := os.Remove(configPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("项目配置文件不存在: %s", configPath)
+		}
+		return fmt.Errorf("删除配置文件失败: %w", err)
+	}
+
+	m.mu.Lock()
+	delete(m.configs, projectName)
+	m.mu.Unlock()
 
 	return nil
 }
@@ -176,9 +884,116 @@ func (m *ProjectConfigManager) GetProjectInfo(projectName string) (map[string]in
 	return map[string]interface{}{
 		"project_name": cfg.ProjectName,
 		"description":  cfg.Description,
+		"team":         cfg.Team,
 		"repo_url":     cfg.RepoURL,
 		"local_path":   cfg.LocalPath,
 		"language":     cfg.Parser.Language,
 		"apifox_id":    cfg.Apifox.ProjectID,
 	}, nil
 }
+
+// Reload 重新加载配置目录下的所有项目并替换缓存，返回加载失败的项目及原因。
+// 加载完成后才替换缓存，重新加载期间查询到的仍是原有配置
+func (m *ProjectConfigManager) Reload() (map[string]error, error) {
+	projects, err := m.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]*ProjectConfig)
+	failures := make(map[string]error)
+	for _, name := range projects {
+		cfg, err := m.readProjectConfig(name)
+		if err != nil {
+			failures[name] = err
+			continue
+		}
+		configs[name] = cfg
+	}
+
+	m.mu.Lock()
+	m.configs = configs
+	m.mu.Unlock()
+	return failures, nil
+}
+
+// Loaded 返回当前已加载（缓存中）的项目配置
+func (m *ProjectConfigManager) Loaded() []*ProjectConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	configs := make([]*ProjectConfig, 0, len(m.configs))
+	for _, cfg := range m.configs {
+		configs = append(configs, cfg)
+	}
+	return configs
+}
+
+// FindByRepoURL 根据仓库地址查找已加载的项目配置，HTTPS 与 SSH 地址视为同一仓库
+func (m *ProjectConfigManager) FindByRepoURL(repoURL string) *ProjectConfig {
+	target := NormalizeRepoURL(repoURL)
+	if target == "" {
+		return nil
+	}
+	for _, cfg := range m.Loaded() {
+		if NormalizeRepoURL(cfg.RepoURL) == target {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// RepoURLOwner 返回已用 cfg 的仓库地址登记的其他项目，没有时返回 nil。
+// webhook 按仓库地址查找项目，同一仓库只能属于一个项目
+func (m *ProjectConfigManager) RepoURLOwner(cfg *ProjectConfig) *ProjectConfig {
+	target := NormalizeRepoURL(cfg.RepoURL)
+	if target == "" {
+		return nil
+	}
+	for _, other := range m.Loaded() {
+		if other.ProjectName != cfg.ProjectName && NormalizeRepoURL(other.RepoURL) == target {
+			return other
+		}
+	}
+	return nil
+}
+
+// IsRemoteRepoURL 判断仓库地址是否指向远程仓库（http、https、ssh、git 协议或 scp 风格的 SSH 地址），
+// file:// 和本地路径返回 false
+func IsRemoteRepoURL(repoURL string) bool {
+	u := strings.TrimSpace(strings.ToLower(repoURL))
+	for _, scheme := range []string{"http://", "https://", "ssh://", "git://", "git+ssh://"} {
+		if strings.HasPrefix(u, scheme) {
+			return len(u) > len(scheme) && !strings.HasPrefix(u[len(scheme):], "/")
+		}
+	}
+	if strings.Contains(u, "://") {
+		return false
+	}
+	// scp 风格：[user@]host:path，冒号前不能有 /，且不是 Windows 盘符
+	i := strings.Index(u, ":")
+	return i > 1 && !strings.Contains(u[:i], "/")
+}
+
+// NormalizeRepoURL 将仓库地址规范化为 host/owner/repo 形式，便于比较
+// https://github.com/acme/api.git 与 git@github.com:acme/api.git 均得到 github.com/acme/api
+func NormalizeRepoURL(repoURL string) string {
+	u := strings.TrimSpace(strings.ToLower(repoURL))
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	if i := strings.Index(u, "@"); i >= 0 {
+		u = u[i+1:]
+	}
+	// scp 风格的 SSH 地址 host:owner/repo
+	if i := strings.Index(u, ":"); i >= 0 && !strings.Contains(u[:i], "/") {
+		rest := u[i+1:]
+		// 去掉端口号
+		if j := strings.Index(rest, "/"); j > 0 && strings.Trim(rest[:j], "0123456789") == "" {
+			rest = rest[j+1:]
+		}
+		u = u[:i] + "/" + rest
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	return u
+}
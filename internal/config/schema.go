@@ -0,0 +1,423 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProjectConfigSchemaID 项目配置 JSON Schema 的标识，也是服务对外提供的地址
+const ProjectConfigSchemaID = "/api/v1/schema/project-config"
+
+// JSONSchema JSON Schema (draft-07) 的子集，足以描述项目配置
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+}
+
+// projectConfigDescriptions 字段说明，按 JSON 路径索引
+var projectConfigDescriptions = map[string]string{
+	"project_name":                "项目名称，与配置文件名一致",
+	"repo_url":                    "Git 仓库地址，用于匹配 webhook 推送的仓库",
+	"local_path":                  "项目源码的本地路径（service 项目必填）",
+	"type":                        "项目类型：service 解析源码生成文档，aggregate 合并其他项目的文档",
+	"aggregate":                   "聚合项目配置",
+	"aggregate.projects":          "合并的项目名称，使用各项目最近一次同步的文档",
+	"description":                 "项目描述",
+	"team":                        "所属团队，配置了 tenants 时只有该团队的 API 密钥能访问项目；产物保存在数据目录的 artifacts/teams/{team}/ 下",
+	"apifox":                      "Apifox 同步配置",
+	"apifox.Token":                "Apifox API 令牌",
+	"apifox.ProjectID":            "Apifox 项目 ID",
+	"apifox.BaseURL":              "Apifox 开放 API 地址，默认 https://api.apifox.com",
+	"apifox.SyncMode":             "同步方式：string 直接发送文档内容，url 发送文档地址",
+	"apifox.Branch":               "导入的 Apifox 分支 ID，为空时导入主分支",
+	"apifox.APIFolder":            "导入的接口目录 ID，为空时按 tag 自动创建目录",
+	"apifox.GitBranches":          "按 Git 分支（支持 feature/* 通配）选择导入的 Apifox 分支和目录",
+	"apifox.Retention":            "历史文档和请求日志的保留策略，各项为 0 时不限制，最近一次同步总是保留",
+	"apifox.Retention.KeepLast":   "保留最近 N 次同步",
+	"apifox.Retention.MaxAgeDays": "删除早于 N 天的同步",
+	"apifox.Retention.MaxTotalMB": "目录总大小上限（MB），超出时从最早的同步开始删除",
+	"parser":                      "解析器配置",
+	"parser.language":             "指定解析器，为空时根据项目文件自动识别",
+	"parser.exclude_detectors":    "自动识别语言时跳过的检测器（解析器名称）",
+	"parser.skip_paths":           "跳过的路径",
+	"parser.skip_prefix":          "跳过的路径前缀",
+	"parser.resolve_external":     "下载依赖模块，解析项目外部定义的类型",
+	"parser.goproxy":              "解析外部类型时使用的 GOPROXY",
+	"parser.goprivate":            "解析外部类型时使用的 GOPRIVATE",
+	"parser.keep_all_schemas":     "保留所有结构体，不裁剪接口未引用的 schema",
+	"parser.engine_mode":          "项目创建了多个 gin.Engine 时的处理方式：sections 按 engine 划分 tag 目录，merge 直接合并，split 每个 engine 另外生成一份文档",
+	"parser.mode":                 "分析方式：ast 只解析语法树，deep 加载完整类型信息（需要 go 工具链）",
+	"parser.max_files":            "分析的 Go 文件数上限，超出时分析失败；0 使用默认值 50000，负数表示不限制",
+	"parser.max_file_size":        "单个文件的大小上限（字节），更大的文件不解析；0 使用默认值 5 MB，负数表示不限制",
+	"parser.source_links":         "在每个接口上添加 x-source-file / x-source-line，指向 handler 的定义",
+	"parser.include_static":       "将 r.Static、r.StaticFS、r.StaticFile 挂载的静态文件作为 GET 接口写入文档（tag Static），NoRoute / NoMethod 总是不写入",
+	"parser.fold_path_case":       "只有大小写不同的路径（/Users 与 /users）合并为一个，以先出现的写法为准",
+	"parser.base_path":            "服务部署在网关路径下时加在所有接口路径前的前缀，如 /api/payments",
+	"parser.version_tags":         "同一资源出现在多个 API 版本下时，在 tag 后加上版本（Users v1、Users v2）",
+	"parser.summary_template":     "接口 summary 的 Go 模板，字段 Handler、Words、Sentence、Verb、Object、Method、Path、Tag，tr 函数按 translations 翻译",
+	"parser.translations":         "tag 与 summary 的翻译表，如 {\"Users\": \"用户\"}",
+	"parser.envelope":             "中间件包装 JSON 响应的统一结构，未配置时为 {code, message, data}",
+	"parser.envelope.disabled":    "不包装，响应直接使用 handler 返回的类型",
+	"parser.envelope.data_field":  "承载数据的字段名，默认 data",
+	"parser.envelope.properties":  "其余字段的 OpenAPI schema，如 {\"code\": {\"type\": \"integer\"}}",
+	"parser.envelope.required":    "必填字段，可以包含数据字段",
+	"parser.errors":               "所有接口统一附加的错误响应，未配置时为不带响应体的 400/404/500",
+	"parser.errors.schema":        "错误响应体 ErrorResponse 的 OpenAPI schema，默认 {code, message}",
+	"parser.errors.responses":     "状态码到描述，如 {\"400\": \"参数错误\", \"500\": \"服务器内部错误\"}",
+
+	"parser.pagination":              "分页约定，配置后识别读取分页参数的 GET 接口，统一分页参数并包装列表响应",
+	"parser.pagination.page_param":   "页码参数，默认 page",
+	"parser.pagination.size_param":   "每页数量参数，默认 page_size",
+	"parser.pagination.offset_param": "偏移参数，默认 offset",
+	"parser.pagination.limit_param":  "数量参数，默认 limit",
+	"parser.pagination.default_size": "每页数量的默认值，默认 20",
+	"parser.pagination.max_size":     "每页数量的上限，0 表示不限制",
+	"parser.pagination.items_field":  "分页响应中列表的字段名，默认 list",
+	"parser.pagination.total_field":  "分页响应中总数的字段名，默认 total",
+	"parser.messages":                "服务生产或消费的消息通道，与代码中的 @Publishes、@Consumes 注释一起生成 AsyncAPI 文档",
+	"parser.messages[].channel":      "topic 或队列名，如 user.created",
+	"parser.messages[].direction":    "publish 服务生产消息，consume 服务消费消息",
+	"parser.messages[].protocol":     "消息协议，如 kafka、amqp",
+	"parser.messages[].schema":       "消息体的结构体名称",
+	"parser.messages[].summary":      "消息说明",
+	"parser.graphql_path":            "graphql 解析器记录 GraphQL 接口的路径，默认 /graphql",
+
+	"parser.engine_specs": "split 模式下 engine 变量名 -> 文档名，如 {\"r\": \"public\"}，未列出的 engine 以变量名命名",
+
+	"parser.type_mappings":        "自定义类型对应的 OpenAPI schema，键为 import 路径.类型、包名.类型或类型名",
+	"description_template":        "接口 description 的 Go 模板，字段 Method、Path、Summary、Description、Handler、SourceFile、SourceLine、Project、Team、Branch、Commit、ShortCommit、CommitTime",
+	"overlay":                     "覆盖文件路径，相对 local_path，默认 openapi-overrides.yaml",
+	"path_prefix":                 "与其他项目合并文档时的路径前缀，默认 /项目名",
+	"sync":                        "同步前的检查项",
+	"sync.fail_on_breaking":       "检测到破坏性变更时中止同步",
+	"sync.max_untyped_percent":    "请求/响应体回退为 type: object 的接口占比上限（百分比），超过时中止同步",
+	"sync.paths":                  "推送触发同步的文件过滤规则，如 **/*.go、api/、!**/*_test.go，没有匹配的变更文件时跳过分析",
+	"sync_targets":                "同步目标（内置 apifox、postman、swaggerhub、html、git、export、asyncapi、engines，可注册其他目标），默认只同步到 apifox",
+	"postman":                     "Postman 同步配置",
+	"postman.api_key":             "Postman API Key",
+	"postman.workspace_id":        "Postman Workspace ID",
+	"postman.collection_id":       "要更新的集合 uid，为空时按名称查找或新建",
+	"postman.base_url":            "Postman API 地址，默认 https://api.getpostman.com",
+	"swaggerhub":                  "SwaggerHub 同步配置",
+	"swaggerhub.api_key":          "SwaggerHub API Key",
+	"swaggerhub.owner":            "组织或用户名",
+	"swaggerhub.api":              "API 名称",
+	"swaggerhub.version":          "固定版本号，为空时根据 Git 信息自动生成",
+	"swaggerhub.private":          "是否为私有 API",
+	"swaggerhub.publish":          "发布该版本并设为默认版本",
+	"swaggerhub.base_url":         "SwaggerHub API 地址，默认 https://api.swaggerhub.com",
+	"html":                        "静态 HTML 文档发布配置，发布到项目产物目录的 html/ 下",
+	"html.renderer":               "渲染器：redoc 或 swagger-ui",
+	"html.try_it":                 "Swagger UI 的 Try it out 代理：页面请求经 /api/v1/projects/{name}/try-it/ 转发到 upstream，避免跨域限制",
+	"html.try_it.enabled":         "启用代理，swagger-ui 页面的请求改为发往代理",
+	"html.try_it.upstream":        "转发的目标环境，如 https://api-staging.example.com，为空时使用 verify.base_url",
+	"html.try_it.headers":         "转发时附加的请求头，覆盖页面发送的同名请求头",
+	"html.try_it.bearer_token":    "页面未发送 Authorization 时，以 Authorization: Bearer 发送的令牌",
+	"exports":                     "自定义导出：用 Go text/template 模板渲染文档，同步目标为 export",
+	"exports[].template":          "模板文件路径，相对 local_path",
+	"exports[].output":            "输出文件名，写入项目产物目录的 exports/ 下",
+	"git_auth":                    "克隆 repo_url 使用的凭据，未配置时使用全局 GIT_TOKEN / GIT_SSH_KEY 或 GitHub App",
+	"git_auth.token":              "HTTPS 仓库的访问令牌",
+	"git_auth.token_user":         "令牌对应的用户名，默认 oauth2（Gitee 需填写用户名）",
+	"git_auth.ssh_key":            "SSH 仓库使用的私钥文件路径",
+	"sparse_checkout":             "稀疏检出的文件模式（如 **/*.go、api/），大仓库只检出解析需要的文件，默认使用全局 GIT_SPARSE_CHECKOUT",
+	"git_push":                    "将文档提交到文档仓库（Stoplight、GitHub/Gitee Pages 等），同步目标为 git",
+	"git_push.repo_url":           "文档仓库地址（HTTPS 或 SSH）",
+	"git_push.branch":             "提交的分支，默认 main，不存在时自动创建",
+	"git_push.path":               "文档在仓库中的目录，默认仓库根目录",
+	"git_push.file_name":          "文件名（不含扩展名），默认 openapi",
+	"git_push.formats":            "提交的格式：json、yaml，默认 json",
+	"git_push.token":              "HTTPS 仓库的访问令牌",
+	"git_push.token_user":         "令牌对应的用户名，默认 oauth2（Gitee 需填写用户名）",
+	"git_push.ssh_key":            "SSH 仓库使用的私钥文件路径",
+	"git_push.author_name":        "提交作者，默认 api-doc-generator",
+	"git_push.author_email":       "提交作者邮箱",
+	"staging":                     "预发 Apifox 项目，配置后先同步到预发、检查通过再同步到 apifox",
+	"callbacks":                   "结果回调：运行结束后向 url POST JSON 结果（项目、提交、状态、接口数、变更摘要、各同步目标结果）",
+	"callbacks[].url":             "回调地址",
+	"callbacks[].secret":          "签名密钥，设置后请求带 X-Signature-256: sha256=<HMAC-SHA256(请求体)>",
+	"callbacks[].events":          "触发回调的运行状态，默认 success、failed、aborted",
+	"callbacks[].headers":         "附加请求头，如 Authorization",
+	"notifications":               "聊天机器人通知：运行结束后向 Slack、钉钉或飞书群发送同步摘要，失败时附带错误信息",
+	"notifications[].type":        "机器人类型：slack、dingtalk 或 feishu",
+	"notifications[].webhook_url": "机器人的 Webhook 地址",
+	"notifications[].secret":      "钉钉、飞书机器人的加签密钥，未开启加签时留空",
+	"notifications[].events":      "触发通知的运行状态，默认 success、failed、aborted",
+	"extensions":                  "注入文档的自定义扩展字段，名称必须以 x- 开头",
+	"extensions.spec":             "文档级扩展字段，如 {\"x-api-owner\": \"team-user\"}",
+	"extensions.tags":             "tag 级扩展字段，按 tags 匹配（为空时匹配所有 tag），写入文档的 tags 列表",
+	"extensions.operations":       "接口级扩展字段，按 paths（路径前缀）、methods、tags 匹配，均为空时匹配所有接口，后面的规则覆盖前面的值",
+	"lint":                        "API 规范检查，配置后每次同步检查生成的文档，error 级别的违规中止同步",
+	"lint.rules":                  "规则级别：error、warn 或 off，如 {\"auth-responses\": \"error\"}，未列出的规则使用默认级别 warn",
+	"lint.public_paths":           "无需认证的路径前缀（如 /login），不检查 401/403 响应",
+	"verify":                      "契约测试：按文档请求被测环境的接口，检查响应状态码和响应体是否与文档一致",
+	"verify.base_url":             "被测环境地址，如 https://api-staging.example.com",
+	"verify.headers":              "附加请求头，如 X-Tenant-ID",
+	"verify.bearer_token":         "以 Authorization: Bearer 发送的令牌",
+	"verify.params":               "路径、查询和请求头参数的取值，未配置时使用文档中的示例值",
+	"verify.include_unsafe":       "同时请求 POST/PUT/PATCH/DELETE 接口（会修改数据，仅用于可重置的测试环境）",
+	"verify.skip_paths":           "跳过的路径模板，如 /api/v1/users/{id}",
+}
+
+// projectConfigEnums 取值受限的字段
+var projectConfigEnums = map[string][]interface{}{
+	"apifox.SyncMode":          {"string", "url"},
+	"staging.SyncMode":         {"string", "url"},
+	"html.renderer":            {"redoc", "swagger-ui"},
+	"git_push.formats[]":       {"json", "yaml"},
+	"parser.engine_mode":       {EngineModeSections, EngineModeMerge, EngineModeSplit},
+	"parser.mode":              {ParserModeAST, ParserModeDeep},
+	"type":                     {ProjectTypeService, ProjectTypeAggregate},
+	"callbacks[].events[]":     {RunSuccess, RunFailed, RunAborted, RunSkipped},
+	"notifications[].type":     {NotifySlack, NotifyDingTalk, NotifyFeishu},
+	"notifications[].events[]": {RunSuccess, RunFailed, RunAborted, RunSkipped},
+}
+
+// ProjectConfigSchema 根据 ProjectConfig 结构生成 JSON Schema，字段变化时无需手动维护
+func ProjectConfigSchema() *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(ProjectConfig{}), "")
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.ID = ProjectConfigSchemaID
+	schema.Title = "API Doc Generator project config"
+	schema.Required = []string{"project_name"}
+	return schema
+}
+
+func schemaForType(t reflect.Type, path string) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	schema := &JSONSchema{
+		Description: projectConfigDescriptions[path],
+		Enum:        projectConfigEnums[path],
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		closed := false
+		schema.Type = "object"
+		schema.AdditionalProperties = &closed
+		schema.Properties = make(map[string]*JSONSchema)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			schema.Properties[name] = schemaForType(field.Type, joinSchemaPath(path, name))
+		}
+	case reflect.Slice, reflect.Array:
+		schema.Type = "array"
+		schema.Items = schemaForType(t.Elem(), path+"[]")
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Map:
+		schema.Type = "object"
+	}
+	return schema
+}
+
+// jsonFieldName 返回字段在 JSON 中的名称，未导出或 json:"-" 的字段返回空字符串
+func jsonFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func joinSchemaPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// SchemaError 配置校验错误，Path 为出错位置，例如 $.parser.resolve_external
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// SchemaErrors 多个校验错误
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidateProjectConfigJSON 按 JSON Schema 校验项目配置文件内容，
+// 拼错的字段名、类型错误、不支持的取值都会带着精确路径报告出来
+func ValidateProjectConfigJSON(data []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var errs SchemaErrors
+	validateValue(ProjectConfigSchema(), doc, "$", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateValue(schema *JSONSchema, value interface{}, path string, errs *SchemaErrors) {
+	if value == nil {
+		return
+	}
+
+	if !matchesType(schema.Type, value) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf("应为 %s 类型，实际为 %s", schema.Type, jsonTypeOf(value))})
+		return
+	}
+
+	if len(schema.Enum) > 0 {
+		allowed := false
+		for _, v := range schema.Enum {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf("不支持的取值 %v，可选值: %v", value, schema.Enum)})
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf("缺少必填字段 %s", name)})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propSchema, ok := lookupProperty(schema.Properties, key)
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					*errs = append(*errs, SchemaError{Path: path + "." + key, Message: unknownPropertyMessage(key, schema.Properties)})
+				}
+				continue
+			}
+			validateValue(propSchema, v[key], path+"."+key, errs)
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+// lookupProperty 按字段名查找属性，与 encoding/json 一致，没有完全相同的字段名时不区分大小写匹配
+func lookupProperty(properties map[string]*JSONSchema, key string) (*JSONSchema, bool) {
+	if prop, ok := properties[key]; ok {
+		return prop, true
+	}
+	for name, prop := range properties {
+		if strings.EqualFold(name, key) {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+// unknownPropertyMessage 报告未知字段，并给出最接近的合法字段名
+func unknownPropertyMessage(key string, properties map[string]*JSONSchema) string {
+	best, bestDistance := "", 3
+	for name := range properties {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(key)); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("未知字段（是否为 %q？）", best)
+	}
+	return "未知字段"
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "":
+		return true
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return jsonTypeOf(value) == schemaType
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return "null"
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
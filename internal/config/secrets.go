@@ -0,0 +1,508 @@
+package config
+
+import (
+	"api-doc-generator/internal/secrets"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SecretResolvers 解析配置中的密钥引用：env://VAR、vault://API路径#字段、awssm://密钥ID#字段。
+// 可以注册其他密钥管理服务，如 SecretResolvers.Register("gcpsm", resolver)
+var SecretResolvers = secrets.DefaultRegistry()
+
+// EncryptedPrefix 加密字段值的前缀，格式为 enc:v1:base64(nonce + 密文)，使用 AES-256-GCM
+const EncryptedPrefix = "enc:v1:"
+
+// 主密钥来源：CONFIG_MASTER_KEY 直接给出 32 字节密钥（base64 或 hex），
+// CONFIG_MASTER_KEY_FILE 指向密钥文件，便于由 KMS / Vault Agent / Kubernetes Secret 挂载
+const (
+	MasterKeyEnv     = "CONFIG_MASTER_KEY"
+	MasterKeyFileEnv = "CONFIG_MASTER_KEY_FILE"
+)
+
+// sensitiveFields 项目配置中需要加密的字段路径（键名不区分大小写，与 json.Unmarshal 一致）
+var sensitiveFields = [][]string{
+	{"apifox", "token"},
+	{"staging", "token"},
+	{"postman", "api_key"},
+	{"swaggerhub", "api_key"},
+	{"git_push", "token"},
+	{"git_auth", "token"},
+	{"verify", "bearer_token"},
+	{"html", "try_it", "bearer_token"},
+	{"callbacks", "[]", "secret"},
+	{"notifications", "[]", "webhook_url"},
+	{"notifications", "[]", "secret"},
+}
+
+// SecretMask 通过 API 返回项目配置时敏感字段的占位值，提交时保留该值表示不修改原值
+const SecretMask = "******"
+
+// secretValues 返回项目配置中的敏感字段，顺序固定，未配置 staging 时对应位置为 nil。
+// 需与 sensitiveFields 保持一致
+func (c *ProjectConfig) secretValues() []*string {
+	values := []*string{&c.Apifox.Token, &c.Postman.APIKey, &c.SwaggerHub.APIKey, &c.GitPush.Token, &c.GitAuth.Token, &c.Verify.BearerToken, &c.HTML.TryIt.BearerToken, nil}
+	if c.Staging != nil {
+		values[len(values)-1] = &c.Staging.Token
+	}
+	return values
+}
+
+// secretDestinations 返回 secretValues 中各密钥发往的地址，顺序与 secretValues 一致
+func (c *ProjectConfig) secretDestinations() []string {
+	tryIt := c.HTML.TryIt.Upstream
+	if tryIt == "" {
+		tryIt = c.Verify.BaseURL
+	}
+	destinations := []string{c.Apifox.BaseURL, c.Postman.BaseURL, c.SwaggerHub.BaseURL, c.GitPush.RepoURL, c.RepoURL, c.Verify.BaseURL, tryIt, ""}
+	if c.Staging != nil {
+		destinations[len(destinations)-1] = c.Staging.BaseURL
+	}
+	return destinations
+}
+
+// sameDestination 判断保留的密钥能否发往新地址。新地址为空时使用默认地址（或不发送），
+// 与 VerifyConfig.WithBaseURL 一样，改为其他地址时不带上原密钥
+func sameDestination(next, previous string) bool {
+	return next == "" || strings.TrimSuffix(next, "/") == strings.TrimSuffix(previous, "/")
+}
+
+// maskSecret 返回敏感字段对外显示的值：来自密钥引用的显示引用本身，其余显示 SecretMask
+func (c *ProjectConfig) maskSecret(value string) string {
+	if ref, ok := c.secretRefs[value]; ok {
+		return ref
+	}
+	return SecretMask
+}
+
+// storedSecret 返回敏感字段保存时写入的值：来自密钥引用的写回引用，避免明文落盘
+func (c *ProjectConfig) storedSecret(value string) string {
+	if ref, ok := c.secretRefs[value]; ok {
+		return ref
+	}
+	return value
+}
+
+// Redacted 返回敏感字段替换为 SecretMask（或密钥引用）的副本
+func (c *ProjectConfig) Redacted() *ProjectConfig {
+	redacted := *c
+	if c.Staging != nil {
+		staging := *c.Staging
+		redacted.Staging = &staging
+	}
+	for _, value := range redacted.secretValues() {
+		if value != nil && *value != "" {
+			*value = c.maskSecret(*value)
+		}
+	}
+	redacted.Callbacks = append([]CallbackConfig(nil), c.Callbacks...)
+	for i := range redacted.Callbacks {
+		if redacted.Callbacks[i].Secret != "" {
+			redacted.Callbacks[i].Secret = c.maskSecret(redacted.Callbacks[i].Secret)
+		}
+	}
+	redacted.Notifications = append([]NotificationConfig(nil), c.Notifications...)
+	for i := range redacted.Notifications {
+		for _, value := range []*string{&redacted.Notifications[i].WebhookURL, &redacted.Notifications[i].Secret} {
+			if *value != "" {
+				*value = c.maskSecret(*value)
+			}
+		}
+	}
+	return &redacted
+}
+
+// KeepSecrets 将值为 SecretMask 的敏感字段恢复为 previous 中的原值（来自密钥引用的恢复为引用）。
+// 密钥发往的地址（如 verify.base_url、git_push.repo_url、staging.base_url）改变时清空该字段，
+// 否则只能看到掩码的用户改一下地址就能把原密钥发到自己的服务器
+func (c *ProjectConfig) KeepSecrets(previous *ProjectConfig) {
+	old := previous.secretValues()
+	oldDestinations := previous.secretDestinations()
+	destinations := c.secretDestinations()
+	for i, value := range c.secretValues() {
+		if value == nil || *value != SecretMask {
+			continue
+		}
+		if old[i] != nil && sameDestination(destinations[i], oldDestinations[i]) {
+			*value = previous.storedSecret(*old[i])
+		} else {
+			*value = ""
+		}
+	}
+	// 回调按 URL 对应
+	for i := range c.Callbacks {
+		if c.Callbacks[i].Secret != SecretMask {
+			continue
+		}
+		c.Callbacks[i].Secret = ""
+		for _, callback := range previous.Callbacks {
+			if callback.URL == c.Callbacks[i].URL {
+				c.Callbacks[i].Secret = previous.storedSecret(callback.Secret)
+				break
+			}
+		}
+	}
+	// 机器人地址本身也被隐藏，按位置对应
+	for i := range c.Notifications {
+		var old NotificationConfig
+		if i < len(previous.Notifications) {
+			old = previous.Notifications[i]
+		}
+		if c.Notifications[i].WebhookURL == SecretMask {
+			c.Notifications[i].WebhookURL = previous.storedSecret(old.WebhookURL)
+		} else if c.Notifications[i].WebhookURL != previous.storedSecret(old.WebhookURL) {
+			// 签名密钥只随原机器人地址保留
+			old.Secret = ""
+		}
+		if c.Notifications[i].Secret == SecretMask {
+			c.Notifications[i].Secret = previous.storedSecret(old.Secret)
+		}
+	}
+}
+
+// LoadMasterKey 读取主密钥，未配置时返回 nil
+func LoadMasterKey() ([]byte, error) {
+	value := os.Getenv(MasterKeyEnv)
+	if value == "" {
+		if file := os.Getenv(MasterKeyFileEnv); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("读取主密钥文件失败: %w", err)
+			}
+			value = string(data)
+		}
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	return ParseMasterKey(value)
+}
+
+// ParseMasterKey 解析 base64 或 hex 编码的 32 字节主密钥
+func ParseMasterKey(value string) ([]byte, error) {
+	if key, err := base64.StdEncoding.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("主密钥应为 32 字节的 base64 或 hex 编码")
+}
+
+// GenerateMasterKey 生成新的主密钥（base64 编码）
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue 加密单个字段值
+func EncryptValue(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue 解密 EncryptValue 生成的字段值
+func DecryptValue(key []byte, value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("加密字段格式错误: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("加密字段格式错误")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("解密失败，主密钥不匹配或内容被篡改")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("主密钥无效: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsEncrypted 判断字段值是否已加密
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+// decryptConfigJSON 解密配置文件中所有 enc:v1: 字段，没有加密字段时原样返回
+func decryptConfigJSON(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(EncryptedPrefix)) {
+		return data, nil
+	}
+	key, err := LoadMasterKey()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("配置包含加密字段，但未设置 %s 或 %s", MasterKeyEnv, MasterKeyFileEnv)
+	}
+	return rewriteJSONStrings(data, func(path []string, value string) (string, error) {
+		if !IsEncrypted(value) {
+			return value, nil
+		}
+		plaintext, err := DecryptValue(key, value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		return plaintext, nil
+	})
+}
+
+// EncryptConfigJSON 加密配置文件中尚未加密的敏感字段，保持字段顺序，返回新内容和加密的字段数
+func EncryptConfigJSON(data []byte, key []byte) ([]byte, int, error) {
+	count := 0
+	out, err := rewriteJSONStrings(data, func(path []string, value string) (string, error) {
+		if value == "" || IsEncrypted(value) || SecretResolvers.IsReference(value) || !isSensitiveField(path) {
+			return value, nil
+		}
+		count++
+		return EncryptValue(key, value)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, count, nil
+}
+
+// resolveSecretRefsJSON 将配置文件敏感字段（见 sensitiveFields）中的密钥引用替换为密钥值，
+// 返回新内容和 密钥值 → 引用 的对应关系；没有密钥引用时原样返回，对应关系为 nil。
+// 其他字段不解析，否则 description 等原样返回的字段会把密钥暴露给读取配置的人
+func resolveSecretRefsJSON(data []byte) ([]byte, map[string]string, error) {
+	found := false
+	for _, scheme := range SecretResolvers.Schemes() {
+		if bytes.Contains(data, []byte(`"`+scheme+"://")) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return data, nil, nil
+	}
+	refs := make(map[string]string)
+	out, err := rewriteJSONStrings(data, func(path []string, value string) (string, error) {
+		if !isSensitiveField(path) || !SecretResolvers.IsReference(value) {
+			return value, nil
+		}
+		secret, err := SecretResolvers.Resolve(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		refs[secret] = value
+		return secret, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(refs) == 0 {
+		return data, nil, nil
+	}
+	return out, refs, nil
+}
+
+// SecretRefPaths 返回配置 JSON 中写成密钥引用的字段路径 → 引用。
+// 通过 API 提交的配置只有管理员可以引用服务端的密钥
+func SecretRefPaths(data []byte) (map[string]string, error) {
+	refs := make(map[string]string)
+	_, err := rewriteJSONStrings(data, func(path []string, value string) (string, error) {
+		if SecretResolvers.IsReference(value) {
+			refs[strings.Join(path, ".")] = value
+		}
+		return value, nil
+	})
+	return refs, err
+}
+
+// UsesSecretRef 判断配置的敏感字段是否从该密钥引用读取
+func (c *ProjectConfig) UsesSecretRef(ref string) bool {
+	for _, value := range c.secretRefs {
+		if value == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveServiceSecrets 读取服务配置中以密钥引用给出的令牌和密钥
+func resolveServiceSecrets(cfg *Config) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"git.token", &cfg.Git.Token},
+		{"webhook.secret", &cfg.Webhook.Secret},
+		{"apifox.token", &cfg.Apifox.Token},
+		{"storage.access_key_id", &cfg.Storage.AccessKeyID},
+		{"storage.secret_access_key", &cfg.Storage.SecretAccessKey},
+		{"storage.session_token", &cfg.Storage.SessionToken},
+		{"github.token", &cfg.GitHub.Token},
+		{"metrics.push_token", &cfg.Metrics.PushToken},
+		{"history.dsn", &cfg.History.DSN},
+	}
+	for i := range cfg.Tenants {
+		for j := range cfg.Tenants[i].APIKeys {
+			fields = append(fields, struct {
+				name  string
+				value *string
+			}{fmt.Sprintf("tenants[%d].api_keys[%d]", i, j), &cfg.Tenants[i].APIKeys[j]})
+		}
+	}
+	for _, field := range fields {
+		secret, err := SecretResolvers.Resolve(*field.value)
+		if err != nil {
+			return fmt.Errorf("%s 密钥读取失败: %w", field.name, err)
+		}
+		*field.value = secret
+	}
+	return nil
+}
+
+func isSensitiveField(path []string) bool {
+	for _, field := range sensitiveFields {
+		if len(field) != len(path) {
+			continue
+		}
+		match := true
+		for i := range field {
+			if !strings.EqualFold(field[i], path[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteJSONStrings 按原有顺序重写 JSON 中的字符串值（不含键名），输出两空格缩进。
+// path 为值所在的键路径，数组元素记为 []
+func rewriteJSONStrings(data []byte, fn func(path []string, value string) (string, error)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	type frame struct {
+		object    bool
+		expectKey bool
+		count     int
+	}
+	var stack []frame
+	var path []string
+	var out bytes.Buffer
+
+	// 一个值写完后弹出其路径，所在对象转为等待下一个键
+	endValue := func() {
+		if n := len(stack); n > 0 {
+			path = path[:len(path)-1]
+			if stack[n-1].object {
+				stack[n-1].expectKey = true
+			}
+		}
+	}
+	writeString := func(s string) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(s)
+		out.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+
+		// 对象的键
+		if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+			if _, isDelim := tok.(json.Delim); !isDelim {
+				if stack[n-1].count > 0 {
+					out.WriteByte(',')
+				}
+				stack[n-1].count++
+				stack[n-1].expectKey = false
+				key := tok.(string)
+				path = append(path, key)
+				writeString(key)
+				out.WriteByte(':')
+				continue
+			}
+		}
+
+		// 值（或容器结束）
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			out.WriteRune(rune(delim))
+			endValue()
+			continue
+		}
+		if n := len(stack); n > 0 && !stack[n-1].object {
+			if stack[n-1].count > 0 {
+				out.WriteByte(',')
+			}
+			stack[n-1].count++
+			path = append(path, "[]")
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			out.WriteRune(rune(v))
+			stack = append(stack, frame{object: v == '{', expectKey: v == '{'})
+			continue
+		case string:
+			rewritten, err := fn(path, v)
+			if err != nil {
+				return nil, err
+			}
+			writeString(rewritten)
+		case json.Number:
+			out.WriteString(v.String())
+		case bool:
+			fmt.Fprintf(&out, "%t", v)
+		case nil:
+			out.WriteString("null")
+		}
+		endValue()
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
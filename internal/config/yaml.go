@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigExts 项目配置文件支持的扩展名，按查找顺序排列
+var projectConfigExts = []string{".json", ".yaml", ".yml"}
+
+// isYAMLFile 判断配置文件是否为 YAML 格式
+func isYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// envRefPattern 匹配 ${VAR}、${VAR:-默认值} 和转义的 $${
+var envRefPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// yamlConfigToJSON 将 YAML 项目配置转换为 JSON，并展开敏感字段（见 sensitiveFields）中的环境变量引用：
+// ${VAR} 替换为环境变量的值，${VAR:-默认值} 在变量未设置或为空时使用默认值，$${ 表示字面的 ${。
+// 引用了未设置且没有默认值的变量时返回错误，避免以空令牌同步。
+// 其他字段引用环境变量时返回错误，否则 description 等原样返回的字段会把服务端的环境变量暴露给读取配置的人
+func yamlConfigToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析 YAML 失败: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("YAML 无法转换为 JSON（键名必须是字符串）: %w", err)
+	}
+
+	missing := make(map[string]bool)
+	jsonData, err = rewriteJSONStrings(jsonData, func(path []string, value string) (string, error) {
+		if isSensitiveField(path) {
+			return expandEnvRefs(value, missing), nil
+		}
+		if hasEnvRef(value) {
+			return "", fmt.Errorf("%s: 只有令牌、密钥等敏感字段可以引用环境变量", strings.Join(path, "."))
+		}
+		return strings.ReplaceAll(value, "$${", "${"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("未设置环境变量: %s", strings.Join(sortedKeys(missing), ", "))
+	}
+	return jsonData, nil
+}
+
+// sortedKeys 返回按字典序排列的键
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasEnvRef 判断 value 是否包含 ${VAR} 形式的环境变量引用（不含转义的 $${）
+func hasEnvRef(value string) bool {
+	for _, match := range envRefPattern.FindAllStringSubmatch(value, -1) {
+		if match[1] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvRefPaths 返回配置 JSON 中包含 ${VAR} 环境变量引用的字段路径。
+// 只有 YAML 配置文件展开环境变量，通过 API 提交的配置不能引用服务端的环境变量
+func EnvRefPaths(data []byte) ([]string, error) {
+	var paths []string
+	_, err := rewriteJSONStrings(data, func(path []string, value string) (string, error) {
+		if hasEnvRef(value) {
+			paths = append(paths, strings.Join(path, "."))
+		}
+		return value, nil
+	})
+	return paths, err
+}
+
+// expandEnvRefs 展开 value 中的环境变量引用，未设置且没有默认值的变量记入 missing
+func expandEnvRefs(value string, missing map[string]bool) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	return envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		match := envRefPattern.FindStringSubmatch(ref)
+		name, hasDefault := match[1], strings.Contains(ref, ":-")
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		if hasDefault {
+			return match[2]
+		}
+		if _, set := os.LookupEnv(name); !set {
+			missing[name] = true
+		}
+		return ""
+	})
+}
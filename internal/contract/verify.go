@@ -0,0 +1,247 @@
+// Package contract replays documented operations against a running
+// environment and reports where the service diverges from its documentation.
+package contract
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Outcomes of a verified operation
+const (
+	Passed  = "passed"
+	Failed  = "failed"
+	Skipped = "skipped"
+)
+
+// Result is the outcome of one documented operation
+type Result struct {
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	URL      string   `json:"url,omitempty"`
+	Status   int      `json:"status,omitempty"`
+	Outcome  string   `json:"outcome"`
+	Problems []string `json:"problems,omitempty"`
+	Duration string   `json:"duration,omitempty"`
+}
+
+// Report summarizes a verification run
+type Report struct {
+	BaseURL string   `json:"base_url"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+	Results []Result `json:"results"`
+}
+
+// OK reports whether no operation diverged from the documentation
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+// Verifier sends one request per documented operation. Only GET, HEAD and
+// OPTIONS are sent unless IncludeUnsafe is set, since other methods change
+// data in the target environment.
+type Verifier struct {
+	cfg    config.VerifyConfig
+	client *http.Client
+}
+
+// New returns a Verifier for the environment described by cfg
+func New(cfg config.VerifyConfig) *Verifier {
+	return &Verifier{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			// Redirects are documented responses too
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Verify replays every operation of spec
+func (v *Verifier) Verify(spec *openapi.Spec) (*Report, error) {
+	if v.cfg.BaseURL == "" {
+		return nil, fmt.Errorf("verify.base_url is not configured")
+	}
+	report := &Report{BaseURL: v.cfg.BaseURL, Results: []Result{}}
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		result := v.verifyOperation(spec, path, method, op)
+		switch result.Outcome {
+		case Passed:
+			report.Passed++
+		case Failed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+		report.Results = append(report.Results, result)
+	})
+	return report, nil
+}
+
+func (v *Verifier) verifyOperation(spec *openapi.Spec, path, method string, op *openapi.Operation) Result {
+	result := Result{Method: method, Path: path}
+	for _, skip := range v.cfg.SkipPaths {
+		if skip == path {
+			result.Outcome = Skipped
+			result.Problems = []string{"skipped by verify.skip_paths"}
+			return result
+		}
+	}
+	if !isSafe(method) && !v.cfg.IncludeUnsafe {
+		result.Outcome = Skipped
+		result.Problems = []string{"modifies data, set verify.include_unsafe to replay it"}
+		return result
+	}
+
+	req, err := v.buildRequest(spec, path, method, op)
+	if err != nil {
+		result.Outcome = Failed
+		result.Problems = []string{err.Error()}
+		return result
+	}
+	result.URL = req.URL.String()
+
+	start := time.Now()
+	resp, err := v.client.Do(req)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		result.Outcome = Failed
+		result.Problems = []string{fmt.Sprintf("request failed: %v", err)}
+		return result
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	result.Status = resp.StatusCode
+
+	result.Problems = checkResponse(spec, op, resp, body)
+	result.Outcome = Passed
+	if len(result.Problems) > 0 {
+		result.Outcome = Failed
+	}
+	return result
+}
+
+// buildRequest fills path, query and header parameters from verify.params,
+// falling back to the documented examples, and sends the documented example
+// as the request body
+func (v *Verifier) buildRequest(spec *openapi.Spec, path, method string, op *openapi.Operation) (*http.Request, error) {
+	query := url.Values{}
+	headers := http.Header{}
+	for _, param := range op.Parameters {
+		value, ok := v.cfg.Params[param.Name]
+		if !ok {
+			if !param.Required && param.In != "path" {
+				continue
+			}
+			value = fmt.Sprint(spec.Example(param.Schema))
+		}
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(param.Name, value)
+		case "header":
+			headers.Set(param.Name, value)
+		}
+	}
+	if strings.Contains(path, "{") {
+		return nil, fmt.Errorf("path parameters of %s have no value, set them in verify.params", path)
+	}
+
+	target := strings.TrimRight(v.cfg.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if op.RequestBody != nil && method != "GET" && method != "HEAD" {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			example := media.Example
+			if example == nil {
+				example = spec.Example(media.Schema)
+			}
+			data, err := json.Marshal(example)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(data)
+			headers.Set("Content-Type", "application/json")
+		}
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	for name, value := range v.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	if v.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+v.cfg.BearerToken)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// checkResponse compares the status and body with the documented responses
+func checkResponse(spec *openapi.Spec, op *openapi.Operation, resp *http.Response, body []byte) []string {
+	response, ok := documentedResponse(op, resp.StatusCode)
+	if !ok {
+		return []string{fmt.Sprintf("undocumented status %d", resp.StatusCode)}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	isJSON := strings.HasSuffix(mediaType, "json")
+	media, documented := response.Content["application/json"]
+	switch {
+	case len(bytes.TrimSpace(body)) == 0:
+		return nil
+	case !documented && isJSON && len(response.Content) == 0:
+		return []string{fmt.Sprintf("status %d returns a JSON body, but none is documented", resp.StatusCode)}
+	case !documented:
+		return nil
+	case !isJSON:
+		return []string{fmt.Sprintf("expected application/json, got %s", resp.Header.Get("Content-Type"))}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("invalid JSON body: %v", err)}
+	}
+	return spec.CheckValue(media.Schema, value)
+}
+
+// documentedResponse finds the response for status: the exact code, then a
+// range such as 4XX, then default
+func documentedResponse(op *openapi.Operation, status int) (openapi.Response, bool) {
+	code := strconv.Itoa(status)
+	if response, ok := op.Responses[code]; ok {
+		return response, true
+	}
+	if response, ok := op.Responses[code[:1]+"XX"]; ok {
+		return response, true
+	}
+	response, ok := op.Responses["default"]
+	return response, ok
+}
+
+func isSafe(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Auth holds the credentials for a repository. Token is used for HTTPS URLs,
+// SSHKey (a private key file) for SSH URLs. Git runs in-process, so the
+// credentials show up neither in process listings nor in .git/config.
+type Auth struct {
+	Token     string
+	TokenUser string // user name sent with the token, default oauth2
+	SSHKey    string
+
+	anonymous bool
+}
+
+// Anonymous accesses repositories without any credentials, not even the
+// default SSH keys, for URLs the credentials must not be sent to
+var Anonymous = Auth{anonymous: true}
+
+// IsZero reports whether no credentials are set
+func (a Auth) IsZero() bool {
+	return a.Token == "" && a.SSHKey == ""
+}
+
+// Host returns the host name of a repository URL, HTTPS, ssh:// or
+// scp-like (git@host:owner/repo.git), empty when the URL is invalid
+func Host(repoURL string) string {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return ""
+	}
+	return endpoint.Host
+}
+
+// defaultSSHKeys are tried, like ssh does, when no key is configured
+var defaultSSHKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// method returns the go-git authentication for repoURL, nil when the
+// repository is accessed anonymously (or through the SSH agent)
+func (a Auth) method(repoURL string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if a.anonymous {
+		// Without credentials go-git would fall back to the SSH agent
+		if endpoint.Protocol == "ssh" {
+			return nil, fmt.Errorf("no credentials for SSH host %s", endpoint.Host)
+		}
+		return nil, nil
+	}
+
+	switch endpoint.Protocol {
+	case "http", "https":
+		if a.Token == "" {
+			return nil, nil
+		}
+		user := a.TokenUser
+		if user == "" {
+			user = "oauth2"
+		}
+		return &githttp.BasicAuth{Username: user, Password: a.Token}, nil
+	case "ssh":
+		key := a.SSHKey
+		if key == "" {
+			key = defaultSSHKey()
+		}
+		if key == "" {
+			return nil, nil
+		}
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+		keys, err := gitssh.NewPublicKeysFromFile(user, key, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %w", key, err)
+		}
+		keys.HostKeyCallback = acceptNewHostKeys
+		return keys, nil
+	}
+	return nil, nil
+}
+
+func defaultSSHKey() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range defaultSSHKeys {
+		path := filepath.Join(home, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// acceptNewHostKeys checks host keys against ~/.ssh/known_hosts and records
+// the keys of hosts seen for the first time, like ssh's
+// StrictHostKeyChecking=accept-new. A changed key is rejected.
+func acceptNewHostKeys(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(home, ".ssh", "known_hosts")
+	if check, err := knownhosts.New(file); err == nil {
+		err = check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+	return err
+}
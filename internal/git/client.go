@@ -1,78 +1,268 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 type Client struct {
 	workDir string
+	auth    Auth
+	sparse  []string // sparse checkout patterns, empty for a full checkout
+	ctx     context.Context
 }
 
 func NewClient(workDir string) *Client {
 	return &Client{workDir: workDir}
 }
 
+// WithAuth returns a copy of the client that authenticates with auth
+func (c *Client) WithAuth(auth Auth) *Client {
+	authenticated := *c
+	authenticated.auth = auth
+	return &authenticated
+}
+
+// WithContext returns a copy of the client whose clones, fetches and remote
+// listings give up when ctx is done
+func (c *Client) WithContext(ctx context.Context) *Client {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+// context returns the context network operations run under
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 // CloneOrPull clones a repository if it doesn't exist, or pulls latest changes
 func (c *Client) CloneOrPull(cloneURL, repoName string) (string, error) {
-	// Ensure work directory exists
+	return c.CloneOrPullBranch(cloneURL, repoName, "")
+}
+
+// CloneOrPullBranch is CloneOrPull for a specific branch: the checkout is
+// switched to the latest commit of branch. An empty branch means the remote's
+// default branch, the one its HEAD points at.
+func (c *Client) CloneOrPullBranch(cloneURL, repoName, branch string) (string, error) {
 	if err := os.MkdirAll(c.workDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create work directory: %w", err)
 	}
+	auth, err := c.auth.method(cloneURL)
+	if err != nil {
+		return "", err
+	}
 
 	repoPath := filepath.Join(c.workDir, sanitizeRepoName(repoName))
+	repo, err := c.openCheckout(repoPath)
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		options := &gogit.CloneOptions{
+			URL:          cloneURL,
+			Auth:         auth,
+			Depth:        cloneDepth(cloneURL),
+			SingleBranch: true,
+			NoCheckout:   len(c.sparse) > 0,
+		}
+		if branch != "" {
+			options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+		}
+		repo, err := gogit.PlainCloneContext(c.context(), repoPath, false, options)
+		if err == nil && options.NoCheckout {
+			var head *plumbing.Reference
+			if head, err = repo.Head(); err == nil {
+				err = c.checkoutHead(repo, repoPath, &gogit.CheckoutOptions{Branch: head.Name()})
+			}
+		}
+		if err != nil {
+			// Leave no partial checkout behind for the next run to trip over
+			os.RemoveAll(repoPath)
+			return "", wrapError("clone", cloneURL, err)
+		}
+		return repoPath, nil
+	}
+	if err != nil {
+		return "", wrapError("open", repoPath, err)
+	}
 
-	// Check if repository already exists
-	if _, err := os.Stat(filepath.Join(repoPath, ".git")); err == nil {
-		// Repository exists, pull latest changes
-		return repoPath, c.pull(repoPath)
+	if branch == "" {
+		if branch, err = remoteHead(c.context(), cloneURL, auth); err != nil {
+			return "", wrapError("ls-remote", cloneURL, err)
+		}
+	}
+	remoteRef := plumbing.NewRemoteReferenceName("origin", branch)
+	err = repo.FetchContext(c.context(), &gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", branch, remoteRef))},
+		Depth:      cloneDepth(cloneURL),
+		Auth:       auth,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return "", wrapError("fetch", cloneURL, err)
+	}
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return "", wrapError("fetch", cloneURL, err)
 	}
 
-	// Clone repository
-	return repoPath, c.clone(cloneURL, repoPath)
+	// Like checkout -f -B: the local branch is reset to the fetched commit
+	localRef := plumbing.NewBranchReferenceName(branch)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(localRef, ref.Hash())); err != nil {
+		return "", wrapError("checkout", repoPath, err)
+	}
+	if err := c.checkoutHead(repo, repoPath, &gogit.CheckoutOptions{Branch: localRef, Force: true}); err != nil {
+		return "", wrapError("checkout", repoPath, err)
+	}
+	return repoPath, nil
 }
 
-func (c *Client) clone(cloneURL, repoPath string) error {
-	cmd := exec.Command("git", "clone", "--depth", "1", cloneURL, repoPath)
-	output, err := cmd.CombinedOutput()
+// CheckoutRef checks out any ref the remote advertises, such as the
+// refs/pull/{number}/head ref GitHub keeps for every pull request, on a
+// detached HEAD. dirName should differ from the checkouts of CloneOrPull,
+// which expect to be on a branch.
+func (c *Client) CheckoutRef(cloneURL, dirName, ref string) (string, error) {
+	if err := os.MkdirAll(c.workDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create work directory: %w", err)
+	}
+	auth, err := c.auth.method(cloneURL)
+	if err != nil {
+		return "", err
+	}
+
+	repoPath := filepath.Join(c.workDir, sanitizeRepoName(dirName))
+	repo, err := c.openCheckout(repoPath)
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		if repo, err = gogit.PlainInit(repoPath, false); err != nil {
+			return "", wrapError("init", repoPath, err)
+		}
+		if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{cloneURL}}); err != nil {
+			return "", wrapError("init", repoPath, err)
+		}
+	} else if err != nil {
+		return "", wrapError("open", repoPath, err)
+	}
+
+	err = repo.FetchContext(c.context(), &gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec("+" + ref + ":" + ref)},
+		Depth:      cloneDepth(cloneURL),
+		Auth:       auth,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return "", wrapError("fetch", cloneURL, err)
+	}
+	fetched, err := repo.Reference(plumbing.ReferenceName(ref), true)
 	if err != nil {
-		return fmt.Errorf("git clone failed: %w\nOutput: %s", err, string(output))
+		return "", wrapError("fetch", cloneURL, err)
 	}
-	return nil
+	if err := c.checkoutHead(repo, repoPath, &gogit.CheckoutOptions{Hash: fetched.Hash(), Force: true}); err != nil {
+		return "", wrapError("checkout", repoPath, err)
+	}
+	return repoPath, nil
 }
 
-func (c *Client) pull(repoPath string) error {
-	cmd := exec.Command("git", "-C", repoPath, "pull", "origin")
-	output, err := cmd.CombinedOutput()
+func checkout(repo *gogit.Repository, options *gogit.CheckoutOptions) error {
+	worktree, err := repo.Worktree()
 	if err != nil {
-		// If pull fails, try to reset and pull again
-		resetCmd := exec.Command("git", "-C", repoPath, "reset", "--hard", "HEAD")
-		resetCmd.Run()
+		return err
+	}
+	return worktree.Checkout(options)
+}
 
-		cmd = exec.Command("git", "-C", repoPath, "pull", "origin")
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("git pull failed: %w\nOutput: %s", err, string(output))
-		}
+// cloneDepth returns the history depth fetched from cloneURL. Local
+// repositories are served in-process, which cannot serve shallow clones,
+// and are cheap to copy in full anyway.
+func cloneDepth(cloneURL string) int {
+	if endpoint, err := transport.NewEndpoint(cloneURL); err == nil && endpoint.Protocol == "file" {
+		return 0
 	}
-	return nil
+	return 1
 }
 
-// GetChangedFiles returns list of files changed in the last commit
+// GetChangedFiles returns list of files changed in the last commit. Shallow
+// clones are deepened by one commit first; if that fails, the last commit is
+// compared against an empty tree and every file is reported as changed.
 func (c *Client) GetChangedFiles(repoPath string) ([]string, error) {
-	cmd := exec.Command("git", "-C", repoPath, "diff", "--name-only", "HEAD~1", "HEAD")
-	output, err := cmd.CombinedOutput()
+	repo, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	if commit.NumParents() > 0 && head.Name().IsBranch() {
+		if _, err := commit.Parent(0); err != nil {
+			c.deepen(repo, head.Name().Short())
+		}
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	parentTree := &object.Tree{}
+	if parent, err := commit.Parent(0); err == nil {
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get changed files: %w", err)
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
 
-	files := strings.Split(strings.TrimSpace(string(output)), "\n")
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
 	return files, nil
 }
 
+// deepen fetches the parent of the branch tip into a shallow clone
+func (c *Client) deepen(repo *gogit.Repository, branch string) {
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return
+	}
+	auth, err := c.auth.method(remote.Config().URLs[0])
+	if err != nil {
+		return
+	}
+	repo.FetchContext(c.context(), &gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", branch, plumbing.NewRemoteReferenceName("origin", branch)))},
+		Depth:      2,
+		Auth:       auth,
+		Force:      true,
+	})
+}
+
 func sanitizeRepoName(name string) string {
 	// Remove .git suffix and replace special characters
 	name = strings.TrimSuffix(name, ".git")
@@ -80,3 +270,128 @@ func sanitizeRepoName(name string) string {
 	name = strings.ReplaceAll(name, "\\", "_")
 	return name
 }
+
+// CheckRemote checks that the repository can be read with the client's
+// credentials by listing its refs, like git ls-remote, without cloning it
+func (c *Client) CheckRemote(repoURL string) error {
+	auth, err := c.auth.method(repoURL)
+	if err != nil {
+		return err
+	}
+	remote := gogit.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{repoURL}})
+	if _, err := remote.ListContext(c.context(), &gogit.ListOptions{Auth: auth}); err != nil {
+		return wrapError("ls-remote", repoURL, err)
+	}
+	return nil
+}
+
+// remoteHead returns the branch HEAD of the remote repository points at
+func remoteHead(ctx context.Context, repoURL string, auth transport.AuthMethod) (string, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{repoURL}})
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("HEAD of %s is not a branch", repoURL)
+}
+
+// CommitInfo describes the checked out HEAD commit
+type CommitInfo struct {
+	Hash      string
+	ShortHash string
+	Branch    string
+	Tag       string // tag pointing at HEAD, empty if none
+	Time      time.Time
+}
+
+// HeadInfo returns metadata of the HEAD commit of a repository. repoPath
+// may be any directory inside the working tree.
+func (c *Client) HeadInfo(repoPath string) (*CommitInfo, error) {
+	repo, err := gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+
+	hash := head.Hash().String()
+	info := &CommitInfo{Hash: hash, ShortHash: hash[:7], Branch: "HEAD", Time: commit.Committer.When}
+	if head.Name().IsBranch() {
+		info.Branch = head.Name().Short()
+	}
+	if tags := tagsAt(repo, head.Hash()); len(tags) > 0 {
+		info.Tag = tags[0]
+	}
+	return info, nil
+}
+
+// tagsAt returns the tags pointing at commit, highest version first
+func tagsAt(repo *gogit.Repository, commit plumbing.Hash) []string {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		// Annotated tags point at a tag object, which points at the commit
+		if tag, err := repo.TagObject(target); err == nil {
+			target = tag.Target
+		}
+		if target == commit {
+			tags = append(tags, ref.Name().Short())
+		}
+		return nil
+	})
+	sort.Slice(tags, func(i, j int) bool {
+		return compareVersions(tags[i], tags[j]) > 0
+	})
+	return tags
+}
+
+// compareVersions orders tag names like git's version sort: runs of digits
+// compare as numbers, so v1.10.0 sorts after v1.9.0
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		na, restA := splitRun(a)
+		nb, restB := splitRun(b)
+		if na != nb {
+			if !isDigit(na[0]) || !isDigit(nb[0]) {
+				return strings.Compare(na, nb)
+			}
+			ta, tb := strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(ta) != len(tb) {
+				return len(ta) - len(tb)
+			}
+			if ta != tb {
+				return strings.Compare(ta, tb)
+			}
+		}
+		a, b = restA, restB
+	}
+	return len(a) - len(b)
+}
+
+// splitRun splits off the leading run of digits or of non-digits
+func splitRun(s string) (string, string) {
+	i := 1
+	for i < len(s) && isDigit(s[i]) == isDigit(s[0]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Causes of failed operations, matched with errors.Is
+var (
+	ErrAuthentication     = errors.New("authentication failed")
+	ErrRepositoryNotFound = errors.New("repository not found")
+	ErrReferenceNotFound  = errors.New("branch or ref not found")
+)
+
+// Error is a failed git operation
+type Error struct {
+	Op  string // clone, fetch, checkout, push, ...
+	URL string // repository URL or checkout path, without credentials
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git %s of %s failed: %v", e.Op, e.URL, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError classifies err and wraps it in an *Error
+func wrapError(op, repoURL string, err error) error {
+	var noMatch gogit.NoMatchingRefSpecError
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		err = fmt.Errorf("%w: %v", ErrAuthentication, err)
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		err = fmt.Errorf("%w: %v", ErrRepositoryNotFound, err)
+	case errors.Is(err, plumbing.ErrReferenceNotFound), errors.As(err, &noMatch):
+		err = fmt.Errorf("%w: %v", ErrReferenceNotFound, err)
+	}
+	if u, parseErr := url.Parse(repoURL); parseErr == nil && u.User != nil {
+		repoURL = u.Redacted()
+	}
+	return &Error{Op: op, URL: repoURL, Err: err}
+}
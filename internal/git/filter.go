@@ -0,0 +1,73 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPaths reports whether any of files matches the path filter patterns.
+// Patterns are slash-separated and relative to the repository root. A *
+// matches within one path segment and ** matches any number of segments, as
+// in **/*.go. A trailing slash, as in api/, matches everything below that
+// directory, and a leading ! excludes the files the rest of the pattern
+// matches. Patterns are evaluated in order and the last match wins. Files
+// start out excluded, unless every pattern is an exclusion.
+func MatchPaths(patterns, files []string) bool {
+	for _, file := range files {
+		if file != "" && matchFile(patterns, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFile(patterns []string, file string) bool {
+	included := true
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "!") {
+			included = false
+			break
+		}
+	}
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		if MatchGlob(strings.TrimPrefix(pattern, "!"), file) {
+			included = !negate
+		}
+	}
+	return included
+}
+
+// MatchGlob matches one file path against a pattern as described in MatchPaths
+func MatchGlob(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
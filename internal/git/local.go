@@ -0,0 +1,32 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+func init() {
+	// go-git runs git-upload-pack for file:// and path remotes; serve them
+	// in-process instead, so no git binary is needed at all
+	client.InstallProtocol("file", server.NewServer(localLoader{}))
+}
+
+// localLoader opens local repositories, bare or with a working tree
+type localLoader struct{}
+
+func (localLoader) Load(endpoint *transport.Endpoint) (storer.Storer, error) {
+	for _, dir := range []string{filepath.Join(endpoint.Path, ".git"), endpoint.Path} {
+		if info, err := os.Stat(filepath.Join(dir, "config")); err == nil && !info.IsDir() {
+			return filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), nil
+		}
+	}
+	return nil, transport.ErrRepositoryNotFound
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// PushRequest describes a commit of generated files to a branch
+type PushRequest struct {
+	RepoURL     string
+	Branch      string
+	Files       map[string][]byte // path inside the repository -> content
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+	Auth        Auth
+}
+
+// CommitAndPush clones the branch into memory, writes the files, and pushes
+// a commit. Nothing is written to disk. It returns false when the files are
+// unchanged and nothing was pushed. A branch that does not exist yet is
+// created.
+func CommitAndPush(req PushRequest) (bool, error) {
+	auth, err := req.Auth.method(req.RepoURL)
+	if err != nil {
+		return false, err
+	}
+	branch := plumbing.NewBranchReferenceName(req.Branch)
+	fs := memfs.New()
+
+	repo, err := gogit.Clone(memory.NewStorage(), fs, &gogit.CloneOptions{
+		URL:           req.RepoURL,
+		Auth:          auth,
+		ReferenceName: branch,
+		SingleBranch:  true,
+		Depth:         cloneDepth(req.RepoURL),
+	})
+	if err != nil {
+		var noMatch gogit.NoMatchingRefSpecError
+		if !errors.Is(err, transport.ErrEmptyRemoteRepository) && !errors.Is(err, plumbing.ErrReferenceNotFound) && !errors.As(err, &noMatch) {
+			return false, wrapError("clone", req.RepoURL, err)
+		}
+		// The branch (or the whole repository) is empty: start it from scratch
+		fs = memfs.New()
+		if repo, err = gogit.Init(memory.NewStorage(), fs); err != nil {
+			return false, wrapError("init", req.RepoURL, err)
+		}
+		if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{req.RepoURL}}); err != nil {
+			return false, wrapError("init", req.RepoURL, err)
+		}
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+			return false, wrapError("init", req.RepoURL, err)
+		}
+	}
+
+	for name, content := range req.Files {
+		if err := fs.MkdirAll(path.Dir(name), 0755); err != nil {
+			return false, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := util.WriteFile(fs, name, content, 0644); err != nil {
+			return false, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, wrapError("add", req.RepoURL, err)
+	}
+	if err := worktree.AddWithOptions(&gogit.AddOptions{All: true}); err != nil {
+		return false, wrapError("add", req.RepoURL, err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, wrapError("status", req.RepoURL, err)
+	}
+	if status.IsClean() {
+		return false, nil
+	}
+
+	author := &object.Signature{Name: req.AuthorName, Email: req.AuthorEmail, When: time.Now()}
+	if _, err := worktree.Commit(req.Message, &gogit.CommitOptions{Author: author, Committer: author}); err != nil {
+		return false, wrapError("commit", req.RepoURL, err)
+	}
+	err = repo.Push(&gogit.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(branch + ":" + branch)},
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return false, wrapError("push", req.RepoURL, err)
+	}
+	return true, nil
+}
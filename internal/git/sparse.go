@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sparseCheckoutFile records the patterns of a sparse checkout, in the
+// place git itself keeps them
+const sparseCheckoutFile = "info/sparse-checkout"
+
+// WithSparseCheckout returns a copy of the client whose checkouts contain
+// only the files matching patterns (see MatchPaths), such as **/*.go.
+// Clones stay shallow, so only the blobs of the checked out commit are
+// downloaded; go-git cannot make blobless (partial) clones, which would
+// skip downloading the other files too. No patterns checks out every file.
+func (c *Client) WithSparseCheckout(patterns []string) *Client {
+	sparse := *c
+	sparse.sparse = patterns
+	return &sparse
+}
+
+// checkoutHead writes the working tree of repoPath for the commit HEAD
+// points at, honouring the client's sparse checkout patterns
+func (c *Client) checkoutHead(repo *gogit.Repository, repoPath string, options *gogit.CheckoutOptions) error {
+	if len(c.sparse) == 0 {
+		return checkout(repo, options)
+	}
+	if options.Branch != "" {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, options.Branch)); err != nil {
+			return err
+		}
+	} else if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, options.Hash)); err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	// The index is left alone: the checkout only feeds the parsers and is
+	// never committed from
+	if err := clearWorktree(repoPath); err != nil {
+		return err
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !matchFile(c.sparse, f.Name) {
+			return nil
+		}
+		if err := checkPath(repoPath, f.Name); err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(repoPath, filepath.FromSlash(f.Name)), f)
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(repoPath, ".git", sparseCheckoutFile), []byte(strings.Join(c.sparse, "\n")+"\n"), 0644)
+}
+
+// openCheckout opens the repository at repoPath. A sparse checkout is
+// discarded when the client wants every file, since its index does not
+// describe the working tree.
+func (c *Client) openCheckout(repoPath string) (*gogit.Repository, error) {
+	if len(c.sparse) == 0 {
+		if _, err := os.Stat(filepath.Join(repoPath, ".git", sparseCheckoutFile)); err == nil {
+			if err := os.RemoveAll(repoPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return gogit.PlainOpen(repoPath)
+}
+
+// clearWorktree removes everything but .git from repoPath
+func clearWorktree(repoPath string) error {
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(repoPath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(filepath.Join(repoPath, ".git", filepath.Dir(sparseCheckoutFile)), 0755)
+}
+
+// checkPath rejects tree entries that would be written outside repoPath or
+// into .git: absolute names, .. components, .git components (in any case,
+// for case-insensitive filesystems) and directories that are symlinks
+// written earlier by the same checkout
+func checkPath(repoPath, name string) error {
+	local := filepath.FromSlash(name)
+	if !filepath.IsLocal(local) {
+		return fmt.Errorf("refusing to check out %q: path leaves the worktree", name)
+	}
+	parts := strings.Split(name, "/")
+	for _, part := range parts {
+		if strings.EqualFold(part, ".git") {
+			return fmt.Errorf("refusing to check out %q: path inside .git", name)
+		}
+	}
+	dir := repoPath
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to check out %q: %s is a symlink", name, part)
+		}
+	}
+	return nil
+}
+
+// writeFile creates name, which must not exist yet, so a symlink already at
+// that path is never written through
+func writeFile(name string, f *object.File) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	if f.Mode == filemode.Symlink {
+		target, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, name)
+	}
+	perm := os.FileMode(0644)
+	if f.Mode == filemode.Executable {
+		perm = 0755
+	}
+	reader, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	out, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, reader); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %w", f.Name, err)
+	}
+	return out.Close()
+}
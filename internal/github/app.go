@@ -0,0 +1,157 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRenewal is how long before expiry an installation token is replaced
+const tokenRenewal = 5 * time.Minute
+
+// App issues installation access tokens of a GitHub App, used to clone the
+// private repositories the App is installed on. Tokens are cached per
+// account until shortly before they expire (after one hour).
+type App struct {
+	baseURL string
+	host    string // host of the repositories served by baseURL
+	id      string
+	key     *rsa.PrivateKey
+	client  *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]installationToken // account -> token
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewApp loads the App's private key (the PEM file downloaded from the App
+// settings). It returns nil when appID is empty; a nil App issues no tokens.
+func NewApp(baseURL, appID, keyFile string) (*App, error) {
+	if appID == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid GitHub API URL: %s", baseURL)
+	}
+	host := base.Hostname()
+	if host == "api.github.com" {
+		host = "github.com"
+	}
+	return &App{
+		baseURL: base.String(),
+		host:    host,
+		id:      appID,
+		key:     key,
+		client:  &http.Client{Timeout: 15 * time.Second},
+		tokens:  make(map[string]installationToken),
+	}, nil
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Repository returns owner/name of an HTTPS clone URL on the App's GitHub
+// host, or "" for other repositories
+func (a *App) Repository(cloneURL string) string {
+	if a == nil {
+		return ""
+	}
+	u, err := url.Parse(cloneURL)
+	if err != nil || u.Scheme != "https" || !strings.EqualFold(u.Hostname(), a.host) {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// Token returns an installation token for repo (owner/name). The token is
+// sent as the password of the user x-access-token.
+func (a *App) Token(repo string) (string, error) {
+	account := strings.ToLower(strings.SplitN(repo, "/", 2)[0])
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.tokens[account]; ok && time.Until(cached.ExpiresAt) > tokenRenewal {
+		return cached.Token, nil
+	}
+
+	jwt, err := a.jwt()
+	if err != nil {
+		return "", err
+	}
+	api := &Client{baseURL: a.baseURL, token: jwt, client: a.client}
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := api.do("GET", "/repos/"+repo+"/installation", nil, &installation); err != nil {
+		return "", fmt.Errorf("GitHub App is not installed on %s: %w", repo, err)
+	}
+	var token installationToken
+	if err := api.do("POST", fmt.Sprintf("/app/installations/%d/access_tokens", installation.ID), nil, &token); err != nil {
+		return "", err
+	}
+	a.tokens[account] = token
+	return token.Token, nil
+}
+
+// jwt returns the short-lived token that authenticates as the App itself
+func (a *App) jwt() (string, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	// Backdated to allow for clock drift; GitHub rejects lifetimes over 10 minutes
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.id,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
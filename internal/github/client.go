@@ -0,0 +1,129 @@
+// Package github reports analysis results back to GitHub: a commit status on
+// the analyzed commit and, for pull requests, a comment listing the API
+// changes. It also issues GitHub App installation tokens for cloning.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Commit status states
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// commentMarker identifies the comment the service maintains on a pull
+// request, so later pushes update it instead of adding another one
+const commentMarker = "<!-- api-doc-generator -->"
+
+// maxDescription is the longest commit status description GitHub accepts
+const maxDescription = 140
+
+// Client calls the GitHub REST API with a token that can write commit
+// statuses and issue comments (the repo:status and public_repo/repo scopes,
+// or the statuses and pull requests permissions of a fine-grained token)
+type Client struct {
+	baseURL string
+	token   string
+	context string
+	client  *http.Client
+}
+
+// NewClient returns nil when token is empty; a nil Client reports nothing.
+// baseURL is https://api.github.com, or https://HOST/api/v3 for GitHub
+// Enterprise Server. context names the status on the commit.
+func NewClient(baseURL, token, context string) *Client {
+	if token == "" {
+		return nil
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		context: context,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SetStatus sets the commit status of sha in repo (owner/name)
+func (c *Client) SetStatus(repo, sha, state, description, targetURL string) error {
+	if c == nil {
+		return nil
+	}
+	if len(description) > maxDescription {
+		description = description[:maxDescription-3] + "..."
+	}
+	body := map[string]string{"state": state, "description": description, "context": c.context}
+	if targetURL != "" {
+		body["target_url"] = targetURL
+	}
+	return c.do("POST", fmt.Sprintf("/repos/%s/statuses/%s", repo, sha), body, nil)
+}
+
+// UpsertComment posts body as a comment on pull request number, or updates
+// the comment posted earlier by the service
+func (c *Client) UpsertComment(repo string, number int, body string) error {
+	if c == nil {
+		return nil
+	}
+	body = commentMarker + "\n" + body
+
+	var comments []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100", repo, number), nil, &comments); err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if strings.HasPrefix(comment.Body, commentMarker) {
+			return c.do("PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", repo, comment.ID), map[string]string{"body": body}, nil)
+		}
+	}
+	return c.do("POST", fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), map[string]string{"body": body}, nil)
+}
+
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GitHub %s %s failed (HTTP %d): %s", method, path, resp.StatusCode, string(data))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse GitHub response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"api-doc-generator/internal/openapi/diff"
+	"fmt"
+	"strings"
+)
+
+// maxCommentChanges keeps the comment readable on large refactors
+const maxCommentChanges = 100
+
+// PullRequestReport is what the pull request comment shows for one project
+type PullRequestReport struct {
+	Project   string
+	Base      string // branch the pull request merges into
+	Endpoints int
+	// Report holds the changes against the document last synced from Base,
+	// nil when none has been synced yet
+	Report *diff.Report
+	// Blocked is set when the breaking changes would abort the sync after
+	// the merge, because sync.fail_on_breaking is set
+	Blocked bool
+	Error   string // analysis failed
+}
+
+// Comment renders the pull request comment in GitHub Markdown
+func (r PullRequestReport) Comment() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### API changes: %s\n\n", r.Project)
+
+	if r.Error != "" {
+		fmt.Fprintf(&b, "❌ The API docs could not be generated:\n\n```\n%s\n```\n", r.Error)
+		return b.String()
+	}
+	if r.Report == nil {
+		fmt.Fprintf(&b, "%d endpoints. No document has been synced from `%s` yet, so there is nothing to compare with.\n", r.Endpoints, r.Base)
+		return b.String()
+	}
+	if !r.Report.HasChanges() {
+		fmt.Fprintf(&b, "%d endpoints, no API changes compared to `%s`.\n", r.Endpoints, r.Base)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%d endpoints, %s compared to `%s`.\n\n", r.Endpoints, r.Report.Summary(), r.Base)
+	if breaking := len(r.Report.Breaking()); breaking > 0 {
+		fmt.Fprintf(&b, "⚠️ **%d breaking change(s)** can break existing clients.", breaking)
+		if r.Blocked {
+			b.WriteString(" The docs will not be synced after the merge unless a commit message contains `[allow-breaking]`.")
+		}
+		b.WriteString("\n\n")
+	}
+
+	// A diff block colors added lines green and removed lines red
+	b.WriteString("```diff\n")
+	for i, change := range r.Report.Changes {
+		if i == maxCommentChanges {
+			fmt.Fprintf(&b, "# ...and %d more\n", len(r.Report.Changes)-maxCommentChanges)
+			break
+		}
+		b.WriteString(change.String() + "\n")
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// StatusDescription summarizes the report in one line for the commit status
+func (r PullRequestReport) StatusDescription() string {
+	switch {
+	case r.Error != "":
+		return "API docs could not be generated"
+	case r.Report == nil:
+		return fmt.Sprintf("%d endpoints", r.Endpoints)
+	case r.Blocked:
+		return fmt.Sprintf("%d breaking API change(s)", len(r.Report.Breaking()))
+	}
+	return fmt.Sprintf("%d endpoints, %s", r.Endpoints, r.Report.Summary())
+}
@@ -0,0 +1,180 @@
+// Package health probes the dependencies of the service (work directory,
+// git, Apifox, run history database, document storage) for the readiness
+// endpoint that Kubernetes and load balancers poll.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Overall statuses of a report
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"    // a non-critical dependency failed
+	StatusUnavailable = "unavailable" // a critical dependency failed
+)
+
+// Probe checks one dependency. Check returns an optional detail shown with a
+// successful result.
+type Probe struct {
+	Name string
+	// Critical probes make the service not ready when they fail; the others
+	// only degrade it. Outages of external services such as Apifox are not
+	// critical: taking every instance out of rotation would not help.
+	Critical bool
+	// Timeout overrides the checker's timeout, for probes of remote
+	// services that need longer than local ones
+	Timeout time.Duration
+	Check   func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one probe. The endpoint is public, so details and
+// errors, which may name hosts and paths, are only logged.
+type Result struct {
+	Status    string `json:"status"` // ok or failed
+	Critical  bool   `json:"critical"`
+	Detail    string `json:"-"`
+	Error     string `json:"-"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the outcome of all probes
+type Report struct {
+	Status    string            `json:"status"`
+	Checks    map[string]Result `json:"checks"`
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// Ready reports whether every critical probe passed
+func (r *Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+// Checker runs the probes concurrently, each with a timeout. Reports are
+// reused for ttl, so frequent polling by several probes does not hammer the
+// dependencies.
+type Checker struct {
+	probes  []Probe
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu   sync.Mutex
+	last *Report
+}
+
+func NewChecker(timeout, ttl time.Duration, probes ...Probe) *Checker {
+	return &Checker{probes: probes, timeout: timeout, ttl: ttl}
+}
+
+// Check returns the current report, running the probes when the last report
+// is older than the ttl
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.ttl {
+		return c.last
+	}
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]Result, len(c.probes)), CheckedAt: time.Now()}
+	results := make([]Result, len(c.probes))
+	var wg sync.WaitGroup
+	for i, probe := range c.probes {
+		wg.Add(1)
+		go func(i int, probe Probe) {
+			defer wg.Done()
+			results[i] = c.run(ctx, probe)
+		}(i, probe)
+	}
+	wg.Wait()
+
+	for i, probe := range c.probes {
+		result := results[i]
+		report.Checks[probe.Name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		log.Printf("⚠️  Health check %s failed: %s", probe.Name, result.Error)
+		if probe.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	c.last = report
+	return report
+}
+
+// run runs one probe, giving up after its timeout even when the probe does
+// not honor its context
+func (c *Checker) run(ctx context.Context, probe Probe) Result {
+	timeout := c.timeout
+	if probe.Timeout > 0 {
+		timeout = probe.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	started := time.Now()
+	go func() {
+		detail, err := probe.Check(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = fmt.Errorf("timed out after %s", timeout)
+	}
+	result := Result{Status: StatusOK, Critical: probe.Critical, LatencyMS: time.Since(started).Milliseconds()}
+	if o.err != nil {
+		result.Status, result.Error = "failed", o.err.Error()
+	} else {
+		result.Detail = o.detail
+	}
+	return result
+}
+
+// WritableDir checks that files can be created in dir, creating it if needed
+func WritableDir(dir string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		file, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return "", fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		file.Close()
+		os.Remove(file.Name())
+		return dir, nil
+	}
+}
+
+// Reachable checks that url answers HTTP requests. Any response counts, since
+// APIs answer unauthenticated requests with 401 or 404.
+func Reachable(url string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		return fmt.Sprintf("%s answered HTTP %d", url, resp.StatusCode), nil
+	}
+}
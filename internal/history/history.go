@@ -0,0 +1,83 @@
+// Package history records every analysis run in a database, so past runs of
+// a project can be reviewed after the service logs have rotated.
+package history
+
+import (
+	"api-doc-generator/internal/config"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Triggers of a run
+const (
+	TriggerPush   = "push"
+	TriggerManual = "manual"
+	TriggerScoped = "scoped"
+	// TriggerPullRequest runs analyze a pull request and report the changes
+	// on it, without syncing
+	TriggerPullRequest = "pull_request"
+)
+
+// Run is one analysis run of a repository
+type Run struct {
+	ID         int64  `json:"id,omitempty"` // zero when history is disabled
+	Project    string `json:"project"`
+	Repository string `json:"repository"`
+	Branch     string `json:"branch,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	Trigger    string `json:"trigger"`
+	Status     string `json:"status"` // success, failed, skipped, aborted
+	Error      string `json:"error,omitempty"`
+	// SpecHash is the SHA-256 of the generated document, equal across runs
+	// that produced the same document
+	SpecHash    string `json:"spec_hash,omitempty"`
+	Endpoints   int    `json:"endpoints"`
+	Schemas     int    `json:"schemas"`
+	Added       int    `json:"added"`
+	Removed     int    `json:"removed"`
+	Changed     int    `json:"changed"`
+	Breaking    int    `json:"breaking"`
+	DiffSummary string `json:"diff_summary,omitempty"`
+	// Warnings is the number of non-fatal issues of the analysis, see
+	// openapi.AnalysisReport
+	Warnings int `json:"warnings"`
+	// Results holds the outcome reported by each sync target, e.g. the
+	// endpoint counts of the Apifox import
+	Results    map[string]string `json:"results,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMS int64             `json:"duration_ms"`
+}
+
+// Query filters the runs returned by List
+type Query struct {
+	Status string    // only runs with this status
+	Since  time.Time // only runs started at or after Since
+	Limit  int
+}
+
+// Store persists runs
+type Store interface {
+	Record(run *Run) error
+	// List returns the runs of a project, newest first
+	List(project string, query Query) ([]Run, error)
+	// Ping checks that the database can be reached
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// New opens the database configured in cfg, or returns nil when history is
+// disabled
+func New(cfg config.HistoryConfig) (Store, error) {
+	switch cfg.Driver {
+	case "", config.HistoryDisabled:
+		return nil, nil
+	case config.HistorySQLite, config.HistoryPostgres:
+		store, err := openSQL(cfg.Driver, cfg.DSN)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("unsupported history driver: %s", cfg.Driver)
+}
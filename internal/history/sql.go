@@ -0,0 +1,201 @@
+package history
+
+import (
+	"api-doc-generator/internal/config"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// dialect covers the differences between SQLite and PostgreSQL
+type dialect struct {
+	driverName string
+	idColumn   string
+	timeType   string
+	// placeholder returns the bind parameter for the n-th (1-based) argument
+	placeholder func(n int) string
+}
+
+var dialects = map[string]dialect{
+	config.HistorySQLite: {
+		driverName:  "sqlite3",
+		idColumn:    "INTEGER PRIMARY KEY AUTOINCREMENT",
+		timeType:    "TIMESTAMP",
+		placeholder: func(int) string { return "?" },
+	},
+	config.HistoryPostgres: {
+		driverName:  "postgres",
+		idColumn:    "BIGSERIAL PRIMARY KEY",
+		timeType:    "TIMESTAMPTZ",
+		placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	},
+}
+
+// runColumns are the columns written by Record, in order
+var runColumns = []string{
+	"project", "repository", "branch", "commit_hash", "trigger_type", "status", "error",
+	"spec_hash", "endpoints", "schemas", "added", "removed", "changed", "breaking",
+	"diff_summary", "results", "started_at", "duration_ms", "warnings",
+}
+
+// SQLStore stores runs in SQLite or PostgreSQL
+type SQLStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func openSQL(driver, dsn string) (*SQLStore, error) {
+	d := dialects[driver]
+	if driver == config.HistorySQLite {
+		if dir := filepath.Dir(dsn); dir != "." && !strings.HasPrefix(dsn, "file:") {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create history directory: %w", err)
+			}
+		}
+	}
+	db, err := sql.Open(d.driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if driver == config.HistorySQLite {
+		// Writes from concurrent runs would otherwise fail with "database is locked"
+		db.SetMaxOpenConns(1)
+	}
+
+	store := &SQLStore{db: db, dialect: d}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *SQLStore) migrate() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS sync_runs (
+			id ` + s.dialect.idColumn + `,
+			project TEXT NOT NULL,
+			repository TEXT NOT NULL,
+			branch TEXT NOT NULL DEFAULT '',
+			commit_hash TEXT NOT NULL DEFAULT '',
+			trigger_type TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			spec_hash TEXT NOT NULL DEFAULT '',
+			endpoints INTEGER NOT NULL DEFAULT 0,
+			schemas INTEGER NOT NULL DEFAULT 0,
+			added INTEGER NOT NULL DEFAULT 0,
+			removed INTEGER NOT NULL DEFAULT 0,
+			changed INTEGER NOT NULL DEFAULT 0,
+			breaking INTEGER NOT NULL DEFAULT 0,
+			diff_summary TEXT NOT NULL DEFAULT '',
+			results TEXT NOT NULL DEFAULT '',
+			started_at ` + s.dialect.timeType + ` NOT NULL,
+			duration_ms BIGINT NOT NULL DEFAULT 0,
+			warnings INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS sync_runs_project_started ON sync_runs (project, started_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create history table: %w", err)
+		}
+	}
+	// Tables created before warnings were recorded lack the column
+	if _, err := s.db.Exec(`SELECT warnings FROM sync_runs LIMIT 0`); err != nil {
+		if _, err := s.db.Exec(`ALTER TABLE sync_runs ADD COLUMN warnings INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("failed to migrate history table: %w", err)
+		}
+	}
+	return nil
+}
+
+// Record inserts run and sets its ID
+func (s *SQLStore) Record(run *Run) error {
+	results := ""
+	if len(run.Results) > 0 {
+		data, err := json.Marshal(run.Results)
+		if err != nil {
+			return err
+		}
+		results = string(data)
+	}
+
+	placeholders := make([]string, len(runColumns))
+	for i := range placeholders {
+		placeholders[i] = s.dialect.placeholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO sync_runs (%s) VALUES (%s) RETURNING id",
+		strings.Join(runColumns, ", "), strings.Join(placeholders, ", "))
+	err := s.db.QueryRow(query,
+		run.Project, run.Repository, run.Branch, run.Commit, run.Trigger, run.Status, run.Error,
+		run.SpecHash, run.Endpoints, run.Schemas, run.Added, run.Removed, run.Changed, run.Breaking,
+		run.DiffSummary, results, run.StartedAt.UTC(), run.DurationMS, run.Warnings,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// List returns the runs of project, newest first
+func (s *SQLStore) List(project string, query Query) ([]Run, error) {
+	conditions := []string{"project = " + s.dialect.placeholder(1)}
+	args := []interface{}{project}
+	if query.Status != "" {
+		args = append(args, query.Status)
+		conditions = append(conditions, "status = "+s.dialect.placeholder(len(args)))
+	}
+	if !query.Since.IsZero() {
+		args = append(args, query.Since.UTC())
+		conditions = append(conditions, "started_at >= "+s.dialect.placeholder(len(args)))
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(fmt.Sprintf("SELECT id, %s FROM sync_runs WHERE %s ORDER BY started_at DESC, id DESC LIMIT %s",
+		strings.Join(runColumns, ", "), strings.Join(conditions, " AND "), s.dialect.placeholder(len(args))), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []Run{}
+	for rows.Next() {
+		var run Run
+		var results string
+		var startedAt time.Time
+		err := rows.Scan(&run.ID,
+			&run.Project, &run.Repository, &run.Branch, &run.Commit, &run.Trigger, &run.Status, &run.Error,
+			&run.SpecHash, &run.Endpoints, &run.Schemas, &run.Added, &run.Removed, &run.Changed, &run.Breaking,
+			&run.DiffSummary, &results, &startedAt, &run.DurationMS, &run.Warnings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		run.StartedAt = startedAt.UTC()
+		if results != "" {
+			json.Unmarshal([]byte(results), &run.Results)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,113 @@
+// Package metrics pushes per-sync measurements to an external time series
+// database using the InfluxDB line protocol, which InfluxDB and
+// VictoriaMetrics both accept.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SyncMetrics describes one analysis/sync run
+type SyncMetrics struct {
+	Project   string
+	Status    string // success, failed, aborted
+	Duration  time.Duration
+	Endpoints int
+	Schemas   int
+	Added     int
+	Removed   int
+	Changed   int
+	Breaking  int
+	Labels    map[string]string // extra tags, e.g. repository or branch
+	Timestamp time.Time
+}
+
+// Pusher writes SyncMetrics to a line protocol endpoint such as
+// http://influxdb:8086/api/v2/write?org=acme&bucket=apidoc or
+// http://victoriametrics:8428/write
+type Pusher struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewPusher returns nil when url is empty; a nil Pusher silently drops metrics
+func NewPusher(url, token string) *Pusher {
+	if url == "" {
+		return nil
+	}
+	return &Pusher{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Push sends the metrics of one run
+func (p *Pusher) Push(m SyncMetrics) error {
+	if p == nil {
+		return nil
+	}
+
+	req, err := http.NewRequest("POST", p.url, strings.NewReader(m.Line()))
+	if err != nil {
+		return fmt.Errorf("failed to create metrics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Token "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("metrics push failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("metrics push failed (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Line renders the metrics as a single line protocol record
+func (m SyncMetrics) Line() string {
+	tags := map[string]string{"project": m.Project, "status": m.Status}
+	for k, v := range m.Labels {
+		tags[k] = v
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if tags[k] != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("apidoc_sync")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, ",%s=%s", escapeTag(k), escapeTag(tags[k]))
+	}
+	fmt.Fprintf(&buf, " duration_ms=%di,endpoints=%di,schemas=%di,diff_added=%di,diff_removed=%di,diff_changed=%di,breaking=%di",
+		m.Duration.Milliseconds(), m.Endpoints, m.Schemas, m.Added, m.Removed, m.Changed, m.Breaking)
+
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	fmt.Fprintf(&buf, " %d\n", ts.UnixNano())
+	return buf.String()
+}
+
+// escapeTag escapes characters that are special in line protocol tag keys/values
+func escapeTag(s string) string {
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
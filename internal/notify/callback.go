@@ -0,0 +1,127 @@
+// Package notify delivers the outcome of analysis runs to the callback URLs
+// and chat channels configured per project, so CI pipelines, bots and people
+// can react to documentation updates.
+package notify
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/version"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// EventRunFinished is the event of every callback, sent in the X-ApiDoc-Event header
+const EventRunFinished = "run.finished"
+
+// SignatureHeader carries sha256=<hex HMAC-SHA256 of the body> when the callback has a secret
+const SignatureHeader = "X-Signature-256"
+
+// Payload is the JSON body POSTed to callback URLs
+type Payload struct {
+	Event string       `json:"event"`
+	Run   *history.Run `json:"run"`
+}
+
+// Notifier POSTs run results to callback URLs and chat channels. Failed
+// deliveries are retried with backoff; one that keeps failing is logged and
+// dropped.
+type Notifier struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+}
+
+// NewNotifier returns a Notifier that tries each delivery up to 3 times
+func NewNotifier() *Notifier {
+	return &Notifier{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		attempts: 3,
+		backoff:  2 * time.Second,
+	}
+}
+
+// Notify delivers payload to the callbacks that subscribe to the run status.
+// Deliveries run in the background, so a slow receiver never delays a sync.
+func (n *Notifier) Notify(callbacks []config.CallbackConfig, payload Payload) {
+	payload.Event = EventRunFinished
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode callback payload: %v", err)
+		return
+	}
+	for _, callback := range callbacks {
+		if !callback.Wants(payload.Run.Status) {
+			continue
+		}
+		callback := callback
+		go n.deliver("Callback to "+callback.URL, func() (bool, error) {
+			return n.post(callback, body)
+		})
+	}
+}
+
+// deliver calls send until it succeeds, it fails with an error that is not
+// worth retrying, or the attempts run out
+func (n *Notifier) deliver(name string, send func() (retry bool, err error)) {
+	wait := n.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := send()
+		if err == nil {
+			log.Printf("📨 %s delivered", name)
+			return
+		}
+		if !retry || attempt == n.attempts {
+			log.Printf("⚠️  %s failed after %d attempt(s): %v", name, attempt, err)
+			return
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post sends one callback delivery and reports whether a failure is worth retrying
+func (n *Notifier) post(callback config.CallbackConfig, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", callback.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "api-doc-generator/"+version.Version)
+	req.Header.Set("X-ApiDoc-Event", EventRunFinished)
+	for name, value := range callback.Headers {
+		req.Header.Set(name, value)
+	}
+	if callback.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(callback.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	_, retry, err := n.do(req)
+	return retry, err
+}
+
+// do sends req and returns the start of the response body. Unreachable
+// receivers, 429 and 5xx responses are worth retrying.
+func (n *Notifier) do(req *http.Request) ([]byte, bool, error) {
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return data, retry, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data))
+	}
+	return data, false, nil
+}
@@ -0,0 +1,266 @@
+package notify
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/openapi/diff"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorLength keeps long errors (e.g. a full Apifox response) from flooding the channel
+const maxErrorLength = 500
+
+// Summary is what a chat notification reports about a run
+type Summary struct {
+	Run *history.Run
+	// Changes are the most relevant changes since the last sync, out of
+	// TotalChanges in the full report
+	Changes      []diff.Change
+	TotalChanges int
+	Links        []Link // where the synced documentation can be viewed
+}
+
+// Link is a titled URL shown at the end of a notification
+type Link struct {
+	Title string
+	URL   string
+}
+
+// NotifyChat sends summary to the chat channels that subscribe to the run status
+func (n *Notifier) NotifyChat(channels []config.NotificationConfig, summary Summary) {
+	for _, channel := range channels {
+		if !channel.Wants(summary.Run.Status) {
+			continue
+		}
+		// The webhook URL holds the bot's access token, so it is not logged
+		channel := channel
+		go n.deliver(fmt.Sprintf("%s notification for %s", channel.Type, summary.Run.Project), func() (bool, error) {
+			return n.sendChat(channel, summary)
+		})
+	}
+}
+
+func (n *Notifier) sendChat(channel config.NotificationConfig, summary Summary) (bool, error) {
+	switch channel.Type {
+	case config.NotifySlack:
+		return n.sendSlack(channel, summary)
+	case config.NotifyDingTalk:
+		return n.sendDingTalk(channel, summary)
+	case config.NotifyFeishu:
+		return n.sendFeishu(channel, summary)
+	}
+	return false, fmt.Errorf("unsupported notification type: %s", channel.Type)
+}
+
+// markup renders the few formatting elements a notification uses in the
+// dialect of one chat service
+type markup struct {
+	bold      func(string) string
+	link      func(title, href string) string
+	separator string // between paragraphs
+}
+
+var (
+	slackMarkup = markup{
+		bold:      func(s string) string { return "*" + s + "*" },
+		link:      func(title, href string) string { return "<" + href + "|" + title + ">" },
+		separator: "\n",
+	}
+	// DingTalk and Feishu both take Markdown; DingTalk only breaks lines at blank lines
+	markdownMarkup = markup{
+		bold:      func(s string) string { return "**" + s + "**" },
+		link:      func(title, href string) string { return "[" + title + "](" + href + ")" },
+		separator: "\n\n",
+	}
+)
+
+var statusTitles = map[string]string{
+	config.RunSuccess: "✅ %s: API docs synced",
+	config.RunFailed:  "❌ %s: sync failed",
+	config.RunAborted: "⛔ %s: sync aborted",
+	config.RunSkipped: "⏭️ %s: sync skipped",
+}
+
+// title is the headline of the notification, e.g. "✅ user-service: API docs synced"
+func (s Summary) title() string {
+	format, ok := statusTitles[s.Run.Status]
+	if !ok {
+		format = "%s: " + s.Run.Status
+	}
+	return fmt.Sprintf(format, s.Run.Project)
+}
+
+// body renders everything below the title
+func (s Summary) body(m markup) string {
+	run := s.Run
+	var paragraphs []string
+
+	source := []string{m.bold("Branch") + " " + run.Branch}
+	if run.Commit != "" {
+		commit := run.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		source = append(source, m.bold("Commit")+" "+commit)
+	}
+	source = append(source, m.bold("Trigger")+" "+run.Trigger)
+	paragraphs = append(paragraphs, strings.Join(source, " · "))
+
+	if run.SpecHash != "" {
+		line := fmt.Sprintf("%s %d", m.bold("Endpoints"), run.Endpoints)
+		if run.DiffSummary != "" {
+			line += " · " + m.bold("Changes") + " " + run.DiffSummary
+		}
+		paragraphs = append(paragraphs, line)
+	}
+	if run.Error != "" {
+		err := run.Error
+		if len(err) > maxErrorLength {
+			err = err[:maxErrorLength] + "…"
+		}
+		paragraphs = append(paragraphs, m.bold("Error")+" "+err)
+	}
+	if len(s.Changes) > 0 {
+		lines := make([]string, 0, len(s.Changes)+1)
+		for _, change := range s.Changes {
+			// As code, so the leading +/- is not taken for a list item
+			lines = append(lines, "`"+change.String()+"`")
+		}
+		if more := s.TotalChanges - len(s.Changes); more > 0 {
+			lines = append(lines, fmt.Sprintf("…and %d more", more))
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, m.separator))
+	}
+	if len(s.Links) > 0 {
+		links := make([]string, len(s.Links))
+		for i, link := range s.Links {
+			links[i] = m.link(link.Title, link.URL)
+		}
+		paragraphs = append(paragraphs, strings.Join(links, " · "))
+	}
+	return strings.Join(paragraphs, m.separator)
+}
+
+// sendSlack posts to a Slack incoming webhook
+func (n *Notifier) sendSlack(channel config.NotificationConfig, summary Summary) (bool, error) {
+	message := map[string]interface{}{
+		"text": slackMarkup.bold(summary.title()) + "\n" + summary.body(slackMarkup),
+	}
+	_, retry, err := n.postJSON(channel.WebhookURL, message)
+	return retry, err
+}
+
+// sendDingTalk posts to a DingTalk group robot. With a secret, the request is
+// signed as described in the robot's 加签 setting.
+func (n *Notifier) sendDingTalk(channel config.NotificationConfig, summary Summary) (bool, error) {
+	target := channel.WebhookURL
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(channel.Secret))
+		mac.Write([]byte(timestamp + "\n" + channel.Secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+	title := summary.title()
+	message := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  "### " + title + "\n\n" + summary.body(markdownMarkup),
+		},
+	}
+	data, retry, err := n.postJSON(target, message)
+	if err != nil {
+		return retry, err
+	}
+
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return false, fmt.Errorf("unexpected DingTalk response: %s", string(data))
+	}
+	if resp.ErrCode != 0 {
+		// 130101: the robot's limit of 20 messages per minute
+		return resp.ErrCode == 130101, fmt.Errorf("DingTalk error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return false, nil
+}
+
+var feishuTemplates = map[string]string{
+	config.RunSuccess: "green",
+	config.RunFailed:  "red",
+	config.RunAborted: "orange",
+	config.RunSkipped: "grey",
+}
+
+// sendFeishu posts a message card to a Feishu (Lark) custom bot. With a
+// secret, the request is signed as described in the bot's 签名校验 setting.
+func (n *Notifier) sendFeishu(channel config.NotificationConfig, summary Summary) (bool, error) {
+	message := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"header": map[string]interface{}{
+				"title":    map[string]string{"tag": "plain_text", "content": summary.title()},
+				"template": feishuTemplates[summary.Run.Status],
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag":  "div",
+					"text": map[string]string{"tag": "lark_md", "content": summary.body(markdownMarkup)},
+				},
+			},
+		},
+	}
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+channel.Secret))
+		message["timestamp"] = timestamp
+		message["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	data, retry, err := n.postJSON(channel.WebhookURL, message)
+	if err != nil {
+		return retry, err
+	}
+
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return false, fmt.Errorf("unexpected Feishu response: %s", string(data))
+	}
+	if resp.Code != 0 {
+		// 11232: the bot's message frequency limit
+		return resp.Code == 11232, fmt.Errorf("Feishu error %d: %s", resp.Code, resp.Msg)
+	}
+	return false, nil
+}
+
+func (n *Notifier) postJSON(target string, message interface{}) ([]byte, bool, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequest("POST", target, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return n.do(req)
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Kinds of analysis warnings
+const (
+	WarnParseError        = "parse_error"        // a source file does not parse and was skipped
+	WarnSkippedFile       = "skipped_file"       // a source file was skipped, e.g. for its size
+	WarnUnresolvedHandler = "unresolved_handler" // the handler of a route was not found
+	WarnUnknownType       = "unknown_type"       // a type has no schema and fell back to an object
+	WarnMissingType       = "missing_type"       // no response type could be inferred
+	WarnGuessedType       = "guessed_type"       // a type was guessed rather than read from the code
+	WarnAnalysis          = "analysis"           // other issues, e.g. a fallback of the analysis mode
+)
+
+// AnalysisWarning is a non-fatal issue found while generating a spec.
+// Warnings about one operation carry its method and path.
+type AnalysisWarning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"` // relative to the project root
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Warn records a warning. The path of an operation warning may be given
+// before the operation is added, in any form AddPath accepts.
+func (s *Spec) Warn(warning AnalysisWarning) {
+	s.Warnings = append(s.Warnings, warning)
+}
+
+// How the payload types of an operation were determined
+const (
+	InferenceFull    = "inferred" // read from the code
+	InferenceGuessed = "guessed"  // typed, but with warnings about the operation
+	InferenceNone    = "untyped"  // a payload fell back to a generic object
+)
+
+// OperationInference is the inference status of one operation
+type OperationInference struct {
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Status   string   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// AnalysisReport tells which operations were fully inferred and which were
+// guessed or left untyped, along with every warning of the analysis
+type AnalysisReport struct {
+	Inferred   int                  `json:"inferred"`
+	Guessed    int                  `json:"guessed"`
+	Untyped    int                  `json:"untyped"`
+	Operations []OperationInference `json:"operations"`
+	Warnings   []AnalysisWarning    `json:"warnings"`
+}
+
+// AnalysisReport builds the report from the spec's coverage and warnings,
+// with operations ordered by path and method
+func (s *Spec) AnalysisReport() *AnalysisReport {
+	report := &AnalysisReport{Operations: []OperationInference{}, Warnings: []AnalysisWarning{}}
+	byOperation := make(map[string][]string)
+	for _, w := range s.Warnings {
+		if w.Path != "" {
+			w.Path = s.resolvePath(NormalizePath(w.Path))
+		}
+		report.Warnings = append(report.Warnings, w)
+		if w.Path != "" {
+			key := w.Method + " " + w.Path
+			byOperation[key] = append(byOperation[key], w.Message)
+		}
+	}
+	sort.SliceStable(report.Warnings, func(i, j int) bool {
+		a, b := report.Warnings[i], report.Warnings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.File+a.Path+a.Method < b.File+b.Path+b.Method
+	})
+
+	for _, c := range s.Coverage().Operations {
+		op := OperationInference{Method: c.Method, Path: c.Path, Warnings: byOperation[c.Method+" "+c.Path]}
+		switch {
+		case !c.Documented():
+			op.Status = InferenceNone
+			report.Untyped++
+		case len(op.Warnings) > 0:
+			op.Status = InferenceGuessed
+			report.Guessed++
+		default:
+			op.Status = InferenceFull
+			report.Inferred++
+		}
+		report.Operations = append(report.Operations, op)
+	}
+	return report
+}
+
+// Summary returns a one-line summary, e.g. "12 inferred, 2 guessed, 1 untyped, 4 warning(s)"
+func (r *AnalysisReport) Summary() string {
+	return fmt.Sprintf("%d inferred, %d guessed, %d untyped, %d warning(s)", r.Inferred, r.Guessed, r.Untyped, len(r.Warnings))
+}
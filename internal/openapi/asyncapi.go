@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// AsyncAPIVersion is the version of the AsyncAPI documents generated
+const AsyncAPIVersion = "2.6.0"
+
+// Directions of a message channel, from the service's point of view
+const (
+	MessagePublish = "publish" // the service produces messages on the channel
+	MessageConsume = "consume" // the service consumes messages from the channel
+)
+
+// Message is a Kafka topic, RabbitMQ queue or similar channel the service
+// produces to or consumes from, with its payload component schema. Messages
+// are kept in memory and documented by AsyncAPI, not by the OpenAPI document.
+type Message struct {
+	Channel   string
+	Direction string // MessagePublish or MessageConsume
+	Protocol  string // kafka, amqp..., empty when unknown
+	Schema    string // payload component schema name
+	Summary   string
+}
+
+// AddMessage records a message channel of the service. A channel declared
+// again in the same direction replaces the earlier declaration.
+func (s *Spec) AddMessage(message Message) {
+	for i, existing := range s.Messages {
+		if existing.Channel == message.Channel && existing.Direction == message.Direction {
+			s.Messages[i] = message
+			return
+		}
+	}
+	s.Messages = append(s.Messages, message)
+}
+
+// AsyncAPI is an AsyncAPI 2.6 document describing the message channels of a
+// service, generated alongside its OpenAPI document
+type AsyncAPI struct {
+	AsyncAPI   string                  `json:"asyncapi"`
+	Info       Info                    `json:"info"`
+	Channels   map[string]AsyncChannel `json:"channels"`
+	Components *Components             `json:"components,omitempty"`
+}
+
+// AsyncChannel is a channel of an AsyncAPI document. As defined by AsyncAPI
+// 2.x, publish describes the messages other applications send to the
+// service, which consumes them, and subscribe the messages the service
+// produces.
+type AsyncChannel struct {
+	Description string          `json:"description,omitempty"`
+	Publish     *AsyncOperation `json:"publish,omitempty"`
+	Subscribe   *AsyncOperation `json:"subscribe,omitempty"`
+	Protocol    string          `json:"x-protocol,omitempty"`
+}
+
+type AsyncOperation struct {
+	OperationID string       `json:"operationId,omitempty"`
+	Summary     string       `json:"summary,omitempty"`
+	Message     AsyncMessage `json:"message"`
+}
+
+type AsyncMessage struct {
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"contentType"`
+	Payload     Schema `json:"payload"`
+}
+
+// AsyncAPI returns the AsyncAPI document of the spec's messages, with the
+// component schemas their payloads refer to, or nil when the service
+// declares no messages
+func (s *Spec) AsyncAPI() *AsyncAPI {
+	if len(s.Messages) == 0 {
+		return nil
+	}
+	doc := &AsyncAPI{
+		AsyncAPI: AsyncAPIVersion,
+		Info:     s.Info,
+		Channels: make(map[string]AsyncChannel),
+	}
+	refs := make(map[string]bool)
+	for _, message := range s.Messages {
+		channel := doc.Channels[message.Channel]
+		if channel.Protocol == "" {
+			channel.Protocol = message.Protocol
+		}
+		op := &AsyncOperation{
+			OperationID: asyncOperationID(message),
+			Summary:     message.Summary,
+			Message: AsyncMessage{
+				Name:        message.Schema,
+				ContentType: "application/json",
+				Payload:     Schema{Ref: SchemaRefPrefix + message.Schema},
+			},
+		}
+		if message.Direction == MessageConsume {
+			channel.Publish = op
+		} else {
+			channel.Subscribe = op
+		}
+		doc.Channels[message.Channel] = channel
+		refs[message.Schema] = true
+	}
+
+	// Payload schemas and the schemas they refer to
+	schemas := make(map[string]Schema)
+	queue := make([]string, 0, len(refs))
+	for name := range refs {
+		queue = append(queue, name)
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, done := schemas[name]; done {
+			continue
+		}
+		schema, ok := s.schema(name)
+		if !ok {
+			schema = Schema{Type: "object"}
+		}
+		schemas[name] = schema
+		nested := make(map[string]bool)
+		CollectRefs(schema, nested)
+		for ref := range nested {
+			queue = append(queue, ref)
+		}
+	}
+	doc.Components = &Components{Schemas: schemas}
+	return doc
+}
+
+// schema returns a component schema of the spec
+func (s *Spec) schema(name string) (Schema, bool) {
+	if s.Components == nil {
+		return Schema{}, false
+	}
+	schema, ok := s.Components.Schemas[name]
+	return schema, ok
+}
+
+// asyncOperationID names an operation after its direction and channel:
+// consumeOrderPaid for order.paid
+func asyncOperationID(message Message) string {
+	id := message.Direction
+	for _, part := range strings.FieldsFunc(message.Channel, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '/' || r == ':'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
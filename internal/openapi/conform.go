@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CheckValue validates a decoded JSON value (as produced by encoding/json
+// with UseNumber or plain float64 numbers) against schema and returns one
+// message per mismatch, prefixed with the JSON path of the offending field.
+// Schemas without a type accept any value; null is accepted for properties
+// that are not required, since Go pointer fields are encoded as null.
+func (s *Spec) CheckValue(schema Schema, value interface{}) []string {
+	var problems []string
+	s.checkValue(schema, value, "$", map[string]bool{}, &problems)
+	return problems
+}
+
+func (s *Spec) checkValue(schema Schema, value interface{}, path string, visiting map[string]bool, problems *[]string) {
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if name := RefName(schema.Ref); name != "" {
+		// Guards against a schema that references itself without nesting
+		if visiting[name+"@"+path] || s.Components == nil {
+			return
+		}
+		target, ok := s.Components.Schemas[name]
+		if !ok {
+			return
+		}
+		visiting[name+"@"+path] = true
+		s.checkValue(target, value, path, visiting, problems)
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		report("value %v is not one of %v", value, schema.Enum)
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			report("expected string, got %s", jsonType(value))
+			return
+		}
+		if schema.MinLength != nil && len([]rune(str)) < *schema.MinLength {
+			report("string shorter than minLength %d", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len([]rune(str)) > *schema.MaxLength {
+			report("string longer than maxLength %d", *schema.MaxLength)
+		}
+	case "integer", "number":
+		n, ok := toFloat(value)
+		if !ok {
+			report("expected %s, got %s", schema.Type, jsonType(value))
+			return
+		}
+		if schema.Type == "integer" && n != float64(int64(n)) {
+			report("expected integer, got %v", n)
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			report("%v is less than minimum %v", n, *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			report("%v is greater than maximum %v", n, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("expected boolean, got %s", jsonType(value))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			report("expected array, got %s", jsonType(value))
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			report("array has fewer than %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			report("array has more than %d items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				s.checkValue(*schema.Items, item, fmt.Sprintf("%s[%d]", path, i), visiting, problems)
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("expected object, got %s", jsonType(value))
+			return
+		}
+		s.checkObject(schema, object, path, visiting, problems)
+	case "":
+		if object, ok := value.(map[string]interface{}); ok && len(schema.Properties) > 0 {
+			s.checkObject(schema, object, path, visiting, problems)
+		}
+	}
+}
+
+func (s *Spec) checkObject(schema Schema, object map[string]interface{}, path string, visiting map[string]bool, problems *[]string) {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+		if _, ok := object[name]; !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := object[name]
+		prop, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil {
+				s.checkValue(*schema.AdditionalProperties, value, path+"."+name, visiting, problems)
+			}
+			continue
+		}
+		if value == nil && !required[name] {
+			continue
+		}
+		s.checkValue(prop, value, path+"."+name, visiting, problems)
+	}
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
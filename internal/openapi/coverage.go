@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OperationCoverage describes how completely one operation is documented,
+// together with the largest request and response payloads its schemas allow
+type OperationCoverage struct {
+	Method         string        `json:"method"`
+	Path           string        `json:"path"`
+	Described      bool          `json:"described"`       // has a description
+	RequestSchema  bool          `json:"request_schema"`  // request body is typed (true when there is no body)
+	ResponseSchema bool          `json:"response_schema"` // success response is typed
+	RequestSize    *SizeEstimate `json:"request_size,omitempty"`
+	ResponseSize   *SizeEstimate `json:"response_size,omitempty"`
+}
+
+// Documented reports whether both request and response payloads are typed
+func (c OperationCoverage) Documented() bool {
+	return c.RequestSchema && c.ResponseSchema
+}
+
+// CoverageReport summarises documentation coverage of a spec
+type CoverageReport struct {
+	Operations []OperationCoverage `json:"operations"`
+}
+
+// Coverage builds the coverage report of the spec, ordered by path and method
+func (s *Spec) Coverage() *CoverageReport {
+	report := &CoverageReport{Operations: []OperationCoverage{}}
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		c := OperationCoverage{
+			Method:        method,
+			Path:          path,
+			Described:     op.Description != "",
+			RequestSchema: true,
+		}
+		if op.RequestBody != nil {
+			if media, ok := jsonContent(op.RequestBody.Content); ok {
+				size := s.EstimateSize(media.Schema)
+				c.RequestSize = &size
+				c.RequestSchema = isTypedSchema(media.Schema)
+			}
+		}
+		if resp, ok := op.Responses["200"]; ok {
+			if media, ok := jsonContent(resp.Content); ok {
+				size := s.EstimateSize(media.Schema)
+				c.ResponseSize = &size
+				// Responses wrapped in {code, message, data} are judged by their payload
+				payload := media.Schema
+				dataField := s.EnvelopeData
+				if dataField == "" {
+					dataField = "data"
+				}
+				if data, ok := payload.Properties[dataField]; ok {
+					payload = data
+				}
+				c.ResponseSchema = isTypedSchema(payload)
+			} else {
+				// Text, XML and file responses are documented by their media type
+				c.ResponseSchema = len(resp.Content) > 0
+			}
+		} else {
+			c.ResponseSchema = hasRedirect(op)
+		}
+		report.Operations = append(report.Operations, c)
+	})
+	return report
+}
+
+// Percent returns the share of operations with typed request and response payloads
+func (r *CoverageReport) Percent() float64 {
+	if len(r.Operations) == 0 {
+		return 100
+	}
+	documented := 0
+	for _, c := range r.Operations {
+		if c.Documented() {
+			documented++
+		}
+	}
+	return float64(documented) * 100 / float64(len(r.Operations))
+}
+
+// Untyped returns the operations whose request or response payload fell
+// back to a generic object
+func (r *CoverageReport) Untyped() []OperationCoverage {
+	var untyped []OperationCoverage
+	for _, c := range r.Operations {
+		if !c.Documented() {
+			untyped = append(untyped, c)
+		}
+	}
+	return untyped
+}
+
+// CheckCompleteness fails when more than maxUntypedPercent of the operations
+// have untyped payloads, so inference gaps are fixed before docs regress further
+func (r *CoverageReport) CheckCompleteness(maxUntypedPercent float64) error {
+	untyped := 100 - r.Percent()
+	if untyped > maxUntypedPercent {
+		return fmt.Errorf("%.1f%% of operations (%d/%d) have untyped request or response payloads, limit is %.1f%%",
+			untyped, len(r.Untyped()), len(r.Operations), maxUntypedPercent)
+	}
+	return nil
+}
+
+// Oversized returns the operations whose request or response schemas allow
+// more than limit bytes through their explicit bounds, such as maxLength and
+// maxItems. Payloads without an upper bound, like any string without
+// maxLength, are not reported; their estimate lists the unbounded fields.
+func (r *CoverageReport) Oversized(limit int64) []OperationCoverage {
+	exceeds := func(e *SizeEstimate) bool {
+		return e != nil && e.Bytes > limit
+	}
+	var oversized []OperationCoverage
+	for _, c := range r.Operations {
+		if exceeds(c.RequestSize) || exceeds(c.ResponseSize) {
+			oversized = append(oversized, c)
+		}
+	}
+	return oversized
+}
+
+func (r *CoverageReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Operations {
+		fmt.Fprintf(&b, "%-7s %s\n", c.Method, c.Path)
+		if c.RequestSize != nil {
+			fmt.Fprintf(&b, "        request:  %s\n", c.RequestSize)
+		}
+		if c.ResponseSize != nil {
+			fmt.Fprintf(&b, "        response: %s\n", c.ResponseSize)
+		}
+		if !c.Documented() {
+			fmt.Fprintf(&b, "        untyped payload\n")
+		}
+	}
+	return b.String()
+}
+
+// hasRedirect reports whether the operation documents a 3xx response, the
+// success response of handlers that only redirect
+func hasRedirect(op *Operation) bool {
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "3") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonContent returns the JSON media type of a request or response content map
+func jsonContent(content map[string]MediaType) (MediaType, bool) {
+	media, ok := content["application/json"]
+	return media, ok
+}
+
+// isTypedSchema reports whether the schema describes a concrete shape rather
+// than the generic object used when a type could not be resolved
+func isTypedSchema(schema Schema) bool {
+	if schema.Ref != "" || len(schema.Properties) > 0 {
+		return true
+	}
+	if schema.Type == "array" {
+		return schema.Items != nil && isTypedSchema(*schema.Items)
+	}
+	return schema.Type != "" && schema.Type != "object"
+}
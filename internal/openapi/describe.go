@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DescriptionContext is the project level data of description templates
+type DescriptionContext struct {
+	Project     string
+	Team        string
+	Branch      string
+	Commit      string
+	ShortCommit string
+	CommitTime  time.Time
+}
+
+// DescriptionData is what a description template is executed with for each
+// operation. Handler and the source fields are empty for operations the
+// parser didn't generate from a handler.
+type DescriptionData struct {
+	DescriptionContext
+	Method      string
+	Path        string
+	Summary     string
+	Description string // the generated description, e.g. timeouts and body limits
+	Handler     string
+	SourceFile  string
+	SourceLine  int
+}
+
+// ParseDescriptionTemplate parses a description template, trying it on
+// sample data so references to unknown fields are reported up front
+func ParseDescriptionTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("description").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	sample := DescriptionData{Method: "GET", Path: "/users/{id}", Summary: "Get User", Handler: "GetUser"}
+	if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// ApplyDescriptionTemplate replaces the description of every operation with
+// the rendered template. Webhooks are left alone. Run it once, on freshly
+// generated operations: the generated description is an input of the
+// template.
+func (s *Spec) ApplyDescriptionTemplate(text string, ctx DescriptionContext) error {
+	if text == "" {
+		return nil
+	}
+	tmpl, err := ParseDescriptionTemplate(text)
+	if err != nil {
+		return fmt.Errorf("description_template: %w", err)
+	}
+	var failed error
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		data := DescriptionData{
+			DescriptionContext: ctx,
+			Method:             method,
+			Path:               path,
+			Summary:            op.Summary,
+			Description:        op.Description,
+		}
+		if op.Origin != nil {
+			data.Handler, data.SourceFile, data.SourceLine = op.Origin.Handler, op.Origin.File, op.Origin.Line
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			if failed == nil {
+				failed = fmt.Errorf("description_template: %s %s: %w", method, path, err)
+			}
+			return
+		}
+		op.Description = strings.TrimSpace(buf.String())
+	})
+	return failed
+}
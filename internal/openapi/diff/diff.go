@@ -0,0 +1,536 @@
+// Package diff compares two OpenAPI specs and reports what changed between them
+package diff
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind describes the direction of a change
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Category is the part of the spec a change belongs to
+type Category string
+
+const (
+	CategoryEndpoint  Category = "endpoint"
+	CategoryParameter Category = "parameter"
+	CategoryRequest   Category = "request"
+	CategoryResponse  Category = "response"
+	CategorySchema    Category = "schema"
+	CategoryField     Category = "field"
+)
+
+// Change is a single difference between two specs
+type Change struct {
+	Kind     Kind     `json:"kind"`
+	Category Category `json:"category"`
+	Location string   `json:"location"` // "GET /users", "schema User"
+	Name     string   `json:"name,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	Breaking bool     `json:"breaking"`
+}
+
+// String renders the change as one changelog line
+func (c Change) String() string {
+	symbol := map[Kind]string{Added: "+", Removed: "-", Changed: "~"}[c.Kind]
+	line := fmt.Sprintf("%s %s", symbol, c.Location)
+	if c.Category != CategoryEndpoint && c.Category != CategorySchema {
+		line += fmt.Sprintf(": %s %s %s", c.Category, c.Name, c.Kind)
+	}
+	if c.Detail != "" {
+		line += " (" + c.Detail + ")"
+	}
+	if c.Breaking {
+		line += " [BREAKING]"
+	}
+	return line
+}
+
+// Report is the result of comparing two specs
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasChanges reports whether the specs differ
+func (r *Report) HasChanges() bool {
+	return len(r.Changes) > 0
+}
+
+// Count returns how many changes of the given kind are in the report
+func (r *Report) Count(kind Kind) int {
+	count := 0
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Breaking returns the changes that can break existing clients
+func (r *Report) Breaking() []Change {
+	var breaking []Change
+	for _, c := range r.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// HasBreaking reports whether any change can break existing clients
+func (r *Report) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// Highlights returns up to max changes worth pointing out first: breaking
+// changes, then added and removed endpoints, then the rest in report order
+func (r *Report) Highlights(max int) []Change {
+	rank := func(c Change) int {
+		switch {
+		case c.Breaking:
+			return 0
+		case c.Category == CategoryEndpoint:
+			return 1
+		}
+		return 2
+	}
+	changes := append([]Change(nil), r.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool { return rank(changes[i]) < rank(changes[j]) })
+	if len(changes) > max {
+		changes = changes[:max]
+	}
+	return changes
+}
+
+// Summary returns a one-line overview, e.g. "2 added, 1 removed, 3 changed (1 breaking)"
+func (r *Report) Summary() string {
+	if !r.HasChanges() {
+		return "no changes"
+	}
+	summary := fmt.Sprintf("%d added, %d removed, %d changed", r.Count(Added), r.Count(Removed), r.Count(Changed))
+	if n := len(r.Breaking()); n > 0 {
+		summary += fmt.Sprintf(" (%d breaking)", n)
+	}
+	return summary
+}
+
+// String renders the full human-readable changelog
+func (r *Report) String() string {
+	if !r.HasChanges() {
+		return "No API changes"
+	}
+	lines := make([]string, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EndpointChanges lists the endpoints ("GET /users") touched by a report
+type EndpointChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"` // parameters, request or response changed
+}
+
+// Endpoints groups the changes by endpoint. Changes to shared schemas are
+// not attributed to the endpoints that use them.
+func (r *Report) Endpoints() EndpointChanges {
+	var endpoints EndpointChanges
+	seen := make(map[string]bool)
+	for _, c := range r.Changes {
+		switch {
+		case c.Category == CategoryEndpoint && c.Kind == Added:
+			endpoints.Added = append(endpoints.Added, c.Location)
+		case c.Category == CategoryEndpoint && c.Kind == Removed:
+			endpoints.Removed = append(endpoints.Removed, c.Location)
+		case !strings.HasPrefix(c.Location, "schema "):
+			endpoint := c.EndpointLocation()
+			if !seen[endpoint] {
+				seen[endpoint] = true
+				endpoints.Changed = append(endpoints.Changed, endpoint)
+			}
+		}
+	}
+	return endpoints
+}
+
+// EndpointLocation returns the endpoint of a change inside an operation,
+// e.g. "GET /users" for a field of "GET /users response 200"
+func (c Change) EndpointLocation() string {
+	parts := strings.SplitN(c.Location, " ", 3)
+	if len(parts) < 2 {
+		return c.Location
+	}
+	return parts[0] + " " + parts[1]
+}
+
+// Compare reports the differences from oldSpec to newSpec. A nil oldSpec is
+// treated as empty, so every endpoint and schema shows up as added.
+func Compare(oldSpec, newSpec *openapi.Spec) *Report {
+	if oldSpec == nil {
+		oldSpec = openapi.NewSpec()
+	}
+	if newSpec == nil {
+		newSpec = openapi.NewSpec()
+	}
+
+	report := &Report{}
+	report.compareOperations(oldSpec, newSpec)
+	usages := schemaUsages(oldSpec)
+	for name, use := range schemaUsages(newSpec) {
+		usages[name] |= use
+	}
+	report.compareSchemas(schemasOf(oldSpec), schemasOf(newSpec), usages)
+	return report
+}
+
+// usage tells whether a schema describes what clients send, what they
+// receive, or both, which decides whether tightening or loosening it breaks
+// them
+type usage int
+
+const (
+	inRequest usage = 1 << iota
+	inResponse
+	inBoth = inRequest | inResponse
+)
+
+func (r *Report) add(kind Kind, category Category, location, name, detail string) {
+	use := inBoth
+	switch category {
+	case CategoryParameter, CategoryRequest:
+		use = inRequest
+	case CategoryResponse:
+		use = inResponse
+	}
+	r.addUsed(use, kind, category, location, name, detail)
+}
+
+func (r *Report) addUsed(use usage, kind Kind, category Category, location, name, detail string) {
+	change := Change{
+		Kind:     kind,
+		Category: category,
+		Location: location,
+		Name:     name,
+		Detail:   detail,
+	}
+	change.Breaking = isBreaking(change, use)
+	r.Changes = append(r.Changes, change)
+}
+
+// isBreaking classifies a change: removed endpoints, removed required fields,
+// type changes, newly required inputs and outputs that are no longer
+// guaranteed break existing clients
+func isBreaking(c Change, use usage) bool {
+	switch c.Kind {
+	case Removed:
+		switch c.Category {
+		case CategoryEndpoint:
+			return true
+		case CategoryField:
+			// Clients only miss a removed field they read
+			return c.Detail == "required" && use&inResponse != 0
+		}
+	case Added:
+		switch c.Category {
+		case CategoryParameter, CategoryField:
+			return c.Detail == "required" && use&inRequest != 0
+		}
+	case Changed:
+		switch c.Detail {
+		case requiredChange(true):
+			return use&inRequest != 0
+		case requiredChange(false):
+			return use&inResponse != 0
+		}
+		// any other change is a type change
+		return true
+	}
+	return false
+}
+
+// schemaUsages returns how the operations of a spec use its component
+// schemas, directly or through other schemas
+func schemaUsages(spec *openapi.Spec) map[string]usage {
+	usages := make(map[string]usage)
+	var mark func(schema openapi.Schema, use usage)
+	mark = func(schema openapi.Schema, use usage) {
+		refs := make(map[string]bool)
+		openapi.CollectRefs(schema, refs)
+		for name := range refs {
+			if usages[name]&use == use {
+				continue
+			}
+			usages[name] |= use
+			if component, ok := schemasOf(spec)[name]; ok {
+				mark(component, use)
+			}
+		}
+	}
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		for _, param := range op.Parameters {
+			mark(param.Schema, inRequest)
+		}
+		if schema := requestSchema(op); schema != nil {
+			mark(*schema, inRequest)
+		}
+		for _, resp := range op.Responses {
+			for _, media := range resp.Content {
+				mark(media.Schema, inResponse)
+			}
+		}
+	})
+	return usages
+}
+
+func (r *Report) compareOperations(oldSpec, newSpec *openapi.Spec) {
+	oldOps := operationsOf(oldSpec)
+	newOps := operationsOf(newSpec)
+
+	for _, key := range sortedKeys(oldOps, newOps) {
+		oldOp, inOld := oldOps[key]
+		newOp, inNew := newOps[key]
+		switch {
+		case !inOld:
+			r.add(Added, CategoryEndpoint, key, "", "")
+		case !inNew:
+			r.add(Removed, CategoryEndpoint, key, "", "")
+		default:
+			r.compareParameters(key, oldOp.Parameters, newOp.Parameters)
+			r.compareBodies(key, oldOp, newOp)
+		}
+	}
+}
+
+func (r *Report) compareParameters(location string, oldParams, newParams []openapi.Parameter) {
+	index := func(params []openapi.Parameter) map[string]openapi.Parameter {
+		m := make(map[string]openapi.Parameter)
+		for _, p := range params {
+			m[p.Name+" ("+p.In+")"] = p
+		}
+		return m
+	}
+	oldIndex := index(oldParams)
+	newIndex := index(newParams)
+
+	for _, name := range sortedKeys(oldIndex, newIndex) {
+		oldParam, inOld := oldIndex[name]
+		newParam, inNew := newIndex[name]
+		switch {
+		case !inOld:
+			r.add(Added, CategoryParameter, location, name, requiredDetail(newParam.Required))
+		case !inNew:
+			r.add(Removed, CategoryParameter, location, name, "")
+		default:
+			if detail := schemaChange(oldParam.Schema, newParam.Schema); detail != "" {
+				r.add(Changed, CategoryParameter, location, name, detail)
+			}
+			if oldParam.Required != newParam.Required {
+				r.add(Changed, CategoryParameter, location, name, requiredChange(newParam.Required))
+			}
+		}
+	}
+}
+
+func (r *Report) compareBodies(location string, oldOp, newOp *openapi.Operation) {
+	oldReq, newReq := requestSchema(oldOp), requestSchema(newOp)
+	switch {
+	case oldReq == nil && newReq != nil:
+		r.add(Added, CategoryRequest, location, "body", describe(*newReq))
+	case oldReq != nil && newReq == nil:
+		r.add(Removed, CategoryRequest, location, "body", "")
+	case oldReq != nil && newReq != nil:
+		r.compareBody(location, location+" request", CategoryRequest, "body", *oldReq, *newReq)
+	}
+
+	for _, code := range sortedKeys(oldOp.Responses, newOp.Responses) {
+		oldResp, inOld := oldOp.Responses[code]
+		newResp, inNew := newOp.Responses[code]
+		switch {
+		case !inOld:
+			r.add(Added, CategoryResponse, location, code, "")
+		case !inNew:
+			r.add(Removed, CategoryResponse, location, code, "")
+		default:
+			oldSchema, newSchema := contentSchema(oldResp.Content), contentSchema(newResp.Content)
+			if oldSchema != nil && newSchema != nil {
+				r.compareBody(location, location+" response "+code, CategoryResponse, code, *oldSchema, *newSchema)
+			}
+		}
+	}
+}
+
+// compareBody compares inline objects (such as the response envelope) field
+// by field and everything else by shape
+func (r *Report) compareBody(location, fieldLocation string, category Category, name string, oldSchema, newSchema openapi.Schema) {
+	if oldSchema.Ref == "" && newSchema.Ref == "" && oldSchema.Properties != nil && newSchema.Properties != nil {
+		use := inRequest
+		if category == CategoryResponse {
+			use = inResponse
+		}
+		r.compareFields(use, fieldLocation, oldSchema, newSchema)
+		return
+	}
+	if detail := schemaChange(oldSchema, newSchema); detail != "" {
+		r.add(Changed, category, location, name, detail)
+	}
+}
+
+// compareSchemas compares component schemas, classifying their changes by
+// how operations use them; schemas no operation uses count as both inputs
+// and outputs
+func (r *Report) compareSchemas(oldSchemas, newSchemas map[string]openapi.Schema, usages map[string]usage) {
+	for _, name := range sortedKeys(oldSchemas, newSchemas) {
+		location := "schema " + name
+		oldSchema, inOld := oldSchemas[name]
+		newSchema, inNew := newSchemas[name]
+		switch {
+		case !inOld:
+			r.add(Added, CategorySchema, location, "", "")
+		case !inNew:
+			r.add(Removed, CategorySchema, location, "", "")
+		default:
+			use := usages[name]
+			if use == 0 {
+				use = inBoth
+			}
+			r.compareFields(use, location, oldSchema, newSchema)
+		}
+	}
+}
+
+func (r *Report) compareFields(use usage, location string, oldSchema, newSchema openapi.Schema) {
+	oldRequired := toSet(oldSchema.Required)
+	newRequired := toSet(newSchema.Required)
+
+	for _, field := range sortedKeys(oldSchema.Properties, newSchema.Properties) {
+		oldField, inOld := oldSchema.Properties[field]
+		newField, inNew := newSchema.Properties[field]
+		switch {
+		case !inOld:
+			r.addUsed(use, Added, CategoryField, location, field, requiredDetail(newRequired[field]))
+		case !inNew:
+			r.addUsed(use, Removed, CategoryField, location, field, requiredDetail(oldRequired[field]))
+		default:
+			if detail := schemaChange(oldField, newField); detail != "" {
+				r.addUsed(use, Changed, CategoryField, location, field, detail)
+			}
+			if oldRequired[field] != newRequired[field] {
+				r.addUsed(use, Changed, CategoryField, location, field, requiredChange(newRequired[field]))
+			}
+		}
+	}
+}
+
+// schemaChange describes a change of shape between two schemas, or "" if none
+func schemaChange(oldSchema, newSchema openapi.Schema) string {
+	oldDesc, newDesc := describe(oldSchema), describe(newSchema)
+	if oldDesc == newDesc {
+		return ""
+	}
+	return oldDesc + " -> " + newDesc
+}
+
+// describe renders the shape of a schema: "string(date-time)", "User", "array of User"
+func describe(s openapi.Schema) string {
+	if name := openapi.RefName(s.Ref); name != "" {
+		return name
+	}
+	switch s.Type {
+	case "array":
+		if s.Items != nil {
+			return "array of " + describe(*s.Items)
+		}
+		return "array"
+	case "":
+		return "any"
+	}
+	if s.Format != "" {
+		return s.Type + "(" + s.Format + ")"
+	}
+	return s.Type
+}
+
+func requiredDetail(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+func requiredChange(nowRequired bool) string {
+	if nowRequired {
+		return "optional -> required"
+	}
+	return "required -> optional"
+}
+
+func requestSchema(op *openapi.Operation) *openapi.Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return contentSchema(op.RequestBody.Content)
+}
+
+// contentSchema picks the JSON schema of a content map, falling back to any content type
+func contentSchema(content map[string]openapi.MediaType) *openapi.Schema {
+	if media, ok := content["application/json"]; ok {
+		return &media.Schema
+	}
+	for _, contentType := range sortedKeys(content, nil) {
+		media := content[contentType]
+		return &media.Schema
+	}
+	return nil
+}
+
+func operationsOf(spec *openapi.Spec) map[string]*openapi.Operation {
+	ops := make(map[string]*openapi.Operation)
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		ops[method+" "+path] = op
+	})
+	return ops
+}
+
+func schemasOf(spec *openapi.Spec) map[string]openapi.Schema {
+	if spec.Components == nil {
+		return nil
+	}
+	return spec.Components.Schemas
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// sortedKeys returns the union of the keys of both maps in sorted order
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
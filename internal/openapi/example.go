@@ -0,0 +1,116 @@
+package openapi
+
+import "strings"
+
+// formatExamples are sample values of string formats
+var formatExamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"time":      "12:00:00",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.168.0.1",
+	"ipv6":      "::1",
+	"byte":      "U3dhZ2dlciByb2Nrcw==",
+	"password":  "********",
+}
+
+// Example builds a sample value for schema: declared examples and enum values
+// are used as-is, everything else gets a placeholder of the right type and
+// format. Component references are resolved; a recursive reference yields nil.
+func (s *Spec) Example(schema Schema) interface{} {
+	return s.example(schema, map[string]bool{})
+}
+
+func (s *Spec) example(schema Schema, visiting map[string]bool) interface{} {
+	if name := RefName(schema.Ref); name != "" {
+		if visiting[name] || s.Components == nil {
+			return nil
+		}
+		target, ok := s.Components.Schemas[name]
+		if !ok {
+			return nil
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return s.example(target, visiting)
+	}
+
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "string":
+		value, ok := formatExamples[schema.Format]
+		if !ok {
+			value = "string"
+		}
+		if schema.MinLength != nil && len(value) < *schema.MinLength {
+			value += strings.Repeat("x", *schema.MinLength-len(value))
+		}
+		if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+			value = value[:*schema.MaxLength]
+		}
+		return value
+	case "integer":
+		return int64(exampleNumber(schema))
+	case "number":
+		return exampleNumber(schema)
+	case "boolean":
+		return true
+	case "array":
+		items := []interface{}{}
+		if schema.Items == nil {
+			return items
+		}
+		count := 1
+		if schema.MinItems != nil && *schema.MinItems > count {
+			count = *schema.MinItems
+		}
+		if schema.MaxItems != nil && *schema.MaxItems < count {
+			count = *schema.MaxItems
+		}
+		item := s.example(*schema.Items, visiting)
+		if item == nil {
+			// Recursive item type: an empty list ends the recursion
+			return items
+		}
+		for i := 0; i < count; i++ {
+			items = append(items, item)
+		}
+		return items
+	}
+
+	// Objects, including schemas without a type
+	object := make(map[string]interface{}, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		object[name] = s.example(prop, visiting)
+	}
+	if len(schema.Properties) == 0 && schema.AdditionalProperties != nil {
+		object["key"] = s.example(*schema.AdditionalProperties, visiting)
+	}
+	return object
+}
+
+// exampleNumber returns the minimum when it is positive, 0 clamped to the
+// allowed range otherwise
+func exampleNumber(schema Schema) float64 {
+	value := 0.0
+	if schema.Minimum != nil && *schema.Minimum > value {
+		value = *schema.Minimum
+	}
+	if schema.Maximum != nil && *schema.Maximum < value {
+		value = *schema.Maximum
+	}
+	return value
+}
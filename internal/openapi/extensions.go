@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SetExtension sets a vendor extension (x-...) on the operation
+func (o *Operation) SetExtension(name string, value interface{}) {
+	if o.Extensions == nil {
+		o.Extensions = make(map[string]interface{})
+	}
+	o.Extensions[name] = value
+}
+
+// MarshalJSON inlines vendor extensions next to the regular operation fields
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type plain Operation
+	return marshalWithExtensions(plain(o), o.Extensions)
+}
+
+// UnmarshalJSON reads the inlined vendor extensions back into Extensions
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type plain Operation
+	extensions, err := unmarshalWithExtensions(data, (*plain)(o))
+	if err != nil {
+		return err
+	}
+	o.Extensions = extensions
+	return nil
+}
+
+// SetExtension sets a document level vendor extension (x-...)
+func (s *Spec) SetExtension(name string, value interface{}) {
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]interface{})
+	}
+	s.Extensions[name] = value
+}
+
+// MarshalJSON inlines vendor extensions after the regular document fields
+func (s Spec) MarshalJSON() ([]byte, error) {
+	type plain Spec
+	return marshalWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalJSON reads the document level vendor extensions back into Extensions
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type plain Spec
+	extensions, err := unmarshalWithExtensions(data, (*plain)(s))
+	if err != nil {
+		return err
+	}
+	s.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON inlines vendor extensions next to the tag's name and description
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type plain Tag
+	return marshalWithExtensions(plain(t), t.Extensions)
+}
+
+// UnmarshalJSON reads the tag's vendor extensions back into Extensions
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	type plain Tag
+	extensions, err := unmarshalWithExtensions(data, (*plain)(t))
+	if err != nil {
+		return err
+	}
+	t.Extensions = extensions
+	return nil
+}
+
+// marshalWithExtensions marshals v and appends extensions to the resulting
+// object, sorted by name, so the regular fields keep their order
+func marshalWithExtensions(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1]) // without the closing brace
+	for i, name := range names {
+		raw, err := json.Marshal(extensions[name])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 || len(data) > 2 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalWithExtensions unmarshals data into v and returns the x- keys of
+// the object, which v has no fields for; nil when there are none
+func unmarshalWithExtensions(data []byte, v interface{}) (map[string]interface{}, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var extensions map[string]interface{}
+	for name, raw := range fields {
+		if !strings.HasPrefix(name, "x-") {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+		}
+		extensions[name] = value
+	}
+	return extensions, nil
+}
+
+// ExtensionRules inject vendor extensions, such as x-api-owner or
+// x-service-tier, into a generated spec to carry API governance metadata
+type ExtensionRules struct {
+	Spec       map[string]interface{} `json:"spec"`       // document level extensions
+	Tags       []ExtensionRule        `json:"tags"`       // tag level, matched by tag name
+	Operations []ExtensionRule        `json:"operations"` // operation level, matched by path, method and tag
+}
+
+// ExtensionRule sets Values on the tags or operations it matches. A rule
+// matches when each of its non-empty matchers does; a rule without matchers
+// matches everything. Later rules override the values of earlier ones.
+type ExtensionRule struct {
+	Paths   []string               `json:"paths"`   // path prefixes, /admin covers /admin/users
+	Methods []string               `json:"methods"` // HTTP methods, case-insensitive
+	Tags    []string               `json:"tags"`    // tag names, an operation matches with any of its tags
+	Values  map[string]interface{} `json:"values"`
+}
+
+// Validate checks that every extension name starts with x- and that rules set values
+func (r *ExtensionRules) Validate() error {
+	if err := validateExtensionNames(r.Spec); err != nil {
+		return fmt.Errorf("spec: %w", err)
+	}
+	for i, rule := range r.Tags {
+		if len(rule.Paths) > 0 || len(rule.Methods) > 0 {
+			return fmt.Errorf("tags[%d]: tag rules match by tags only", i)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("tags[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range r.Operations {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("operations[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r ExtensionRule) validate() error {
+	if len(r.Values) == 0 {
+		return fmt.Errorf("values is empty")
+	}
+	return validateExtensionNames(r.Values)
+}
+
+func validateExtensionNames(values map[string]interface{}) error {
+	for name := range values {
+		if !strings.HasPrefix(name, "x-") {
+			return fmt.Errorf("extension %q must start with x-", name)
+		}
+	}
+	return nil
+}
+
+// ApplyExtensions injects the extensions of rules into the spec. Tags get a
+// tag object in the document's tags list when a rule matches them.
+func (s *Spec) ApplyExtensions(rules *ExtensionRules) {
+	if rules == nil {
+		return
+	}
+	for name, value := range rules.Spec {
+		s.SetExtension(name, value)
+	}
+
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		for _, rule := range rules.Operations {
+			if rule.matchesOperation(path, method, op) {
+				for name, value := range rule.Values {
+					op.SetExtension(name, value)
+				}
+			}
+		}
+	})
+
+	if len(rules.Tags) == 0 {
+		return
+	}
+	var names []string
+	seen := make(map[string]bool)
+	s.ForEachOperation(func(_, _ string, op *Operation) {
+		for _, tag := range op.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				names = append(names, tag)
+			}
+		}
+	})
+	for _, name := range names {
+		for _, rule := range rules.Tags {
+			if len(rule.Tags) == 0 || containsFold(rule.Tags, name) {
+				tag := s.tag(name)
+				for key, value := range rule.Values {
+					tag.SetExtension(key, value)
+				}
+			}
+		}
+	}
+}
+
+func (r ExtensionRule) matchesOperation(path, method string, op *Operation) bool {
+	if len(r.Methods) > 0 && !containsFold(r.Methods, method) {
+		return false
+	}
+	if len(r.Paths) > 0 {
+		matched := false
+		for _, prefix := range r.Paths {
+			if HasPathPrefix(path, prefix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Tags) > 0 {
+		for _, tag := range op.Tags {
+			if containsFold(r.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// tag returns the tag object of the document's tags list, adding it when missing
+func (s *Spec) tag(name string) *Tag {
+	for i := range s.Tags {
+		if s.Tags[i].Name == name {
+			return &s.Tags[i]
+		}
+	}
+	s.Tags = append(s.Tags, Tag{Name: name})
+	return &s.Tags[len(s.Tags)-1]
+}
+
+// SetExtension sets a vendor extension (x-...) on the tag
+func (t *Tag) SetExtension(name string, value interface{}) {
+	if t.Extensions == nil {
+		t.Extensions = make(map[string]interface{})
+	}
+	t.Extensions[name] = value
+}
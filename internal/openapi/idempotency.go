@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Extensions consumed by SDK generators and gateway retry policies
+const (
+	ExtIdempotent     = "x-idempotent"
+	ExtIdempotencyKey = "x-idempotency-key"
+)
+
+// MarkIdempotent flags the operation as safe to retry and appends a note to its
+// description. key names the request header that makes retries safe (e.g.
+// Idempotency-Key); an empty key keeps the one already set, if any. Calling it
+// again, e.g. after an overlay replaced the description, does not duplicate the note.
+func (o *Operation) MarkIdempotent(key string) {
+	o.SetExtension(ExtIdempotent, true)
+	if key != "" {
+		o.SetExtension(ExtIdempotencyKey, key)
+	}
+
+	note := "Idempotent: safe to retry."
+	if k, ok := o.Extensions[ExtIdempotencyKey].(string); ok && k != "" {
+		note = fmt.Sprintf("Idempotent: safe to retry with the same %s header.", k)
+	}
+	if strings.Contains(o.Description, note) {
+		return
+	}
+	if o.Description == "" {
+		o.Description = note
+	} else {
+		o.Description += "; " + note
+	}
+}
+
+// IsIdempotent reports whether the operation has been marked idempotent
+func (o *Operation) IsIdempotent() bool {
+	idempotent, _ := o.Extensions[ExtIdempotent].(bool)
+	return idempotent
+}
@@ -0,0 +1,140 @@
+// Package lint checks a generated OpenAPI spec against API governance rules,
+// such as plural resource names or documented auth failures
+package lint
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity is how a rule violation is reported
+type Severity string
+
+const (
+	Error   Severity = "error" // aborts the sync
+	Warning Severity = "warn"  // reported only
+	Off     Severity = "off"   // the rule does not run
+)
+
+// Config selects the rules of a project and their options
+type Config struct {
+	// Rules sets the severity of rules by name; rules not listed keep their
+	// default severity
+	Rules map[string]Severity `json:"rules"`
+	// PublicPaths are path prefixes that need no authentication, e.g. /login,
+	// exempt from auth-responses
+	PublicPaths []string `json:"public_paths"`
+}
+
+// Validate checks that the configured rules exist and severities are known
+func (c *Config) Validate() error {
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if findRule(name) == nil {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+		switch c.Rules[name] {
+		case Error, Warning, Off:
+		default:
+			return fmt.Errorf("rule %s: unknown severity %q (error, warn or off)", name, c.Rules[name])
+		}
+	}
+	return nil
+}
+
+// severity returns the effective severity of rule
+func (c *Config) severity(rule *Rule) Severity {
+	if severity, ok := c.Rules[rule.Name]; ok {
+		return severity
+	}
+	return rule.Severity
+}
+
+// Violation is one place where the spec breaks a rule
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Location string   `json:"location"` // "GET /users/{id}"
+	Message  string   `json:"message"`
+}
+
+// String renders the violation as one report line
+func (v Violation) String() string {
+	return fmt.Sprintf("[%s] %s: %s (%s)", v.Severity, v.Location, v.Message, v.Rule)
+}
+
+// Report is the result of linting a spec
+type Report struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Count returns how many violations have the given severity
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, v := range r.Violations {
+		if v.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors reports whether any violation has error severity
+func (r *Report) HasErrors() bool {
+	return r.Count(Error) > 0
+}
+
+// Summary returns a one-line overview, e.g. "2 error(s), 5 warning(s)"
+func (r *Report) Summary() string {
+	if len(r.Violations) == 0 {
+		return "no violations"
+	}
+	return fmt.Sprintf("%d error(s), %d warning(s)", r.Count(Error), r.Count(Warning))
+}
+
+// String renders every violation, errors first
+func (r *Report) String() string {
+	lines := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		lines = append(lines, v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Run checks the spec against every rule that is not turned off. Violations
+// are ordered by severity, then by location.
+func Run(spec *openapi.Spec, cfg *Config) *Report {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	report := &Report{Violations: []Violation{}}
+	for i := range Rules {
+		rule := &Rules[i]
+		severity := cfg.severity(rule)
+		if severity == Off {
+			continue
+		}
+		rule.Check(spec, cfg, func(location, format string, args ...interface{}) {
+			report.Violations = append(report.Violations, Violation{
+				Rule:     rule.Name,
+				Severity: severity,
+				Location: location,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+	}
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		a, b := report.Violations[i], report.Violations[j]
+		if a.Severity != b.Severity {
+			return a.Severity == Error
+		}
+		return a.Location < b.Location
+	})
+	return report
+}
@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"api-doc-generator/internal/openapi"
+	"regexp"
+	"strings"
+)
+
+// Rule is one governance check
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity // default severity
+	Check       func(spec *openapi.Spec, cfg *Config, report reportFunc)
+}
+
+// reportFunc records a violation of the running rule at location
+type reportFunc func(location, format string, args ...interface{})
+
+// Rules are the built-in rules, in the order they run
+var Rules = []Rule{
+	{
+		Name:        "plural-resources",
+		Description: "Path segments naming a collection, i.e. followed by a path parameter, are plural: /users/{id}, not /user/{id}",
+		Severity:    Warning,
+		Check:       checkPluralResources,
+	},
+	{
+		Name:        "kebab-case-paths",
+		Description: "Static path segments are lowercase kebab-case: /order-items, not /orderItems or /order_items",
+		Severity:    Warning,
+		Check:       checkKebabCasePaths,
+	},
+	{
+		Name:        "auth-responses",
+		Description: "Operations document 401 and 403 responses, except under public_paths",
+		Severity:    Warning,
+		Check:       checkAuthResponses,
+	},
+	{
+		Name:        "operation-description",
+		Description: "Operations have a description",
+		Severity:    Warning,
+		Check:       checkOperationDescription,
+	},
+}
+
+// findRule returns the built-in rule with the given name, nil when there is none
+func findRule(name string) *Rule {
+	for i := range Rules {
+		if Rules[i].Name == name {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// segments splits a path template into its segments
+func segments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// Nouns whose singular and plural forms are the same, or that are plural
+// without ending in s
+var pluralNouns = map[string]bool{
+	"data": true, "media": true, "people": true, "children": true, "metadata": true,
+	"info": true, "feedback": true, "staff": true, "equipment": true, "news": true,
+}
+
+// isPlural guesses whether the last word of a kebab-case segment is plural
+func isPlural(segment string) bool {
+	words := strings.Split(strings.ToLower(segment), "-")
+	word := words[len(words)-1]
+	if pluralNouns[word] {
+		return true
+	}
+	return strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us")
+}
+
+// versionSegment matches API version segments such as v1 or v2beta
+var versionSegment = regexp.MustCompile(`^v\d+`)
+
+func checkPluralResources(spec *openapi.Spec, _ *Config, report reportFunc) {
+	for _, path := range spec.Paths.Keys() {
+		parts := segments(path)
+		for i := 0; i+1 < len(parts); i++ {
+			segment := parts[i]
+			if isParameter(segment) || !isParameter(parts[i+1]) || versionSegment.MatchString(segment) {
+				continue
+			}
+			if !isPlural(segment) {
+				report(path, "resource %q should be plural", segment)
+			}
+		}
+	}
+}
+
+var kebabCase = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+)?$`)
+
+func checkKebabCasePaths(spec *openapi.Spec, _ *Config, report reportFunc) {
+	for _, path := range spec.Paths.Keys() {
+		for _, segment := range segments(path) {
+			if segment != "" && !isParameter(segment) && !kebabCase.MatchString(segment) {
+				report(path, "segment %q is not kebab-case", segment)
+			}
+		}
+	}
+}
+
+func checkAuthResponses(spec *openapi.Spec, cfg *Config, report reportFunc) {
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		for _, prefix := range cfg.PublicPaths {
+			if openapi.HasPathPrefix(path, prefix) {
+				return
+			}
+		}
+		var missing []string
+		for _, status := range []string{"401", "403"} {
+			if _, ok := op.Responses[status]; !ok {
+				missing = append(missing, status)
+			}
+		}
+		if len(missing) > 0 {
+			report(method+" "+path, "missing %s response(s)", strings.Join(missing, " and "))
+		}
+	})
+}
+
+func checkOperationDescription(spec *openapi.Spec, _ *Config, report reportFunc) {
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		if strings.TrimSpace(op.Description) == "" {
+			report(method+" "+path, "operation has no description")
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PrefixPaths namespaces every path of the spec under prefix, e.g. "/orders"
+// turns "/api/v1/list" into "/orders/api/v1/list"
+func (s *Spec) PrefixPaths(prefix string) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		return
+	}
+
+	paths := make(Paths, len(s.Paths))
+	for path, item := range s.Paths {
+		if path == "/" {
+			paths[prefix] = item
+			continue
+		}
+		paths[prefix+path] = item
+	}
+	s.Paths = paths
+}
+
+// Merge combines several specs into one aggregate document. Schemas with the
+// same name and identical content are shared; conflicting schemas from later
+// specs are renamed (Name_2, Name_3, ... after the spec's position, or the
+// next name not in use) and their references rewritten.
+// Two specs defining the same path and method is an error, so callers should
+// namespace paths with PrefixPaths first.
+func Merge(specs ...*Spec) (*Spec, error) {
+	merged := NewSpec()
+	if len(specs) > 0 && specs[0] != nil {
+		merged.Info = specs[0].Info
+	}
+
+	for i, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
+		// Decide the final name of every schema of this spec first. Schemas
+		// are compared with their references renamed, so a schema referring
+		// to a renamed one is renamed too; renames only grow, so this ends.
+		renames := make(map[string]string)
+		rename := func(schema Schema) Schema {
+			return MapSchema(schema, func(s Schema) Schema {
+				if newName, ok := renames[RefName(s.Ref)]; ok {
+					s.Ref = SchemaRefPrefix + newName
+				}
+				return s
+			})
+		}
+		for changed := spec.Components != nil; changed; {
+			changed = false
+			for name, schema := range spec.Components.Schemas {
+				if _, renamed := renames[name]; renamed {
+					continue
+				}
+				existing, exists := merged.Components.Schemas[name]
+				if !exists || sameSchema(existing, rename(schema)) {
+					continue
+				}
+				renames[name] = mergedName(name, i+1, merged, spec, renames)
+				changed = true
+			}
+		}
+
+		if spec.Components != nil {
+			for name, schema := range spec.Components.Schemas {
+				if newName, ok := renames[name]; ok {
+					name = newName
+				}
+				merged.AddSchema(name, rename(schema))
+			}
+		}
+
+		var conflict error
+		spec.ForEachOperation(func(path, method string, op *Operation) {
+			if conflict != nil {
+				return
+			}
+			if existing, ok := merged.Paths[path]; ok && existing.Operation(method) != nil {
+				conflict = fmt.Errorf("duplicate operation %s %s in spec %d", method, path, i+1)
+				return
+			}
+			merged.AddPath(path, method, renameOperationRefs(op, rename))
+		})
+		if conflict != nil {
+			return nil, conflict
+		}
+
+		for name, pathItem := range spec.Webhooks {
+			for _, method := range Methods {
+				if op := pathItem.Operation(method); op != nil {
+					merged.AddWebhook(name, method, renameOperationRefs(op, rename))
+				}
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// mergedName picks the name of a conflicting schema: the first of Name_n,
+// Name_n+1, ... that is neither in merged, nor a schema of spec, nor given to
+// another schema of spec
+func mergedName(name string, n int, merged, spec *Spec, renames map[string]string) string {
+	taken := make(map[string]bool, len(renames))
+	for _, newName := range renames {
+		taken[newName] = true
+	}
+	for ; ; n++ {
+		candidate := fmt.Sprintf("%s_%d", name, n)
+		if _, exists := merged.Components.Schemas[candidate]; exists {
+			continue
+		}
+		if _, exists := spec.Components.Schemas[candidate]; exists || taken[candidate] {
+			continue
+		}
+		return candidate
+	}
+}
+
+// renameOperationRefs returns a copy of op with fn applied to all its schemas
+func renameOperationRefs(op *Operation, fn func(Schema) Schema) *Operation {
+	copied := *op
+
+	copied.Parameters = make([]Parameter, len(op.Parameters))
+	for i, param := range op.Parameters {
+		param.Schema = fn(param.Schema)
+		copied.Parameters[i] = param
+	}
+
+	if op.RequestBody != nil {
+		body := *op.RequestBody
+		body.Content = renameContent(body.Content, fn)
+		copied.RequestBody = &body
+	}
+
+	copied.Responses = make(map[string]Response, len(op.Responses))
+	for code, resp := range op.Responses {
+		resp.Content = renameContent(resp.Content, fn)
+		copied.Responses[code] = resp
+	}
+
+	if op.Callbacks != nil {
+		copied.Callbacks = make(map[string]Callback, len(op.Callbacks))
+		for name, callback := range op.Callbacks {
+			renamed := make(Callback, len(callback))
+			for expression, pathItem := range callback {
+				var item PathItem
+				for _, method := range Methods {
+					if cbOp := pathItem.Operation(method); cbOp != nil {
+						item.SetOperation(method, renameOperationRefs(cbOp, fn))
+					}
+				}
+				renamed[expression] = item
+			}
+			copied.Callbacks[name] = renamed
+		}
+	}
+
+	return &copied
+}
+
+func renameContent(content map[string]MediaType, fn func(Schema) Schema) map[string]MediaType {
+	if content == nil {
+		return nil
+	}
+	result := make(map[string]MediaType, len(content))
+	for contentType, media := range content {
+		media.Schema = fn(media.Schema)
+		result[contentType] = media
+	}
+	return result
+}
+
+// sameSchema compares two schemas structurally
+func sameSchema(a, b Schema) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+// HasPathPrefix reports whether path is prefix or lies below it, matching
+// whole segments: /users/{id} is under /users, /users-admin is not
+func HasPathPrefix(path, prefix string) bool {
+	prefix = NormalizePath(prefix)
+	if prefix == "/" {
+		return true
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// PatchPrefix replaces every path under prefix with the paths of partial, a
+// spec generated for that prefix only. Schemas of partial replace those of
+// the same name; schemas no longer referenced are left for PruneSchemas.
+// It returns the paths that were removed because partial no longer has them.
+func (s *Spec) PatchPrefix(prefix string, partial *Spec) []string {
+	var removed []string
+	for _, path := range s.Paths.Keys() {
+		if !HasPathPrefix(path, prefix) {
+			continue
+		}
+		if _, ok := partial.Paths[path]; !ok {
+			removed = append(removed, path)
+		}
+		delete(s.Paths, path)
+	}
+	for path, item := range partial.Paths {
+		if HasPathPrefix(path, prefix) {
+			s.Paths[path] = item
+		}
+	}
+	if partial.Components != nil {
+		for name, schema := range partial.Components.Schemas {
+			s.AddSchema(name, schema)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOverlayFile is the overlay file looked up in the project root when
+// no explicit overlay path is configured
+const DefaultOverlayFile = "openapi-overrides.yaml"
+
+// Overlay 描述叠加在生成文档之上的人工修订，
+// 让团队无需改动源码即可修正描述、补充示例或隐藏接口，且不会在下次同步时丢失
+type Overlay struct {
+	Info    *InfoOverlay                            `yaml:"info"`
+	Paths   map[string]map[string]*OperationOverlay `yaml:"paths"`   // path -> method("get"/"post"/"*") -> patch
+	Schemas map[string]*SchemaOverlay               `yaml:"schemas"` // schema name -> patch
+	// Webhooks 声明服务主动推送的事件（OpenAPI 3.1）：name -> method -> event
+	Webhooks map[string]map[string]*EventOverlay `yaml:"webhooks"`
+}
+
+// InfoOverlay 覆盖文档的基本信息
+type InfoOverlay struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+// OperationOverlay 覆盖单个接口
+type OperationOverlay struct {
+	Summary         string      `yaml:"summary"`
+	Description     string      `yaml:"description"`
+	Tags            []string    `yaml:"tags"`
+	Deprecated      bool        `yaml:"deprecated"`
+	Hidden          bool        `yaml:"hidden"`          // 从文档中移除该接口
+	Idempotent      bool        `yaml:"idempotent"`      // 标记为可安全重试，输出 x-idempotent
+	IdempotencyKey  string      `yaml:"idempotency_key"` // 保证幂等的请求头，例如 Idempotency-Key
+	RequestExample  interface{} `yaml:"request_example"`
+	ResponseExample interface{} `yaml:"response_example"`
+	// Callbacks 声明接口调用后回调给调用方的请求：name -> URL 表达式 -> method -> event
+	Callbacks map[string]map[string]map[string]*EventOverlay `yaml:"callbacks"`
+}
+
+// EventOverlay 描述一个由服务发出的请求（webhook 或 callback）
+type EventOverlay struct {
+	Summary     string `yaml:"summary"`
+	Description string `yaml:"description"`
+	Schema      string `yaml:"schema"` // 请求体对应的 components 数据结构名
+}
+
+func (e *EventOverlay) operation() *Operation {
+	return NewEventOperation(e.Summary, e.Description, e.Schema)
+}
+
+// SchemaOverlay 覆盖 components 中的数据结构
+type SchemaOverlay struct {
+	Description string                      `yaml:"description"`
+	Example     interface{}                 `yaml:"example"`
+	Properties  map[string]*PropertyOverlay `yaml:"properties"`
+}
+
+// PropertyOverlay 覆盖数据结构中的单个字段
+type PropertyOverlay struct {
+	Description string      `yaml:"description"`
+	Example     interface{} `yaml:"example"`
+	Hidden      bool        `yaml:"hidden"`
+}
+
+// LoadOverlay reads an overlay file. A missing file is not an error: it
+// returns (nil, nil) so callers can treat overlays as optional.
+func LoadOverlay(path string) (*Overlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+
+	var overlay Overlay
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay %s: %w", path, err)
+	}
+	return &overlay, nil
+}
+
+// ApplyOverlay merges the overlay onto the spec in place. Patches that point
+// at paths, methods or schemas that don't exist are ignored.
+func (s *Spec) ApplyOverlay(overlay *Overlay) {
+	if overlay == nil {
+		return
+	}
+
+	if overlay.Info != nil {
+		if overlay.Info.Title != "" {
+			s.Info.Title = overlay.Info.Title
+		}
+		if overlay.Info.Description != "" {
+			s.Info.Description = overlay.Info.Description
+		}
+		if overlay.Info.Version != "" {
+			s.Info.Version = overlay.Info.Version
+		}
+	}
+
+	for path, methods := range overlay.Paths {
+		path = s.resolvePath(NormalizePath(path))
+		pathItem, exists := s.Paths[path]
+		if !exists {
+			continue
+		}
+
+		for method, patch := range methods {
+			if patch == nil {
+				continue
+			}
+			targets := []string{strings.ToUpper(method)}
+			if method == "*" {
+				targets = Methods
+			}
+			for _, m := range targets {
+				op := pathItem.Operation(m)
+				if op == nil {
+					continue
+				}
+				if patch.Hidden {
+					pathItem.SetOperation(m, nil)
+					continue
+				}
+				applyOperationOverlay(op, patch)
+			}
+		}
+
+		if pathItem.IsEmpty() {
+			delete(s.Paths, path)
+		} else {
+			s.Paths[path] = pathItem
+		}
+	}
+
+	for name, methods := range overlay.Webhooks {
+		for method, event := range methods {
+			if event != nil {
+				s.AddWebhook(name, strings.ToUpper(method), event.operation())
+			}
+		}
+	}
+
+	if s.Components == nil {
+		return
+	}
+	for name, patch := range overlay.Schemas {
+		schema, exists := s.Components.Schemas[name]
+		if !exists || patch == nil {
+			continue
+		}
+		if patch.Description != "" {
+			schema.Description = patch.Description
+		}
+		if patch.Example != nil {
+			schema.Example = patch.Example
+		}
+		for propName, propPatch := range patch.Properties {
+			prop, exists := schema.Properties[propName]
+			if !exists || propPatch == nil {
+				continue
+			}
+			if propPatch.Hidden {
+				delete(schema.Properties, propName)
+				schema.Required = removeString(schema.Required, propName)
+				continue
+			}
+			if propPatch.Description != "" {
+				prop.Description = propPatch.Description
+			}
+			if propPatch.Example != nil {
+				prop.Example = propPatch.Example
+			}
+			schema.Properties[propName] = prop
+		}
+		s.Components.Schemas[name] = schema
+	}
+}
+
+func applyOperationOverlay(op *Operation, patch *OperationOverlay) {
+	if patch.Summary != "" {
+		op.Summary = patch.Summary
+	}
+	if patch.Description != "" {
+		op.Description = patch.Description
+	}
+	if len(patch.Tags) > 0 {
+		op.Tags = patch.Tags
+	}
+	if patch.Deprecated {
+		op.Deprecated = true
+	}
+	// 覆盖描述后重新追加幂等说明
+	if patch.Idempotent || patch.IdempotencyKey != "" || op.IsIdempotent() {
+		op.MarkIdempotent(patch.IdempotencyKey)
+	}
+	for name, expressions := range patch.Callbacks {
+		for expression, methods := range expressions {
+			for method, event := range methods {
+				if event != nil {
+					op.AddCallback(name, expression, strings.ToUpper(method), event.operation())
+				}
+			}
+		}
+	}
+	if patch.RequestExample != nil && op.RequestBody != nil {
+		for contentType, media := range op.RequestBody.Content {
+			media.Example = patch.RequestExample
+			op.RequestBody.Content[contentType] = media
+		}
+	}
+	if patch.ResponseExample != nil {
+		if resp, ok := op.Responses["200"]; ok {
+			for contentType, media := range resp.Content {
+				media.Example = patch.ResponseExample
+				resp.Content[contentType] = media
+			}
+		}
+	}
+}
+
+func removeString(list []string, item string) []string {
+	result := list[:0]
+	for _, s := range list {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
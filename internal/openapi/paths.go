@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Paths maps path templates to their operations. It is marshaled in
+// hierarchical order so that a resource and its sub-resources stay together.
+type Paths map[string]PathItem
+
+// NormalizePath removes duplicate and trailing slashes and ensures a leading
+// slash, so /users/, //users and /users map to the same path
+func NormalizePath(path string) string {
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	return "/" + strings.Join(segments, "/")
+}
+
+// resolvePath returns the key path is documented under. With FoldPathCase
+// it is the existing key that matches path case-insensitively, so /Users and
+// /users are documented once under the first spelling seen; gin routes are
+// case-sensitive, so this is opt-in.
+func (s *Spec) resolvePath(path string) string {
+	if !s.FoldPathCase {
+		return path
+	}
+	if _, ok := s.Paths[path]; ok {
+		return path
+	}
+	// Paths may also be set directly; rebuild the index when it misses some
+	if s.pathIndex == nil || len(s.pathIndex) != len(s.Paths) {
+		s.pathIndex = make(map[string]string, len(s.Paths))
+		for _, existing := range s.Paths.Keys() {
+			if _, ok := s.pathIndex[strings.ToLower(existing)]; !ok {
+				s.pathIndex[strings.ToLower(existing)] = existing
+			}
+		}
+	}
+	if existing, ok := s.pathIndex[strings.ToLower(path)]; ok {
+		if _, ok := s.Paths[existing]; ok {
+			return existing
+		}
+	}
+	return path
+}
+
+// Keys returns the paths in hierarchical order
+func (p Paths) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for path := range p {
+		keys = append(keys, path)
+	}
+	SortPaths(keys)
+	return keys
+}
+
+// Match finds the path template matching a concrete request path, e.g.
+// /users/42 matches /users/{id}. Static segments win over parameters, and
+// the returned map holds the parameter values.
+func (p Paths) Match(path string) (string, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(NormalizePath(path), "/"), "/")
+	for _, template := range p.Keys() {
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") && segments[i] != "" {
+				params[strings.Trim(part, "{}")] = segments[i]
+				continue
+			}
+			if part != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return template, params, true
+		}
+	}
+	return "", nil, false
+}
+
+// SortPaths sorts paths segment by segment: a path comes before its
+// sub-paths, and static segments come before parameters, e.g.
+// /users, /users/me, /users/{id}, /users/{id}/orders, /users-admin
+func SortPaths(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return comparePaths(paths[i], paths[j]) < 0
+	})
+}
+
+func comparePaths(a, b string) int {
+	as := strings.Split(strings.Trim(a, "/"), "/")
+	bs := strings.Split(strings.Trim(b, "/"), "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if strings.EqualFold(as[i], bs[i]) {
+			continue
+		}
+		aParam, bParam := strings.HasPrefix(as[i], "{"), strings.HasPrefix(bs[i], "{")
+		if aParam != bParam {
+			if aParam {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(strings.ToLower(as[i]), strings.ToLower(bs[i]))
+	}
+	if len(as) != len(bs) {
+		return len(as) - len(bs)
+	}
+	return strings.Compare(a, b)
+}
+
+// MarshalJSON writes the paths in hierarchical order instead of the
+// alphabetical order used for maps
+func (p Paths) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, path := range p.Keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(path)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p[path])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
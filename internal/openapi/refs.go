@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// SchemaRefPrefix is the JSON pointer prefix of component schema references
+const SchemaRefPrefix = "#/components/schemas/"
+
+// RefName returns the component name a $ref points at, or "" for non-component refs
+func RefName(ref string) string {
+	if !strings.HasPrefix(ref, SchemaRefPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(ref, SchemaRefPrefix)
+}
+
+// MapSchema applies fn to every nested schema (children first) and then to
+// the schema itself, returning the rewritten copy
+func MapSchema(schema Schema, fn func(Schema) Schema) Schema {
+	if schema.Properties != nil {
+		props := make(map[string]Schema, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			props[name] = MapSchema(prop, fn)
+		}
+		schema.Properties = props
+	}
+	if schema.Items != nil {
+		items := MapSchema(*schema.Items, fn)
+		schema.Items = &items
+	}
+	if schema.AdditionalProperties != nil {
+		additional := MapSchema(*schema.AdditionalProperties, fn)
+		schema.AdditionalProperties = &additional
+	}
+	return fn(schema)
+}
+
+// CollectRefs adds the names of all component schemas referenced by schema
+// (including nested schemas) to refs
+func CollectRefs(schema Schema, refs map[string]bool) {
+	MapSchema(schema, func(s Schema) Schema {
+		if name := RefName(s.Ref); name != "" {
+			refs[name] = true
+		}
+		return s
+	})
+}
+
+// PruneSchemas removes component schemas that are not reachable, directly or
+// transitively, from any operation or message. It returns the names of
+// removed schemas.
+func (s *Spec) PruneSchemas() []string {
+	if s.Components == nil || len(s.Components.Schemas) == 0 {
+		return nil
+	}
+
+	// Seed with everything referenced from operations, webhooks, callbacks
+	// and message payloads
+	reachable := make(map[string]bool)
+	for _, message := range s.Messages {
+		reachable[message.Schema] = true
+	}
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		collectOperationRefs(op, reachable)
+	})
+	for _, pathItem := range s.Webhooks {
+		for _, method := range Methods {
+			if op := pathItem.Operation(method); op != nil {
+				collectOperationRefs(op, reachable)
+			}
+		}
+	}
+
+	// Follow references between component schemas
+	queue := make([]string, 0, len(reachable))
+	for name := range reachable {
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		schema, exists := s.Components.Schemas[name]
+		if !exists {
+			continue
+		}
+		refs := make(map[string]bool)
+		CollectRefs(schema, refs)
+		for ref := range refs {
+			if !reachable[ref] {
+				reachable[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	var removed []string
+	for name := range s.Components.Schemas {
+		if !reachable[name] {
+			delete(s.Components.Schemas, name)
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// collectOperationRefs adds the schemas referenced by an operation and its callbacks
+func collectOperationRefs(op *Operation, refs map[string]bool) {
+	for _, param := range op.Parameters {
+		CollectRefs(param.Schema, refs)
+	}
+	if op.RequestBody != nil {
+		for _, media := range op.RequestBody.Content {
+			CollectRefs(media.Schema, refs)
+		}
+	}
+	for _, resp := range op.Responses {
+		for _, media := range resp.Content {
+			CollectRefs(media.Schema, refs)
+		}
+	}
+	for _, callback := range op.Callbacks {
+		for _, pathItem := range callback {
+			for _, method := range Methods {
+				if cbOp := pathItem.Operation(method); cbOp != nil {
+					collectOperationRefs(cbOp, refs)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Upper bounds, in bytes, of JSON scalars without a declared limit
+const (
+	maxIntegerSize = 20 // -9223372036854775808
+	maxNumberSize  = 24 // -1.7976931348623157e+308
+	maxBooleanSize = 5  // false
+)
+
+// formatSizes bounds strings whose format fixes their length
+var formatSizes = map[string]int64{
+	"date":      10,
+	"date-time": 35,
+	"uuid":      36,
+	"ipv4":      15,
+	"ipv6":      45,
+}
+
+// SizeEstimate is the largest JSON encoding a schema allows. Strings are
+// counted as single-byte characters. When a string or array has no upper
+// limit, Unbounded lists the fields responsible and Bytes only covers the
+// bounded part of the payload.
+type SizeEstimate struct {
+	Bytes     int64    `json:"bytes"`
+	Unbounded []string `json:"unbounded,omitempty"`
+}
+
+// Bounded reports whether every field of the payload has an upper limit
+func (e SizeEstimate) Bounded() bool {
+	return len(e.Unbounded) == 0
+}
+
+func (e SizeEstimate) String() string {
+	if e.Bounded() {
+		return formatBytes(e.Bytes)
+	}
+	return fmt.Sprintf("unbounded (%s)", strings.Join(e.Unbounded, ", "))
+}
+
+// EstimateSize computes the maximum payload size allowed by schema, resolving
+// component references against the spec
+func (s *Spec) EstimateSize(schema Schema) SizeEstimate {
+	var estimate SizeEstimate
+	estimate.Bytes = s.estimateSize(schema, "$", map[string]bool{}, &estimate.Unbounded)
+	return estimate
+}
+
+func (s *Spec) estimateSize(schema Schema, path string, visiting map[string]bool, unbounded *[]string) int64 {
+	if name := RefName(schema.Ref); name != "" {
+		var target Schema
+		ok := false
+		if s.Components != nil {
+			target, ok = s.Components.Schemas[name]
+		}
+		if !ok {
+			*unbounded = append(*unbounded, path+" (undefined "+name+")")
+			return 0
+		}
+		if visiting[name] {
+			*unbounded = append(*unbounded, path+" (recursive "+name+")")
+			return 0
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return s.estimateSize(target, path, visiting, unbounded)
+	}
+
+	if len(schema.Enum) > 0 {
+		var largest int64
+		for _, value := range schema.Enum {
+			if n := int64(len(fmt.Sprint(value))) + 2; n > largest {
+				largest = n
+			}
+		}
+		return largest
+	}
+
+	switch schema.Type {
+	case "string":
+		if schema.MaxLength != nil {
+			return int64(*schema.MaxLength) + 2
+		}
+		if n, ok := formatSizes[schema.Format]; ok {
+			return n + 2
+		}
+		*unbounded = append(*unbounded, path)
+		return 0
+	case "integer":
+		return maxIntegerSize
+	case "number":
+		return maxNumberSize
+	case "boolean":
+		return maxBooleanSize
+	case "array":
+		if schema.MaxItems == nil {
+			*unbounded = append(*unbounded, path+"[]")
+			return 0
+		}
+		var item int64
+		if schema.Items != nil {
+			item = s.estimateSize(*schema.Items, path+"[]", visiting, unbounded)
+		}
+		return 2 + int64(*schema.MaxItems)*(item+1)
+	}
+
+	// Maps and free-form objects can hold any number of entries
+	if schema.AdditionalProperties != nil {
+		*unbounded = append(*unbounded, path+".*")
+	} else if len(schema.Properties) == 0 {
+		*unbounded = append(*unbounded, path)
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// {"name":value,...}
+	size := int64(2)
+	for _, name := range names {
+		size += int64(len(name)) + 4 + s.estimateSize(schema.Properties[name], path+"."+name, visiting, unbounded)
+	}
+	return size
+}
+
+// formatBytes renders a byte count as B, KB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,23 @@
+package openapi
+
+// Extensions pointing at the code an operation was generated from
+const (
+	ExtSourceFile = "x-source-file"
+	ExtSourceLine = "x-source-line"
+)
+
+// Origin is the handler an operation was generated from
+type Origin struct {
+	Handler string
+	File    string // relative to the repository root, with forward slashes
+	Line    int
+}
+
+// SetSource records where the operation's handler is defined. file is
+// relative to the repository root, with forward slashes.
+func (o *Operation) SetSource(file string, line int) {
+	o.SetExtension(ExtSourceFile, file)
+	if line > 0 {
+		o.SetExtension(ExtSourceLine, line)
+	}
+}
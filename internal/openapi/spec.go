@@ -1,11 +1,34 @@
 package openapi
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Spec represents an OpenAPI 3.0 specification
 type Spec struct {
 	OpenAPI    string              `json:"openapi"`
 	Info       Info                `json:"info"`
-	Paths      map[string]PathItem `json:"paths"`
+	Paths      Paths               `json:"paths"`
+	Webhooks   map[string]PathItem `json:"webhooks,omitempty"` // OpenAPI 3.1 outbound events
 	Components *Components         `json:"components,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	// Extensions holds document level vendor extensions (x-...), see ApplyExtensions
+	Extensions map[string]interface{} `json:"-"`
+	// Warnings are the non-fatal issues of the analysis that generated the
+	// spec; they are not part of the document, see AnalysisReport
+	Warnings []AnalysisWarning `json:"-"`
+	// EnvelopeData is the property of the response envelope holding the
+	// payload, "data" when empty; Coverage judges wrapped responses by it
+	EnvelopeData string `json:"-"`
+	// Messages are the message channels of the service, documented by
+	// AsyncAPI; see Spec.AsyncAPI
+	Messages []Message `json:"-"`
+	// FoldPathCase documents paths differing only in case, /Users and
+	// /users, once; see AddPath
+	FoldPathCase bool `json:"-"`
+
+	pathIndex map[string]string // lower-cased path -> key, see resolvePath
 }
 
 type Info struct {
@@ -14,6 +37,16 @@ type Info struct {
 	Version     string `json:"version"`
 }
 
+// Tag describes a tag used by operations. Tags are listed only when they
+// carry extensions or other metadata.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Extensions holds vendor extensions (x-...) rendered inline by MarshalJSON
+	Extensions map[string]interface{} `json:"-"`
+}
+
 type Components struct {
 	Schemas map[string]Schema `json:"schemas,omitempty"`
 }
@@ -35,8 +68,20 @@ type Operation struct {
 	Parameters  []Parameter         `json:"parameters,omitempty"`
 	RequestBody *RequestBody        `json:"requestBody,omitempty"`
 	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Callbacks   map[string]Callback `json:"callbacks,omitempty"`
+
+	// Extensions holds vendor extensions (x-...) rendered inline by MarshalJSON
+	Extensions map[string]interface{} `json:"-"`
+	// Origin is the handler the operation was generated from, kept in memory
+	// only; nil for operations read from a document
+	Origin *Origin `json:"-"`
 }
 
+// Callback maps a runtime expression (e.g. {$request.body#/callback_url}) to
+// the requests the API sends to that URL
+type Callback map[string]PathItem
+
 type Parameter struct {
 	Name        string `json:"name"`
 	In          string `json:"in"` // path, query, header, cookie
@@ -52,14 +97,22 @@ type RequestBody struct {
 }
 
 type MediaType struct {
-	Schema Schema `json:"schema"`
+	Schema  Schema      `json:"schema"`
+	Example interface{} `json:"example,omitempty"`
 }
 
 type Response struct {
 	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
 	Content     map[string]MediaType `json:"content,omitempty"`
 }
 
+// Header documents a response header such as X-Total-Count or Set-Cookie
+type Header struct {
+	Description string `json:"description,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
 type Schema struct {
 	Type                 string            `json:"type,omitempty"`
 	Format               string            `json:"format,omitempty"`
@@ -69,6 +122,15 @@ type Schema struct {
 	Items                *Schema           `json:"items,omitempty"`
 	Ref                  string            `json:"$ref,omitempty"`
 	AdditionalProperties *Schema           `json:"additionalProperties,omitempty"`
+	Example              interface{}       `json:"example,omitempty"`
+	Default              interface{}       `json:"default,omitempty"`
+	Enum                 []interface{}     `json:"enum,omitempty"`
+	Minimum              *float64          `json:"minimum,omitempty"`
+	Maximum              *float64          `json:"maximum,omitempty"`
+	MinLength            *int              `json:"minLength,omitempty"`
+	MaxLength            *int              `json:"maxLength,omitempty"`
+	MinItems             *int              `json:"minItems,omitempty"`
+	MaxItems             *int              `json:"maxItems,omitempty"`
 }
 
 // NewSpec creates a new OpenAPI specification
@@ -79,38 +141,92 @@ func NewSpec() *Spec {
 			Title:   "API",
 			Version: "1.0.0",
 		},
-		Paths: make(map[string]PathItem),
+		Paths: make(Paths),
 		Components: &Components{
 			Schemas: make(map[string]Schema),
 		},
 	}
 }
 
-// AddPath adds or updates a path in the specification
+// Methods lists the HTTP methods a PathItem can hold, in document order
+var Methods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+
+// AddPath adds or updates a path in the specification. The path is
+// normalized first, see NormalizePath. With FoldPathCase a path differing
+// from an existing one only in case is added to it, with a warning.
 func (s *Spec) AddPath(path, method string, operation *Operation) {
+	normalized := NormalizePath(path)
+	path = s.resolvePath(normalized)
+	if path != normalized {
+		s.Warn(AnalysisWarning{
+			Kind:    WarnAnalysis,
+			Message: fmt.Sprintf("%s differs from %s only in case and is documented under it", normalized, path),
+			Method:  method,
+			Path:    path,
+		})
+	}
 	pathItem, exists := s.Paths[path]
 	if !exists {
 		pathItem = PathItem{}
+		if s.FoldPathCase {
+			s.pathIndex[strings.ToLower(path)] = path // built by resolvePath
+		}
 	}
 
+	pathItem.SetOperation(method, operation)
+
+	s.Paths[path] = pathItem
+}
+
+// Operation returns the operation registered for the given method, or nil
+func (p *PathItem) Operation(method string) *Operation {
 	switch method {
 	case "GET":
-		pathItem.Get = operation
+		return p.Get
 	case "POST":
-		pathItem.Post = operation
+		return p.Post
 	case "PUT":
-		pathItem.Put = operation
+		return p.Put
 	case "DELETE":
-		pathItem.Delete = operation
+		return p.Delete
 	case "PATCH":
-		pathItem.Patch = operation
+		return p.Patch
 	case "HEAD":
-		pathItem.Head = operation
+		return p.Head
 	case "OPTIONS":
-		pathItem.Options = operation
+		return p.Options
 	}
+	return nil
+}
 
-	s.Paths[path] = pathItem
+// SetOperation sets (or clears, when operation is nil) the operation for a method
+func (p *PathItem) SetOperation(method string, operation *Operation) {
+	switch method {
+	case "GET":
+		p.Get = operation
+	case "POST":
+		p.Post = operation
+	case "PUT":
+		p.Put = operation
+	case "DELETE":
+		p.Delete = operation
+	case "PATCH":
+		p.Patch = operation
+	case "HEAD":
+		p.Head = operation
+	case "OPTIONS":
+		p.Options = operation
+	}
+}
+
+// IsEmpty reports whether the path item has no operations left
+func (p *PathItem) IsEmpty() bool {
+	for _, method := range Methods {
+		if p.Operation(method) != nil {
+			return false
+		}
+	}
+	return true
 }
 
 // AddSchema adds a schema definition to the components section
@@ -122,3 +238,25 @@ func (s *Spec) AddSchema(name string, schema Schema) {
 	}
 	s.Components.Schemas[name] = schema
 }
+
+// ForEachOperation calls fn for every operation, ordered by path (see
+// SortPaths) and then method
+func (s *Spec) ForEachOperation(fn func(path, method string, op *Operation)) {
+	for _, path := range s.Paths.Keys() {
+		pathItem := s.Paths[path]
+		for _, method := range Methods {
+			if op := pathItem.Operation(method); op != nil {
+				fn(path, method, op)
+			}
+		}
+	}
+}
+
+// OperationCount returns the number of operations across all paths
+func (s *Spec) OperationCount() int {
+	count := 0
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		count++
+	})
+	return count
+}
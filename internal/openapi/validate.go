@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var pathTemplateParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Validate checks the spec for problems that break an import or produce
+// unusable documentation: no operations, dangling $refs and path template
+// parameters without a matching parameter definition. All problems are
+// returned joined into one error.
+func (s *Spec) Validate() error {
+	var problems []error
+
+	if s.OperationCount() == 0 {
+		problems = append(problems, errors.New("spec has no operations"))
+	}
+
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		declared := make(map[string]bool)
+		for _, param := range op.Parameters {
+			if param.In == "path" {
+				declared[param.Name] = true
+			}
+		}
+		for _, match := range pathTemplateParam.FindAllStringSubmatch(path, -1) {
+			if !declared[match[1]] {
+				problems = append(problems, fmt.Errorf("%s %s: path parameter %q is not declared", method, path, match[1]))
+			}
+		}
+		if len(op.Responses) == 0 {
+			problems = append(problems, fmt.Errorf("%s %s: no responses", method, path))
+		}
+	})
+
+	// Every referenced schema must exist in components
+	refs := make(map[string]bool)
+	s.ForEachOperation(func(path, method string, op *Operation) {
+		collectOperationRefs(op, refs)
+	})
+	if s.Components != nil {
+		for _, schema := range s.Components.Schemas {
+			CollectRefs(schema, refs)
+		}
+	}
+	var missing []string
+	for name := range refs {
+		if !s.hasSchema(name) {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		problems = append(problems, fmt.Errorf("schema %q is referenced but not defined", name))
+	}
+
+	return errors.Join(problems...)
+}
+
+func (s *Spec) hasSchema(name string) bool {
+	if s.Components == nil {
+		return false
+	}
+	_, ok := s.Components.Schemas[name]
+	return ok
+}
@@ -0,0 +1,55 @@
+package openapi
+
+// OpenAPI31 is the document version required once webhooks are declared
+const OpenAPI31 = "3.1.0"
+
+// NewEventOperation builds the operation describing an outbound event whose
+// payload is the given component schema
+func NewEventOperation(summary, description, schemaName string) *Operation {
+	op := &Operation{
+		Summary:     summary,
+		Description: description,
+		Responses: map[string]Response{
+			"200": {Description: "Event received"},
+		},
+	}
+	if schemaName != "" {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {
+					Schema: Schema{Ref: SchemaRefPrefix + schemaName},
+				},
+			},
+		}
+	}
+	return op
+}
+
+// AddWebhook declares an outbound webhook. Webhooks are an OpenAPI 3.1
+// feature, so the document version is raised accordingly.
+func (s *Spec) AddWebhook(name, method string, operation *Operation) {
+	if s.Webhooks == nil {
+		s.Webhooks = make(map[string]PathItem)
+	}
+	pathItem := s.Webhooks[name]
+	pathItem.SetOperation(method, operation)
+	s.Webhooks[name] = pathItem
+	s.OpenAPI = OpenAPI31
+}
+
+// AddCallback declares a request the API sends back to the caller, e.g.
+// AddCallback("onPaid", "{$request.body#/callback_url}", "POST", op)
+func (o *Operation) AddCallback(name, expression, method string, operation *Operation) {
+	if o.Callbacks == nil {
+		o.Callbacks = make(map[string]Callback)
+	}
+	callback := o.Callbacks[name]
+	if callback == nil {
+		callback = make(Callback)
+	}
+	pathItem := callback[expression]
+	pathItem.SetOperation(method, operation)
+	callback[expression] = pathItem
+	o.Callbacks[name] = callback
+}
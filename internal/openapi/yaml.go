@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML renders the spec as YAML, keeping the field order of the JSON output
+func (s *Spec) ToYAML() ([]byte, error) {
+	return toYAML(s)
+}
+
+// ToYAML renders the AsyncAPI document as YAML
+func (a *AsyncAPI) ToYAML() ([]byte, error) {
+	return toYAML(a)
+}
+
+// toYAML renders a document as YAML, keeping the field order of its JSON
+// encoding
+func toYAML(doc interface{}) ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML: parse it into a node tree to keep key order,
+	// then drop the flow/quoted styles so it is written as block YAML
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Detector is implemented by parsers that can recognise projects they support
+type Detector interface {
+	// Detect returns a confidence between 0 (not this framework) and 1
+	// (certainly this framework) together with a human readable reason
+	Detect(projectPath string) (confidence float64, reason string)
+}
+
+// Detection is the result of one detector for a project
+type Detection struct {
+	Language   string
+	Confidence float64
+	Reason     string
+}
+
+// MarkerDetector scores a project by marker files and by dependency names
+// found inside them, e.g. go.mod containing github.com/gin-gonic/gin
+type MarkerDetector struct {
+	Files        map[string]float64  // marker file -> confidence contributed when present
+	Dependencies map[string][]string // marker file -> dependency names to look for
+	DepScore     float64             // confidence contributed by a matching dependency
+}
+
+// Detect implements Detector
+func (d MarkerDetector) Detect(projectPath string) (float64, string) {
+	var confidence float64
+	var reasons []string
+
+	files := make([]string, 0, len(d.Files)+len(d.Dependencies))
+	for file := range d.Files {
+		files = append(files, file)
+	}
+	for file := range d.Dependencies {
+		if _, ok := d.Files[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if err != nil {
+			continue
+		}
+		if score := d.Files[file]; score > 0 {
+			confidence += score
+			reasons = append(reasons, file)
+		}
+		for _, dep := range d.Dependencies[file] {
+			if strings.Contains(string(data), dep) {
+				confidence += d.DepScore
+				reasons = append(reasons, fmt.Sprintf("%s requires %s", file, dep))
+				break
+			}
+		}
+	}
+
+	if confidence > 1 {
+		confidence = 1
+	}
+	return confidence, strings.Join(reasons, ", ")
+}
+
+// DetectAll runs every registered detector that is not excluded and returns
+// the results with a positive confidence, best match first
+func (r *Registry) DetectAll(projectPath string, exclude []string) []Detection {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	var detections []Detection
+	for name, p := range r.parsers {
+		detector, ok := p.(Detector)
+		if !ok || excluded[name] {
+			continue
+		}
+		if confidence, reason := detector.Detect(projectPath); confidence > 0 {
+			detections = append(detections, Detection{Language: name, Confidence: confidence, Reason: reason})
+		}
+	}
+	sort.Slice(detections, func(i, j int) bool {
+		if detections[i].Confidence != detections[j].Confidence {
+			return detections[i].Confidence > detections[j].Confidence
+		}
+		return detections[i].Language < detections[j].Language
+	})
+	return detections
+}
+
+// Detect returns the language of the parser that best matches the project
+func (r *Registry) Detect(projectPath string, exclude []string) (Detection, error) {
+	detections := r.DetectAll(projectPath, exclude)
+	if len(detections) == 0 {
+		return Detection{Language: "unknown"}, errors.New("no parser recognises the project")
+	}
+	return detections[0], nil
+}
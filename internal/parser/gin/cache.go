@@ -0,0 +1,148 @@
+package gin
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheVersion is bumped whenever the analyzers change what they extract,
+// which invalidates existing caches
+const cacheVersion = 16
+
+// cacheFileName is the file the facts are kept in, inside the cache directory
+const cacheFileName = "facts.gob"
+
+// factsCache keeps the facts of a project's files between analyses, keyed by
+// path and content hash, so only changed files are parsed again. A nil cache
+// caches nothing.
+type factsCache struct {
+	dir         string
+	projectPath string
+	settings    string // fingerprint of the parser settings the facts depend on
+
+	previous map[string]cacheEntry // loaded from disk, read only
+	mu       sync.Mutex
+	current  map[string]cacheEntry // files of this analysis, saved for the next one
+	hits     int
+}
+
+// cacheFile is the on-disk format of the cache
+type cacheFile struct {
+	Version  int
+	Settings string
+	Entries  map[string]cacheEntry // path relative to the project -> entry
+}
+
+type cacheEntry struct {
+	Hash  string
+	Facts *fileFacts
+}
+
+// openCache loads the analysis cache of the project. Without a cache
+// directory it returns nil; an unreadable or outdated cache starts empty.
+func (p *GinParser) openCache(projectPath string) *factsCache {
+	if p.cacheDir == "" {
+		return nil
+	}
+	settings, _ := json.Marshal(p.cfg.TypeMappings)
+	cache := &factsCache{
+		dir:         p.cacheDir,
+		projectPath: projectPath,
+		settings:    string(settings),
+		previous:    make(map[string]cacheEntry),
+		current:     make(map[string]cacheEntry),
+	}
+
+	f, err := os.Open(filepath.Join(cache.dir, cacheFileName))
+	if err != nil {
+		return cache
+	}
+	defer f.Close()
+	var stored cacheFile
+	if err := gob.NewDecoder(f).Decode(&stored); err != nil {
+		log.Printf("⚠️  Ignoring analysis cache: %v", err)
+		return cache
+	}
+	if stored.Version == cacheVersion && stored.Settings == cache.settings {
+		cache.previous = stored.Entries
+	}
+	return cache
+}
+
+// lookup returns the cached facts of a file, nil when the file changed
+func (c *factsCache) lookup(path string, src []byte) *fileFacts {
+	if c == nil {
+		return nil
+	}
+	key, hash := c.key(path), contentHash(src)
+	entry, ok := c.previous[key]
+	if !ok || entry.Hash != hash || entry.Facts == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current[key] = entry
+	c.hits++
+	return entry.Facts
+}
+
+// store records the facts of a file analyzed from scratch
+func (c *factsCache) store(path string, src []byte, facts *fileFacts) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current[c.key(path)] = cacheEntry{Hash: contentHash(src), Facts: facts}
+}
+
+// save writes the facts of this analysis; files that are gone are dropped.
+// It must run before the facts are used, as the analysis modifies them.
+func (c *factsCache) save() {
+	if c == nil {
+		return
+	}
+	log.Printf("⚡ Analysis cache: %d of %d file(s) unchanged", c.hits, len(c.current))
+	if err := c.write(); err != nil {
+		log.Printf("⚠️  Failed to save analysis cache: %v", err)
+	}
+}
+
+func (c *factsCache) write() error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, cacheFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	stored := cacheFile{Version: cacheVersion, Settings: c.settings, Entries: c.current}
+	if err := gob.NewEncoder(tmp).Encode(&stored); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode analysis cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, cacheFileName))
+}
+
+func (c *factsCache) key(path string) string {
+	if rel, err := filepath.Rel(c.projectPath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func contentHash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,21 +1,44 @@
 package gin
 
 import (
+	"api-doc-generator/internal/config"
 	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/parser"
 	"api-doc-generator/pkg/ast"
-	"go/parser"
+	"context"
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
 	"go/token"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
-type GinParser struct{}
+type GinParser struct {
+	cfg      config.ParserConfig
+	cacheDir string          // directory of the analysis cache, empty for none
+	ctx      context.Context // cancels analyses, nil for none
+}
 
 func NewGinParser() *GinParser {
 	return &GinParser{}
 }
 
+// NewGinParserWithConfig creates a parser honoring project-level parser settings
+func NewGinParserWithConfig(cfg config.ParserConfig) *GinParser {
+	return &GinParser{cfg: cfg}
+}
+
+// WithConfig returns a parser using project-level parser settings
+func (p *GinParser) WithConfig(cfg config.ParserConfig) parser.Parser {
+	return NewGinParserWithConfig(cfg)
+}
+
 func (p *GinParser) Name() string {
 	return "Gin Framework Parser"
 }
@@ -24,101 +47,561 @@ func (p *GinParser) Language() string {
 	return "go-gin"
 }
 
+// ginDetector recognises Go modules that depend on Gin
+var ginDetector = parser.MarkerDetector{
+	Files:        map[string]float64{"go.mod": 0.3},
+	Dependencies: map[string][]string{"go.mod": {"github.com/gin-gonic/gin"}},
+	DepScore:     0.7,
+}
+
+// Detect implements parser.Detector
+func (p *GinParser) Detect(projectPath string) (float64, string) {
+	return ginDetector.Detect(projectPath)
+}
+
+// WithCache implements parser.Cacheable
+func (p *GinParser) WithCache(dir string) parser.Parser {
+	cached := *p
+	cached.cacheDir = dir
+	return &cached
+}
+
+// WithContext implements parser.Cancellable
+func (p *GinParser) WithContext(ctx context.Context) parser.Parser {
+	bound := *p
+	bound.ctx = ctx
+	return &bound
+}
+
+// context returns the context analyses run under
+func (p *GinParser) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
 func (p *GinParser) Analyze(projectPath string) (*openapi.Spec, error) {
+	structAnalyzer, err := p.newStructAnalyzer()
+	if err != nil {
+		return nil, err
+	}
+	files, skipped, err := p.collectFacts(projectPath, structAnalyzer)
+	if err != nil {
+		return nil, err
+	}
+	return p.analyze(projectPath, files, skipped, structAnalyzer, nil)
+}
+
+// AnalyzePrefix implements parser.PrefixAnalyzer. Route registrations are
+// located in every file, but structs, handlers and services are analyzed only
+// in the packages registering routes under prefix and the project packages
+// they import, directly or transitively.
+func (p *GinParser) AnalyzePrefix(projectPath, prefix string) (*openapi.Spec, error) {
+	// Routes are matched without parser.base_path, which prefix may include
+	prefix = openapi.NormalizePath(prefix)
+	if base := strings.TrimSuffix(p.cfg.BasePath, "/"); base != "" && openapi.HasPathPrefix(prefix, base) {
+		prefix = openapi.NormalizePath(strings.TrimPrefix(prefix, base))
+	}
+	scope := &analysisScope{prefix: prefix, dirs: make(map[string]bool)}
+
+	structAnalyzer, err := p.newStructAnalyzer()
+	if err != nil {
+		return nil, err
+	}
+	files, skipped, err := p.collectFacts(projectPath, structAnalyzer)
+	if err != nil {
+		return nil, err
+	}
+	var allRoutes []ast.RouteInfo
+	for _, file := range files {
+		for _, route := range file.routes {
+			allRoutes = append(allRoutes, route)
+			if scope.covers(route.Path) {
+				scope.dirs[filepath.Dir(file.path)] = true
+			}
+		}
+	}
+	if len(scope.dirs) == 0 {
+		return nil, fmt.Errorf("no routes under %s", scope.prefix)
+	}
+	// Tag sections must match those of a full analysis
+	scope.engines = ast.DistinctEngines(allRoutes)
+	scope.versioned = ast.VersionedTags(allRoutes)
+
+	module := modulePath(projectPath)
+	if module == "" {
+		// Without go.mod imports can't be mapped to directories: analyze everything
+		scope.dirs = nil
+	} else {
+		addImportedDirs(projectPath, module, scope.dirs)
+	}
+	log.Printf("🎯 Analyzing %s: %d package(s)", scope.prefix, len(scope.dirs))
+
+	return p.analyze(projectPath, files, skipped, structAnalyzer, scope)
+}
+
+// analysisScope limits an analysis to the routes under one path prefix
+type analysisScope struct {
+	prefix    string
+	dirs      map[string]bool // directories analyzed, nil for all
+	engines   []string        // engines of the whole project
+	versioned map[string]bool // tags of the whole project found under several API versions
+}
+
+// includes reports whether files of dir are analyzed; a nil scope includes everything
+func (s *analysisScope) includes(dir string) bool {
+	return s == nil || s.dirs == nil || s.dirs[dir]
+}
+
+// covers reports whether a route path lies under the scope's prefix
+func (s *analysisScope) covers(path string) bool {
+	return s == nil || openapi.HasPathPrefix(openapi.NormalizePath(path), s.prefix)
+}
+
+// newStructAnalyzer creates the struct analyzer with the configured type mappings
+func (p *GinParser) newStructAnalyzer() (*ast.StructAnalyzer, error) {
+	structAnalyzer := ast.NewStructAnalyzer()
+	if len(p.cfg.TypeMappings) > 0 {
+		mapper, err := ast.NewStaticTypeMapper(p.cfg.TypeMappings)
+		if err != nil {
+			return nil, err
+		}
+		structAnalyzer.AddTypeMapper(mapper)
+	}
+	return structAnalyzer, nil
+}
+
+// analyze builds the spec from the facts of files. Non-fatal issues, starting
+// with the skipped files, are recorded as warnings of the spec.
+func (p *GinParser) analyze(projectPath string, files []goFile, skipped []openapi.AnalysisWarning, structAnalyzer *ast.StructAnalyzer, scope *analysisScope) (*openapi.Spec, error) {
 	spec := openapi.NewSpec()
 	spec.Info.Title = "Auto-Generated API Documentation"
 	spec.Info.Description = "Generated from code analysis"
 	spec.Info.Version = "1.0.0"
+	spec.FoldPathCase = p.cfg.FoldPathCase
 
 	// Create analyzers
-	structAnalyzer := ast.NewStructAnalyzer()
 	serviceAnalyzer := ast.NewServiceAnalyzer()
 	handlerInfoMap := make(map[string]*ast.HandlerInfo)
+	var webhooks []ast.EventAnnotation
+	var messages []openapi.Message
 
-	// First pass: extract all struct schemas and handler info
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Continue on errors
+	var scoped []goFile
+	for _, file := range files {
+		if scope.includes(filepath.Dir(file.path)) {
+			scoped = append(scoped, file)
+		}
+	}
+	for _, warning := range skipped {
+		if scope.includes(filepath.Dir(filepath.Join(projectPath, warning.File))) {
+			spec.Warn(warning)
 		}
+	}
 
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
+	// First pass: extract all struct schemas and handler info
+	for _, file := range scoped {
+		structAnalyzer.AddFacts(file.facts.Structs)
+		serviceAnalyzer.AddFunctions(file.facts.Services)
+		for name, handler := range file.facts.Handlers {
+			handler.File = file.path // cached facts may come from another checkout
+			handlerInfoMap[name] = handler
 		}
+		webhooks = append(webhooks, file.facts.Webhooks...)
+		messages = append(messages, file.facts.Messages...)
+	}
 
-		// Skip test files, vendor, and tool directories
-		if strings.HasSuffix(path, "_test.go") ||
-			strings.Contains(path, "/vendor/") ||
-			strings.Contains(path, "/tools/") ||
-			strings.Contains(path, "/.git/") {
-			return nil
+	// Post-process: expand embedded fields
+	structAnalyzer.ExpandEmbeddedFields()
+
+	// Inline enum-like named types with their value tables
+	structAnalyzer.ResolveEnums()
+
+	// Resolve types declared outside the project (e.g. shared company modules)
+	if p.cfg.ResolveExternal {
+		resolver := ast.NewExternalResolver(projectPath, p.cfg.GoProxy, p.cfg.GoPrivate)
+		for typeName, err := range resolver.Resolve(structAnalyzer) {
+			log.Printf("⚠️  Unresolved external type %s: %v", typeName, err)
+			spec.Warn(openapi.AnalysisWarning{
+				Kind:    openapi.WarnUnknownType,
+				Message: fmt.Sprintf("external type %s could not be resolved: %v", typeName, err),
+			})
 		}
+	}
+	structAnalyzer.ReplaceDanglingRefs()
 
-		// Parse Go file
-		fset := token.NewFileSet()
-		node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	// Deep mode: refine handler types with the type checker's view of the project
+	var typed *ast.TypedResolver
+	if p.cfg.Mode == config.ParserModeDeep {
+		resolver, err := ast.LoadTypes(p.context(), projectPath, p.cfg.GoProxy, p.cfg.GoPrivate, structAnalyzer)
+		if ctxErr := p.context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
-			return nil // Continue on parse errors
+			log.Printf("⚠️  Deep analysis unavailable, falling back to AST analysis: %v", err)
+			spec.Warn(openapi.AnalysisWarning{
+				Kind:    openapi.WarnAnalysis,
+				Message: fmt.Sprintf("deep analysis unavailable, AST analysis used: %v", err),
+			})
+		} else {
+			resolver.ResolveHandlers(handlerInfoMap)
+			typed = resolver
 		}
+	}
+	
+	// Add all schemas to components
+	for name, schema := range structAnalyzer.GetAllSchemas() {
+		spec.AddSchema(name, *schema)
+	}
+	if typed != nil {
+		for name, schema := range typed.Schemas() {
+			spec.AddSchema(name, *schema)
+		}
+	}
+	
+	// Add common response wrapper schema
+	envelope, err := p.envelope()
+	if err != nil {
+		return nil, err
+	}
+	if envelope != nil {
+		spec.AddSchema("ApiResponse", envelope.Wrap(openapi.Schema{Type: "object", Description: "响应数据"}))
+		spec.EnvelopeData = envelope.DataField
+	}
 
-		// Analyze structs in this file with package context
-		packageName := extractPackageNameFromPath(path)
-		structAnalyzer.AnalyzeFileWithPackage(node, packageName)
-
-		// Analyze service functions (from service layer)
-		if strings.Contains(path, "/service/") {
-			// Extract package name from path
-			parts := strings.Split(path, "/")
-			for i, part := range parts {
-				if part == "service" && i+1 < len(parts) {
-					pkgName := parts[i+1]
-					serviceAnalyzer.AnalyzeFile(node, pkgName)
-					break
+	// Shared error schema of the project's error catalog
+	errorCatalog, err := p.errorCatalog()
+	if err != nil {
+		return nil, err
+	}
+	var components map[string]openapi.Schema
+	if spec.Components != nil {
+		components = spec.Components.Schemas
+	}
+	errorCatalog, add := errorCatalog.WithSchemaName(components)
+	if add {
+		if errorCatalog.SchemaName != "" {
+			log.Printf("⚠️  The project declares its own %s, error responses refer to %s", ast.ErrorSchemaName, errorCatalog.SchemaName)
+		}
+		spec.AddSchema(errorCatalog.ComponentName(), *errorCatalog.Schema)
+	}
+	style := ast.OperationStyle{Envelope: envelope, Errors: errorCatalog, Pagination: p.pagination()}
+
+	// Second pass: extract routes
+	var routes []ast.RouteInfo
+	for _, file := range scoped {
+		for _, route := range file.routes {
+			if route.IsFallback() {
+				log.Printf("ℹ️  %s handler (%s) is not documented", route.Kind, route.Handler)
+				continue
+			}
+			if !scope.covers(route.Path) {
+				continue
+			}
+			if route.IsStatic() {
+				if p.cfg.IncludeStatic {
+					routes = append(routes, route)
+				}
+				continue
+			}
+			// Link handler info to route
+			handlerInfo, exists := handlerInfoMap[route.Handler]
+			if route.InlineHandler != nil {
+				handlerInfo, exists = route.InlineHandler, true
+			}
+			if exists {
+				route.RequestType = handlerInfo.RequestType
+				route.ResponseType = handlerInfo.ResponseType
+				route.ResponseSchema = handlerInfo.ResponseSchema
+				route.RendersJSON, route.RawResponses = handlerInfo.RendersJSON, handlerInfo.RawResponses
+				route.QueryParams = handlerInfo.QueryParams
+				route.Callbacks = handlerInfo.Callbacks
+				route.ResponseHeaders = handlerInfo.ResponseHeaders
+				route.Cookies = handlerInfo.Cookies
+				route.Idempotency = handlerInfo.Idempotency
+				route.HandlerFile, route.HandlerLine = handlerInfo.File, handlerInfo.Line
+				route.Pagination = style.Pagination.Detect(route, requestFields(spec, route.RequestType))
+				
+				// Try to infer response type from service calls
+				if !handlerInfo.TypedResponse && handlerInfo.ResponseSchema == nil {
+					if inferredType := inferResponseTypeFromServiceCalls(handlerInfo, serviceAnalyzer); inferredType != "" {
+						route.ResponseType = inferredType
+						warnRoute(spec, route, openapi.WarnGuessedType, "response type %s inferred from the service called by %s", inferredType, route.Handler)
+					}
+				}
+				if route.ResponseType == "" && route.ResponseSchema == nil && (route.RendersJSON || len(route.RawResponses) == 0) {
+					warnRoute(spec, route, openapi.WarnMissingType, "no response type found in handler %s", route.Handler)
 				}
+			} else if route.Handler == "handler" { // not a named function nor a func(*gin.Context) literal
+				warnRoute(spec, route, openapi.WarnUnresolvedHandler, "inline handler is not analyzed, request and response are untyped")
+			} else {
+				warnRoute(spec, route, openapi.WarnUnresolvedHandler, "handler %s not found, request and response are untyped", route.Handler)
 			}
+			routes = append(routes, route)
 		}
+	}
 
-		// Analyze handlers in this file
-		handlers := ast.AnalyzeHandlers(node)
-		for name, handler := range handlers {
-			handlerInfoMap[name] = handler
+	// Several gin.Engine instances (e.g. public and admin servers) are kept
+	// apart with x-gin-engine and, by default, one tag section per engine.
+	// In split mode the sync targets and export --engine separate them.
+	engines := ast.DistinctEngines(routes)
+	if scope != nil {
+		engines = scope.engines
+	}
+	if len(engines) > 1 {
+		found := make(map[string]string)
+		for _, file := range files {
+			for engine, addr := range file.engines {
+				found[engine] = addr
+			}
+		}
+		addrs := make(map[string]string)
+		labels := make([]string, len(engines))
+		for i, engine := range engines {
+			labels[i] = engine
+			if addr := found[engine]; addr != "" {
+				addrs[engine] = addr
+				labels[i] += " (" + addr + ")"
+			}
 		}
+		log.Printf("🔀 Found %d gin engines: %s", len(engines), strings.Join(labels, ", "))
+		if len(addrs) > 0 {
+			spec.SetExtension(ast.ExtEngines, addrs)
+		}
+	}
+	labeler, err := ast.NewLabeler(p.cfg.SummaryTemplate, p.cfg.Translations)
+	if err != nil {
+		return nil, fmt.Errorf("parser.%w", err)
+	}
 
-		return nil
-	})
+	// Resources found under several API versions get one tag per version
+	var versioned map[string]bool
+	if p.cfg.VersionTags {
+		versioned = ast.VersionedTags(routes)
+		if scope != nil {
+			versioned = scope.versioned
+		}
+	}
+	// An operation is documented once per method and path. Engines that
+	// serve the same one (e.g. GET /health on every server) would silently
+	// lose theirs in split documents, so collisions are reported.
+	engineOf := make(map[string]string) // method and path -> engine
+	for _, route := range routes {
+		op := route.ToOperationWithStyle(style)
+		if len(versioned) > 0 {
+			ast.MarkVersion(op, route.Path, versioned)
+		}
+		labeler.Apply(op, route)
+		if len(engines) > 1 {
+			ast.MarkEngine(op, route.Engine, p.cfg.EngineMode != config.EngineModeMerge && p.cfg.EngineMode != config.EngineModeSplit)
+			key := route.Method + " " + openapi.NormalizePath(p.withBasePath(route.Path))
+			if previous, ok := engineOf[key]; ok && previous != route.Engine && previous != "" && route.Engine != "" {
+				log.Printf("⚠️  %s is served by gin engines %s and %s, only %s's operation is documented", key, previous, route.Engine, route.Engine)
+				warnRoute(spec, route, openapi.WarnAnalysis, "also served by gin engine %s, whose operation is replaced by %s's and missing from its split document", previous, route.Engine)
+			}
+			engineOf[key] = route.Engine
+		}
+		op.Origin = &openapi.Origin{Handler: route.Handler}
+		if route.HandlerFile != "" {
+			op.Origin.File, op.Origin.Line = relativePath(projectPath, route.HandlerFile), route.HandlerLine
+			if p.cfg.SourceLinks {
+				op.SetSource(op.Origin.File, op.Origin.Line)
+			}
+		}
+		spec.AddPath(p.withBasePath(route.Path), route.Method, op)
+	}
 
+	// Webhooks don't belong to a path prefix and are left to full analyses
+	if scope == nil {
+		for _, webhook := range webhooks {
+			spec.AddWebhook(webhook.Name, webhook.Method, webhook.ToOperation())
+		}
+
+		// Message channels for the AsyncAPI document; parser.messages
+		// declares channels of code that isn't annotated, and wins
+		for _, message := range p.cfg.Messages {
+			messages = append(messages, openapi.Message{
+				Channel:   message.Channel,
+				Direction: message.Direction,
+				Protocol:  message.Protocol,
+				Schema:    message.Schema[strings.LastIndex(message.Schema, ".")+1:],
+				Summary:   message.Summary,
+			})
+		}
+		for _, message := range messages {
+			if _, ok := spec.Components.Schemas[message.Schema]; !ok {
+				spec.Warn(openapi.AnalysisWarning{
+					Kind:    openapi.WarnUnknownType,
+					Message: fmt.Sprintf("payload type %s of %s channel %s has no schema and is documented as an object", message.Schema, message.Direction, message.Channel),
+				})
+			}
+			spec.AddMessage(message)
+		}
+		if len(spec.Messages) > 0 {
+			log.Printf("📨 Found %d message channels", len(spec.Messages))
+		}
+	}
+
+	// Drop ORM models, config types etc. that no endpoint refers to
+	if !p.cfg.KeepAllSchemas {
+		spec.PruneSchemas()
+	}
+
+	return spec, nil
+}
+
+// envelope returns the response envelope of the project: parser.envelope,
+// the DefaultEnvelope when not configured, nil when disabled
+func (p *GinParser) envelope() (*ast.Envelope, error) {
+	cfg := p.cfg.Envelope
+	if cfg == nil {
+		return ast.DefaultEnvelope, nil
+	}
+	if cfg.Disabled {
+		return nil, nil
+	}
+	envelope, err := ast.NewEnvelope(cfg.DataField, cfg.Properties, cfg.Required)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parser.%w", err)
 	}
+	return envelope, nil
+}
 
-	// Post-process: expand embedded fields
-	structAnalyzer.ExpandEmbeddedFields()
-	
-	// Add all schemas to components
-	for name, schema := range structAnalyzer.GetAllSchemas() {
-		spec.AddSchema(name, *schema)
+// errorCatalog returns the error responses of the project: parser.errors, or
+// the DefaultErrorCatalog when not configured
+func (p *GinParser) errorCatalog() (*ast.ErrorCatalog, error) {
+	cfg := p.cfg.Errors
+	if cfg == nil {
+		return ast.DefaultErrorCatalog, nil
 	}
-	
-	// Add common response wrapper schema
-	spec.AddSchema("ApiResponse", openapi.Schema{
-		Type: "object",
-		Properties: map[string]openapi.Schema{
-			"code": {
-				Type:        "integer",
-				Description: "响应状态码",
-			},
-			"message": {
-				Type:        "string",
-				Description: "响应消息",
-			},
-			"data": {
-				Type:        "object",
-				Description: "响应数据",
-			},
-		},
+	catalog, err := ast.NewErrorCatalog(cfg.Schema, cfg.Responses)
+	if err != nil {
+		return nil, fmt.Errorf("parser.%w", err)
+	}
+	return catalog, nil
+}
+
+// pagination returns the pagination convention of the project, nil when
+// parser.pagination is not configured
+func (p *GinParser) pagination() *ast.Pagination {
+	cfg := p.cfg.Pagination
+	if cfg == nil {
+		return nil
+	}
+	return &ast.Pagination{
+		PageParam:   cfg.PageParam,
+		SizeParam:   cfg.SizeParam,
+		OffsetParam: cfg.OffsetParam,
+		LimitParam:  cfg.LimitParam,
+		DefaultSize: cfg.DefaultSize,
+		MaxSize:     cfg.MaxSize,
+		ItemsField:  cfg.ItemsField,
+		TotalField:  cfg.TotalField,
+	}
+}
+
+// requestFields returns the properties of a request type's schema, the
+// fields a c.ShouldBindQuery(&req) fills
+func requestFields(spec *openapi.Spec, requestType string) map[string]openapi.Schema {
+	if requestType == "" {
+		return nil
+	}
+	return spec.Components.Schemas[requestType[strings.LastIndex(requestType, ".")+1:]].Properties
+}
+
+// withBasePath prefixes a route path with parser.base_path. Gin redirects
+// trailing slashes by default, so the root route is the base path itself.
+func (p *GinParser) withBasePath(path string) string {
+	base := strings.TrimSuffix(p.cfg.BasePath, "/")
+	if base == "" {
+		return path
+	}
+	if path == "/" {
+		return base
+	}
+	return base + path
+}
+
+// warnRoute records a warning about the operation of route
+func warnRoute(spec *openapi.Spec, route ast.RouteInfo, kind, format string, args ...interface{}) {
+	spec.Warn(openapi.AnalysisWarning{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		Method:  route.Method,
+		Path:    route.Path,
 	})
+}
 
-	// Second pass: extract routes
-	err = filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+// goFile is a Go source file of the project and what the analyzers found in it
+type goFile struct {
+	path   string
+	facts  *fileFacts
+	routes []ast.RouteInfo // facts.Routes mounted on the routers passed to their setup functions
+	// engines maps the engines declared in the file, by the name their
+	// routes carry (see ast.EngineNames), to the address they listen on
+	engines map[string]string
+}
+
+// fileFacts is everything the analysis uses from one Go file. It depends only
+// on the file's content and path, and the type mappings.
+type fileFacts struct {
+	Structs  *ast.StructFacts
+	Services []*ast.ServiceFuncInfo // service layer functions
+	Handlers map[string]*ast.HandlerInfo
+	Routes   []ast.RouteInfo
+	Mounts   []ast.RouterMount     // routers passed to setup functions
+	Webhooks []ast.EventAnnotation // outbound webhooks declared with @Webhook annotations
+	Messages []openapi.Message     // message channels declared with @Publishes and @Consumes
+	Engines  map[string]string     // engine -> address it listens on, see ast.ExtractEngineAddrs
+	Package  string                // package name declared by the file
+	Imports  []string              // paths imported without alias, see ast.ResolvePackageKey
+}
+
+// extractFacts runs the analyzers on one parsed file
+func extractFacts(fset *token.FileSet, path string, node *goast.File, structAnalyzer *ast.StructAnalyzer) *fileFacts {
+	facts := &fileFacts{
+		// Analyze structs in this file with package context
+		Structs:  structAnalyzer.ExtractFacts(node, extractPackageNameFromPath(path)),
+		Handlers: ast.AnalyzeHandlers(fset, node),
+		Webhooks: ast.ExtractWebhookAnnotations(node),
+		Messages: ast.ExtractMessageAnnotations(node),
+		Engines:  ast.ExtractEngineAddrs(node),
+		Package:  node.Name.Name,
+		Imports:  ast.ImportPaths(node),
+	}
+	facts.Routes, facts.Mounts = ast.ExtractGinRoutes(fset, node)
+
+	// Analyze service functions (from service layer)
+	if strings.Contains(path, "/service/") {
+		// Extract package name from path
+		parts := strings.Split(path, "/")
+		for i, part := range parts {
+			if part == "service" && i+1 < len(parts) {
+				facts.Services = ast.NewServiceAnalyzer().ExtractFunctions(node, parts[i+1])
+				break
+			}
+		}
+	}
+	return facts
+}
+
+// collectFacts analyzes every Go source file of the project, skipping tests,
+// vendored code, tool directories and files that don't parse. The project is
+// walked once and the files are parsed by a pool of workers sharing one
+// FileSet; they are returned in walk order, so analyses stay deterministic.
+// Files unchanged since the last analysis are taken from the cache.
+// Files larger than parser.max_file_size are skipped; more Go files than
+// parser.max_files, or a done context, end the analysis with an error.
+// Skipped files are returned as warnings.
+func (p *GinParser) collectFacts(projectPath string, structAnalyzer *ast.StructAnalyzer) ([]goFile, []openapi.AnalysisWarning, error) {
+	ctx := p.context()
+	maxFiles, maxFileSize := p.cfg.FileLimits()
+	var paths []string
+	var skipped []openapi.AnalysisWarning
+	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // Continue on errors
 		}
@@ -135,38 +618,247 @@ func (p *GinParser) Analyze(projectPath string) (*openapi.Spec, error) {
 			return nil
 		}
 
-		// Parse Go file
-		fset := token.NewFileSet()
-		node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if err != nil {
-			return nil // Continue on parse errors
+		// Symlinked files are analyzed like the files they point to
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+		}
+		if maxFileSize > 0 && info.Size() > maxFileSize {
+			log.Printf("⚠️  Skipping %s: %d bytes exceeds parser.max_file_size", path, info.Size())
+			skipped = append(skipped, openapi.AnalysisWarning{
+				Kind:    openapi.WarnSkippedFile,
+				Message: fmt.Sprintf("%d bytes exceeds parser.max_file_size", info.Size()),
+				File:    relativePath(projectPath, path),
+			})
+			return nil
+		}
+		if maxFiles > 0 && len(paths) >= maxFiles {
+			return fmt.Errorf("%w: more than %d Go files (parser.max_files)", parser.ErrLimitExceeded, maxFiles)
 		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Extract routes using AST analysis
-		routes := ast.ExtractGinRoutes(node)
-		for _, route := range routes {
-			// Link handler info to route
-			if handlerInfo, exists := handlerInfoMap[route.Handler]; exists {
-				route.RequestType = handlerInfo.RequestType
-				route.ResponseType = handlerInfo.ResponseType
-				
-				// Try to infer response type from service calls
-				inferredType := inferResponseTypeFromServiceCalls(handlerInfo, serviceAnalyzer)
-				if inferredType != "" {
-					route.ResponseType = inferredType
+	cache := p.openCache(projectPath)
+	fset := token.NewFileSet()
+	facts := make([]*fileFacts, len(paths))
+	parseErrors := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0) && w < len(paths); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				src, err := os.ReadFile(paths[i])
+				if err != nil {
+					continue
+				}
+				if cached := cache.lookup(paths[i], src); cached != nil {
+					facts[i] = cached
+					continue
+				}
+				node, err := goparser.ParseFile(fset, paths[i], src, goparser.ParseComments)
+				if err != nil { // Files that don't parse are skipped
+					parseErrors[i] = err
+					continue
 				}
+				facts[i] = extractFacts(fset, paths[i], node, structAnalyzer)
+				cache.store(paths[i], src, facts[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	cache.save()
+
+	files := make([]goFile, 0, len(paths))
+	for i, f := range facts {
+		if f != nil {
+			files = append(files, goFile{path: paths[i], facts: f, engines: make(map[string]string)})
+		} else if parseErrors[i] != nil {
+			skipped = append(skipped, openapi.AnalysisWarning{
+				Kind:    openapi.WarnParseError,
+				Message: strings.TrimPrefix(parseErrors[i].Error(), paths[i]+":"),
+				File:    relativePath(projectPath, paths[i]),
+			})
+		}
+	}
+
+	// Function keys get the import path of their package and engine keys
+	// their file, which the facts of a single file don't know
+	keys := newFuncKeys(projectPath, files)
+	var mounts []ast.RouterMount
+	declared := make([][]ast.RouteInfo, len(files)) // routes of each file, before mounting
+	var all []ast.RouteInfo
+	for i, file := range files {
+		rel := relativePath(projectPath, file.path)
+		for _, mount := range file.facts.Mounts {
+			mount.Engine = ast.QualifyEngine(mount.Engine, rel)
+			mount.Callee, mount.Caller = keys.qualify(mount.Callee, file), keys.qualify(mount.Caller, file)
+			mounts = append(mounts, mount)
+		}
+		for _, route := range file.facts.Routes {
+			route.Engine = ast.QualifyEngine(route.Engine, rel)
+			if route.Origin != nil {
+				origin := *route.Origin
+				origin.Func = keys.qualify(origin.Func, file)
+				route.Origin = &origin
 			}
-			spec.AddPath(route.Path, route.Method, route.ToOperation())
+			declared[i] = append(declared[i], route)
 		}
+		all = append(all, declared[i]...)
+	}
 
-		return nil
+	// Routes registered in setup functions get the prefixes of the routers
+	// the functions are called with, in whichever file the call is
+	routerMounts := ast.NewRouterMounts(mounts, all)
+	var engines []string
+	for i := range files {
+		for _, route := range declared[i] {
+			for _, mounted := range routerMounts.Expand(route) {
+				files[i].routes = append(files[i].routes, mounted)
+				engines = append(engines, mounted.Engine)
+			}
+		}
+	}
+
+	// Engines are told apart by file and function, so each r := gin.New()
+	// of several binaries is its own engine
+	names := ast.EngineNames(engines)
+	for i := range files {
+		for j := range files[i].routes {
+			files[i].routes[j].Engine = names[files[i].routes[j].Engine]
+		}
+		for key, addr := range files[i].facts.Engines {
+			if name := names[ast.QualifyEngine(key, relativePath(projectPath, files[i].path))]; name != "" {
+				files[i].engines[name] = addr
+			}
+		}
+	}
+	return files, skipped, nil
+}
+
+// funcKeys qualifies the function keys found in the files of a project with
+// the import paths of their packages: the module path and the directory.
+// Without go.mod, import paths can't be mapped to directories and packages
+// are told apart by name.
+type funcKeys struct {
+	projectPath string
+	module      string
+	packages    map[string]string // import path -> package name, of the project's packages
+	names       map[string]bool   // package names declared in the project
+}
+
+func newFuncKeys(projectPath string, files []goFile) *funcKeys {
+	k := &funcKeys{projectPath: projectPath, module: modulePath(projectPath), packages: make(map[string]string), names: make(map[string]bool)}
+	for _, file := range files {
+		k.names[file.facts.Package] = true
+		if k.module != "" {
+			k.packages[k.importPath(file)] = file.facts.Package
+		}
+	}
+	return k
+}
+
+// importPath returns the import path of the package of file
+func (k *funcKeys) importPath(file goFile) string {
+	dir := path.Dir(relativePath(k.projectPath, file.path))
+	if dir == "." {
+		return k.module
+	}
+	return k.module + "/" + dir
+}
+
+// qualify returns key, found in file, as it is known across the project
+func (k *funcKeys) qualify(key string, file goFile) string {
+	if k.module == "" {
+		if name, fn, ok := strings.Cut(strings.TrimPrefix(key, "?"), "."); ok && strings.HasPrefix(key, "?") {
+			if key = "(*)." + fn; k.names[name] {
+				key = name + "." + fn
+			}
+		}
+		return ast.PackageNameKey(ast.QualifyFuncKey(key, file.facts.Package))
+	}
+	key = ast.ResolvePackageKey(key, file.facts.Imports, func(importPath string) string {
+		return k.packages[importPath]
 	})
+	return ast.QualifyFuncKey(key, k.importPath(file))
+}
+
+// relativePath returns path relative to the project root, with forward
+// slashes. Either may be absolute (go/packages reports absolute paths).
+func relativePath(projectPath, path string) string {
+	if filepath.IsAbs(path) != filepath.IsAbs(projectPath) {
+		projectPath, _ = filepath.Abs(projectPath)
+		path, _ = filepath.Abs(path)
+	}
+	if rel, err := filepath.Rel(projectPath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
 
+// modulePath reads the module path from the project's go.mod
+func modulePath(projectPath string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "go.mod"))
 	if err != nil {
-		return nil, err
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
 	}
+	return ""
+}
 
-	return spec, nil
+// addImportedDirs adds the directories of project packages imported, directly
+// or transitively, by the packages in dirs
+func addImportedDirs(projectPath, module string, dirs map[string]bool) {
+	queue := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		queue = append(queue, dir)
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			node, err := goparser.ParseFile(token.NewFileSet(), file, nil, goparser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			for _, imp := range node.Imports {
+				importPath := strings.Trim(imp.Path.Value, `"`)
+				if importPath != module && !strings.HasPrefix(importPath, module+"/") {
+					continue
+				}
+				imported := filepath.Join(projectPath, filepath.FromSlash(strings.TrimPrefix(importPath, module)))
+				if !dirs[imported] {
+					dirs[imported] = true
+					queue = append(queue, imported)
+				}
+			}
+		}
+	}
 }
 
 // extractPackageNameFromPath 从文件路径提取包名
@@ -0,0 +1,221 @@
+package graphql
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/parser"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is where the GraphQL endpoint is documented unless
+// parser.graphql_path says otherwise
+const DefaultPath = "/graphql"
+
+// GraphQLParser documents services exposing a GraphQL API, such as those
+// generated by gqlgen, from their schema definition files
+type GraphQLParser struct {
+	cfg config.ParserConfig
+}
+
+func NewGraphQLParser() *GraphQLParser {
+	return &GraphQLParser{}
+}
+
+// NewGraphQLParserWithConfig creates a parser honoring project-level parser settings
+func NewGraphQLParserWithConfig(cfg config.ParserConfig) *GraphQLParser {
+	return &GraphQLParser{cfg: cfg}
+}
+
+// WithConfig returns a parser using project-level parser settings
+func (p *GraphQLParser) WithConfig(cfg config.ParserConfig) parser.Parser {
+	return NewGraphQLParserWithConfig(cfg)
+}
+
+func (p *GraphQLParser) Name() string {
+	return "GraphQL Schema Parser"
+}
+
+func (p *GraphQLParser) Language() string {
+	return "graphql"
+}
+
+// gqlgenDetector recognises gqlgen projects by their config and dependency
+var gqlgenDetector = parser.MarkerDetector{
+	Files:        map[string]float64{"gqlgen.yml": 0.5, "gqlgen.yaml": 0.5},
+	Dependencies: map[string][]string{"go.mod": {"github.com/99designs/gqlgen"}},
+	DepScore:     0.5,
+}
+
+// Detect implements parser.Detector
+func (p *GraphQLParser) Detect(projectPath string) (float64, string) {
+	return gqlgenDetector.Detect(projectPath)
+}
+
+// Analyze reads the project's schema files and documents its GraphQL endpoint
+func (p *GraphQLParser) Analyze(projectPath string) (*openapi.Spec, error) {
+	files, err := schemaFiles(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no GraphQL schema files (*.graphql, *.graphqls) found")
+	}
+
+	schema := NewSchema()
+	for _, file := range files {
+		rel, _ := filepath.Rel(projectPath, file)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := schema.Parse(string(data)); errors.Is(err, errExecutable) {
+			log.Printf("⏭️  Skipping %s: %v", rel, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+	if schema.Root("query") == nil {
+		return nil, fmt.Errorf("GraphQL schema has no %s type", schema.Roots["query"])
+	}
+
+	spec := openapi.NewSpec()
+	spec.Info.Title = "Auto-Generated API Documentation"
+	spec.Info.Description = "Generated from GraphQL schema"
+	spec.Info.Version = "1.0.0"
+	schema.AddToSpec(spec, p.path())
+	for _, name := range schema.scalarNames() {
+		spec.Warn(openapi.AnalysisWarning{
+			Kind:    openapi.WarnUnknownType,
+			Message: fmt.Sprintf("custom scalar %s is documented as a string", name),
+		})
+	}
+
+	counts := schema.Count()
+	log.Printf("🔮 GraphQL schema: %d files, %d queries, %d mutations, %d subscriptions, %d types",
+		len(files), counts["query"], counts["mutation"], counts["subscription"], len(schema.Types))
+	return spec, nil
+}
+
+// path returns where the endpoint is documented, under parser.base_path
+func (p *GraphQLParser) path() string {
+	path := p.cfg.GraphQLPath
+	if path == "" {
+		path = DefaultPath
+	}
+	return strings.TrimSuffix(p.cfg.BasePath, "/") + path
+}
+
+// gqlgenConfig is the part of gqlgen.yml naming the schema files
+type gqlgenConfig struct {
+	Schema interface{} `yaml:"schema"` // a glob or a list of globs
+}
+
+// defaultSchemaGlobs are used when the project has no gqlgen config
+var defaultSchemaGlobs = []string{"**/*.graphql", "**/*.graphqls"}
+
+// schemaFiles returns the schema files of a project: those matching the
+// schema globs of gqlgen.yml, or every .graphql and .graphqls file. Vendored,
+// hidden and node_modules directories are skipped.
+func schemaFiles(projectPath string) ([]string, error) {
+	globs, err := gqlgenSchemaGlobs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []*regexp.Regexp
+	for _, glob := range globs {
+		patterns = append(patterns, globPattern(glob))
+	}
+
+	var files []string
+	err = filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != projectPath && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(filepath.ToSlash(rel)) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// gqlgenSchemaGlobs reads the schema globs of gqlgen.yml or gqlgen.yaml
+func gqlgenSchemaGlobs(projectPath string) ([]string, error) {
+	for _, name := range []string{"gqlgen.yml", "gqlgen.yaml"} {
+		data, err := os.ReadFile(filepath.Join(projectPath, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var cfg gqlgenConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		switch schema := cfg.Schema.(type) {
+		case string:
+			return []string{schema}, nil
+		case []interface{}:
+			var globs []string
+			for _, glob := range schema {
+				if s, ok := glob.(string); ok {
+					globs = append(globs, s)
+				}
+			}
+			if len(globs) > 0 {
+				return globs, nil
+			}
+		}
+	}
+	return defaultSchemaGlobs, nil
+}
+
+// globPattern compiles a gqlgen schema glob, where ** matches any number of
+// directories, into a regexp matching slash separated relative paths
+func globPattern(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(filepath.ToSlash(glob), "./")
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
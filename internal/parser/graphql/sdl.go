@@ -0,0 +1,646 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// errExecutable is returned for documents holding queries rather than type
+// definitions, like the .graphql files of a client
+var errExecutable = errors.New("executable document, not a schema")
+
+// Kinds of type definitions
+const (
+	kindObject    = "type"
+	kindInterface = "interface"
+	kindInput     = "input"
+	kindEnum      = "enum"
+	kindUnion     = "union"
+	kindScalar    = "scalar"
+)
+
+// Schema is the type system declared by a project's SDL files
+type Schema struct {
+	Types map[string]*TypeDef
+	Order []string // type names in declaration order
+	Roots map[string]string
+}
+
+// TypeDef is a type definition, with the fields of its extensions
+type TypeDef struct {
+	Kind        string
+	Name        string
+	Description string
+	Fields      []*FieldDef // fields of objects, interfaces and inputs
+	Values      []string    // values of enums
+	Members     []string    // member types of unions
+	Interfaces  []string
+}
+
+// FieldDef is a field of an object or interface, or an input value of an
+// input type or a field's arguments
+type FieldDef struct {
+	Name        string
+	Description string
+	Type        TypeRef
+	Args        []*FieldDef
+	Default     string
+	Deprecated  string // reason of @deprecated, "" when not deprecated
+}
+
+// TypeRef is a reference to a type: String, [User!]!
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef // element of list types
+	NonNull bool
+}
+
+func (t TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// NewSchema returns an empty schema with the default root type names
+func NewSchema() *Schema {
+	return &Schema{
+		Types: make(map[string]*TypeDef),
+		Roots: map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"},
+	}
+}
+
+// Root returns the root type of an operation type, or nil
+func (s *Schema) Root(operation string) *TypeDef {
+	return s.Types[s.Roots[operation]]
+}
+
+// typeDef returns the definition of a type, creating it for extensions that
+// come before the definition
+func (s *Schema) typeDef(kind, name string) *TypeDef {
+	def, ok := s.Types[name]
+	if !ok {
+		def = &TypeDef{Kind: kind, Name: name}
+		s.Types[name] = def
+		s.Order = append(s.Order, name)
+	}
+	return def
+}
+
+// token is a lexical token of the SDL; punctuators are their own kind
+type token struct {
+	kind  string // name, string, number, punct, eof
+	value string
+	line  int
+}
+
+type lexer struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ',' || unicode.IsSpace(c) || c == '\uFEFF':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: "eof", line: l.line}, nil
+}
+
+func (l *lexer) token() (token, error) {
+	start, c := l.pos, l.src[l.pos]
+	switch {
+	case c == '_' || unicode.IsLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || unicode.IsLetter(l.src[l.pos]) || unicode.IsDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: "name", value: string(l.src[start:l.pos]), line: l.line}, nil
+	case c == '-' || unicode.IsDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && strings.ContainsRune("0123456789.eE+-", l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: "number", value: string(l.src[start:l.pos]), line: l.line}, nil
+	case c == '"':
+		return l.string()
+	case c == '.':
+		if l.pos+2 < len(l.src) && string(l.src[l.pos:l.pos+3]) == "..." {
+			l.pos += 3
+			return token{kind: "punct", value: "...", line: l.line}, nil
+		}
+	case strings.ContainsRune("!$&()[]{}:=@|", c):
+		l.pos++
+		return token{kind: "punct", value: string(c), line: l.line}, nil
+	}
+	return token{}, fmt.Errorf("line %d: unexpected character %q", l.line, c)
+}
+
+// string reads a "string" or a """block string""", whose common indentation
+// is removed
+func (l *lexer) string() (token, error) {
+	line := l.line
+	if l.pos+2 < len(l.src) && string(l.src[l.pos:l.pos+3]) == `"""` {
+		for end := l.pos + 3; end+3 <= len(l.src); end++ {
+			if string(l.src[end:end+3]) == `"""` && l.src[end-1] != '\\' {
+				raw := string(l.src[l.pos+3 : end])
+				l.line += strings.Count(raw, "\n")
+				l.pos = end + 3
+				return token{kind: "string", value: blockString(raw), line: line}, nil
+			}
+		}
+		return token{}, fmt.Errorf("line %d: unterminated block string", line)
+	}
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		switch c := l.src[l.pos]; c {
+		case '"':
+			l.pos++
+			return token{kind: "string", value: b.String(), line: line}, nil
+		case '\\':
+			l.pos++
+			if l.pos < len(l.src) {
+				switch e := l.src[l.pos]; e {
+				case 'n':
+					b.WriteRune('\n')
+				case 't':
+					b.WriteRune('\t')
+				default:
+					b.WriteRune(e)
+				}
+			}
+		case '\n':
+			return token{}, fmt.Errorf("line %d: unterminated string", line)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return token{}, fmt.Errorf("line %d: unterminated string", line)
+}
+
+// blockString trims the blank first and last lines and the common
+// indentation of a block string
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// sdlParser reads type system definitions into a Schema
+type sdlParser struct {
+	lex    *lexer
+	tok    token
+	schema *Schema
+}
+
+// Parse adds the definitions of an SDL document to the schema
+func (s *Schema) Parse(src string) error {
+	p := &sdlParser{lex: &lexer{src: []rune(src), line: 1}, schema: s}
+	if err := p.advance(); err != nil {
+		return err
+	}
+	for p.tok.kind != "eof" {
+		if err := p.definition(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *sdlParser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *sdlParser) is(value string) bool {
+	return (p.tok.kind == "punct" || p.tok.kind == "name") && p.tok.value == value
+}
+
+// accept consumes the token when it is value
+func (p *sdlParser) accept(value string) (bool, error) {
+	if !p.is(value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *sdlParser) expect(value string) error {
+	if !p.is(value) {
+		return p.errorf("expected %q, found %q", value, p.tok.value)
+	}
+	return p.advance()
+}
+
+func (p *sdlParser) name() (string, error) {
+	if p.tok.kind != "name" {
+		return "", p.errorf("expected a name, found %q", p.tok.value)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *sdlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.tok.line, fmt.Sprintf(format, args...))
+}
+
+// description reads an optional description string
+func (p *sdlParser) description() (string, error) {
+	if p.tok.kind != "string" {
+		return "", nil
+	}
+	description := p.tok.value
+	return description, p.advance()
+}
+
+func (p *sdlParser) definition() error {
+	description, err := p.description()
+	if err != nil {
+		return err
+	}
+	if p.is("{") {
+		return errExecutable
+	}
+	keyword, err := p.name()
+	if err != nil {
+		return err
+	}
+	extend := keyword == "extend"
+	if extend {
+		if keyword, err = p.name(); err != nil {
+			return err
+		}
+	}
+
+	switch keyword {
+	case "query", "mutation", "subscription", "fragment":
+		return errExecutable
+	case "schema":
+		return p.schemaDefinition()
+	case "directive":
+		return p.directiveDefinition()
+	case kindObject, kindInterface, kindInput, kindEnum, kindUnion, kindScalar:
+	default:
+		return p.errorf("unexpected %q", keyword)
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	def := p.schema.typeDef(keyword, name)
+	if !extend {
+		def.Kind = keyword
+		if description != "" {
+			def.Description = description
+		}
+	}
+	if ok, err := p.accept("implements"); err != nil {
+		return err
+	} else if ok {
+		interfaces, err := p.names("&")
+		if err != nil {
+			return err
+		}
+		def.Interfaces = append(def.Interfaces, interfaces...)
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+
+	switch keyword {
+	case kindObject, kindInterface, kindInput:
+		if !p.is("{") {
+			return nil
+		}
+		fields, err := p.fields(keyword != kindInput)
+		if err != nil {
+			return err
+		}
+		def.Fields = append(def.Fields, fields...)
+	case kindEnum:
+		if ok, err := p.accept("{"); err != nil || !ok {
+			return err
+		}
+		for !p.is("}") {
+			if _, err := p.description(); err != nil {
+				return err
+			}
+			value, err := p.name()
+			if err != nil {
+				return err
+			}
+			if _, err := p.directives(); err != nil {
+				return err
+			}
+			def.Values = append(def.Values, value)
+		}
+		return p.advance()
+	case kindUnion:
+		if ok, err := p.accept("="); err != nil || !ok {
+			return err
+		}
+		members, err := p.names("|")
+		if err != nil {
+			return err
+		}
+		def.Members = append(def.Members, members...)
+	}
+	return nil
+}
+
+// names reads names separated by sep, which may also lead the list:
+// A & B, | A | B
+func (p *sdlParser) names(sep string) ([]string, error) {
+	if _, err := p.accept(sep); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if ok, err := p.accept(sep); err != nil || !ok {
+			return names, err
+		}
+	}
+}
+
+// schemaDefinition reads schema { query: RootQuery ... }, renaming the roots
+func (p *sdlParser) schemaDefinition() error {
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if ok, err := p.accept("{"); err != nil || !ok {
+		return err
+	}
+	for !p.is("}") {
+		operation, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		root, err := p.name()
+		if err != nil {
+			return err
+		}
+		p.schema.Roots[operation] = root
+	}
+	return p.advance()
+}
+
+// directiveDefinition skips directive @name(args) repeatable on A | B
+func (p *sdlParser) directiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("(") {
+		if _, err := p.arguments(); err != nil {
+			return err
+		}
+	}
+	if _, err := p.accept("repeatable"); err != nil {
+		return err
+	}
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+	_, err := p.names("|")
+	return err
+}
+
+// fields reads { field(args): Type ... }; withArgs is false for input types
+func (p *sdlParser) fields(withArgs bool) ([]*FieldDef, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []*FieldDef
+	for !p.is("}") {
+		if p.tok.kind == "eof" {
+			return nil, p.errorf("unexpected end of document")
+		}
+		field, err := p.inputValue(withArgs)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, p.advance()
+}
+
+// arguments reads (name: Type = default ...)
+func (p *sdlParser) arguments() ([]*FieldDef, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []*FieldDef
+	for !p.is(")") {
+		if p.tok.kind == "eof" {
+			return nil, p.errorf("unexpected end of document")
+		}
+		arg, err := p.inputValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+// inputValue reads a field or argument definition
+func (p *sdlParser) inputValue(withArgs bool) (*FieldDef, error) {
+	description, err := p.description()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldDef{Name: name, Description: description}
+	if withArgs && p.is("(") {
+		if field.Args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if field.Type, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.accept("="); err != nil {
+		return nil, err
+	} else if ok {
+		if field.Default, err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	if reason, ok := directives["deprecated"]; ok {
+		field.Deprecated = reason
+		if field.Deprecated == "" {
+			field.Deprecated = "No longer supported"
+		}
+	}
+	return field, nil
+}
+
+func (p *sdlParser) typeRef() (TypeRef, error) {
+	var ref TypeRef
+	if ok, err := p.accept("["); err != nil {
+		return ref, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return ref, err
+		}
+		ref.Elem = &elem
+		if err := p.expect("]"); err != nil {
+			return ref, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return ref, err
+		}
+		ref.Name = name
+	}
+	ok, err := p.accept("!")
+	ref.NonNull = ok
+	return ref, err
+}
+
+// directives reads @name(args) directives, returning their names with the
+// reason argument, which only @deprecated uses
+func (p *sdlParser) directives() (map[string]string, error) {
+	directives := make(map[string]string)
+	for p.is("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		directives[name] = ""
+		if ok, err := p.accept("("); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		for !p.is(")") {
+			arg, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			if arg == "reason" {
+				directives[name] = value
+			}
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return directives, nil
+}
+
+// value reads a constant value as written, strings unquoted
+func (p *sdlParser) value() (string, error) {
+	switch {
+	case p.tok.kind == "string", p.tok.kind == "number", p.tok.kind == "name":
+		value := p.tok.value
+		return value, p.advance()
+	case p.is("$"):
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		name, err := p.name()
+		return "$" + name, err
+	case p.is("["), p.is("{"):
+		open, close := p.tok.value, "]"
+		if open == "{" {
+			close = "}"
+		}
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		var items []string
+		for !p.is(close) {
+			if p.tok.kind == "eof" {
+				return "", p.errorf("unexpected end of document")
+			}
+			if open == "{" {
+				name, err := p.name()
+				if err != nil {
+					return "", err
+				}
+				if err := p.expect(":"); err != nil {
+					return "", err
+				}
+				value, err := p.value()
+				if err != nil {
+					return "", err
+				}
+				items = append(items, name+": "+value)
+				continue
+			}
+			value, err := p.value()
+			if err != nil {
+				return "", err
+			}
+			items = append(items, value)
+		}
+		return open + strings.Join(items, ", ") + close, p.advance()
+	}
+	return "", p.errorf("unexpected %q", p.tok.value)
+}
@@ -0,0 +1,280 @@
+package graphql
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Components documenting the GraphQL protocol itself
+const (
+	requestSchema  = "GraphQLRequest"
+	responseSchema = "GraphQLResponse"
+	errorSchema    = "GraphQLError"
+)
+
+// scalarSchemas are the built-in scalars and the usual custom ones
+var scalarSchemas = map[string]openapi.Schema{
+	"Int":      {Type: "integer", Format: "int32"},
+	"Int64":    {Type: "integer", Format: "int64"},
+	"Float":    {Type: "number"},
+	"String":   {Type: "string"},
+	"Boolean":  {Type: "boolean"},
+	"ID":       {Type: "string"},
+	"Time":     {Type: "string", Format: "date-time"},
+	"DateTime": {Type: "string", Format: "date-time"},
+	"Date":     {Type: "string", Format: "date"},
+	"UUID":     {Type: "string", Format: "uuid"},
+	"Upload":   {Type: "string", Format: "binary"},
+	"Map":      {Type: "object"},
+	"Any":      {Type: "object"},
+	"JSON":     {Type: "object"},
+}
+
+// operationTypes are the root operation types, in document order
+var operationTypes = []string{"query", "mutation", "subscription"}
+
+// AddToSpec documents the schema as the GraphQL endpoint at path: one POST
+// operation whose description lists the root fields, and a component schema
+// for every object, input, enum, interface and union type, root types
+// included, with the arguments of fields in their descriptions
+func (s *Schema) AddToSpec(spec *openapi.Spec, path string) {
+	for _, name := range s.Order {
+		if def := s.Types[name]; def.Kind != kindScalar {
+			spec.AddSchema(name, s.typeSchema(def))
+		}
+	}
+
+	spec.AddSchema(requestSchema, openapi.Schema{
+		Type: "object",
+		Properties: map[string]openapi.Schema{
+			"query":         {Type: "string", Description: "GraphQL document"},
+			"operationName": {Type: "string", Description: "Operation to run when the document holds several"},
+			"variables":     {Type: "object", Description: "Values of the operation's variables"},
+		},
+		Required: []string{"query"},
+	})
+	spec.AddSchema(errorSchema, openapi.Schema{
+		Type: "object",
+		Properties: map[string]openapi.Schema{
+			"message":    {Type: "string"},
+			"path":       {Type: "array", Items: &openapi.Schema{Type: "string"}},
+			"extensions": {Type: "object"},
+		},
+		Required: []string{"message"},
+	})
+	spec.AddSchema(responseSchema, openapi.Schema{
+		Type: "object",
+		Properties: map[string]openapi.Schema{
+			"data":   {Type: "object", Description: "Selected fields of the " + strings.Join(s.rootNames(), ", ") + " root type"},
+			"errors": {Type: "array", Items: &openapi.Schema{Ref: openapi.SchemaRefPrefix + errorSchema}},
+		},
+	})
+
+	op := &openapi.Operation{
+		Summary:     "GraphQL endpoint",
+		Description: s.operationsDescription(),
+		Tags:        []string{"GraphQL"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"application/json": {Schema: openapi.Schema{Ref: openapi.SchemaRefPrefix + requestSchema}},
+			},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "Results and errors of the operation",
+				Content: map[string]openapi.MediaType{
+					"application/json": {Schema: openapi.Schema{Ref: openapi.SchemaRefPrefix + responseSchema}},
+				},
+			},
+		},
+	}
+	op.SetExtension("x-graphql-operations", s.operationList())
+	spec.AddPath(path, "POST", op)
+}
+
+// typeSchema documents a type definition
+func (s *Schema) typeSchema(def *TypeDef) openapi.Schema {
+	schema := openapi.Schema{Type: "object", Description: def.Description}
+	switch def.Kind {
+	case kindEnum:
+		schema.Type = "string"
+		for _, value := range def.Values {
+			schema.Enum = append(schema.Enum, value)
+		}
+		return schema
+	case kindUnion:
+		schema.Description = joinSentences(def.Description, "One of "+strings.Join(def.Members, ", ")+", told apart by __typename.")
+		return schema
+	}
+	schema.Properties = make(map[string]openapi.Schema, len(def.Fields))
+	for _, field := range def.Fields {
+		property := s.refSchema(field.Type)
+		description := field.Description
+		if len(field.Args) > 0 {
+			description = joinSentences(description, "Arguments: "+argumentList(field.Args)+".")
+		}
+		if field.Deprecated != "" {
+			description = joinSentences(description, "Deprecated: "+field.Deprecated)
+		}
+		if description != "" {
+			property.Description = description
+		}
+		schema.Properties[field.Name] = property
+		if field.Type.NonNull {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+	if def.Kind == kindInterface {
+		schema.Description = joinSentences(schema.Description, "Implemented by "+strings.Join(s.implementations(def.Name), ", ")+".")
+	}
+	return schema
+}
+
+// refSchema documents a type reference
+func (s *Schema) refSchema(ref TypeRef) openapi.Schema {
+	if ref.Elem != nil {
+		items := s.refSchema(*ref.Elem)
+		return openapi.Schema{Type: "array", Items: &items}
+	}
+	if def, ok := s.Types[ref.Name]; ok && def.Kind != kindScalar {
+		return openapi.Schema{Ref: openapi.SchemaRefPrefix + ref.Name}
+	}
+	if schema, ok := scalarSchemas[ref.Name]; ok {
+		return schema
+	}
+	return openapi.Schema{Type: "string", Description: "Custom scalar " + ref.Name}
+}
+
+// implementations returns the object types implementing an interface
+func (s *Schema) implementations(name string) []string {
+	var types []string
+	for _, typeName := range s.Order {
+		for _, iface := range s.Types[typeName].Interfaces {
+			if iface == name {
+				types = append(types, typeName)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return []string{"no type"}
+	}
+	return types
+}
+
+// operationsDescription lists the root fields as Markdown, by operation type
+func (s *Schema) operationsDescription() string {
+	var b strings.Builder
+	b.WriteString("All operations are sent to this endpoint as a GraphQL document.")
+	for _, operation := range operationTypes {
+		root := s.Root(operation)
+		if root == nil || len(root.Fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n**%s**\n", strings.ToUpper(operation[:1])+operation[1:])
+		for _, field := range root.Fields {
+			fmt.Fprintf(&b, "\n- `%s`", signature(field))
+			if field.Description != "" {
+				b.WriteString(": " + strings.ReplaceAll(field.Description, "\n", " "))
+			}
+			if field.Deprecated != "" {
+				b.WriteString(" (deprecated: " + field.Deprecated + ")")
+			}
+		}
+	}
+	return b.String()
+}
+
+// graphQLOperation is an entry of the x-graphql-operations extension
+type graphQLOperation struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+// operationList lists the root fields for tools reading the extension
+func (s *Schema) operationList() []graphQLOperation {
+	var operations []graphQLOperation
+	for _, operation := range operationTypes {
+		root := s.Root(operation)
+		if root == nil {
+			continue
+		}
+		for _, field := range root.Fields {
+			operations = append(operations, graphQLOperation{
+				Type:        operation,
+				Name:        field.Name,
+				Signature:   signature(field),
+				Description: field.Description,
+				Deprecated:  field.Deprecated != "",
+			})
+		}
+	}
+	return operations
+}
+
+// rootNames returns the names of the root types the schema defines
+func (s *Schema) rootNames() []string {
+	var names []string
+	for _, operation := range operationTypes {
+		if root := s.Root(operation); root != nil {
+			names = append(names, root.Name)
+		}
+	}
+	return names
+}
+
+// Count returns the number of root fields by operation type
+func (s *Schema) Count() map[string]int {
+	counts := make(map[string]int)
+	for _, operation := range operationTypes {
+		if root := s.Root(operation); root != nil {
+			counts[operation] = len(root.Fields)
+		}
+	}
+	return counts
+}
+
+// signature renders user(id: ID!): User
+func signature(field *FieldDef) string {
+	if len(field.Args) == 0 {
+		return field.Name + ": " + field.Type.String()
+	}
+	return field.Name + "(" + argumentList(field.Args) + "): " + field.Type.String()
+}
+
+func argumentList(args []*FieldDef) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Name + ": " + arg.Type.String()
+		if arg.Default != "" {
+			parts[i] += " = " + arg.Default
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinSentences(first, second string) string {
+	if first == "" {
+		return second
+	}
+	return strings.TrimRight(first, " ") + " " + second
+}
+
+// scalarNames returns the custom scalars of the schema, sorted
+func (s *Schema) scalarNames() []string {
+	var names []string
+	for name, def := range s.Types {
+		if def.Kind == kindScalar {
+			if _, known := scalarSchemas[name]; !known {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
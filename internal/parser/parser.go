@@ -1,10 +1,16 @@
 package parser
 
 import (
+	"api-doc-generator/internal/config"
 	"api-doc-generator/internal/openapi"
+	"context"
 	"errors"
 )
 
+// ErrLimitExceeded is returned when a project exceeds the file limits of its
+// parser config (parser.max_files)
+var ErrLimitExceeded = errors.New("analysis limit exceeded")
+
 // Parser interface - implement this for each language/framework
 type Parser interface {
 	// Analyze the code and return OpenAPI spec
@@ -17,6 +23,62 @@ type Parser interface {
 	Language() string
 }
 
+// Configurable is implemented by parsers that accept project-level settings
+type Configurable interface {
+	// WithConfig returns a copy of the parser using the given settings
+	WithConfig(cfg config.ParserConfig) Parser
+}
+
+// Cacheable is implemented by parsers that can reuse the results of earlier
+// analyses of the same project for files that did not change
+type Cacheable interface {
+	// WithCache returns a copy of the parser keeping its cache in dir
+	WithCache(dir string) Parser
+}
+
+// Cancellable is implemented by parsers that stop an analysis when its
+// context is done, e.g. at the job's timeout
+type Cancellable interface {
+	// WithContext returns a copy of the parser whose analyses return the
+	// context's error once ctx is done
+	WithContext(ctx context.Context) Parser
+}
+
+// AnalyzeContext runs analyze with p bound to ctx. When ctx is done first,
+// the context's error is returned right away: Cancellable parsers stop soon
+// after, others are left to finish in the background.
+func AnalyzeContext(ctx context.Context, p Parser, analyze func(Parser) (*openapi.Spec, error)) (*openapi.Spec, error) {
+	if cancellable, ok := p.(Cancellable); ok {
+		p = cancellable.WithContext(ctx)
+	}
+	type result struct {
+		spec *openapi.Spec
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		spec, err := analyze(p)
+		done <- result{spec, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return r.spec, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PrefixAnalyzer is implemented by parsers that can regenerate the paths
+// under one prefix without analyzing the whole project
+type PrefixAnalyzer interface {
+	// AnalyzePrefix returns a spec holding only the paths under prefix
+	AnalyzePrefix(projectPath, prefix string) (*openapi.Spec, error)
+}
+
 // Registry manages available parsers
 type Registry struct {
 	parsers map[string]Parser
@@ -40,6 +102,19 @@ func (r *Registry) Get(name string) (Parser, error) {
 	return parser, nil
 }
 
+// GetWithConfig returns the named parser configured for a specific project.
+// Parsers that are not Configurable are returned as registered.
+func (r *Registry) GetWithConfig(name string, cfg config.ParserConfig) (Parser, error) {
+	parser, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if configurable, ok := parser.(Configurable); ok {
+		return configurable.WithConfig(cfg), nil
+	}
+	return parser, nil
+}
+
 func (r *Registry) List() []string {
 	var names []string
 	for name := range r.parsers {
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"api-doc-generator/internal/sigv4"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// awsResolver reads awssm://<secret id>#<field> from AWS Secrets Manager. The
+// secret id is a name or an ARN. Credentials come from AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, the region from the ARN or
+// AWS_REGION / AWS_DEFAULT_REGION. AWS_ENDPOINT_URL_SECRETS_MANAGER points
+// at a compatible endpoint such as LocalStack.
+type awsResolver struct {
+	client *http.Client
+}
+
+func newAWSResolver() *awsResolver {
+	return &awsResolver{client: &http.Client{}}
+}
+
+func (a *awsResolver) Resolve(ctx context.Context, ref Reference) (string, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("awssm references need AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	region := awsRegion(ref.Path)
+	if region == "" {
+		return "", fmt.Errorf("awssm references need AWS_REGION")
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER")
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	payload, _ := json.Marshal(map[string]string{"SecretId": ref.Path})
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	creds := sigv4.Credentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}
+	sigv4.Sign(req, payload, creds, region, "secretsmanager")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secrets manager returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	value := secret.SecretString
+	if value == "" && secret.SecretBinary != "" {
+		decoded, err := base64.StdEncoding.DecodeString(secret.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("invalid secrets manager response: %w", err)
+		}
+		value = string(decoded)
+	}
+	return selectField(value, ref.Field)
+}
+
+// awsRegion takes the region from an ARN (arn:aws:secretsmanager:REGION:...),
+// otherwise from the environment
+func awsRegion(secretID string) string {
+	if parts := strings.Split(secretID, ":"); len(parts) > 3 && parts[0] == "arn" && parts[3] != "" {
+		return parts[3]
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
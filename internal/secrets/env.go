@@ -0,0 +1,17 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// resolveEnv reads env://NAME from the environment. Unlike ${NAME} in YAML
+// configs, the reference has no default: an unset variable is an error.
+func resolveEnv(ctx context.Context, ref Reference) (string, error) {
+	value := os.Getenv(ref.Path)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", ref.Path)
+	}
+	return selectField(value, ref.Field)
+}
@@ -0,0 +1,163 @@
+// Package secrets resolves secret references such as vault://secret/data/apidoc#apifox_token
+// or env://APIFOX_TOKEN, so config files can point at a secret store instead of
+// holding tokens in plaintext.
+//
+// A reference is scheme://path[#field]. The path is interpreted by the
+// resolver registered for the scheme; the optional field selects a key when the
+// secret is a JSON object (an AWS secret, a Vault KV entry).
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// resolveTimeout bounds a single lookup, so an unreachable secret store fails
+// the config load instead of hanging it
+const resolveTimeout = 10 * time.Second
+
+// Reference is a parsed secret reference
+type Reference struct {
+	Scheme string
+	Path   string
+	Field  string // empty when the whole secret is used
+}
+
+func (r Reference) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// Resolver fetches the secret a reference points to
+type Resolver interface {
+	Resolve(ctx context.Context, ref Reference) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface
+type ResolverFunc func(ctx context.Context, ref Reference) (string, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context, ref Reference) (string, error) {
+	return f(ctx, ref)
+}
+
+// Registry maps reference schemes to their resolvers
+type Registry struct {
+	resolvers map[string]Resolver
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		resolvers: make(map[string]Resolver),
+	}
+}
+
+// DefaultRegistry returns a registry with the built-in stores registered:
+// env:// (environment variables), vault:// (HashiCorp Vault) and awssm://
+// (AWS Secrets Manager)
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("env", ResolverFunc(resolveEnv))
+	r.Register("vault", newVaultResolver())
+	r.Register("awssm", newAWSResolver())
+	return r
+}
+
+func (r *Registry) Register(scheme string, resolver Resolver) {
+	r.resolvers[scheme] = resolver
+}
+
+// Schemes returns the registered schemes in sorted order
+func (r *Registry) Schemes() []string {
+	schemes := make([]string, 0, len(r.resolvers))
+	for scheme := range r.resolvers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Parse splits value into a reference when it starts with a registered scheme
+func (r *Registry) Parse(value string) (Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return Reference{}, false
+	}
+	if _, registered := r.resolvers[scheme]; !registered {
+		return Reference{}, false
+	}
+	ref := Reference{Scheme: scheme, Path: rest}
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Path, ref.Field = rest[:i], rest[i+1:]
+	}
+	return ref, true
+}
+
+// IsReference reports whether value is a reference to a registered store
+func (r *Registry) IsReference(value string) bool {
+	_, ok := r.Parse(value)
+	return ok
+}
+
+// Resolve returns the secret value references, or value itself when it is not
+// a reference
+func (r *Registry) Resolve(value string) (string, error) {
+	ref, ok := r.Parse(value)
+	if !ok {
+		return value, nil
+	}
+	if ref.Path == "" {
+		return "", fmt.Errorf("%s: empty secret path", ref)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	secret, err := r.resolvers[ref.Scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// selectField returns the field of a JSON object secret, or the secret itself
+// when no field is requested
+func selectField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &object); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select field %q", field)
+	}
+	return fieldValue(object, field)
+}
+
+// fieldValue returns object[field] as a string; without a field, an object with
+// a single key yields that key's value
+func fieldValue(object map[string]interface{}, field string) (string, error) {
+	if field == "" {
+		if len(object) != 1 {
+			return "", fmt.Errorf("secret has %d keys, select one with #field", len(object))
+		}
+		for key := range object {
+			field = key
+		}
+	}
+	value, ok := object[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultResolver reads vault://<api path>#<field> from HashiCorp Vault with the
+// standard VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE variables. The path is
+// the API path below /v1/, e.g. secret/data/apidoc for the KV v2 entry apidoc
+// of the secret mount; KV v1 and v2 responses are both understood.
+type vaultResolver struct {
+	client *http.Client
+}
+
+func newVaultResolver() *vaultResolver {
+	return &vaultResolver{client: &http.Client{}}
+}
+
+func (v *vaultResolver) Resolve(ctx context.Context, ref Reference) (string, error) {
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("vault references need VAULT_ADDR and VAULT_TOKEN")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", addr+"/v1/"+strings.TrimLeft(ref.Path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := secret.Data
+	// KV v2 nests the entry under data.data next to data.metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = inner
+		}
+	}
+	return fieldValue(data, ref.Field)
+}
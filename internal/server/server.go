@@ -0,0 +1,279 @@
+// Package server wires the HTTP service: webhook endpoints, the manual trigger
+// and project config APIs, and graceful shutdown. It is started by `apidoc serve`.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/health"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/parser"
+	ginparser "api-doc-generator/internal/parser/gin"
+	graphqlparser "api-doc-generator/internal/parser/graphql"
+	"api-doc-generator/internal/storage"
+	"api-doc-generator/internal/sync"
+	"api-doc-generator/internal/version"
+	"api-doc-generator/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Local readiness probes give up after healthProbeTimeout; probes of
+// external services set their own timeouts. Their report is reused
+// for healthReportTTL, since Kubernetes polls every few seconds.
+const (
+	healthProbeTimeout = 3 * time.Second
+	healthReportTTL    = 5 * time.Second
+)
+
+// Run starts the service and blocks until SIGINT or SIGTERM, then shuts the
+// HTTP server down gracefully.
+func Run(cfg *config.Config) error {
+	// Update checks against the release feed (optional)
+	updates := version.NewChecker(cfg.Server.UpdateFeedURL, time.Hour)
+	log.Printf("Version %s", version.Get())
+
+	// Initialize parser registry
+	parserRegistry := parser.NewRegistry()
+	parserRegistry.Register("go-gin", ginparser.NewGinParserWithConfig(cfg.Parser))
+	parserRegistry.Register("graphql", graphqlparser.NewGraphQLParserWithConfig(cfg.Parser))
+	// Future parsers can be registered here:
+	// parserRegistry.Register("node-express", express.NewExpressParser())
+	// parserRegistry.Register("python-fastapi", fastapi.NewFastAPIParser())
+
+	log.Printf("Registered parsers: %v", parserRegistry.List())
+
+	// Initialize sync target registry (apifox, postman, swaggerhub)
+	targetRegistry := sync.DefaultRegistry()
+	// Additional destinations can be registered here:
+	// targetRegistry.Register("confluence", confluence.NewTarget)
+
+	log.Printf("Registered sync targets: %v", targetRegistry.List())
+
+	// Documents, request logs and other artifacts are kept per project below the data directory
+	sync.ArtifactsDir = cfg.ArtifactsDir()
+	log.Printf("Data directory: %s", cfg.DataDir)
+
+	// Setup HTTP server
+	r := gin.New()
+	// X-Forwarded-For is only believed from the configured proxies, so
+	// clients can't pick the IP the rate limits count them under
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	r.Use(gin.Logger(), gin.CustomRecovery(api.Recovery), api.RequestID())
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(api.NotFound)
+	r.NoMethod(api.MethodNotAllowed)
+
+	// Object storage for published documents (optional)
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("invalid storage config: %w", err)
+	}
+	if store != nil {
+		log.Printf("Documents are uploaded to %s storage", cfg.Storage.Provider)
+	}
+
+	// Run history database (optional)
+	runs, err := history.New(cfg.History)
+	if err != nil {
+		return fmt.Errorf("invalid history config: %w", err)
+	}
+	if runs != nil {
+		defer runs.Close()
+		log.Printf("Analysis runs are recorded in %s", cfg.History.Driver)
+	}
+
+	// Webhook endpoints
+	webhookHandler := webhook.NewHandler(cfg, parserRegistry, targetRegistry, store, runs)
+	// Webhook storms and oversized payloads are rejected before any work is done
+	hooks := r.Group("/webhook", api.RateLimit(api.NewRateLimiter(cfg.Webhook.RateLimit)), api.BodyLimit(cfg.Webhook.MaxBodyBytes))
+	hooks.POST("/github", webhookHandler.HandleGitHub)
+	hooks.POST("/gitlab", webhookHandler.HandleGitLab)
+	hooks.POST("/gitee", webhookHandler.HandleGitee)
+	hooks.POST("/bitbucket", webhookHandler.HandleBitbucket)
+	hooks.POST("/azure", webhookHandler.HandleAzureDevOps)
+	hooks.POST("/configs", webhookHandler.HandleConfigRepo)
+
+	// Load project definitions (pulled from the configs repository if configured)
+	if err := webhookHandler.SyncProjectConfigs(); err != nil {
+		log.Printf("⚠️  Project configs not loaded: %v", err)
+	}
+	// Added, edited and removed config files take effect without a restart
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	if err := webhookHandler.WatchProjectConfigs(stopWatch); err != nil {
+		log.Printf("⚠️  Project configs are not reloaded on change: %v", err)
+	}
+	// Jobs left over by the last shutdown run again
+	if err := webhookHandler.ResumeJobs(); err != nil {
+		log.Printf("⚠️  Saved jobs not resumed: %v", err)
+	}
+
+	// With tenants configured, the API, the mock server and /docs need a key and
+	// each team only sees its own projects
+	if len(cfg.Tenants) > 0 {
+		log.Printf("🔐 API keys required for %d team(s)", len(cfg.Tenants))
+	}
+	authenticate := api.Authenticate(tenantKeys(cfg.Tenants))
+
+	v1 := r.Group("/api/v1", authenticate)
+
+	// Manual trigger API
+	v1.POST("/analyze", webhookHandler.ManualTrigger)
+	v1.POST("/analyze/sync", webhookHandler.AnalyzeSync)
+
+	// Project config management
+	v1.GET("/projects", webhookHandler.ListProjectConfigs)
+	v1.POST("/projects", webhookHandler.CreateProjectConfig)
+	v1.GET("/projects/:name", webhookHandler.GetProjectConfig)
+	v1.PUT("/projects/:name", webhookHandler.UpdateProjectConfig)
+	v1.DELETE("/projects/:name", webhookHandler.DeleteProjectConfig)
+	v1.POST("/projects/:name/sync", webhookHandler.SyncProject)
+
+	// Apifox 请求/响应日志查询
+	v1.GET("/projects/:name/sync-logs", webhookHandler.ListSyncLogs)
+	v1.GET("/projects/:name/history", webhookHandler.ListHistory)
+
+	// Latest synced document of a project at a stable URL
+	v1.GET("/projects/:name/openapi.json", api.Gzip(), webhookHandler.GetProjectSpec("json"))
+	v1.GET("/projects/:name/openapi.yaml", api.Gzip(), webhookHandler.GetProjectSpec("yaml"))
+
+	// Files a project has produced: documents, logs, HTML pages and exports
+	v1.GET("/projects/:name/artifacts", webhookHandler.ListArtifacts)
+	v1.GET("/projects/:name/artifacts/*path", webhookHandler.DownloadArtifact)
+
+	// Swagger UI "Try it out" proxy to html.try_it.upstream
+	v1.Any("/projects/:name/try-it/*path", webhookHandler.TryIt)
+
+	// Error codes returned in the error envelope
+	r.GET("/api/v1/errors", func(c *gin.Context) {
+		c.JSON(200, gin.H{"codes": api.Codes()})
+	})
+
+	// 按路径前缀局部刷新文档
+	v1.POST("/projects/:name/analyze", webhookHandler.AnalyzePrefix)
+
+	// 契约测试：按文档请求被测环境的接口
+	v1.POST("/projects/:name/verify", webhookHandler.VerifyProject)
+
+	// Mock API 服务：按项目最近一次同步的文档返回示例响应
+	r.Any("/mock/:name/*path", api.AllowCORS(), authenticate, webhookHandler.ServeMock)
+
+	// JSON Schema of project config files, for editor completion and CI validation
+	r.GET(config.ProjectConfigSchemaID, func(c *gin.Context) {
+		c.JSON(200, config.ProjectConfigSchema())
+	})
+
+	// Health check
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status":  "healthy",
+			"version": version.Version,
+			"parsers": parserRegistry.List(),
+		})
+	})
+
+	// Liveness: the process serves requests. Dependencies are left to the
+	// readiness probe, so an outage does not restart every instance.
+	r.GET("/health/live", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": health.StatusOK})
+	})
+
+	// Readiness: probes the dependencies and answers 503 when a critical one fails
+	checker := health.NewChecker(healthProbeTimeout, healthReportTTL, webhookHandler.HealthProbes()...)
+	r.GET("/health/ready", func(c *gin.Context) {
+		report := checker.Check(c.Request.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	})
+
+	// Info endpoint
+	r.GET("/api/v1/info", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"service":           "API Documentation Generator",
+			"version":           version.Version,
+			"supported_parsers": parserRegistry.List(),
+		})
+	})
+
+	// Build information, plus the latest release when UPDATE_FEED_URL is set
+	r.GET("/api/v1/version", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"build":  version.Get(),
+			"update": updates.Check(),
+		})
+	})
+
+	// 静态文件服务 - 让产物目录可以通过 /docs 被外部访问，支持 ETag/If-Modified-Since 条件请求和 gzip 压缩。
+	// 配置了团队时需要 API 密钥，团队只能访问 teams/{team}/ 下的产物
+	docs := r.Group("/docs", authenticate, webhookHandler.AuthorizeDocs, api.StaticETag("/docs", sync.ArtifactsDir), api.Gzip())
+	docs.Static("/", sync.ArtifactsDir)
+
+	// Graceful shutdown
+	srv := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: r,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("🚀 API Doc Generator Service started on port %s", cfg.Server.Port)
+		log.Printf("📡 Webhook endpoint: http://localhost:%s/webhook/github", cfg.Server.Port)
+		log.Printf("🔧 Manual trigger: http://localhost:%s/api/v1/analyze", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server error: %w", err)
+	case <-quit:
+	}
+
+	log.Println("🛑 Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting requests first, so no new jobs are queued while the
+	// running ones are drained
+	shutdownErr := srv.Shutdown(ctx)
+	log.Printf("⏳ Waiting up to %s for running jobs", cfg.Server.ShutdownTimeout)
+	if err := webhookHandler.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+	if shutdownErr != nil {
+		return fmt.Errorf("server forced to shutdown: %w", shutdownErr)
+	}
+
+	log.Println("✅ Server exited gracefully")
+	return nil
+}
+
+// tenantKeys maps every configured API key to its team
+func tenantKeys(tenants []config.TenantConfig) map[string]*api.Tenant {
+	keys := make(map[string]*api.Tenant)
+	for _, tenant := range tenants {
+		t := &api.Tenant{Name: tenant.Name, Admin: tenant.Admin}
+		for _, key := range tenant.APIKeys {
+			keys[key] = t
+		}
+	}
+	return keys
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage writes documents to a directory served by a web server or
+// CDN, e.g. a volume shared by all instances and exposed by nginx
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+	Prefix    string
+}
+
+func (s *LocalStorage) Put(key string, data []byte, contentType string) (string, error) {
+	key = objectKey(s.Prefix, key)
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return publicURL(s.PublicURL, key), nil
+}
+
+func (s *LocalStorage) Ping(ctx context.Context) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.CreateTemp(s.Dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", s.Dir, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
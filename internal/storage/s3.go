@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/sigv4"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Storage uploads documents with the S3 PutObject API, signed with AWS
+// Signature Version 4. Aliyun OSS accepts the same requests through its
+// S3-compatible endpoints.
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string // signing region
+	bucket    string
+	creds     sigv4.Credentials
+	pathStyle bool
+	prefix    string
+	publicURL string
+	client    *http.Client
+}
+
+func newS3Storage(cfg config.StorageConfig) (*S3Storage, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s storage requires a bucket and access keys", cfg.Provider)
+	}
+
+	region, endpoint := cfg.Region, cfg.Endpoint
+	switch cfg.Provider {
+	case config.StorageOSS:
+		// OSS signs S3-compatible requests with the oss- prefixed region
+		if region == "" {
+			return nil, fmt.Errorf("oss storage requires STORAGE_REGION, e.g. cn-hangzhou")
+		}
+		if !strings.HasPrefix(region, "oss-") {
+			region = "oss-" + region
+		}
+		if endpoint == "" {
+			endpoint = "https://" + region + ".aliyuncs.com"
+		}
+	default:
+		if region == "" {
+			region = "us-east-1"
+		}
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid storage endpoint: %s", endpoint)
+	}
+
+	s := &S3Storage{
+		endpoint:  parsed,
+		region:    region,
+		bucket:    cfg.Bucket,
+		creds:     sigv4.Credentials{AccessKeyID: cfg.AccessKeyID, SecretAccessKey: cfg.SecretAccessKey, SessionToken: cfg.SessionToken},
+		pathStyle: cfg.PathStyle,
+		prefix:    cfg.Prefix,
+		publicURL: cfg.PublicURL,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if s.publicURL == "" {
+		s.publicURL = s.bucketURL()
+	}
+	return s, nil
+}
+
+// bucketURL returns https://bucket.host or, in path style, https://host/bucket
+func (s *S3Storage) bucketURL() string {
+	if s.pathStyle {
+		return s.endpoint.Scheme + "://" + s.endpoint.Host + "/" + s.bucket
+	}
+	return s.endpoint.Scheme + "://" + s.bucket + "." + s.endpoint.Host
+}
+
+func (s *S3Storage) Put(key string, data []byte, contentType string) (string, error) {
+	key = objectKey(s.prefix, key)
+	objectURL := s.bucketURL() + "/" + escapeKey(key)
+
+	req, err := http.NewRequest("PUT", objectURL, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, data)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload failed (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+	return publicURL(s.publicURL, escapeKey(key)), nil
+}
+
+// Ping checks that the bucket exists and the credentials can access it, with
+// a HeadBucket request
+func (s *S3Storage) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", s.bucketURL()+"/", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bucket %s unreachable: %w", s.bucket, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("bucket %s: HTTP %d", s.bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 headers to req
+func (s *S3Storage) sign(req *http.Request, payload []byte) {
+	req.Header.Set("X-Amz-Content-Sha256", sigv4.PayloadHash(payload))
+	sigv4.Sign(req, payload, s.creds, s.region, "s3")
+}
+
+// escapeKey URI-encodes an object key as SigV4 requires: everything except
+// unreserved characters and the slashes between segments
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+// Package storage uploads generated documents to a location every service
+// instance can serve from: a shared directory or an S3-compatible bucket
+// (AWS S3, Aliyun OSS, MinIO).
+package storage
+
+import (
+	"api-doc-generator/internal/config"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Storage stores a document under key and returns the URL it is served at
+type Storage interface {
+	Put(key string, data []byte, contentType string) (string, error)
+	// Ping checks that documents can be stored, for the readiness probe
+	Ping(ctx context.Context) error
+}
+
+// New builds the backend configured in cfg, or returns nil when storage is
+// disabled; callers then keep serving documents from the local docs directory
+func New(cfg config.StorageConfig) (Storage, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case config.StorageLocal:
+		if cfg.LocalDir == "" || cfg.PublicURL == "" {
+			return nil, fmt.Errorf("local storage requires STORAGE_LOCAL_DIR and STORAGE_PUBLIC_URL")
+		}
+		return &LocalStorage{Dir: cfg.LocalDir, PublicURL: cfg.PublicURL, Prefix: cfg.Prefix}, nil
+	case "", config.StorageS3, config.StorageOSS:
+		store, err := newS3Storage(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("unsupported storage provider: %s", cfg.Provider)
+}
+
+// objectKey prepends the configured prefix to key
+func objectKey(prefix, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	key = strings.TrimLeft(key, "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// publicURL joins the public base URL and the object key
+func publicURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// BuildAggregateSpec 合并聚合项目所包含项目最近一次同步的文档（见 LoadBaseline，与同步目标无关），
+// 各项目路径加上其 path_prefix，内容相同的 schema 只保留一份，生成平台级网关文档
+func BuildAggregateSpec(manager *config.ProjectConfigManager, aggregate *config.ProjectConfig) (*openapi.Spec, error) {
+	if !aggregate.IsAggregate() {
+		return nil, fmt.Errorf("%s is not an aggregate project", aggregate.ProjectName)
+	}
+
+	specs := make([]*openapi.Spec, 0, len(aggregate.Aggregate.Projects))
+	for _, name := range aggregate.Aggregate.Projects {
+		member, err := manager.LoadProjectConfig(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load member project %s: %w", name, err)
+		}
+		if member.IsAggregate() {
+			return nil, fmt.Errorf("member project %s is itself an aggregate", name)
+		}
+		// Team projects only aggregate documents of their own team; projects
+		// without a team (admin-managed) may combine any
+		if aggregate.Team != "" && member.Team != aggregate.Team {
+			return nil, fmt.Errorf("member project %s belongs to another team", name)
+		}
+
+		spec, path, err := LoadBaseline(&member.Apifox)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if spec == nil {
+			return nil, fmt.Errorf("member project %s has never been synced", name)
+		}
+		log.Printf("[Aggregate] %s: %s (prefix %s)", name, path, member.EffectivePathPrefix())
+
+		spec.PrefixPaths(member.EffectivePathPrefix())
+		specs = append(specs, spec)
+	}
+
+	merged, err := openapi.Merge(specs...)
+	if err != nil {
+		return nil, err
+	}
+	merged.Info.Title = aggregate.ProjectName
+	merged.Info.Description = aggregate.Description
+	if merged.Info.Description == "" {
+		merged.Info.Description = "Aggregated from: " + strings.Join(aggregate.Aggregate.Projects, ", ")
+	}
+	merged.ApplyExtensions(aggregate.Extensions)
+	return merged, nil
+}
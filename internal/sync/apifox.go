@@ -3,6 +3,7 @@ package sync
 import (
 	"api-doc-generator/internal/config"
 	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/storage"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -10,12 +11,17 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type ApifoxSyncer struct {
 	cfg       *config.ApifoxConfig
 	serverCfg *config.ServerConfig
+	store     storage.Storage // 为 nil 时文档只保存在本地产物目录
+	progress
 }
 
 // ApifoxImportRequest 使用URL方式导入的请求结构
@@ -25,8 +31,10 @@ type ApifoxImportRequest struct {
 }
 
 type ApifoxImportOptions struct {
-	EndpointOverwriteBehavior string `json:"endpointOverwriteBehavior"` // OVERWRITE_EXISTING, KEEP_EXISTING
-	SchemaOverwriteBehavior   string `json:"schemaOverwriteBehavior"`   // OVERWRITE_EXISTING, KEEP_EXISTING
+	EndpointOverwriteBehavior string `json:"endpointOverwriteBehavior"`        // OVERWRITE_EXISTING, KEEP_EXISTING
+	SchemaOverwriteBehavior   string `json:"schemaOverwriteBehavior"`          // OVERWRITE_EXISTING, KEEP_EXISTING
+	TargetBranchID            int64  `json:"targetBranchId,omitempty"`         // 导入的分支，为空时导入主分支
+	TargetEndpointFolderID    int64  `json:"targetEndpointFolderId,omitempty"` // 导入的接口目录
 }
 
 func NewApifoxSyncer(cfg *config.ApifoxConfig, serverCfg *config.ServerConfig) *ApifoxSyncer {
@@ -36,24 +44,34 @@ func NewApifoxSyncer(cfg *config.ApifoxConfig, serverCfg *config.ServerConfig) *
 	}
 }
 
+// SetStorage 设置文档上传的存储，URL 方式导入时 Apifox 从存储地址拉取文档
+func (s *ApifoxSyncer) SetStorage(store storage.Storage) {
+	s.store = store
+}
+
 // Sync 同步OpenAPI规范到Apifox
-// 1. 先保存文档到docs目录
+// 1. 先保存文档到产物目录
 // 2. 根据配置决定使用string还是url方式发送给Apifox
-func (s *ApifoxSyncer) Sync(spec *openapi.Spec, commitMsg string) error {
+func (s *ApifoxSyncer) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
 	// 1. 将OpenAPI规范转换为JSON字符串
 	specJSON, err := json.MarshalIndent(spec, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal spec: %w", err)
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
 	}
 
-	// 2. 先保存文档到docs目录（无论哪种方式都要保存）
+	// 2. 先保存文档到产物目录（无论哪种方式都要保存）
 	docPath, docURL, err := s.saveOpenAPIDocToPublic(string(specJSON), commitMsg)
 	if err != nil {
-		return fmt.Errorf("failed to save doc: %w", err)
+		return nil, fmt.Errorf("failed to save doc: %w", err)
 	}
 
-	fmt.Printf("[Apifox Sync] Document saved to: %s\n", docPath)
-	fmt.Printf("[Apifox Sync] Public URL: %s\n", docURL)
+	s.printf("[Apifox Sync] Document saved to: %s\n", docPath)
+	s.printf("[Apifox Sync] Public URL: %s\n", docURL)
+
+	// 分析报告与文档保存在一起，失败不影响同步
+	if err := saveAnalysisReport(spec, docPath); err != nil {
+		s.printf("[Warning] Failed to save analysis report: %v\n", err)
+	}
 
 	// 3. 根据配置决定同步方式
 	apiURL := fmt.Sprintf("%s/v1/projects/%s/import-openapi?locale=zh-CN",
@@ -63,25 +81,19 @@ func (s *ApifoxSyncer) Sync(spec *openapi.Spec, commitMsg string) error {
 
 	if s.cfg.SyncMode == "url" {
 		// URL方式：发送文档的URL给Apifox
-		fmt.Printf("[Apifox Sync] Using URL mode, sending URL to Apifox\n")
+		s.printf("[Apifox Sync] Using URL mode, sending URL to Apifox\n")
 		payload = ApifoxImportRequest{
 			Input: map[string]interface{}{
 				"url": docURL,
 			},
-			Options: ApifoxImportOptions{
-				EndpointOverwriteBehavior: "OVERWRITE_EXISTING",
-				SchemaOverwriteBehavior:   "OVERWRITE_EXISTING",
-			},
+			Options: s.importOptions(),
 		}
 	} else {
 		// String方式：直接发送JSON内容给Apifox
-		fmt.Printf("[Apifox Sync] Using string mode, sending JSON content to Apifox\n")
+		s.printf("[Apifox Sync] Using string mode, sending JSON content to Apifox\n")
 		payload = ApifoxImportRequest{
-			Input: string(specJSON),
-			Options: ApifoxImportOptions{
-				EndpointOverwriteBehavior: "OVERWRITE_EXISTING",
-				SchemaOverwriteBehavior:   "OVERWRITE_EXISTING",
-			},
+			Input:   string(specJSON),
+			Options: s.importOptions(),
 		}
 	}
 
@@ -90,25 +102,25 @@ func (s *ApifoxSyncer) Sync(spec *openapi.Spec, commitMsg string) error {
 
 // SyncByURL 从外部URL同步OpenAPI规范
 // 1. 从URL下载文档内容
-// 2. 保存到docs目录
+// 2. 保存到产物目录
 // 3. 发送给Apifox（根据配置决定用string还是url方式）
-func (s *ApifoxSyncer) SyncByURL(specURL string, commitMsg string) error {
-	fmt.Printf("[Apifox Sync] Downloading OpenAPI spec from: %s\n", specURL)
+func (s *ApifoxSyncer) SyncByURL(specURL string, commitMsg string) (*SyncResult, error) {
+	s.printf("[Apifox Sync] Downloading OpenAPI spec from: %s\n", specURL)
 
 	// 1. 从URL下载文档内容
 	specJSON, err := s.downloadOpenAPIFromURL(specURL)
 	if err != nil {
-		return fmt.Errorf("failed to download from URL: %w", err)
+		return nil, fmt.Errorf("failed to download from URL: %w", err)
 	}
 
-	// 2. 保存文档到docs目录
+	// 2. 保存文档到产物目录
 	docPath, docURL, err := s.saveOpenAPIDocToPublic(specJSON, commitMsg)
 	if err != nil {
-		return fmt.Errorf("failed to save doc: %w", err)
+		return nil, fmt.Errorf("failed to save doc: %w", err)
 	}
 
-	fmt.Printf("[Apifox Sync] Document downloaded and saved to: %s\n", docPath)
-	fmt.Printf("[Apifox Sync] Public URL: %s\n", docURL)
+	s.printf("[Apifox Sync] Document downloaded and saved to: %s\n", docPath)
+	s.printf("[Apifox Sync] Public URL: %s\n", docURL)
 
 	// 3. 根据配置决定同步方式
 	apiURL := fmt.Sprintf("%s/v1/projects/%s/import-openapi?locale=zh-CN",
@@ -118,46 +130,100 @@ func (s *ApifoxSyncer) SyncByURL(specURL string, commitMsg string) error {
 
 	if s.cfg.SyncMode == "url" {
 		// URL方式：发送我们保存的文档URL
-		fmt.Printf("[Apifox Sync] Using URL mode, sending our URL to Apifox\n")
+		s.printf("[Apifox Sync] Using URL mode, sending our URL to Apifox\n")
 		payload = ApifoxImportRequest{
 			Input: map[string]interface{}{
 				"url": docURL,
 			},
-			Options: ApifoxImportOptions{
-				EndpointOverwriteBehavior: "OVERWRITE_EXISTING",
-				SchemaOverwriteBehavior:   "OVERWRITE_EXISTING",
-			},
+			Options: s.importOptions(),
 		}
 	} else {
 		// String方式：直接发送下载的JSON内容
-		fmt.Printf("[Apifox Sync] Using string mode, sending JSON content to Apifox\n")
+		s.printf("[Apifox Sync] Using string mode, sending JSON content to Apifox\n")
 		payload = ApifoxImportRequest{
-			Input: specJSON,
-			Options: ApifoxImportOptions{
-				EndpointOverwriteBehavior: "OVERWRITE_EXISTING",
-				SchemaOverwriteBehavior:   "OVERWRITE_EXISTING",
-			},
+			Input:   specJSON,
+			Options: s.importOptions(),
 		}
 	}
 
 	return s.sendImportRequest(apiURL, payload, commitMsg)
 }
 
-// sendImportRequest 发送导入请求到Apifox
-func (s *ApifoxSyncer) sendImportRequest(url string, payload ApifoxImportRequest, commitMsg string) error {
+// importOptions 导入选项：覆盖已有接口和数据结构，按配置导入到指定分支和目录
+func (s *ApifoxSyncer) importOptions() ApifoxImportOptions {
+	options := ApifoxImportOptions{
+		EndpointOverwriteBehavior: "OVERWRITE_EXISTING",
+		SchemaOverwriteBehavior:   "OVERWRITE_EXISTING",
+	}
+	// 配置加载时已校验为数字
+	options.TargetBranchID, _ = strconv.ParseInt(s.cfg.Branch, 10, 64)
+	options.TargetEndpointFolderID, _ = strconv.ParseInt(s.cfg.APIFolder, 10, 64)
+	if s.cfg.Branch != "" {
+		s.printf("[Apifox Sync] Importing into branch %s\n", s.cfg.Branch)
+	}
+	return options
+}
+
+// DocKey 返回文档在项目产物目录 apifox/ 下的子目录，不同 Apifox 分支的文档分开保存，
+// 变更对比和回滚只与同一分支的上一版比较
+func DocKey(cfg *config.ApifoxConfig) string {
+	if cfg.Branch == "" {
+		return cfg.ProjectID
+	}
+	return cfg.ProjectID + "/branches/" + cfg.Branch
+}
+
+// ArtifactsDir 产物根目录（数据目录下的 artifacts），由服务和命令行按服务配置设置，
+// 通过 /docs 对外提供访问
+var ArtifactsDir = filepath.Join(".temp", "artifacts")
+
+// ProjectDir 返回项目的产物目录：{ArtifactsDir}/projects/{project}，团队项目为
+// {ArtifactsDir}/teams/{team}/projects/{project}。没有项目配置（使用全局 Apifox 配置）时为 ArtifactsDir
+func ProjectDir(team, project string) string {
+	if project == "" {
+		return ArtifactsDir
+	}
+	if team == "" {
+		return filepath.Join(ArtifactsDir, "projects", project)
+	}
+	return filepath.Join(ArtifactsDir, "teams", team, "projects", project)
+}
+
+// DocDir 返回 Apifox 项目（分支）的文档和日志目录：{ProjectDir}/apifox/{DocKey}
+func DocDir(cfg *config.ApifoxConfig) string {
+	return filepath.Join(ProjectDir(cfg.Team, cfg.Project), "apifox", DocKey(cfg))
+}
+
+// PublicPath 返回产物目录下的文件或目录对外的访问路径：/docs/{相对 ArtifactsDir 的路径}
+func PublicPath(file string) string {
+	return "/docs/" + artifactKey(file)
+}
+
+// artifactKey 返回 path 相对 ArtifactsDir 的路径（/ 分隔），同时用作对象存储的键
+func artifactKey(file string) string {
+	rel, err := filepath.Rel(ArtifactsDir, file)
+	if err != nil {
+		rel = file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sendImportRequest 发送导入请求到Apifox，返回解析后的导入结果
+func (s *ApifoxSyncer) sendImportRequest(url string, payload ApifoxImportRequest, commitMsg string) (*SyncResult, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	// 保存请求日志
+	// 保存请求日志，请求结束后按保留策略清理历史文件
 	s.saveRequestLog(body, commitMsg)
+	defer s.pruneHistory()
 
-	fmt.Printf("[Apifox Sync] Sending import request to Apifox...\n")
+	s.printf("[Apifox Sync] Sending import request to Apifox...\n")
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
@@ -169,28 +235,74 @@ func (s *ApifoxSyncer) sendImportRequest(url string, payload ApifoxImportRequest
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("apifox API error (HTTP %d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("apifox API error (HTTP %d): %s", resp.StatusCode, string(respBody))
 	}
 
-	fmt.Printf("[Apifox Sync] ✅ Sync successful! Response: %s\n", string(respBody))
-
 	// 保存响应日志
 	s.saveResponseLog(respBody, commitMsg)
 
+	result, err := parseApifoxImportResult(respBody)
+	if err != nil {
+		return nil, err
+	}
+	if result.HasFailures() {
+		s.printf("[Apifox Sync] ⚠️  Imported with failures: %s\n", result)
+	} else {
+		s.printf("[Apifox Sync] ✅ Sync successful! %s\n", result)
+	}
+	return result, nil
+}
+
+// CheckApifoxToken 校验令牌能否访问配置的 Apifox 项目。使用只读的导出接口，
+// 不会修改项目中的接口
+func CheckApifoxToken(cfg *config.ApifoxConfig) error {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.apifox.com"
+	}
+	url := fmt.Sprintf("%s/v1/projects/%s/export-openapi?locale=zh-CN", strings.TrimSuffix(baseURL, "/"), cfg.ProjectID)
+	body := []byte(`{"scope":{"type":"ALL"},"oasVersion":"3.0","exportFormat":"JSON"}`)
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Apifox-Api-Version", "2024-03-28")
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("invalid token (HTTP %d): %s", resp.StatusCode, string(respBody))
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("token has no access to project %s (HTTP %d): %s", cfg.ProjectID, resp.StatusCode, string(respBody))
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("apifox API error (HTTP %d): %s", resp.StatusCode, string(respBody))
+	}
 	return nil
 }
 
-// saveRequestLog 保存请求日志到docs目录，按项目ID和时间命名
+// saveRequestLog 保存请求日志到产物目录，按项目ID和时间命名
 func (s *ApifoxSyncer) saveRequestLog(body []byte, commitMsg string) {
-	// 按项目ID创建目录: docs/apifox/{projectID}/
-	logDir := filepath.Join("docs", "apifox", s.cfg.ProjectID)
+	// 按项目ID创建目录: {ProjectDir}/apifox/{projectID}/（导入分支时为 {projectID}/branches/{branch}/）
+	logDir := DocDir(s.cfg)
 	os.MkdirAll(logDir, 0755)
 
 	// 文件名格式: {projectID}_{timestamp}_request.json
@@ -206,16 +318,16 @@ func (s *ApifoxSyncer) saveRequestLog(body []byte, commitMsg string) {
 
 	logJSON, _ := json.MarshalIndent(logData, "", "  ")
 	if err := os.WriteFile(filename, logJSON, 0644); err != nil {
-		fmt.Printf("[Warning] Failed to save request log: %v\n", err)
+		s.printf("[Warning] Failed to save request log: %v\n", err)
 	} else {
-		fmt.Printf("[Apifox Sync] Request saved to: %s\n", filename)
+		s.printf("[Apifox Sync] Request saved to: %s\n", filename)
 	}
 }
 
-// saveResponseLog 保存响应日志到docs目录，按项目ID和时间命名
+// saveResponseLog 保存响应日志到产物目录，按项目ID和时间命名
 func (s *ApifoxSyncer) saveResponseLog(body []byte, commitMsg string) {
-	// 按项目ID创建目录: docs/apifox/{projectID}/
-	logDir := filepath.Join("docs", "apifox", s.cfg.ProjectID)
+	// 按项目ID创建目录: {ProjectDir}/apifox/{projectID}/（导入分支时为 {projectID}/branches/{branch}/）
+	logDir := DocDir(s.cfg)
 	os.MkdirAll(logDir, 0755)
 
 	// 文件名格式: {projectID}_{timestamp}_response.json
@@ -231,16 +343,16 @@ func (s *ApifoxSyncer) saveResponseLog(body []byte, commitMsg string) {
 
 	logJSON, _ := json.MarshalIndent(logData, "", "  ")
 	if err := os.WriteFile(filename, logJSON, 0644); err != nil {
-		fmt.Printf("[Warning] Failed to save response log: %v\n", err)
+		s.printf("[Warning] Failed to save response log: %v\n", err)
 	} else {
-		fmt.Printf("[Apifox Sync] Response saved to: %s\n", filename)
+		s.printf("[Apifox Sync] Response saved to: %s\n", filename)
 	}
 }
 
 // saveOpenAPIDocToPublic 保存OpenAPI文档到公开可访问的目录，并返回文件路径和URL
 func (s *ApifoxSyncer) saveOpenAPIDocToPublic(specJSON string, commitMsg string) (string, string, error) {
-	// 按项目ID创建目录: docs/apifox/{projectID}/
-	docDir := filepath.Join("docs", "apifox", s.cfg.ProjectID)
+	// 按项目ID创建目录: {ProjectDir}/apifox/{projectID}/（导入分支时为 {projectID}/branches/{branch}/）
+	docDir := DocDir(s.cfg)
 	if err := os.MkdirAll(docDir, 0755); err != nil {
 		return "", "", fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -250,18 +362,59 @@ func (s *ApifoxSyncer) saveOpenAPIDocToPublic(specJSON string, commitMsg string)
 	filename := fmt.Sprintf("%s_%s_openapi.json", s.cfg.ProjectID, timestamp)
 	filePath := filepath.Join(docDir, filename)
 
-	// 保存文档
+	// 保存文档，同时更新固定文件名的最新副本
 	if err := os.WriteFile(filePath, []byte(specJSON), 0644); err != nil {
 		return "", "", fmt.Errorf("failed to write file: %w", err)
 	}
+	if err := os.WriteFile(filepath.Join(docDir, LatestDocName), []byte(specJSON), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write latest doc: %w", err)
+	}
 
-	// 生成公网可访问的URL
-	// 格式: http://your-server.com/docs/apifox/{projectID}/{filename}
-	docURL := fmt.Sprintf("%s/docs/apifox/%s/%s", s.serverCfg.PublicURL, s.cfg.ProjectID, filename)
+	// 配置了存储时上传本次的文档并使用存储地址。使用带时间戳的对象而不是最新副本，
+	// 避免 CDN 缓存导致 Apifox 拉到旧文档
+	if s.store != nil {
+		key := artifactKey(filepath.Join(DocDir(s.cfg), filename))
+		docURL, err := s.store.Put(key, []byte(specJSON), "application/json")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to upload doc: %w", err)
+		}
+		return filePath, docURL, nil
+	}
+
+	// 生成公网可访问的URL，指向最新副本，历史文件被清理后地址依然有效
+	// 格式: http://your-server.com/docs/projects/{project}/apifox/{projectID}/latest_openapi.json
+	docURL := s.serverCfg.PublicURL + PublicPath(filepath.Join(DocDir(s.cfg), LatestDocName))
 
 	return filePath, docURL, nil
 }
 
+// saveAnalysisReport 将本次生成的分析报告保存在文档旁：{projectID}_{timestamp}_analysis.json，
+// 同时更新 latest_analysis.json
+func saveAnalysisReport(spec *openapi.Spec, docPath string) error {
+	data, err := json.MarshalIndent(spec.AnalysisReport(), "", "  ")
+	if err != nil {
+		return err
+	}
+	reportPath := strings.TrimSuffix(docPath, "_openapi.json") + "_analysis.json"
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(filepath.Dir(docPath), LatestAnalysisName), data, 0644)
+}
+
+// pruneHistory 按 Retention 配置清理本项目（分支）目录下的历史同步文件
+func (s *ApifoxSyncer) pruneHistory() {
+	docDir := DocDir(s.cfg)
+	removed, err := PruneHistory(docDir, s.cfg.Retention)
+	if err != nil {
+		s.printf("[Warning] Failed to prune sync history: %v\n", err)
+		return
+	}
+	if removed > 0 {
+		s.printf("[Apifox Sync] Pruned %d history file(s) from %s\n", removed, docDir)
+	}
+}
+
 // downloadOpenAPIFromURL 从URL下载OpenAPI文档
 func (s *ApifoxSyncer) downloadOpenAPIFromURL(url string) (string, error) {
 	client := &http.Client{
@@ -285,3 +438,39 @@ func (s *ApifoxSyncer) downloadOpenAPIFromURL(url string) (string, error) {
 
 	return string(body), nil
 }
+
+// LoadLastSyncedSpec 读取 Apifox 项目（分支）文档目录（见 DocDir）下最近一次保存的 OpenAPI 文档，
+// 用于与本次生成的文档做对比。从未同步过时返回 nil
+func LoadLastSyncedSpec(cfg *config.ApifoxConfig) (*openapi.Spec, string, error) {
+	docDir := DocDir(cfg)
+	entries, err := os.ReadDir(docDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("failed to read doc directory: %w", err)
+	}
+
+	// 文件名中的时间戳格式 20060102_150405 可以直接按字典序排序
+	var docs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && entry.Name() != LatestDocName && strings.HasSuffix(entry.Name(), "_openapi.json") {
+			docs = append(docs, entry.Name())
+		}
+	}
+	if len(docs) == 0 {
+		return nil, "", nil
+	}
+	sort.Strings(docs)
+	latest := filepath.Join(docDir, docs[len(docs)-1])
+
+	data, err := os.ReadFile(latest)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read last synced spec: %w", err)
+	}
+	var spec openapi.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, "", fmt.Errorf("failed to parse last synced spec %s: %w", latest, err)
+	}
+	return &spec, latest, nil
+}
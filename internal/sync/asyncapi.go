@@ -0,0 +1,46 @@
+package sync
+
+import (
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AsyncAPIPublisher 将服务生产、消费的消息通道生成 AsyncAPI 文档，
+// 写到项目产物目录（见 ProjectDir）的 asyncapi/asyncapi.json，由服务的 /docs 静态路由对外提供
+type AsyncAPIPublisher struct {
+	team    string
+	project string
+	progress
+}
+
+func NewAsyncAPIPublisher(team, project string) *AsyncAPIPublisher {
+	return &AsyncAPIPublisher{team: team, project: project}
+}
+
+// Sync 写入 AsyncAPI 文档。文档中没有消息通道时（如按目录增量分析的同步）保留上一次的文档
+func (p *AsyncAPIPublisher) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	doc := spec.AsyncAPI()
+	if doc == nil {
+		p.printf("[AsyncAPI Publish] ⏭️  No message channels declared, skipped (%s)\n", commitMsg)
+		return nil, nil
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asyncapi document: %w", err)
+	}
+
+	dir := filepath.Join(ProjectDir(p.team, p.project), "asyncapi")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	path := filepath.Join(dir, "asyncapi.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write asyncapi document: %w", err)
+	}
+
+	p.printf("[AsyncAPI Publish] ✅ %d channels saved to: %s (%s)\n", len(doc.Channels), path, commitMsg)
+	return nil, nil
+}
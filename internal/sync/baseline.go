@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BaselineDocName 每次同步成功后保存的文档，下次同步的变更对比和破坏性变更检查以它为基准
+const BaselineDocName = "openapi.json"
+
+// BaselinePath 返回同步基准文档的路径：{ProjectDir}/specs/openapi.json，按 Apifox 分支
+// 区分时为 {ProjectDir}/specs/branches/{branch}/openapi.json。基准与同步目标无关，
+// 只同步到 Postman、Git 等目标的项目同样有变更对比
+func BaselinePath(cfg *config.ApifoxConfig) string {
+	dir := filepath.Join(ProjectDir(cfg.Team, cfg.Project), "specs")
+	if cfg.Branch != "" {
+		dir = filepath.Join(dir, "branches", cfg.Branch)
+	}
+	return filepath.Join(dir, BaselineDocName)
+}
+
+// SaveBaseline 保存本次同步成功的文档，作为下次同步的对比基准
+func SaveBaseline(cfg *config.ApifoxConfig, spec *openapi.Spec) error {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	path := BaselinePath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline spec: %w", err)
+	}
+	return nil
+}
+
+// LoadBaseline 读取上次同步成功的文档和它的路径。还没有基准文档时（如升级前同步的项目）
+// 使用 Apifox 文档目录下最近一次同步的文档，都没有时返回 nil
+func LoadBaseline(cfg *config.ApifoxConfig) (*openapi.Spec, string, error) {
+	path := BaselinePath(cfg)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return LoadLastSyncedSpec(cfg)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read baseline spec: %w", err)
+	}
+	var spec openapi.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, "", fmt.Errorf("failed to parse baseline spec %s: %w", path, err)
+	}
+	return &spec, path, nil
+}
@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/storage"
+	"fmt"
+	"io"
+)
+
+// Gate 预发同步完成后、推送正式项目前执行的检查，返回错误即中止发布
+type Gate struct {
+	Name  string
+	Check func(spec *openapi.Spec) error
+}
+
+// ValidationGate 校验文档结构（引用完整、路径参数已声明等）
+var ValidationGate = Gate{
+	Name: "validation",
+	Check: func(spec *openapi.Spec) error {
+		return spec.Validate()
+	},
+}
+
+// StagingTargets 返回同步前已有检查失败（如破坏性变更）时仍执行的目标：配置了预发项目时只保留 apifox，
+// 两阶段同步推送到预发后在检查项处中止，其余目标不同步。没有可执行的目标时返回 nil
+func StagingTargets(targets []string, project *config.ProjectConfig) []string {
+	if project == nil || project.Staging == nil {
+		return nil
+	}
+	for _, target := range targets {
+		if target == config.TargetApifox {
+			return []string{target}
+		}
+	}
+	return nil
+}
+
+// CanarySyncer 两阶段同步：
+// 1. 先推送到预发（staging）Apifox 项目
+// 2. 依次执行检查（校验、lint、diff 等）
+// 3. 全部通过后推送到正式项目；正式项目导入出错或有失败的接口时重新推送上一次同步的文档进行回滚
+type CanarySyncer struct {
+	staging    *ApifoxSyncer
+	production *ApifoxSyncer
+	gates      []Gate
+	progress
+}
+
+func NewCanarySyncer(staging, production *config.ApifoxConfig, serverCfg *config.ServerConfig, gates ...Gate) *CanarySyncer {
+	return &CanarySyncer{
+		staging:    NewApifoxSyncer(staging, serverCfg),
+		production: NewApifoxSyncer(production, serverCfg),
+		gates:      gates,
+	}
+}
+
+// SetStorage 设置两个阶段上传文档使用的存储
+func (s *CanarySyncer) SetStorage(store storage.Storage) {
+	s.staging.SetStorage(store)
+	s.production.SetStorage(store)
+}
+
+// SetOutput 设置两个阶段的进度消息写到的位置
+func (s *CanarySyncer) SetOutput(w io.Writer) {
+	s.progress.SetOutput(w)
+	s.staging.SetOutput(w)
+	s.production.SetOutput(w)
+}
+
+// Sync 执行两阶段同步，返回正式项目的导入结果
+func (s *CanarySyncer) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	// 正式项目的上一版文档需要在推送前读取，推送时会保存新文档
+	previous, _, err := LoadLastSyncedSpec(s.production.cfg)
+	if err != nil {
+		s.printf("[Canary Sync] ⚠️  Could not load previous production spec, rollback disabled: %v\n", err)
+	}
+
+	s.printf("[Canary Sync] Stage 1: syncing to staging project %s\n", s.staging.cfg.ProjectID)
+	stagingResult, err := s.staging.Sync(spec, commitMsg)
+	if err != nil {
+		return nil, fmt.Errorf("staging sync failed: %w", err)
+	}
+	// 预发导入有失败的接口时同样不发布到正式项目
+	if stagingResult.HasFailures() {
+		return nil, fmt.Errorf("staging import reported failures, production not updated: %s", stagingResult)
+	}
+
+	for _, gate := range s.gates {
+		s.printf("[Canary Sync] Running gate: %s\n", gate.Name)
+		if err := gate.Check(spec); err != nil {
+			return nil, fmt.Errorf("gate %s failed, production not updated: %w", gate.Name, err)
+		}
+	}
+
+	s.printf("[Canary Sync] Stage 2: syncing to production project %s\n", s.production.cfg.ProjectID)
+	result, err := s.production.Sync(spec, commitMsg)
+	// 正式项目导入有失败的接口时文档不完整，与导入出错一样回滚
+	if err == nil && result.HasFailures() {
+		err = fmt.Errorf("production import reported failures: %s", result)
+	}
+	if err == nil {
+		return result, nil
+	}
+
+	if previous == nil {
+		return nil, fmt.Errorf("production sync failed and no previous spec to roll back to: %w", err)
+	}
+	s.printf("[Canary Sync] ❌ Production sync failed, rolling back to previous spec: %v\n", err)
+	if _, rollbackErr := s.production.Sync(previous, "Rollback: "+commitMsg); rollbackErr != nil {
+		return nil, fmt.Errorf("production sync failed (%v) and rollback failed: %w", err, rollbackErr)
+	}
+	return nil, fmt.Errorf("production sync failed, rolled back to previous spec: %w", err)
+}
@@ -0,0 +1,21 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi"
+)
+
+// DescribeOperations renders the project's description_template on the
+// freshly generated operations of spec. The commit fields describe the
+// checkout at repoPath and are empty when it is not a git repository.
+func DescribeOperations(spec *openapi.Spec, project *config.ProjectConfig, repoPath string) error {
+	if project == nil || project.DescriptionTemplate == "" {
+		return nil
+	}
+	ctx := openapi.DescriptionContext{Project: project.ProjectName, Team: project.Team}
+	if commit, err := git.NewClient("").HeadInfo(repoPath); err == nil {
+		ctx.Branch, ctx.Commit, ctx.ShortCommit, ctx.CommitTime = commit.Branch, commit.Hash, commit.ShortHash, commit.Time
+	}
+	return spec.ApplyDescriptionTemplate(project.DescriptionTemplate, ctx)
+}
@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/pkg/ast"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// EngineSpecPublisher 将创建了多个 gin.Engine 的项目按 engine 拆成多份文档（见 ast.SplitEngines），
+// 写到项目产物目录（见 ProjectDir）的 engines/<文档名>.json，如 engines/public.json、engines/admin.json
+type EngineSpecPublisher struct {
+	team    string
+	project string
+	names   map[string]string // engine 变量名 -> 文档名，即 parser.engine_specs
+	progress
+}
+
+func NewEngineSpecPublisher(team, project string, names map[string]string) *EngineSpecPublisher {
+	return &EngineSpecPublisher{team: team, project: project, names: names}
+}
+
+// Sync 写入各 engine 的文档。文档中的接口不来自多个 engine 时（如按目录增量分析的同步）保留上一次的文档
+func (p *EngineSpecPublisher) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	specs := ast.SplitEngines(spec, p.names)
+	if specs == nil {
+		p.printf("[Engine Specs] ⏭️  Operations don't come from several gin engines, skipped (%s)\n", commitMsg)
+		return nil, nil
+	}
+
+	dir := filepath.Join(ProjectDir(p.team, p.project), "engines")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := json.MarshalIndent(specs[name], "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s spec: %w", name, err)
+		}
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s spec: %w", name, err)
+		}
+		p.printf("[Engine Specs] ✅ %s: %d operations saved to: %s (%s)\n", name, specs[name].OperationCount(), path, commitMsg)
+	}
+	return nil, nil
+}
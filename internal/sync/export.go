@@ -0,0 +1,143 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateExporter 用项目仓库中的 Go text/template 模板渲染文档，
+// 生成内部 Wiki、CSV 接口清单等自定义格式，输出到项目产物目录（见 ProjectDir）的 exports/ 下，
+// 由服务的 /docs 静态路由对外提供
+type TemplateExporter struct {
+	exports  []config.ExportConfig
+	repoPath string
+	team     string
+	project  string
+	progress
+}
+
+func NewTemplateExporter(exports []config.ExportConfig, repoPath, team, project string) *TemplateExporter {
+	return &TemplateExporter{
+		exports:  exports,
+		repoPath: repoPath,
+		team:     team,
+		project:  project,
+	}
+}
+
+// ExportData 模板的数据，例如 {{range .Operations}}{{.Method}} {{.Path}} {{.Summary}}{{end}}
+type ExportData struct {
+	Project       string
+	Spec          *openapi.Spec
+	Operations    []ExportOperation // 按路径、方法排序的所有接口
+	CommitMessage string
+	GeneratedAt   time.Time
+}
+
+// ExportOperation 单个接口，可直接访问 Operation 的字段（.Summary、.Tags、.Responses 等）
+type ExportOperation struct {
+	Method string
+	Path   string
+	*openapi.Operation
+}
+
+// exportFuncs 模板可用的函数
+var exportFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+	"trim":    strings.TrimSpace,
+	// csv 将各参数按 CSV 规则转义并以逗号连接，如 {{csv .Method .Path .Summary}}
+	"csv": func(fields ...string) (string, error) {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write(fields); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimRight(buf.String(), "\r\n"), w.Error()
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// cell 转义 Markdown / Wiki 表格单元格中的 | 和换行
+	"cell": func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+	},
+}
+
+// Sync 依次渲染所有模板，任一模板出错时返回错误，已渲染的文件保留
+func (e *TemplateExporter) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	data := ExportData{
+		Project:       e.project,
+		Spec:          spec,
+		CommitMessage: commitMsg,
+		GeneratedAt:   time.Now(),
+	}
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		data.Operations = append(data.Operations, ExportOperation{Method: method, Path: path, Operation: op})
+	})
+
+	dir := filepath.Join(ProjectDir(e.team, e.project), "exports")
+	for _, export := range e.exports {
+		templatePath, err := e.templatePath(export.Template)
+		if err != nil {
+			return nil, err
+		}
+		source, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(templatePath)).Funcs(exportFuncs).Parse(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse export template: %w", err)
+		}
+
+		// 先渲染到内存，模板执行出错时不留下不完整的文件
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", export.Template, err)
+		}
+
+		path := filepath.Join(dir, export.Output)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write export: %w", err)
+		}
+		e.printf("[Export] ✅ %s rendered to: %s\n", export.Template, path)
+	}
+	return nil, nil
+}
+
+// templatePath 返回模板在项目仓库中的路径。模板必须位于仓库内，符号链接也不能指向仓库外，
+// 否则仓库中的模板配置可以渲染服务器上的任意文件
+func (e *TemplateExporter) templatePath(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("export template must be a path inside the repository: %s", name)
+	}
+	root, err := filepath.EvalSymlinks(e.repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to read export template: %w", err)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("export template %s points outside the repository", name)
+	}
+	return path, nil
+}
@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// GitPushSyncer 将文档提交到文档仓库的指定分支，供 Stoplight、GitHub/Gitee Pages
+// 等以 Git 仓库为数据源的平台使用。内容未变化时不产生提交
+type GitPushSyncer struct {
+	cfg *config.GitPushConfig
+	progress
+}
+
+func NewGitPushSyncer(cfg *config.GitPushConfig) *GitPushSyncer {
+	return &GitPushSyncer{cfg: cfg}
+}
+
+func (s *GitPushSyncer) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	files := make(map[string][]byte)
+	for _, format := range s.cfg.Formats {
+		var data []byte
+		var err error
+		switch format {
+		case "yaml":
+			data, err = spec.ToYAML()
+		default:
+			data, err = json.MarshalIndent(spec, "", "  ")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", format, err)
+		}
+		files[path.Join(s.cfg.Path, s.cfg.FileName+"."+format)] = data
+	}
+
+	s.printf("[Git Push] Committing %d file(s) to %s (%s)\n", len(files), s.cfg.RepoURL, s.cfg.Branch)
+	pushed, err := git.CommitAndPush(git.PushRequest{
+		RepoURL:     s.cfg.RepoURL,
+		Branch:      s.cfg.Branch,
+		Files:       files,
+		Message:     commitMsg,
+		AuthorName:  s.cfg.AuthorName,
+		AuthorEmail: s.cfg.AuthorEmail,
+		Auth: git.Auth{
+			Token:     s.cfg.Token,
+			TokenUser: s.cfg.TokenUser,
+			SSHKey:    s.cfg.SSHKey,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !pushed {
+		s.printf("[Git Push] Docs unchanged, nothing to push\n")
+		return nil, nil
+	}
+	s.printf("[Git Push] ✅ Pushed to %s\n", s.cfg.Branch)
+	return nil, nil
+}
@@ -0,0 +1,152 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HTMLPublisher 将文档渲染为单个 HTML 页面（Redoc 或 Swagger UI），
+// 文档内容直接内嵌在页面中，发布到项目产物目录（见 ProjectDir）的 html/index.html，
+// 由服务的 /docs 静态路由对外提供，无需 Apifox 账号即可浏览
+type HTMLPublisher struct {
+	cfg       *config.HTMLConfig
+	serverCfg *config.ServerConfig
+	team      string
+	project   string
+	progress
+}
+
+func NewHTMLPublisher(cfg *config.HTMLConfig, serverCfg *config.ServerConfig, team, project string) *HTMLPublisher {
+	return &HTMLPublisher{
+		cfg:       cfg,
+		serverCfg: serverCfg,
+		team:      team,
+		project:   project,
+	}
+}
+
+var htmlTemplates = map[string]*template.Template{
+	"redoc": template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+  <div id="redoc"></div>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+  <script>
+    Redoc.init({{.Spec}}, {}, document.getElementById("redoc"));
+  </script>
+</body>
+</html>
+`)),
+	"swagger-ui": template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  {{- if .TryItURL}}
+  <div style="padding: 8px 20px; font-family: sans-serif; font-size: 14px; background: #f7f7f7;">
+    <label>API key for Try it out (required when the service has API keys):
+      <input id="try-it-key" type="password" size="40">
+    </label>
+  </div>
+  {{- end}}
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    const spec = {{.Spec}};
+    const options = { spec: spec, dom_id: "#swagger-ui" };
+    {{- if .TryItURL}}
+    spec.servers = [{ url: {{.TryItURL}}, description: "Try it out proxy" }];
+    const keyInput = document.getElementById("try-it-key");
+    keyInput.value = sessionStorage.getItem("try-it-key") || "";
+    keyInput.addEventListener("change", () => sessionStorage.setItem("try-it-key", keyInput.value));
+    options.requestInterceptor = (req) => {
+      if (keyInput.value) {
+        req.headers["X-API-Key"] = keyInput.value;
+      }
+      return req;
+    };
+    {{- end}}
+    SwaggerUIBundle(options);
+  </script>
+</body>
+</html>
+`)),
+}
+
+// Sync 渲染并写入 HTML 页面
+func (p *HTMLPublisher) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	if _, ok := htmlTemplates[p.cfg.Renderer]; !ok {
+		return nil, fmt.Errorf("unsupported html renderer: %s", p.cfg.Renderer)
+	}
+
+	dir := filepath.Join(ProjectDir(p.team, p.project), "html")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	path := filepath.Join(dir, "index.html")
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create html page: %w", err)
+	}
+	defer file.Close()
+
+	tryItURL := ""
+	if p.cfg.TryIt.Enabled {
+		tryItURL = TryItURL(p.serverCfg.PublicURL, p.project)
+	}
+	if err := RenderHTML(file, p.cfg.Renderer, spec, tryItURL); err != nil {
+		return nil, err
+	}
+
+	p.printf("[HTML Publish] ✅ %s page saved to: %s (%s)\n", p.cfg.Renderer, path, commitMsg)
+	p.printf("[HTML Publish] Public URL: %s%s/\n", p.serverCfg.PublicURL, PublicPath(dir))
+	return nil, nil
+}
+
+// TryItURL 返回项目 Try it out 代理的地址，swagger-ui 页面以它作为 servers
+func TryItURL(publicURL, project string) string {
+	return strings.TrimSuffix(publicURL, "/") + "/api/v1/projects/" + url.PathEscape(project) + "/try-it"
+}
+
+// RenderHTML 将文档渲染为 renderer（redoc 或 swagger-ui）的单个 HTML 页面写入 w。
+// tryItURL 不为空时，swagger-ui 页面的 Try it out 请求发往该代理地址
+func RenderHTML(w io.Writer, renderer string, spec *openapi.Spec, tryItURL string) error {
+	tmpl, ok := htmlTemplates[renderer]
+	if !ok {
+		return fmt.Errorf("unsupported html renderer: %s", renderer)
+	}
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	// template.JS 内嵌的 JSON 已由 json.Marshal 转义 <、>、&，可以安全放入 <script>
+	err = tmpl.Execute(w, map[string]interface{}{
+		"Title":    spec.Info.Title,
+		"Spec":     template.JS(specJSON),
+		"TryItURL": tryItURL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render html page: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of persisted Apifox sync logs
+const (
+	SyncLogRequest  = "request"
+	SyncLogResponse = "response"
+)
+
+// SyncLog 一条保存在项目产物目录 apifox/ 下的 Apifox 请求或响应日志
+type SyncLog struct {
+	Kind      string          `json:"kind"` // request 或 response
+	Time      time.Time       `json:"time"`
+	CommitMsg string          `json:"commit_msg"`
+	ProjectID string          `json:"project_id"`
+	Branch    string          `json:"branch,omitempty"` // Apifox 分支 ID，主分支为空
+	File      string          `json:"file"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// SyncLogQuery 日志查询条件，时间为零值表示不限制
+type SyncLogQuery struct {
+	Since       time.Time
+	Until       time.Time
+	Kind        string // 为空时返回请求和响应
+	Branch      string // 为空时返回所有分支，"main" 只返回主分支
+	Offset      int
+	Limit       int  // 为 0 时不分页
+	IncludeBody bool // 返回发送/收到的完整内容
+}
+
+// syncLogFile 一个日志文件，时间取自文件名，无需读取文件内容
+type syncLogFile struct {
+	path   string
+	name   string
+	kind   string
+	branch string
+	time   time.Time
+}
+
+// QuerySyncLogs 查询某个 Apifox 项目的同步日志，按时间倒序，返回当前页和符合条件的总数。
+// 按文件名中的时间筛选、排序和分页，只读取当前页的文件
+func QuerySyncLogs(cfg *config.ApifoxConfig, query SyncLogQuery) ([]SyncLog, int, error) {
+	root := filepath.Join(ProjectDir(cfg.Team, cfg.Project), "apifox", cfg.ProjectID)
+	dirs := map[string]string{"": root}
+	branchDirs, err := os.ReadDir(filepath.Join(root, "branches"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, 0, fmt.Errorf("failed to read branch logs: %w", err)
+	}
+	for _, entry := range branchDirs {
+		if entry.IsDir() {
+			dirs[entry.Name()] = filepath.Join(root, "branches", entry.Name())
+		}
+	}
+
+	var files []syncLogFile
+	for branch, dir := range dirs {
+		switch {
+		case query.Branch == "main" && branch != "":
+			continue
+		case query.Branch != "" && query.Branch != "main" && branch != query.Branch:
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, 0, fmt.Errorf("failed to read logs: %w", err)
+		}
+		for _, entry := range entries {
+			kind := syncLogKind(entry.Name())
+			if entry.IsDir() || kind == "" || (query.Kind != "" && kind != query.Kind) {
+				continue
+			}
+			file := syncLogFile{path: filepath.Join(dir, entry.Name()), name: entry.Name(), kind: kind, branch: branch}
+			if file.time, err = syncLogTime(entry.Name(), cfg.ProjectID); err != nil {
+				// 文件名不含时间时读取文件中的 timestamp
+				log, err := readSyncLog(file.path, kind, false)
+				if err != nil {
+					fmt.Printf("[Warning] Skipping unreadable sync log %s: %v\n", entry.Name(), err)
+					continue
+				}
+				file.time = log.Time
+			}
+			if (!query.Since.IsZero() && file.time.Before(query.Since)) ||
+				(!query.Until.IsZero() && file.time.After(query.Until)) {
+				continue
+			}
+			files = append(files, file)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].time.Equal(files[j].time) {
+			return files[i].time.After(files[j].time)
+		}
+		if files[i].name != files[j].name {
+			return files[i].name > files[j].name
+		}
+		return files[i].branch < files[j].branch
+	})
+
+	total := len(files)
+	if query.Offset > total {
+		query.Offset = total
+	}
+	files = files[query.Offset:]
+	if query.Limit > 0 && len(files) > query.Limit {
+		files = files[:query.Limit]
+	}
+
+	logs := make([]SyncLog, 0, len(files))
+	for _, file := range files {
+		log, err := readSyncLog(file.path, file.kind, query.IncludeBody)
+		if err != nil {
+			fmt.Printf("[Warning] Skipping unreadable sync log %s: %v\n", file.name, err)
+			continue
+		}
+		log.ProjectID = cfg.ProjectID
+		log.Branch = file.branch
+		logs = append(logs, log)
+	}
+	return logs, total, nil
+}
+
+// syncLogTime 解析文件名 {projectID}_{20060102_150405}_{kind}.json 中的本地时间
+func syncLogTime(name, projectID string) (time.Time, error) {
+	stamp := strings.TrimPrefix(name, projectID+"_")
+	if i := strings.LastIndex(stamp, "_"); i >= 0 {
+		stamp = stamp[:i]
+	}
+	return time.ParseInLocation("20060102_150405", stamp, time.Local)
+}
+
+// syncLogKind 根据文件名判断日志类型：{projectID}_{timestamp}_request.json / _response.json
+func syncLogKind(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_request.json"):
+		return SyncLogRequest
+	case strings.HasSuffix(name, "_response.json"):
+		return SyncLogResponse
+	}
+	return ""
+}
+
+func readSyncLog(path, kind string, includeBody bool) (SyncLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SyncLog{}, err
+	}
+	var entry map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return SyncLog{}, err
+	}
+
+	log := SyncLog{Kind: kind, File: filepath.Base(path)}
+	var timestamp string
+	json.Unmarshal(entry["timestamp"], &timestamp)
+	json.Unmarshal(entry["commitMsg"], &log.CommitMsg)
+	if log.Time, err = time.Parse(time.RFC3339, timestamp); err != nil {
+		return SyncLog{}, fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if includeBody {
+		log.Body = entry[kind]
+	}
+	return log, nil
+}
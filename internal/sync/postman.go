@@ -0,0 +1,369 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PostmanSchemaURL Postman Collection v2.1 格式标识
+const PostmanSchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type PostmanSyncer struct {
+	cfg     *config.PostmanConfig
+	team    string // 集合副本保存在项目的产物目录下
+	project string
+	progress
+}
+
+// PostmanCollection Postman Collection v2.1 结构（只包含生成文档用到的字段）
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string `json:"_postman_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// PostmanItem 既可以是文件夹（Item 非空），也可以是单个请求
+type PostmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []PostmanItem   `json:"item,omitempty"`
+	Request     *PostmanRequest `json:"request,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method      string          `json:"method"`
+	Header      []PostmanHeader `json:"header"`
+	URL         PostmanURL      `json:"url"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+type PostmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []PostmanQuery    `json:"query,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanQuery struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+func NewPostmanSyncer(cfg *config.PostmanConfig, team, project string) *PostmanSyncer {
+	return &PostmanSyncer{cfg: cfg, team: team, project: project}
+}
+
+// Sync 将 OpenAPI 规范转换为 Postman 集合并通过 Postman API 推送
+// 1. 配置了 collection_id 时直接更新该集合
+// 2. 否则在 workspace 中按集合名称查找，找到则更新，找不到则新建
+func (s *PostmanSyncer) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	collection := ToPostmanCollection(spec)
+
+	collectionJSON, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal collection: %w", err)
+	}
+	if path, err := s.saveCollection(collectionJSON, collection.Info.Name); err != nil {
+		s.printf("[Warning] Failed to save Postman collection: %v\n", err)
+	} else {
+		s.printf("[Postman Sync] Collection saved to: %s\n", path)
+	}
+
+	collectionID := s.cfg.CollectionID
+	if collectionID == "" {
+		collectionID, err = s.findCollection(collection.Info.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"collection": collection})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	if collectionID != "" {
+		s.printf("[Postman Sync] Updating collection %s (%s)...\n", collectionID, commitMsg)
+		_, err = s.do("PUT", "/collections/"+collectionID, payload)
+		if err != nil {
+			return nil, err
+		}
+		s.printf("[Postman Sync] ✅ Collection updated: %s\n", collectionID)
+		return nil, nil
+	}
+
+	path := "/collections"
+	if s.cfg.WorkspaceID != "" {
+		path += "?workspace=" + s.cfg.WorkspaceID
+	}
+	s.printf("[Postman Sync] Creating collection %q (%s)...\n", collection.Info.Name, commitMsg)
+	respBody, err := s.do("POST", path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		Collection struct {
+			UID string `json:"uid"`
+		} `json:"collection"`
+	}
+	json.Unmarshal(respBody, &created)
+	s.printf("[Postman Sync] ✅ Collection created: %s (set postman.collection_id to pin it)\n", created.Collection.UID)
+	return nil, nil
+}
+
+// findCollection 在 workspace 中按名称查找集合，返回集合 uid，找不到时返回空字符串
+func (s *PostmanSyncer) findCollection(name string) (string, error) {
+	path := "/collections"
+	if s.cfg.WorkspaceID != "" {
+		path += "?workspace=" + s.cfg.WorkspaceID
+	}
+	respBody, err := s.do("GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var list struct {
+		Collections []struct {
+			Name string `json:"name"`
+			UID  string `json:"uid"`
+		} `json:"collections"`
+	}
+	if err := json.Unmarshal(respBody, &list); err != nil {
+		return "", fmt.Errorf("failed to parse collection list: %w", err)
+	}
+	for _, c := range list.Collections {
+		if c.Name == name {
+			return c.UID, nil
+		}
+	}
+	return "", nil
+}
+
+// do 调用 Postman API
+func (s *PostmanSyncer) do(method, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(s.cfg.BaseURL, "/")+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", s.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("postman API error (HTTP %d): %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
+
+// saveCollection 保存集合到项目产物目录的 postman/ 下，便于手动导入
+func (s *PostmanSyncer) saveCollection(data []byte, name string) (string, error) {
+	dir := filepath.Join(ProjectDir(s.team, s.project), "postman")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	safeName := regexp.MustCompile(`[^A-Za-z0-9_-]+`).ReplaceAllString(name, "_")
+	timestamp := time.Now().Format("20060102_150405")
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s_collection.json", safeName, timestamp))
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// ToPostmanCollection 将 OpenAPI 规范转换为 Postman Collection v2.1，
+// 接口按第一个 tag 分组为文件夹，服务地址通过 {{baseUrl}} 变量引用
+func ToPostmanCollection(spec *openapi.Spec) *PostmanCollection {
+	collection := &PostmanCollection{
+		Info: PostmanInfo{
+			Name:        spec.Info.Title,
+			Description: spec.Info.Description,
+			Schema:      PostmanSchemaURL,
+		},
+		Variable: []PostmanVariable{{Key: "baseUrl", Description: "服务地址，例如 http://localhost:8080"}},
+	}
+
+	var schemas map[string]openapi.Schema
+	if spec.Components != nil {
+		schemas = spec.Components.Schemas
+	}
+
+	folders := make(map[string]*PostmanItem)
+	var folderNames []string
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		folder := "default"
+		if len(op.Tags) > 0 {
+			folder = op.Tags[0]
+		}
+		if folders[folder] == nil {
+			folders[folder] = &PostmanItem{Name: folder}
+			folderNames = append(folderNames, folder)
+		}
+		folders[folder].Item = append(folders[folder].Item, postmanRequestItem(path, method, op, schemas))
+	})
+
+	sort.Strings(folderNames)
+	for _, name := range folderNames {
+		collection.Item = append(collection.Item, *folders[name])
+	}
+	return collection
+}
+
+func postmanRequestItem(path, method string, op *openapi.Operation, schemas map[string]openapi.Schema) PostmanItem {
+	name := op.Summary
+	if name == "" {
+		name = method + " " + path
+	}
+
+	// OpenAPI {id} 转换为 Postman :id
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.Trim(segment, "{}")
+		}
+	}
+
+	url := PostmanURL{
+		Raw:  "{{baseUrl}}/" + strings.Join(segments, "/"),
+		Host: []string{"{{baseUrl}}"},
+		Path: segments,
+	}
+	request := &PostmanRequest{
+		Method:      method,
+		Header:      []PostmanHeader{},
+		Description: op.Description,
+	}
+
+	var query []string
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			url.Variable = append(url.Variable, PostmanVariable{Key: param.Name, Description: param.Description})
+		case "query":
+			url.Query = append(url.Query, PostmanQuery{
+				Key:         param.Name,
+				Description: param.Description,
+				Disabled:    !param.Required,
+			})
+			query = append(query, param.Name+"=")
+		case "header":
+			request.Header = append(request.Header, PostmanHeader{Key: param.Name})
+		}
+	}
+	if len(query) > 0 {
+		url.Raw += "?" + strings.Join(query, "&")
+	}
+	request.URL = url
+
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			example := media.Example
+			if example == nil {
+				example = sampleValue(media.Schema, schemas, 0)
+			}
+			raw, _ := json.MarshalIndent(example, "", "  ")
+			request.Header = append(request.Header, PostmanHeader{Key: "Content-Type", Value: "application/json"})
+			request.Body = &PostmanBody{
+				Mode: "raw",
+				Raw:  string(raw),
+				Options: map[string]interface{}{
+					"raw": map[string]string{"language": "json"},
+				},
+			}
+		}
+	}
+
+	return PostmanItem{Name: name, Request: request}
+}
+
+// sampleValue 根据 schema 生成示例值，用于填充请求体
+func sampleValue(schema openapi.Schema, schemas map[string]openapi.Schema, depth int) interface{} {
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if depth > 5 {
+		return nil
+	}
+	if name := openapi.RefName(schema.Ref); name != "" {
+		if resolved, ok := schemas[name]; ok {
+			return sampleValue(resolved, schemas, depth+1)
+		}
+		return map[string]interface{}{}
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "object":
+		obj := make(map[string]interface{}, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			obj[name] = sampleValue(prop, schemas, depth+1)
+		}
+		return obj
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{sampleValue(*schema.Items, schemas, depth+1)}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		if schema.Format == "date-time" {
+			return "2024-01-01T00:00:00Z"
+		}
+		return ""
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SyncResult summarises what a target did with the pushed spec. Targets that
+// do not report per-endpoint outcomes return a nil result.
+type SyncResult struct {
+	Created        int      `json:"created"` // endpoints
+	Updated        int      `json:"updated"`
+	Failed         int      `json:"failed"`
+	Ignored        int      `json:"ignored"`
+	SchemasCreated int      `json:"schemas_created"`
+	SchemasUpdated int      `json:"schemas_updated"`
+	SchemasFailed  int      `json:"schemas_failed"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// HasFailures reports whether some endpoints or schemas were not imported
+func (r *SyncResult) HasFailures() bool {
+	return r != nil && (r.Failed > 0 || r.SchemasFailed > 0 || len(r.Errors) > 0)
+}
+
+func (r *SyncResult) String() string {
+	if r == nil {
+		return "no import details"
+	}
+	summary := fmt.Sprintf("endpoints: %d created, %d updated, %d failed, %d ignored; schemas: %d created, %d updated, %d failed",
+		r.Created, r.Updated, r.Failed, r.Ignored, r.SchemasCreated, r.SchemasUpdated, r.SchemasFailed)
+	if len(r.Errors) > 0 {
+		summary += "; errors: " + strings.Join(r.Errors, "; ")
+	}
+	return summary
+}
+
+// apifoxImportResponse is the body returned by the Apifox import-openapi API
+type apifoxImportResponse struct {
+	Success      bool   `json:"success"`
+	ErrorMessage string `json:"errorMessage"`
+	Data         struct {
+		Counters struct {
+			EndpointCreated int `json:"endpointCreated"`
+			EndpointUpdated int `json:"endpointUpdated"`
+			EndpointFailed  int `json:"endpointFailed"`
+			EndpointIgnored int `json:"endpointIgnored"`
+			SchemaCreated   int `json:"schemaCreated"`
+			SchemaUpdated   int `json:"schemaUpdated"`
+			SchemaFailed    int `json:"schemaFailed"`
+		} `json:"counters"`
+		Errors []json.RawMessage `json:"errors"`
+	} `json:"data"`
+}
+
+// parseApifoxImportResult converts an Apifox import response into a SyncResult.
+// A response with success=false is an error even when the HTTP status is 200.
+func parseApifoxImportResult(body []byte) (*SyncResult, error) {
+	var resp apifoxImportResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse import response: %w", err)
+	}
+	if !resp.Success {
+		if resp.ErrorMessage != "" {
+			return nil, fmt.Errorf("apifox import failed: %s", resp.ErrorMessage)
+		}
+		return nil, fmt.Errorf("apifox import failed: %s", string(body))
+	}
+
+	counters := resp.Data.Counters
+	result := &SyncResult{
+		Created:        counters.EndpointCreated,
+		Updated:        counters.EndpointUpdated,
+		Failed:         counters.EndpointFailed,
+		Ignored:        counters.EndpointIgnored,
+		SchemasCreated: counters.SchemaCreated,
+		SchemasUpdated: counters.SchemaUpdated,
+		SchemasFailed:  counters.SchemaFailed,
+	}
+	for _, raw := range resp.Data.Errors {
+		result.Errors = append(result.Errors, importErrorMessage(raw))
+	}
+	return result, nil
+}
+
+// importErrorMessage extracts a readable message from one entry of data.errors,
+// which may be a plain string or an object with a message field
+func importErrorMessage(raw json.RawMessage) string {
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return message
+	}
+	var entry struct {
+		Message string `json:"message"`
+		Path    string `json:"path"`
+		Method  string `json:"method"`
+	}
+	if err := json.Unmarshal(raw, &entry); err == nil && entry.Message != "" {
+		if entry.Path != "" {
+			return strings.TrimSpace(strings.ToUpper(entry.Method)+" "+entry.Path) + ": " + entry.Message
+		}
+		return entry.Message
+	}
+	return string(raw)
+}
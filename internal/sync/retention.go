@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// LatestDocName 每个文档目录中最近一次同步文档的固定副本，URL 方式导入时 Apifox 拉取该地址
+const LatestDocName = "latest_openapi.json"
+
+// LatestAnalysisName 最近一次同步的分析报告副本，记录哪些接口是推断出的、哪些是猜测的
+const LatestAnalysisName = "latest_analysis.json"
+
+// historyFilePattern 匹配 {projectID}_{timestamp}_{openapi|request|response|analysis}.json
+var historyFilePattern = regexp.MustCompile(`_(\d{8}_\d{6})_(openapi|request|response|analysis)\.json$`)
+
+// syncBatch 同一次同步保存的文件（时间戳相同）
+type syncBatch struct {
+	timestamp string
+	time      time.Time
+	files     []string
+	size      int64
+}
+
+// PruneHistory 按保留策略删除 dir 下的历史同步文件，返回删除的文件数。
+// 只处理带时间戳的文件，latest_*.json 和分支子目录不受影响
+func PruneHistory(dir string, retention config.RetentionConfig) (int, error) {
+	if !retention.Enabled() {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read doc directory: %w", err)
+	}
+
+	batches := make(map[string]*syncBatch)
+	for _, entry := range entries {
+		match := historyFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		batch, ok := batches[match[1]]
+		if !ok {
+			t, err := time.ParseInLocation("20060102_150405", match[1], time.Local)
+			if err != nil {
+				continue
+			}
+			batch = &syncBatch{timestamp: match[1], time: t}
+			batches[match[1]] = batch
+		}
+		batch.files = append(batch.files, filepath.Join(dir, entry.Name()))
+		batch.size += info.Size()
+	}
+
+	// 时间戳格式 20060102_150405 可以直接按字典序排序，最新的在前
+	ordered := make([]*syncBatch, 0, len(batches))
+	var total int64
+	for _, batch := range batches {
+		ordered = append(ordered, batch)
+		total += batch.size
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].timestamp > ordered[j].timestamp
+	})
+
+	maxAge := time.Duration(retention.MaxAgeDays) * 24 * time.Hour
+	maxTotal := int64(retention.MaxTotalMB) << 20
+
+	removed := 0
+	// 从最早的批次开始检查，最近一次同步总是保留
+	for i := len(ordered) - 1; i >= 1; i-- {
+		batch := ordered[i]
+		expired := (retention.KeepLast > 0 && i >= retention.KeepLast) ||
+			(maxAge > 0 && time.Since(batch.time) > maxAge) ||
+			(maxTotal > 0 && total > maxTotal)
+		if !expired {
+			continue
+		}
+		for _, file := range batch.files {
+			if err := os.Remove(file); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+			removed++
+		}
+		total -= batch.size
+	}
+	return removed, nil
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type SwaggerHubSyncer struct {
+	cfg     *config.SwaggerHubConfig
+	version string
+	progress
+}
+
+// NewSwaggerHubSyncer 创建 SwaggerHub 同步器，version 为本次上传的 API 版本号
+func NewSwaggerHubSyncer(cfg *config.SwaggerHubConfig, version string) *SwaggerHubSyncer {
+	return &SwaggerHubSyncer{cfg: cfg, version: version}
+}
+
+// SwaggerHubVersion 生成 API 版本号：
+// 1. 配置了固定版本时直接使用
+// 2. HEAD 上有 tag（如 v1.2.3）时使用 tag 去掉 v 前缀
+// 3. 否则使用 {文档版本}-{提交日期}.{短哈希}，例如 1.0.0-20240101.a1b2c3d
+func SwaggerHubVersion(cfg *config.SwaggerHubConfig, specVersion string, commit *git.CommitInfo) string {
+	if cfg.Version != "" {
+		return cfg.Version
+	}
+	if specVersion == "" {
+		specVersion = "1.0.0"
+	}
+	if commit == nil {
+		return specVersion + "-" + time.Now().Format("20060102150405")
+	}
+	if commit.Tag != "" {
+		return strings.TrimPrefix(commit.Tag, "v")
+	}
+	return fmt.Sprintf("%s-%s.%s", specVersion, commit.Time.Format("20060102"), commit.ShortHash)
+}
+
+// Sync 通过 SwaggerHub Registry API 创建或更新 API 版本，
+// 配置了 publish 时将该版本标记为已发布并设为默认版本
+func (s *SwaggerHubSyncer) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	// SwaggerHub 以 info.version 作为版本号，上传副本避免修改原文档
+	versioned := *spec
+	versioned.Info.Version = s.version
+
+	specJSON, err := json.Marshal(&versioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("isPrivate", fmt.Sprintf("%t", s.cfg.Private))
+	query.Set("version", s.version)
+	query.Set("force", "true")
+	apiPath := fmt.Sprintf("/apis/%s/%s", url.PathEscape(s.cfg.Owner), url.PathEscape(s.cfg.API))
+
+	s.printf("[SwaggerHub Sync] Uploading %s/%s version %s (%s)...\n", s.cfg.Owner, s.cfg.API, s.version, commitMsg)
+	if err := s.do("POST", apiPath+"?"+query.Encode(), specJSON); err != nil {
+		return nil, err
+	}
+
+	if s.cfg.Publish {
+		versionPath := apiPath + "/" + url.PathEscape(s.version)
+		if err := s.do("PUT", versionPath+"/settings/lifecycle", []byte(`{"published":true}`)); err != nil {
+			return nil, fmt.Errorf("failed to publish version %s: %w", s.version, err)
+		}
+		defaultBody, _ := json.Marshal(map[string]string{"version": s.version})
+		if err := s.do("PUT", apiPath+"/settings/default", defaultBody); err != nil {
+			return nil, fmt.Errorf("failed to set default version %s: %w", s.version, err)
+		}
+		s.printf("[SwaggerHub Sync] Version %s published and set as default\n", s.version)
+	}
+
+	s.printf("[SwaggerHub Sync] ✅ Sync successful: https://app.swaggerhub.com/apis/%s/%s/%s\n", s.cfg.Owner, s.cfg.API, s.version)
+	return nil, nil
+}
+
+// do 调用 SwaggerHub Registry API
+func (s *SwaggerHubSyncer) do(method, path string, body []byte) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(s.cfg.BaseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", s.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("swaggerhub API error (HTTP %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
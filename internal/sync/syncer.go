@@ -0,0 +1,211 @@
+package sync
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Syncer interface - implement this for each documentation destination
+type Syncer interface {
+	// Sync pushes the spec to the destination. The result is nil for
+	// destinations that do not report per-endpoint outcomes.
+	Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error)
+}
+
+// Context carries everything a target needs to build its Syncer for one run
+type Context struct {
+	Project  *config.ProjectConfig // nil when the repository has no project config
+	Apifox   *config.ApifoxConfig  // effective Apifox settings (project or global)
+	Server   *config.ServerConfig
+	RepoPath string          // checkout of the analyzed repository, used for git metadata
+	Gates    []Gate          // checks run between the stages of a canary sync
+	Storage  storage.Storage // where published documents are uploaded, nil for the local artifacts directory
+	Output   io.Writer       // where the targets write their progress, standard output when nil
+}
+
+// progress writes the progress messages of a target, to standard output
+// unless SetOutput gives another writer
+type progress struct {
+	out io.Writer
+}
+
+// SetOutput sets where progress messages are written
+func (p *progress) SetOutput(w io.Writer) {
+	p.out = w
+}
+
+func (p *progress) printf(format string, args ...interface{}) {
+	out := p.out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintf(out, format, args...)
+}
+
+// Factory builds the Syncer of a target for one run
+type Factory func(ctx *Context) (Syncer, error)
+
+// Registry manages available sync targets
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// DefaultRegistry returns a registry with the built-in targets registered
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(config.TargetApifox, newApifoxTarget)
+	r.Register(config.TargetPostman, newPostmanTarget)
+	r.Register(config.TargetSwaggerHub, newSwaggerHubTarget)
+	r.Register(config.TargetHTML, newHTMLTarget)
+	r.Register(config.TargetGit, newGitPushTarget)
+	r.Register(config.TargetExport, newExportTarget)
+	r.Register(config.TargetAsyncAPI, newAsyncAPITarget)
+	r.Register(config.TargetEngines, newEnginesTarget)
+	return r
+}
+
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// Get builds the named target's Syncer for the given run
+func (r *Registry) Get(name string, ctx *Context) (Syncer, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, errors.New("sync target not found: " + name)
+	}
+	syncer, err := factory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if out, ok := syncer.(interface{ SetOutput(io.Writer) }); ok && ctx.Output != nil {
+		out.SetOutput(ctx.Output)
+	}
+	return syncer, nil
+}
+
+// Resolve builds the Syncers of several targets, failing before anything is
+// pushed if one of them is unknown or misconfigured
+func (r *Registry) Resolve(names []string, ctx *Context) ([]Syncer, error) {
+	syncers := make([]Syncer, 0, len(names))
+	for _, name := range names {
+		syncer, err := r.Get(name, ctx)
+		if err != nil {
+			return nil, err
+		}
+		syncers = append(syncers, syncer)
+	}
+	return syncers, nil
+}
+
+func (r *Registry) List() []string {
+	var names []string
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newApifoxTarget syncs to Apifox, in two stages when a staging project is configured
+func newApifoxTarget(ctx *Context) (Syncer, error) {
+	if ctx.Apifox == nil || ctx.Apifox.Token == "" || ctx.Apifox.ProjectID == "" {
+		return nil, errors.New("apifox credentials not configured")
+	}
+	if ctx.Project != nil && ctx.Project.Staging != nil {
+		gates := append([]Gate{ValidationGate}, ctx.Gates...)
+		canary := NewCanarySyncer(ctx.Project.Staging, ctx.Apifox, ctx.Server, gates...)
+		canary.SetStorage(ctx.Storage)
+		return canary, nil
+	}
+	syncer := NewApifoxSyncer(ctx.Apifox, ctx.Server)
+	syncer.SetStorage(ctx.Storage)
+	return syncer, nil
+}
+
+func newPostmanTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil || ctx.Project.Postman.APIKey == "" {
+		return nil, errors.New("postman target requires postman.api_key in the project config")
+	}
+	return NewPostmanSyncer(&ctx.Project.Postman, ctx.Project.Team, ctx.Project.ProjectName), nil
+}
+
+func newHTMLTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil {
+		return nil, errors.New("html target requires a project config")
+	}
+	return NewHTMLPublisher(&ctx.Project.HTML, ctx.Server, ctx.Project.Team, ctx.Project.ProjectName), nil
+}
+
+func newAsyncAPITarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil {
+		return nil, errors.New("asyncapi target requires a project config")
+	}
+	return NewAsyncAPIPublisher(ctx.Project.Team, ctx.Project.ProjectName), nil
+}
+
+func newEnginesTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil {
+		return nil, errors.New("engines target requires a project config")
+	}
+	return NewEngineSpecPublisher(ctx.Project.Team, ctx.Project.ProjectName, ctx.Project.Parser.EngineSpecs), nil
+}
+
+func newGitPushTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil || ctx.Project.GitPush.RepoURL == "" {
+		return nil, errors.New("git target requires git_push.repo_url in the project config")
+	}
+	return NewGitPushSyncer(&ctx.Project.GitPush), nil
+}
+
+// newExportTarget reads templates from the analyzed checkout, so template
+// changes ship with the code they document
+func newExportTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil || len(ctx.Project.Exports) == 0 {
+		return nil, errors.New("export target requires exports in the project config")
+	}
+	repoPath := ctx.RepoPath
+	if repoPath == "" {
+		repoPath = ctx.Project.LocalPath
+	}
+	return NewTemplateExporter(ctx.Project.Exports, repoPath, ctx.Project.Team, ctx.Project.ProjectName), nil
+}
+
+// newSwaggerHubTarget names the uploaded version after the repository's git metadata
+func newSwaggerHubTarget(ctx *Context) (Syncer, error) {
+	if ctx.Project == nil || ctx.Project.SwaggerHub.APIKey == "" {
+		return nil, errors.New("swaggerhub target requires swaggerhub.api_key in the project config")
+	}
+	return &swaggerHubTarget{cfg: &ctx.Project.SwaggerHub, repoPath: ctx.RepoPath}, nil
+}
+
+// swaggerHubTarget defers version naming until the spec (and its info.version) is known
+type swaggerHubTarget struct {
+	cfg      *config.SwaggerHubConfig
+	repoPath string
+	progress
+}
+
+func (t *swaggerHubTarget) Sync(spec *openapi.Spec, commitMsg string) (*SyncResult, error) {
+	commit, err := git.NewClient("").HeadInfo(t.repoPath)
+	if err != nil {
+		t.printf("[SwaggerHub Sync] ⚠️  No git metadata, using timestamp version: %v\n", err)
+	}
+	version := SwaggerHubVersion(t.cfg, spec.Info.Version, commit)
+	syncer := NewSwaggerHubSyncer(t.cfg, version)
+	syncer.SetOutput(t.out)
+	return syncer.Sync(spec, commitMsg)
+}
@@ -0,0 +1,179 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Release is the latest release announced by the feed. The feed is a JSON
+// document such as {"version": "1.4.0", "url": "...", "notes": "..."};
+// the GitHub/Gitea "latest release" format (tag_name, html_url, body) is
+// accepted as well.
+type Release struct {
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// UpdateStatus is the result of the last update check
+type UpdateStatus struct {
+	Latest    *Release  `json:"latest,omitempty"`
+	Available bool      `json:"available"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Checker polls the release feed, caching the result for an interval so
+// that version requests don't hit the feed every time
+type Checker struct {
+	feedURL  string
+	interval time.Duration
+	client   *http.Client
+
+	mu       sync.Mutex
+	status   *UpdateStatus
+	checking chan struct{} // closed when the fetch in progress ends, nil when none is
+}
+
+// NewChecker returns nil when feedURL is empty; a nil Checker never checks
+func NewChecker(feedURL string, interval time.Duration) *Checker {
+	if feedURL == "" {
+		return nil
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Checker{
+		feedURL:  feedURL,
+		interval: interval,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Check returns the cached status, refreshing it when it is older than the
+// interval. Only one request fetches the feed at a time; others meanwhile get
+// the previous status, or wait for the first check to end.
+func (c *Checker) Check() *UpdateStatus {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	status := c.status
+	if status != nil && time.Since(status.CheckedAt) < c.interval {
+		c.mu.Unlock()
+		return status
+	}
+	if checking := c.checking; checking != nil {
+		c.mu.Unlock()
+		if status != nil {
+			return status
+		}
+		<-checking
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.status
+	}
+	checking := make(chan struct{})
+	c.checking = checking
+	c.mu.Unlock()
+
+	status = &UpdateStatus{CheckedAt: time.Now()}
+	latest, err := c.fetch()
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Latest = latest
+		status.Available = Newer(latest.Version, Version)
+	}
+	c.mu.Lock()
+	c.status, c.checking = status, nil
+	c.mu.Unlock()
+	close(checking)
+	return status
+}
+
+func (c *Checker) fetch() (*Release, error) {
+	resp, err := c.client.Get(c.feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("release feed request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release feed returned HTTP %d", resp.StatusCode)
+	}
+
+	var feed struct {
+		Release
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+		Body    string `json:"body"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("invalid release feed: %w", err)
+	}
+	release := feed.Release
+	if release.Version == "" {
+		release.Version = feed.TagName
+	}
+	if release.URL == "" {
+		release.URL = feed.HTMLURL
+	}
+	if release.Notes == "" {
+		release.Notes = feed.Body
+	}
+	if release.Version == "" {
+		return nil, fmt.Errorf("release feed has no version")
+	}
+	return &release, nil
+}
+
+// Newer reports whether version a is newer than b, comparing dotted numeric
+// versions (a leading v is ignored). Builds without a release version, such
+// as "dev" or a bare commit hash, are never reported as outdated, and a feed
+// announcing no release version never reports an update.
+func Newer(a, b string) bool {
+	if !isRelease(a) || !isRelease(b) {
+		return false
+	}
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// releasePattern matches release tags such as 1.4, v1.4.0 and 1.4.0-rc1+build.5
+var releasePattern = regexp.MustCompile(`^v?\d+\.\d+(\.\d+)?(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+func isRelease(v string) bool {
+	return releasePattern.MatchString(strings.TrimSpace(v))
+}
+
+// versionParts parses 1.4.0-rc1 into [1 4 0]; pre-release suffixes are ignored
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, part := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
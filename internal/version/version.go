@@ -0,0 +1,82 @@
+// Package version reports which build of the service is running and checks
+// a release feed for newer versions.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X api-doc-generator/internal/version.Version=1.4.0 \
+//	  -X api-doc-generator/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X api-doc-generator/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Commit and BuildDate fall back to the VCS information the Go toolchain
+// embeds when building inside a git checkout.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit hash
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+func (i Info) String() string {
+	s := i.Version
+	if commit := i.ShortCommit(); commit != "" {
+		s += " (" + commit
+		if i.Modified {
+			s += "-dirty"
+		}
+		s += ")"
+	}
+	if i.BuildDate != "" {
+		s += " built " + i.BuildDate
+	}
+	return fmt.Sprintf("%s, %s", s, i.GoVersion)
+}
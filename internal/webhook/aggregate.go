@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// syncAggregates rebuilds and re-syncs every aggregate project that includes
+// member, so the platform-level document follows each service sync.
+// allowBreaking carries the member sync's override of the breaking-change gate.
+func (h *Handler) syncAggregates(member, commitMsg string, allowBreaking bool) {
+	var aggregates []*config.ProjectConfig
+	for _, project := range h.projects.Loaded() {
+		if project.IsAggregate() && project.IncludesProject(member) {
+			aggregates = append(aggregates, project)
+		}
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].ProjectName < aggregates[j].ProjectName
+	})
+
+	for _, aggregate := range aggregates {
+		log.Printf("🧩 Rebuilding aggregate project %s after %s sync", aggregate.ProjectName, member)
+		if err := h.syncAggregate(aggregate, commitMsg, allowBreaking); err != nil {
+			log.Printf("❌ Aggregate %s sync failed: %v", aggregate.ProjectName, err)
+			continue
+		}
+		log.Printf("✅ Successfully synced aggregate %s", aggregate.ProjectName)
+	}
+}
+
+// syncAggregate merges the members' documents and syncs the result through
+// the same gates as a member sync: completeness, lint rules and breaking
+// changes against the aggregate's last synced document
+func (h *Handler) syncAggregate(aggregate *config.ProjectConfig, commitMsg string, allowBreaking bool) error {
+	spec, err := sync.BuildAggregateSpec(h.projects, aggregate)
+	if err != nil {
+		return err
+	}
+
+	if limit := aggregate.Sync.MaxUntypedPercent; limit != nil {
+		if err := spec.Coverage().CheckCompleteness(*limit); err != nil {
+			return fmt.Errorf("completeness gate: %w", err)
+		}
+	}
+	if aggregate.Lint != nil {
+		lintReport := lint.Run(spec, aggregate.Lint)
+		log.Printf("📏 Lint of aggregate %s: %s", aggregate.ProjectName, lintReport.Summary())
+		if lintReport.HasErrors() {
+			return fmt.Errorf("lint: %s", lintReport.Summary())
+		}
+	}
+
+	targets := aggregate.Targets()
+	var breakingErr error
+	if lastSpec, _, err := sync.LoadBaseline(&aggregate.Apifox); err != nil {
+		log.Printf("⚠️  Could not load last synced spec of aggregate %s: %v", aggregate.ProjectName, err)
+	} else if lastSpec != nil {
+		report := diff.Compare(lastSpec, spec)
+		log.Printf("📝 Aggregate %s changes since last sync: %s", aggregate.ProjectName, report.Summary())
+		if report.HasBreaking() && aggregate.Sync.FailOnBreaking && !allowBreaking {
+			breakingErr = fmt.Errorf("%d breaking change(s) detected", len(report.Breaking()))
+			// As for members, staging still gets the change and the canary stops before production
+			if targets = sync.StagingTargets(targets, aggregate); targets == nil {
+				return breakingErr
+			}
+		}
+	}
+
+	syncers, err := h.targets.Resolve(targets, &sync.Context{
+		Project: aggregate,
+		Apifox:  &aggregate.Apifox,
+		Server:  &h.cfg.Server,
+		Gates:   []sync.Gate{{Name: "breaking-changes", Check: func(*openapi.Spec) error { return breakingErr }}},
+		Storage: h.storage,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid sync targets: %w", err)
+	}
+	for i, syncer := range syncers {
+		if _, err := syncer.Sync(spec, commitMsg); err != nil {
+			return fmt.Errorf("%s: %w", targets[i], err)
+		}
+	}
+	if err := sync.SaveBaseline(&aggregate.Apifox, spec); err != nil {
+		log.Printf("⚠️  Could not save the spec for the next diff: %v", err)
+	}
+	return nil
+}
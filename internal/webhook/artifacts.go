@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Artifact is one file in a project's artifacts directory
+type Artifact struct {
+	Path     string    `json:"path"` // relative to the project's artifacts directory
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	URL      string    `json:"url"`
+}
+
+// ListArtifacts lists the files a project has produced: synced documents and
+// analysis reports, Apifox request logs, HTML pages, exports and Postman
+// collections. Query parameters:
+//
+//	prefix  only files below this directory, e.g. apifox/ or html/
+func (h *Handler) ListArtifacts(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	prefix, ok := artifactPath(c.Query("prefix"))
+	if !ok {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("invalid prefix %q", c.Query("prefix")), nil)
+		return
+	}
+
+	root := sync.ProjectDir(project.Team, project.ProjectName)
+	publicURL := strings.TrimSuffix(h.cfg.Server.PublicURL, "/")
+	artifacts := []Artifact{}
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(prefix)), func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, file)
+		artifacts = append(artifacts, Artifact{
+			Path:     filepath.ToSlash(rel),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			URL:      publicURL + sync.PublicPath(file),
+		})
+		return nil
+	})
+	if err != nil {
+		api.Error(c, api.CodeInternal, fmt.Sprintf("failed to list artifacts: %v", err), nil)
+		return
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	c.JSON(200, gin.H{"project": project.ProjectName, "artifacts": artifacts})
+}
+
+// DownloadArtifact returns one file of a project's artifacts directory as an
+// attachment
+func (h *Handler) DownloadArtifact(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	rel, ok := artifactPath(c.Param("path"))
+	if !ok || rel == "" {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("invalid artifact path %q", c.Param("path")), nil)
+		return
+	}
+
+	file := filepath.Join(sync.ProjectDir(project.Team, project.ProjectName), filepath.FromSlash(rel))
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		api.Error(c, api.CodeNotFound, fmt.Sprintf("artifact %s not found", rel), nil)
+		return
+	}
+	c.FileAttachment(file, path.Base(rel))
+}
+
+// artifactPath cleans a path below a project's artifacts directory, rejecting
+// paths that would leave it
+func artifactPath(p string) (string, bool) {
+	p = strings.Trim(p, "/")
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." || strings.Contains(part, "\\") {
+			return "", false
+		}
+	}
+	return path.Clean("/" + p)[1:], true
+}
+
+// AuthorizeDocs limits the files served under /docs to the caller's team:
+// team keys may read teams/{team}/ only, and the artifacts of projects
+// without a team and of the global Apifox settings are left to admins. It runs
+// after api.Authenticate; with authentication disabled everything is served.
+func (h *Handler) AuthorizeDocs(c *gin.Context) {
+	tenant := api.TenantFrom(c)
+	if tenant == nil || tenant.Admin {
+		c.Next()
+		return
+	}
+	// Cleaned like the file server does, so .. can't leave the team's directory
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(c.Request.URL.Path, "/docs")), "/")
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 || parts[0] != "teams" || parts[1] != tenant.Name {
+		api.Error(c, api.CodeNotFound, "", nil)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/github"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/openapi/diff"
+	"fmt"
+	"log"
+)
+
+// reportToGitHub sets the commit status of the analyzed commit and, for pull
+// requests, posts or updates the comment listing the API changes. Skipped
+// runs only resolve the pending status they posted.
+func (h *Handler) reportToGitHub(commit *githubCommit, record *history.Run, report *diff.Report) {
+	if h.github == nil || commit == nil {
+		return
+	}
+	// Skipped runs leave the commit alone, unless they already marked it pending
+	if record.Status == config.RunSkipped {
+		if commit.pending {
+			if err := h.github.SetStatus(commit.Repo, commit.SHA, github.StateSuccess, "Skipped, API docs not synced", ""); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		}
+		return
+	}
+
+	var description string
+	blocked := record.Status == config.RunAborted && report != nil && report.HasBreaking()
+	if commit.PullRequest > 0 {
+		pr := github.PullRequestReport{
+			Project:   record.Project,
+			Base:      commit.Base,
+			Endpoints: record.Endpoints,
+			Report:    report,
+			Blocked:   blocked,
+		}
+		if record.Status != config.RunSuccess && !blocked {
+			pr.Error = record.Error
+		}
+		if err := h.github.UpsertComment(commit.Repo, commit.PullRequest, pr.Comment()); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+		description = pr.StatusDescription()
+	}
+
+	state := github.StateSuccess
+	switch record.Status {
+	case config.RunSuccess:
+		if description == "" {
+			description = fmt.Sprintf("API docs generated: %d endpoints", record.Endpoints)
+			if record.DiffSummary != "" {
+				description += ", " + record.DiffSummary
+			}
+		}
+	case config.RunAborted:
+		state = github.StateFailure
+		if description == "" {
+			description = record.Error
+		}
+	default:
+		state = github.StateError
+		description = record.Error
+	}
+	if err := h.github.SetStatus(commit.Repo, commit.SHA, state, description, ""); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+}
@@ -1,17 +1,31 @@
 package webhook
 
 import (
+	"api-doc-generator/internal/api"
 	"api-doc-generator/internal/config"
 	"api-doc-generator/internal/git"
+	"api-doc-generator/internal/github"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/metrics"
+	"api-doc-generator/internal/notify"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
 	"api-doc-generator/internal/parser"
+	"api-doc-generator/internal/storage"
 	"api-doc-generator/internal/sync"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"log"
-	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,12 +33,27 @@ import (
 type Handler struct {
 	cfg      *config.Config
 	registry *parser.Registry
+	targets  *sync.Registry
+	metrics  *metrics.Pusher
+	projects *config.ProjectConfigManager
+	storage  storage.Storage // nil when documents are only served from the local docs directory
+	runs     history.Store   // nil when run history is disabled
+	jobs     *jobQueue
+	notifier *notify.Notifier
+	github   *github.Client // nil when results are not reported to GitHub
+	// githubApp issues tokens for cloning the repositories a GitHub App is
+	// installed on, nil when no App is configured
+	githubApp *github.App
+	// repoLimiter limits the pushes accepted per repository, nil when unlimited
+	repoLimiter *api.RateLimiter
 }
 
 type GitHubWebhook struct {
 	Ref        string `json:"ref"`
+	After      string `json:"after"` // head commit after the push
 	Repository struct {
 		Name     string `json:"name"`
+		FullName string `json:"full_name"`
 		CloneURL string `json:"clone_url"`
 		SSHURL   string `json:"ssh_url"`
 	} `json:"repository"`
@@ -37,6 +66,26 @@ type GitHubWebhook struct {
 	} `json:"commits"`
 }
 
+// GitHubPullRequestWebhook is the pull_request event payload sent by GitHub
+type GitHubPullRequestWebhook struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+}
+
 type GitLabWebhook struct {
 	Ref     string `json:"ref"`
 	Project struct {
@@ -44,24 +93,189 @@ type GitLabWebhook struct {
 		HTTPURL string `json:"http_url"`
 	} `json:"project"`
 	Commits []struct {
-		ID      string `json:"id"`
-		Message string `json:"message"`
+		ID       string   `json:"id"`
+		Message  string   `json:"message"`
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// GiteeWebhook is the Push Hook payload sent by Gitee
+type GiteeWebhook struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		Name       string `json:"name"`
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"repository"`
+	Commits []struct {
+		ID       string   `json:"id"`
+		Message  string   `json:"message"`
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
 	} `json:"commits"`
 }
 
+// BitbucketWebhook is the repo:push payload sent by Bitbucket Cloud. One push
+// can update several branches, each listed in push.changes.
+type BitbucketWebhook struct {
+	Push struct {
+		Changes []struct {
+			New *struct {
+				Type string `json:"type"` // branch or tag
+				Name string `json:"name"`
+			} `json:"new"` // nil when the branch was deleted
+			Commits []struct {
+				Hash    string `json:"hash"`
+				Message string `json:"message"`
+			} `json:"commits"`
+		} `json:"changes"`
+	} `json:"push"`
+	Repository struct {
+		Name  string `json:"name"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	} `json:"repository"`
+}
+
+// AzureDevOpsWebhook is the git.push service hook payload sent by Azure DevOps
+type AzureDevOpsWebhook struct {
+	EventType string `json:"eventType"`
+	Resource  struct {
+		Commits []struct {
+			CommitID string `json:"commitId"`
+			Comment  string `json:"comment"`
+		} `json:"commits"`
+		RefUpdates []struct {
+			Name        string `json:"name"`
+			NewObjectID string `json:"newObjectId"`
+		} `json:"refUpdates"`
+		Repository struct {
+			Name      string `json:"name"`
+			RemoteURL string `json:"remoteUrl"`
+		} `json:"repository"`
+	} `json:"resource"`
+}
+
+// deletedObjectID is the new object ID of a deleted ref
+const deletedObjectID = "0000000000000000000000000000000000000000"
+
 type ManualTriggerRequest struct {
 	RepositoryURL string `json:"repository_url" binding:"required"`
 	Branch        string `json:"branch"`
-	Language      string `json:"language"` // Optional: force specific parser
+	Language      string `json:"language"`       // Optional: force specific parser
+	AllowBreaking bool   `json:"allow_breaking"` // Sync even if breaking changes are detected
+	DryRun        bool   `json:"dry_run"`        // Return the document and its changes without syncing
+}
+
+// ProjectSyncRequest is the optional body of a project sync
+type ProjectSyncRequest struct {
+	Branch        string `json:"branch"`         // git branch to analyze, default the checked-out branch
+	AllowBreaking bool   `json:"allow_breaking"` // Sync even if breaking changes are detected
+	DryRun        bool   `json:"dry_run"`        // Return the document and its changes without syncing
+}
+
+// allowBreakingMarker in a commit message overrides the breaking-change gate
+const allowBreakingMarker = "[allow-breaking]"
+
+// processOptions carries per-run switches for processRepository
+type processOptions struct {
+	AllowBreaking bool
+	Language      string // force a specific parser instead of detecting one
+	Branch        string // git branch to analyze, empty for the current checkout
+	Trigger       string // history.TriggerPush or history.TriggerManual
+	// FilterPaths skips the run when no changed file matches sync.paths.
+	// ChangedFiles lists the files from the push payload; when nil, the files
+	// changed by the last commit are used.
+	FilterPaths  bool
+	ChangedFiles []string
+	// GitHub is the commit the run reports its status to, nil when the run
+	// was not triggered by GitHub
+	GitHub *githubCommit
+	// Project is the project to sync, instead of the one registered for the
+	// repository URL
+	Project *config.ProjectConfig
+	// DryRun receives the generated document and its changes; when set,
+	// nothing is synced, recorded or reported
+	DryRun *dryRunResult
+}
+
+// dryRunResult is the outcome of a dry run
+type dryRunResult struct {
+	Status   string // the status the run would have: success, failed, skipped or aborted
+	Error    string
+	Spec     *openapi.Spec
+	Report   *diff.Report // nil when no document was synced before
+	Analysis *openapi.AnalysisReport
+	Lint     *lint.Report // nil when the project has no lint config
+}
+
+// githubCommit identifies the GitHub commit, and pull request, a run reports to
+type githubCommit struct {
+	Repo        string // owner/name
+	SHA         string
+	PullRequest int    // 0 for pushes
+	Base        string // branch the pull request merges into
+	pending     bool   // a pending status was posted, which the run must resolve
+}
+
+// queueKey identifies the runs that replace each other in the job queue:
+// pushes to the same branch, or updates of the same pull request
+func (o processOptions) queueKey() string {
+	if o.GitHub != nil && o.GitHub.PullRequest > 0 {
+		return fmt.Sprintf("pull/%d", o.GitHub.PullRequest)
+	}
+	return o.Branch
 }
 
-func NewHandler(cfg *config.Config, registry *parser.Registry) *Handler {
-	return &Handler{
-		cfg:      cfg,
-		registry: registry,
+func NewHandler(cfg *config.Config, registry *parser.Registry, targets *sync.Registry, store storage.Storage, runs history.Store) *Handler {
+	h := &Handler{
+		cfg:         cfg,
+		registry:    registry,
+		targets:     targets,
+		metrics:     metrics.NewPusher(cfg.Metrics.PushURL, cfg.Metrics.PushToken),
+		projects:    newProjectConfigManager(cfg),
+		storage:     store,
+		runs:        runs,
+		notifier:    notify.NewNotifier(),
+		github:      github.NewClient(cfg.GitHub.APIURL, cfg.GitHub.Token, cfg.GitHub.StatusContext),
+		repoLimiter: api.NewRateLimiter(cfg.Webhook.RepoRateLimit),
+	}
+	app, err := github.NewApp(cfg.GitHub.APIURL, cfg.GitHub.AppID, cfg.GitHub.AppPrivateKey)
+	if err != nil {
+		log.Printf("⚠️  GitHub App disabled: %v", err)
+	}
+	h.githubApp = app
+	h.jobs = newJobQueue(cfg.Server.MaxConcurrentSyncs, cfg.Server.PushDebounce, func(job *syncJob) {
+		h.processRepository(job.cloneURL, job.repoName, job.commits, job.opts)
+	})
+	return h
+}
+
+// enqueue schedules a run of processRepository. Runs of one repository are
+// serialized, and a newer push replaces a run of the same branch that has
+// not started yet.
+func (h *Handler) enqueue(cloneURL, repoName string, commits interface{}, opts processOptions) {
+	job := &syncJob{cloneURL: cloneURL, repoName: repoName, commits: commits, opts: opts}
+	if h.jobs.Enqueue(job) {
+		log.Printf("⏭️  Superseded the pending run of %s with a newer push", repoName)
 	}
 }
 
+// allowPush applies the per-repository webhook rate limit and reports whether
+// the push may be processed
+func (h *Handler) allowPush(c *gin.Context, cloneURL string) bool {
+	if !api.CheckRate(c, h.repoLimiter, config.NormalizeRepoURL(cloneURL)) {
+		log.Printf("🚦 Rate limited: too many pushes of %s", cloneURL)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) HandleGitHub(c *gin.Context) {
 	log.Println("📥 Received GitHub webhook")
 
@@ -71,126 +285,923 @@ func (h *Handler) HandleGitHub(c *gin.Context) {
 		body, _ := io.ReadAll(c.Request.Body)
 		if !h.validateGitHubSignature(body, signature) {
 			log.Println("❌ Invalid webhook signature")
-			c.JSON(401, gin.H{"error": "Invalid signature"})
+			api.Error(c, api.CodeInvalidSignature, "", nil)
 			return
 		}
 		// Reset body for JSON binding
 		c.Request.Body = io.NopCloser(strings.NewReader(string(body)))
 	}
 
+	switch c.GetHeader("X-GitHub-Event") {
+	case "", "push":
+	case "pull_request":
+		h.handleGitHubPullRequest(c)
+		return
+	default:
+		c.JSON(200, gin.H{"message": "Ignored: not a push or pull_request event"})
+		return
+	}
+
 	var webhook GitHubWebhook
 	if err := c.ShouldBindJSON(&webhook); err != nil {
 		log.Printf("❌ Invalid webhook payload: %v", err)
-		c.JSON(400, gin.H{"error": err.Error()})
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
 		return
 	}
 
-	// Only process main/master/develop branches
-	if !strings.HasSuffix(webhook.Ref, "/main") &&
-		!strings.HasSuffix(webhook.Ref, "/master") &&
-		!strings.HasSuffix(webhook.Ref, "/develop") {
+	// Only process main/master/develop and branches mapped to an Apifox branch
+	branch := strings.TrimPrefix(webhook.Ref, "refs/heads/")
+	if !h.isTrackedBranch(webhook.Repository.CloneURL, branch) {
 		log.Printf("ℹ️  Ignored: branch %s", webhook.Ref)
 		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
 		return
 	}
 
+	if !h.allowPush(c, webhook.Repository.CloneURL) {
+		return
+	}
+
 	// Process asynchronously
-	go h.processRepository(webhook.Repository.CloneURL, webhook.Repository.Name, webhook.Commits)
+	opts := processOptions{Branch: branch, Trigger: history.TriggerPush, FilterPaths: true}
+	if webhook.Repository.FullName != "" && webhook.After != "" {
+		opts.GitHub = &githubCommit{Repo: webhook.Repository.FullName, SHA: webhook.After}
+	}
+	for _, commit := range webhook.Commits {
+		if strings.Contains(commit.Message, allowBreakingMarker) {
+			opts.AllowBreaking = true
+		}
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Added...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Modified...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Removed...)
+	}
+
+	h.enqueue(webhook.Repository.CloneURL, webhook.Repository.Name, webhook.Commits, opts)
 
 	c.JSON(200, gin.H{"message": "Processing started", "repository": webhook.Repository.Name})
 }
 
+// handleGitHubPullRequest analyzes the head of an opened or updated pull
+// request and reports the API changes on it. Nothing is synced.
+func (h *Handler) handleGitHubPullRequest(c *gin.Context) {
+	var webhook GitHubPullRequestWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		log.Printf("❌ Invalid webhook payload: %v", err)
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	switch webhook.Action {
+	case "opened", "reopened", "synchronize", "ready_for_review":
+	default:
+		c.JSON(200, gin.H{"message": "Ignored: pull request " + webhook.Action})
+		return
+	}
+
+	// Only pull requests into tracked branches are reported on
+	base := webhook.PullRequest.Base.Ref
+	if !h.isTrackedBranch(webhook.Repository.CloneURL, base) {
+		log.Printf("ℹ️  Ignored: pull request into %s", base)
+		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
+		return
+	}
+	if h.github == nil {
+		c.JSON(200, gin.H{"message": "Ignored: GITHUB_TOKEN is not set"})
+		return
+	}
+	if !h.allowPush(c, webhook.Repository.CloneURL) {
+		return
+	}
+
+	opts := processOptions{
+		Branch:  webhook.PullRequest.Head.Ref,
+		Trigger: history.TriggerPullRequest,
+		GitHub: &githubCommit{
+			Repo:        webhook.Repository.FullName,
+			SHA:         webhook.PullRequest.Head.SHA,
+			PullRequest: webhook.Number,
+			Base:        base,
+		},
+	}
+	h.enqueue(webhook.Repository.CloneURL, webhook.Repository.Name, nil, opts)
+
+	c.JSON(200, gin.H{"message": "Processing started", "repository": webhook.Repository.Name, "pull_request": webhook.Number})
+}
+
 func (h *Handler) HandleGitLab(c *gin.Context) {
 	log.Println("📥 Received GitLab webhook")
 
 	var webhook GitLabWebhook
 	if err := c.ShouldBindJSON(&webhook); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
 		return
 	}
 
-	// Only process main/master/develop branches
-	if !strings.HasSuffix(webhook.Ref, "/main") &&
-		!strings.HasSuffix(webhook.Ref, "/master") &&
-		!strings.HasSuffix(webhook.Ref, "/develop") {
+	// Only process main/master/develop and branches mapped to an Apifox branch
+	branch := strings.TrimPrefix(webhook.Ref, "refs/heads/")
+	if !h.isTrackedBranch(webhook.Project.HTTPURL, branch) {
 		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
 		return
 	}
+	if !h.allowPush(c, webhook.Project.HTTPURL) {
+		return
+	}
 
-	go h.processRepository(webhook.Project.HTTPURL, webhook.Project.Name, nil)
+	opts := processOptions{Branch: branch, Trigger: history.TriggerPush, FilterPaths: true}
+	for _, commit := range webhook.Commits {
+		if strings.Contains(commit.Message, allowBreakingMarker) {
+			opts.AllowBreaking = true
+		}
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Added...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Modified...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Removed...)
+	}
+
+	h.enqueue(webhook.Project.HTTPURL, webhook.Project.Name, nil, opts)
 
 	c.JSON(200, gin.H{"message": "Processing started", "repository": webhook.Project.Name})
 }
 
+func (h *Handler) HandleGitee(c *gin.Context) {
+	log.Println("📥 Received Gitee webhook")
+
+	// Validate the webhook password or signing key
+	if !h.validateGiteeToken(c.GetHeader("X-Gitee-Token"), c.GetHeader("X-Gitee-Timestamp")) {
+		log.Println("❌ Invalid webhook token")
+		api.Error(c, api.CodeInvalidSignature, "", nil)
+		return
+	}
+	if event := c.GetHeader("X-Gitee-Event"); event != "" && event != "Push Hook" {
+		c.JSON(200, gin.H{"message": "Ignored: not a push event"})
+		return
+	}
+
+	var webhook GiteeWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		log.Printf("❌ Invalid webhook payload: %v", err)
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	// Only process main/master/develop and branches mapped to an Apifox branch
+	branch := strings.TrimPrefix(webhook.Ref, "refs/heads/")
+	if !h.isTrackedBranch(webhook.Repository.GitHTTPURL, branch) {
+		log.Printf("ℹ️  Ignored: branch %s", webhook.Ref)
+		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
+		return
+	}
+	if !h.allowPush(c, webhook.Repository.GitHTTPURL) {
+		return
+	}
+
+	opts := processOptions{Branch: branch, Trigger: history.TriggerPush, FilterPaths: true}
+	for _, commit := range webhook.Commits {
+		if strings.Contains(commit.Message, allowBreakingMarker) {
+			opts.AllowBreaking = true
+		}
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Added...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Modified...)
+		opts.ChangedFiles = append(opts.ChangedFiles, commit.Removed...)
+	}
+
+	h.enqueue(webhook.Repository.GitHTTPURL, webhook.Repository.Name, webhook.Commits, opts)
+
+	c.JSON(200, gin.H{"message": "Processing started", "repository": webhook.Repository.Name})
+}
+
+func (h *Handler) HandleBitbucket(c *gin.Context) {
+	log.Println("📥 Received Bitbucket webhook")
+
+	// Bitbucket signs the body like GitHub, in X-Hub-Signature
+	body, _ := io.ReadAll(c.Request.Body)
+	if h.cfg.Webhook.Secret != "" && !h.validateGitHubSignature(body, c.GetHeader("X-Hub-Signature")) {
+		log.Println("❌ Invalid webhook signature")
+		api.Error(c, api.CodeInvalidSignature, "", nil)
+		return
+	}
+	c.Request.Body = io.NopCloser(strings.NewReader(string(body)))
+	if event := c.GetHeader("X-Event-Key"); event != "" && event != "repo:push" {
+		c.JSON(200, gin.H{"message": "Ignored: not a push event"})
+		return
+	}
+
+	var webhook BitbucketWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		log.Printf("❌ Invalid webhook payload: %v", err)
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	cloneURL := webhook.Repository.Links.HTML.Href + ".git"
+	var tracked []int // indexes of the changes of tracked branches
+	for i, change := range webhook.Push.Changes {
+		if change.New == nil || change.New.Type != "branch" {
+			continue
+		}
+		// Only process main/master/develop and branches mapped to an Apifox branch
+		if !h.isTrackedBranch(cloneURL, change.New.Name) {
+			log.Printf("ℹ️  Ignored: branch %s", change.New.Name)
+			continue
+		}
+		tracked = append(tracked, i)
+	}
+	if len(tracked) == 0 {
+		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
+		return
+	}
+	// Pushes to untracked branches don't count against the rate limit
+	if !h.allowPush(c, cloneURL) {
+		return
+	}
+
+	var branches []string
+	for _, i := range tracked {
+		change := webhook.Push.Changes[i]
+		opts := processOptions{Branch: change.New.Name, Trigger: history.TriggerPush, FilterPaths: true}
+		for _, commit := range change.Commits {
+			if strings.Contains(commit.Message, allowBreakingMarker) {
+				opts.AllowBreaking = true
+			}
+		}
+		h.enqueue(cloneURL, webhook.Repository.Name, change.Commits, opts)
+		branches = append(branches, change.New.Name)
+	}
+
+	c.JSON(200, gin.H{"message": "Processing started", "repository": webhook.Repository.Name, "branches": branches})
+}
+
+func (h *Handler) HandleAzureDevOps(c *gin.Context) {
+	log.Println("📥 Received Azure DevOps webhook")
+
+	// Azure DevOps service hooks authenticate with basic auth; the secret is the password
+	if h.cfg.Webhook.Secret != "" {
+		_, password, _ := c.Request.BasicAuth()
+		if !hmac.Equal([]byte(password), []byte(h.cfg.Webhook.Secret)) {
+			log.Println("❌ Invalid webhook credentials")
+			api.Error(c, api.CodeInvalidSignature, "", nil)
+			return
+		}
+	}
+
+	var webhook AzureDevOpsWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		log.Printf("❌ Invalid webhook payload: %v", err)
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	if webhook.EventType != "git.push" {
+		c.JSON(200, gin.H{"message": "Ignored: not a push event"})
+		return
+	}
+
+	repo := webhook.Resource.Repository
+	var branches []string
+	for _, ref := range webhook.Resource.RefUpdates {
+		if !strings.HasPrefix(ref.Name, "refs/heads/") || ref.NewObjectID == deletedObjectID {
+			continue
+		}
+		// Only process main/master/develop and branches mapped to an Apifox branch
+		branch := strings.TrimPrefix(ref.Name, "refs/heads/")
+		if !h.isTrackedBranch(repo.RemoteURL, branch) {
+			log.Printf("ℹ️  Ignored: branch %s", ref.Name)
+			continue
+		}
+		branches = append(branches, branch)
+	}
+	if len(branches) == 0 {
+		c.JSON(200, gin.H{"message": "Ignored: not a tracked branch"})
+		return
+	}
+	// Pushes to untracked branches don't count against the rate limit
+	if !h.allowPush(c, repo.RemoteURL) {
+		return
+	}
+
+	opts := processOptions{Trigger: history.TriggerPush, FilterPaths: true}
+	for _, commit := range webhook.Resource.Commits {
+		if strings.Contains(commit.Comment, allowBreakingMarker) {
+			opts.AllowBreaking = true
+		}
+	}
+	for _, branch := range branches {
+		opts.Branch = branch
+		h.enqueue(repo.RemoteURL, repo.Name, webhook.Resource.Commits, opts)
+	}
+
+	c.JSON(200, gin.H{"message": "Processing started", "repository": repo.Name, "branches": branches})
+}
+
 func (h *Handler) ManualTrigger(c *gin.Context) {
 	var req ManualTriggerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
 		return
 	}
 
+	if !h.repositoryAccessible(c, req.RepositoryURL) {
+		return
+	}
 	log.Printf("🔧 Manual trigger for: %s", req.RepositoryURL)
 
 	// Extract repo name from URL
 	parts := strings.Split(strings.TrimSuffix(req.RepositoryURL, ".git"), "/")
 	repoName := parts[len(parts)-1]
 
-	go h.processRepository(req.RepositoryURL, repoName, nil)
+	opts := processOptions{AllowBreaking: req.AllowBreaking, Language: req.Language, Branch: req.Branch, Trigger: history.TriggerManual}
+	if req.DryRun {
+		h.dryRun(c, req.RepositoryURL, repoName, opts)
+		return
+	}
+	h.enqueue(req.RepositoryURL, repoName, nil, opts)
+
+	c.JSON(200, gin.H{
+		"message":    "Processing started",
+		"repository": repoName,
+	})
+}
+
+// SyncProject runs the full pipeline of a registered project with its stored
+// config, as the sync CLI does. Projects without repo_url are analyzed in
+// their local_path, and aggregate projects are rebuilt from their members.
+func (h *Handler) SyncProject(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	var req ProjectSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	log.Printf("🔧 Manual sync of project: %s", project.ProjectName)
+
+	if project.IsAggregate() {
+		if req.DryRun {
+			api.Error(c, api.CodeInvalidRequest, "aggregate projects have no source code to analyze", nil)
+			return
+		}
+		go func() {
+			log.Printf("🧩 Rebuilding aggregate project %s", project.ProjectName)
+			if err := h.syncAggregate(project, extractCommitMessage(nil), req.AllowBreaking); err != nil {
+				log.Printf("❌ Aggregate %s sync failed: %v", project.ProjectName, err)
+				return
+			}
+			log.Printf("✅ Successfully synced aggregate %s", project.ProjectName)
+		}()
+		c.JSON(200, gin.H{"message": "Processing started", "project": project.ProjectName})
+		return
+	}
+
+	source := project.RepoURL
+	if source == "" {
+		source = project.LocalPath
+	}
+	parts := strings.Split(strings.TrimSuffix(filepath.ToSlash(source), ".git"), "/")
+	repoName := parts[len(parts)-1]
+
+	opts := processOptions{AllowBreaking: req.AllowBreaking, Branch: req.Branch, Trigger: history.TriggerManual, Project: project}
+	if req.DryRun {
+		h.dryRun(c, source, repoName, opts)
+		return
+	}
+	h.enqueue(source, repoName, nil, opts)
 
 	c.JSON(200, gin.H{
 		"message":    "Processing started",
+		"project":    project.ProjectName,
 		"repository": repoName,
 	})
 }
 
-func (h *Handler) processRepository(cloneURL, repoName string, commits interface{}) {
+// AnalyzeSync analyzes a repository while the caller waits and responds with
+// the generated OpenAPI document itself. Nothing is synced or recorded. The
+// X-Run-Status header carries the status a sync would have ended with.
+func (h *Handler) AnalyzeSync(c *gin.Context) {
+	var req ManualTriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	if !h.repositoryAccessible(c, req.RepositoryURL) {
+		return
+	}
+	log.Printf("🔧 Inline analysis of: %s", req.RepositoryURL)
+
+	parts := strings.Split(strings.TrimSuffix(req.RepositoryURL, ".git"), "/")
+	repoName := parts[len(parts)-1]
+	result, ok := h.analyzeNow(c, req.RepositoryURL, repoName, processOptions{AllowBreaking: req.AllowBreaking, Language: req.Language, Branch: req.Branch, Trigger: history.TriggerManual})
+	if !ok {
+		return
+	}
+	c.Header("X-Run-Status", result.Status)
+	c.JSON(200, result.Spec)
+}
+
+// dryRun analyzes a repository while the caller waits, and responds with the
+// generated document and its changes against the last synced document
+func (h *Handler) dryRun(c *gin.Context, cloneURL, repoName string, opts processOptions) {
+	result, ok := h.analyzeNow(c, cloneURL, repoName, opts)
+	if !ok {
+		return
+	}
+	response := gin.H{
+		"dry_run":    true,
+		"repository": repoName,
+		"status":     result.Status,
+		"endpoints":  result.Spec.OperationCount(),
+		"schemas":    len(result.Spec.Components.Schemas),
+		"changes":    "",
+		"diff":       []diff.Change{},
+		"analysis":   result.Analysis,
+		"spec":       result.Spec,
+	}
+	if result.Lint != nil {
+		response["lint"] = result.Lint
+	}
+	if opts.Project != nil {
+		response["project"] = opts.Project.ProjectName
+	}
+	if result.Error != "" {
+		response["error"] = result.Error
+	}
+	if result.Report != nil {
+		response["changes"], response["diff"] = result.Report.Summary(), result.Report.Changes
+	}
+	c.JSON(200, response)
+}
+
+// analyzeNow runs processRepository as a dry run, outside the job queue but
+// holding the repository's checkout lock. It responds with an error and
+// returns false when no document was generated.
+func (h *Handler) analyzeNow(c *gin.Context, cloneURL, repoName string, opts processOptions) (*dryRunResult, bool) {
+	result := &dryRunResult{}
+	opts.DryRun = result
+	unlock := h.jobs.Lock(cloneURL)
+	h.processRepository(cloneURL, repoName, nil, opts)
+	unlock()
+
+	if result.Spec == nil {
+		message := result.Error
+		if message == "" {
+			message = fmt.Sprintf("run of %s %s", repoName, result.Status)
+		}
+		api.Error(c, api.CodeAnalysisFailed, message, gin.H{"status": result.Status})
+		return nil, false
+	}
+	return result, true
+}
+
+func (h *Handler) processRepository(cloneURL, repoName string, commits interface{}, opts processOptions) {
 	log.Printf("🔄 Processing repository: %s", repoName)
 
-	// 1. Clone/pull repository
-	gitClient := git.NewClient(h.cfg.Git.WorkDir)
-	repoPath, err := gitClient.CloneOrPull(cloneURL, repoName)
+	// Push per-run metrics to the external TSDB once the run ends
+	started := time.Now()
+	run := metrics.SyncMetrics{Project: repoName, Status: "failed", Timestamp: started}
+	record := &history.Run{Repository: cloneURL, Branch: opts.Branch, Trigger: opts.Trigger, StartedAt: started}
+	var project *config.ProjectConfig
+	var report *diff.Report
+	defer func() {
+		if opts.DryRun != nil {
+			// Dry runs are not recorded or reported
+			opts.DryRun.Status, opts.DryRun.Error = run.Status, record.Error
+			return
+		}
+		run.Duration = time.Since(started)
+		if err := h.metrics.Push(run); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+		h.recordRun(record, run)
+		h.notifyRun(project, record, report)
+		h.reportToGitHub(opts.GitHub, record, report)
+	}()
+
+	// Settings default to the global configuration and are replaced by the
+	// registered project definition when the repository is managed
+	apifoxCfg, parserCfg, syncCfg := &h.cfg.Apifox, h.cfg.Parser, h.cfg.Sync
+	overlayFile := openapi.DefaultOverlayFile
+	project = opts.Project
+	if project == nil {
+		project = h.projects.FindByRepoURL(cloneURL)
+	}
+	if project != nil {
+		log.Printf("📚 Using project config: %s", project.ProjectName)
+		apifoxCfg, parserCfg, syncCfg = &project.Apifox, project.Parser, project.Sync
+		if project.Overlay != "" {
+			overlayFile = project.Overlay
+		}
+		run.Project = project.ProjectName
+	} else if h.cfg.Projects.ConfigRepoURL != "" {
+		log.Printf("ℹ️  Ignored: %s is not a registered project", repoName)
+		run.Status = "skipped"
+		return
+	} else if apifoxCfg.Token == "" && opts.DryRun == nil {
+		log.Printf("❌ %s is not a registered project and no global Apifox token is configured", repoName)
+		record.Error = "not a registered project and no global Apifox token (apifox.token / APIFOX_TOKEN) is configured"
+		return
+	}
+
+	// Skip pushes that touch none of the files the docs are generated from
+	filterPaths := opts.FilterPaths && len(syncCfg.Paths) > 0
+	if filterPaths && opts.ChangedFiles != nil && !git.MatchPaths(syncCfg.Paths, opts.ChangedFiles) {
+		log.Printf("ℹ️  Skipped: none of %d changed file(s) match sync.paths", len(opts.ChangedFiles))
+		run.Status = "skipped"
+		return
+	}
+
+	// 1. Clone/pull repository. ANALYSIS_TIMEOUT covers the clone too, so a
+	// hung remote does not hold the worker
+	ctx, cancel := h.analysisContext()
+	defer cancel()
+	gitClient := h.sourceClient(project, cloneURL).WithContext(ctx)
+	pullRequest := opts.GitHub != nil && opts.GitHub.PullRequest > 0
+	var repoPath string
+	var err error
+	if pullRequest {
+		// Pull requests, including those from forks, are fetched from the base repository
+		repoPath, err = gitClient.CheckoutRef(cloneURL, repoName+"-pulls", fmt.Sprintf("refs/pull/%d/head", opts.GitHub.PullRequest))
+	} else if project != nil && project.RepoURL == "" {
+		// Projects without a repository are analyzed in place
+		repoPath = project.LocalPath
+	} else {
+		repoPath, err = gitClient.CloneOrPullBranch(cloneURL, repoName, opts.Branch)
+	}
 	if err != nil {
+		err = h.timeoutError(err)
 		log.Printf("❌ Git clone/pull failed: %v", err)
+		record.Error = err.Error()
 		return
 	}
+	if commit, err := gitClient.HeadInfo(repoPath); err == nil {
+		record.Commit = commit.Hash
+		if record.Branch == "" {
+			record.Branch = commit.Branch
+		}
+	}
+
+	// Payloads without file lists fall back to the files of the last commit
+	if filterPaths && opts.ChangedFiles == nil {
+		if files, err := gitClient.GetChangedFiles(repoPath); err != nil {
+			log.Printf("⚠️  Could not list changed files, analyzing anyway: %v", err)
+		} else if !git.MatchPaths(syncCfg.Paths, files) {
+			log.Printf("ℹ️  Skipped: none of %d changed file(s) match sync.paths", len(files))
+			run.Status = "skipped"
+			return
+		}
+	}
+	if opts.GitHub != nil {
+		if err := h.github.SetStatus(opts.GitHub.Repo, opts.GitHub.SHA, github.StatePending, "Generating API docs", ""); err != nil {
+			log.Printf("⚠️  %v", err)
+		} else {
+			opts.GitHub.pending = true
+		}
+	}
 
-	// 2. Detect language and select parser
-	language := detectLanguage(repoPath)
-	log.Printf("🔍 Detected language: %s", language)
+	// 2. Detect language and select parser (a configured or requested language wins)
+	language := parserCfg.Language
+	if opts.Language != "" {
+		language = opts.Language
+	}
+	if language == "" {
+		detection, err := h.registry.Detect(repoPath, parserCfg.ExcludeDetectors)
+		if err != nil {
+			log.Printf("⚠️  Language detection failed: %v", err)
+			record.Error = err.Error()
+			return
+		}
+		language = detection.Language
+		log.Printf("🔍 Detected language: %s (confidence %.0f%%: %s)", language, detection.Confidence*100, detection.Reason)
+	} else {
+		log.Printf("🔍 Using configured language: %s", language)
+	}
 
-	p, err := h.registry.Get(language)
+	// The overlay can add webhooks and callbacks whose schemas no route uses,
+	// so unused schemas are pruned once it is applied
+	keepAllSchemas := parserCfg.KeepAllSchemas
+	parserCfg.KeepAllSchemas = true
+	p, err := h.registry.GetWithConfig(language, parserCfg)
 	if err != nil {
 		log.Printf("⚠️  No parser available for: %s", language)
+		record.Error = err.Error()
 		return
 	}
+	p = h.withAnalysisCache(p, repoPath)
 
 	// 3. Analyze code and generate OpenAPI
 	log.Printf("🔍 Analyzing code with %s parser...", p.Name())
-	spec, err := p.Analyze(repoPath)
+	spec, err := h.analyze(ctx, p, func(p parser.Parser) (*openapi.Spec, error) {
+		return p.Analyze(repoPath)
+	})
 	if err != nil {
 		log.Printf("❌ Code analysis failed: %v", err)
+		record.Error = err.Error()
 		return
 	}
+	if err := sync.DescribeOperations(spec, project, repoPath); err != nil {
+		log.Printf("❌ %v", err)
+		record.Error = err.Error()
+		return
+	}
+
+	// Apply overlay committed in the repository, if any
+	overlay, err := openapi.LoadOverlay(filepath.Join(repoPath, overlayFile))
+	if err != nil {
+		log.Printf("⚠️  Ignoring overlay: %v", err)
+	} else if overlay != nil {
+		spec.ApplyOverlay(overlay)
+		log.Printf("🩹 Applied overlay %s", overlayFile)
+	}
+	if project != nil {
+		spec.ApplyExtensions(project.Extensions)
+	}
+	if !keepAllSchemas {
+		spec.PruneSchemas()
+	}
 
 	log.Printf("✅ Generated OpenAPI spec with %d paths", len(spec.Paths))
+	analysis := spec.AnalysisReport()
+	record.Warnings = len(analysis.Warnings)
+	if record.Warnings > 0 || analysis.Guessed+analysis.Untyped > 0 {
+		log.Printf("🔎 Analysis: %s", analysis.Summary())
+	}
+	if opts.DryRun != nil {
+		opts.DryRun.Spec, opts.DryRun.Analysis = spec, analysis
+	}
+	run.Endpoints = spec.OperationCount()
+	run.Schemas = len(spec.Components.Schemas)
+	record.SpecHash = specHash(spec)
 
-	// 4. Sync to Apifox
-	if h.cfg.Apifox.Token == "" || h.cfg.Apifox.ProjectID == "" {
+	// Completeness gate: too many payloads falling back to type: object means inference regressed
+	if syncCfg.MaxUntypedPercent != nil {
+		coverage := spec.Coverage()
+		if err := coverage.CheckCompleteness(*syncCfg.MaxUntypedPercent); err != nil {
+			log.Printf("❌ Sync aborted by completeness gate: %v", err)
+			record.Error = err.Error()
+			for _, c := range coverage.Untyped() {
+				log.Printf("   untyped: %s %s", c.Method, c.Path)
+			}
+			run.Status = "aborted"
+			return
+		}
+	}
+
+	// Governance rules: violations are logged, errors abort the sync
+	if project != nil && project.Lint != nil {
+		lintReport := lint.Run(spec, project.Lint)
+		if opts.DryRun != nil {
+			opts.DryRun.Lint = lintReport
+		}
+		log.Printf("📏 Lint: %s", lintReport.Summary())
+		if len(lintReport.Violations) > 0 {
+			log.Printf("\n%s", lintReport.String())
+		}
+		if lintReport.HasErrors() {
+			log.Printf("❌ Sync aborted by lint rules")
+			record.Error = fmt.Sprintf("lint: %s", lintReport.Summary())
+			run.Status = "aborted"
+			return
+		}
+	}
+
+	// 4. Sync to the configured targets, importing into the Apifox branch mapped to the git branch
+	// Pull requests are compared with the docs of the branch they merge into
+	docBranch := opts.Branch
+	if pullRequest {
+		docBranch = opts.GitHub.Base
+	} else if docBranch == "" && opts.Project != nil {
+		// Project syncs follow the checked-out branch, as the sync CLI does
+		docBranch = record.Branch
+	}
+	apifoxCfg = apifoxCfg.ForGitBranch(docBranch)
+	if apifoxCfg.Branch != "" {
+		log.Printf("🌿 Git branch %s -> Apifox branch %s", docBranch, apifoxCfg.Branch)
+	}
+	targets := []string{config.TargetApifox}
+	if project != nil {
+		targets = project.Targets()
+	}
+	// Registered projects are validated on load; only the global fallback can lack credentials
+	if project == nil && (apifoxCfg.Token == "" || apifoxCfg.ProjectID == "") {
 		log.Println("⚠️  Apifox credentials not configured, skipping sync")
+		run.Status = "skipped"
 		return
 	}
 
-	syncer := sync.NewApifoxSyncer(&h.cfg.Apifox, &h.cfg.Server)
-	commitMsg := extractCommitMessage(commits)
+	// Log a changelog against the previously synced document
+	var breakingErr error
+	if lastSpec, _, err := sync.LoadBaseline(apifoxCfg); err != nil {
+		log.Printf("⚠️  Could not load last synced spec: %v", err)
+	} else if lastSpec != nil {
+		report = diff.Compare(lastSpec, spec)
+		if opts.DryRun != nil {
+			opts.DryRun.Report = report
+		}
+		run.Added, run.Removed, run.Changed = report.Count(diff.Added), report.Count(diff.Removed), report.Count(diff.Changed)
+		run.Breaking = len(report.Breaking())
+		log.Printf("📝 API changes since last sync: %s", report.Summary())
+		record.DiffSummary = report.Summary()
+		if report.HasChanges() {
+			log.Printf("\n%s", report.String())
+		}
+		if report.HasBreaking() && syncCfg.FailOnBreaking && !opts.AllowBreaking {
+			breakingErr = fmt.Errorf("%d breaking change(s) detected (add %s to the commit message to override)",
+				len(report.Breaking()), allowBreakingMarker)
+			// With a staging project the change still reaches staging and the
+			// gate stops the canary before production; other targets are skipped
+			if targets = sync.StagingTargets(targets, project); targets == nil {
+				log.Printf("❌ Sync aborted: %v", breakingErr)
+				record.Error = breakingErr.Error()
+				run.Status = "aborted"
+				return
+			}
+			log.Printf("⚠️  %v; syncing to the staging project only", breakingErr)
+		}
+	}
 
-	log.Printf("📤 Syncing to Apifox...")
-	err = syncer.Sync(spec, commitMsg)
+	// Pull requests only report their changes and dry runs only return them;
+	// the docs are synced by the next push
+	if pullRequest || opts.DryRun != nil {
+		if breakingErr != nil {
+			record.Error = breakingErr.Error()
+			run.Status = "aborted"
+			return
+		}
+		run.Status = "success"
+		if pullRequest {
+			log.Printf("✅ Analyzed pull request #%d of %s", opts.GitHub.PullRequest, repoName)
+		} else {
+			log.Printf("✅ Dry run of %s finished, sync skipped", repoName)
+		}
+		return
+	}
+
+	syncers, err := h.targets.Resolve(targets, &sync.Context{
+		Project:  project,
+		Apifox:   apifoxCfg,
+		Server:   &h.cfg.Server,
+		RepoPath: repoPath,
+		Gates:    []sync.Gate{{Name: "breaking-changes", Check: func(*openapi.Spec) error { return breakingErr }}},
+		Storage:  h.storage,
+	})
 	if err != nil {
-		log.Printf("❌ Apifox sync failed: %v", err)
+		log.Printf("❌ Invalid sync targets: %v", err)
+		record.Error = err.Error()
 		return
 	}
 
-	log.Printf("✅ Successfully synced %s to Apifox", repoName)
+	commitMsg := extractCommitMessage(commits)
+	record.Results = make(map[string]string, len(syncers))
+	for i, syncer := range syncers {
+		log.Printf("📤 Syncing to %s...", targets[i])
+		result, err := syncer.Sync(spec, commitMsg)
+		if err != nil {
+			log.Printf("❌ %s sync failed: %v", targets[i], err)
+			record.Error = fmt.Sprintf("%s: %v", targets[i], err)
+			record.Results[targets[i]] = "failed"
+			return
+		}
+		record.Results[targets[i]] = "ok"
+		if result != nil {
+			record.Results[targets[i]] = result.String()
+		}
+		if result.HasFailures() {
+			log.Printf("⚠️  %s import finished with failures: %s", targets[i], result)
+		} else if result != nil {
+			log.Printf("📊 %s import: %s", targets[i], result)
+		}
+	}
+
+	// The next run diffs against this document, whichever targets it went to
+	if err := sync.SaveBaseline(apifoxCfg, spec); err != nil {
+		log.Printf("⚠️  Could not save the spec for the next diff: %v", err)
+	}
+	run.Status = "success"
+	log.Printf("✅ Successfully synced %s to %s", repoName, strings.Join(targets, ", "))
+
+	if project != nil {
+		h.syncAggregates(project.ProjectName, commitMsg, opts.AllowBreaking)
+	}
+}
+
+// isTrackedBranch reports whether pushes to branch trigger a sync: the default
+// main/master/develop branches, plus any branch the repository's project maps
+// to an Apifox branch
+func (h *Handler) isTrackedBranch(cloneURL, branch string) bool {
+	switch branch {
+	case "main", "master", "develop":
+		return true
+	}
+	if project := h.projects.FindByRepoURL(cloneURL); project != nil {
+		return project.Apifox.MapsGitBranch(branch)
+	}
+	return h.cfg.Apifox.MapsGitBranch(branch)
+}
+
+// gitClient returns a git client with the credentials for cloneURL: the
+// project's git_auth, else an installation token of the GitHub App, else the
+// global GIT_TOKEN / GIT_SSH_KEY when cloneURL is on one of GIT_HOSTS. Other
+// URLs, which may come from anyone calling the analyze API, are cloned
+// anonymously. Registered projects are checked out below
+// GIT_WORK_DIR/projects/{project}, so two projects never share a checkout.
+func (h *Handler) gitClient(project *config.ProjectConfig, cloneURL string) *git.Client {
+	workDir := h.cfg.Git.WorkDir
+	if project != nil {
+		workDir = filepath.Join(workDir, "projects", project.ProjectName)
+	}
+	client := git.NewClient(workDir)
+	if project != nil {
+		auth := git.Auth{Token: project.GitAuth.Token, TokenUser: project.GitAuth.TokenUser, SSHKey: project.GitAuth.SSHKey}
+		if !auth.IsZero() {
+			return client.WithAuth(auth)
+		}
+	}
+	if repo := h.githubApp.Repository(cloneURL); repo != "" {
+		token, err := h.githubApp.Token(repo)
+		if err == nil {
+			return client.WithAuth(git.Auth{Token: token, TokenUser: "x-access-token"})
+		}
+		log.Printf("⚠️  %v", err)
+	}
+	if !h.isGitHost(cloneURL) {
+		return client.WithAuth(git.Anonymous)
+	}
+	return client.WithAuth(git.Auth{Token: h.cfg.Git.Token, TokenUser: h.cfg.Git.TokenUser, SSHKey: h.cfg.Git.SSHKey})
+}
+
+// isGitHost reports whether the global credentials may be sent to the host of cloneURL
+func (h *Handler) isGitHost(cloneURL string) bool {
+	host := git.Host(cloneURL)
+	for _, allowed := range h.cfg.Git.Hosts {
+		if host != "" && strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// withAnalysisCache lets a parser reuse the results of earlier analyses of
+// repoPath, kept per checkout under GIT_WORK_DIR/.analysis-cache
+func (h *Handler) withAnalysisCache(p parser.Parser, repoPath string) parser.Parser {
+	cacheable, ok := p.(parser.Cacheable)
+	if !ok || !h.cfg.Git.AnalysisCache {
+		return p
+	}
+	name, err := filepath.Rel(h.cfg.Git.WorkDir, repoPath)
+	if err != nil || strings.HasPrefix(name, "..") {
+		// Projects analyzed in place (local_path) are told apart by their location
+		abs, _ := filepath.Abs(repoPath)
+		sum := sha256.Sum256([]byte(abs))
+		name = "local-" + filepath.Base(repoPath) + "-" + hex.EncodeToString(sum[:4])
+	}
+	return cacheable.WithCache(filepath.Join(h.cfg.Git.WorkDir, ".analysis-cache", name))
+}
+
+// analyze runs an analysis with p, ending it when ctx is done
+func (h *Handler) analyze(ctx context.Context, p parser.Parser, run func(parser.Parser) (*openapi.Spec, error)) (*openapi.Spec, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return run(p)
+	}
+	spec, err := parser.AnalyzeContext(ctx, p, run)
+	return spec, h.timeoutError(err)
+}
+
+// analysisContext returns the context of one run, from the clone to the end
+// of the analysis, which ends after ANALYSIS_TIMEOUT when set
+func (h *Handler) analysisContext() (context.Context, context.CancelFunc) {
+	if h.cfg.Server.AnalysisTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), h.cfg.Server.AnalysisTimeout)
+}
+
+// timeoutError names ANALYSIS_TIMEOUT in errors caused by it
+func (h *Handler) timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("analysis timed out after %s (ANALYSIS_TIMEOUT)", h.cfg.Server.AnalysisTimeout)
+	}
+	return err
+}
+
+// sourceClient returns the client for checkouts of source code to analyze,
+// which are sparse when the project or GIT_SPARSE_CHECKOUT asks for it. The
+// module files and the overlay file are checked out along with the patterns.
+func (h *Handler) sourceClient(project *config.ProjectConfig, cloneURL string) *git.Client {
+	patterns := h.cfg.Git.SparseCheckout
+	overlayFile := openapi.DefaultOverlayFile
+	if project != nil {
+		if len(project.SparseCheckout) > 0 {
+			patterns = project.SparseCheckout
+		}
+		if project.Overlay != "" {
+			overlayFile = project.Overlay
+		}
+	}
+	client := h.gitClient(project, cloneURL)
+	if len(patterns) == 0 {
+		return client
+	}
+	sparse := append([]string{}, patterns...)
+	sparse = append(sparse, "**/go.mod", "**/go.sum", filepath.ToSlash(filepath.Clean(overlayFile)))
+	return client.WithSparseCheckout(sparse)
 }
 
 func (h *Handler) validateGitHubSignature(body []byte, signature string) bool {
@@ -205,26 +1216,26 @@ func (h *Handler) validateGitHubSignature(body []byte, signature string) bool {
 	return hmac.Equal([]byte(signature), []byte(expectedMAC))
 }
 
-func detectLanguage(repoPath string) string {
-	// Check for Go project
-	if fileExists(repoPath + "/go.mod") {
-		return "go-gin"
+// validateGiteeToken accepts both Gitee authentication modes: a webhook
+// password sent as-is, or a signing key, where the token is
+// base64(HMAC-SHA256(timestamp + "\n" + secret)) keyed by the secret
+func (h *Handler) validateGiteeToken(token, timestamp string) bool {
+	secret := h.cfg.Webhook.Secret
+	if secret == "" {
+		return true
+	}
+	if hmac.Equal([]byte(token), []byte(secret)) {
+		return true
+	}
+	if timestamp == "" {
+		return false
 	}
 
-	// Future: Add detection for other languages
-	// if fileExists(repoPath + "/package.json") {
-	//     return "node-express"
-	// }
-	// if fileExists(repoPath + "/requirements.txt") {
-	//     return "python-fastapi"
-	// }
-
-	return "unknown"
-}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+	return hmac.Equal([]byte(token), []byte(expected))
 }
 
 func extractCommitMessage(commits interface{}) string {
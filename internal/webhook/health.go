@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/health"
+	"context"
+	"time"
+)
+
+// Timeouts of the probes of external services; local probes use the
+// checker's timeout
+const (
+	gitProbeTimeout    = 10 * time.Second
+	apifoxProbeTimeout = 5 * time.Second
+)
+
+// HealthProbes returns the dependency probes of the readiness endpoint. The
+// data and work directories, the run history database and document storage
+// are critical; git remotes and Apifox are external, only degrade the
+// service when unreachable and get longer timeouts.
+func (h *Handler) HealthProbes() []health.Probe {
+	probes := []health.Probe{
+		{Name: "data_dir", Critical: true, Check: health.WritableDir(h.cfg.DataDir)},
+		{Name: "work_dir", Critical: true, Check: health.WritableDir(h.cfg.Git.WorkDir)},
+		{Name: "git", Timeout: gitProbeTimeout, Check: h.checkGit},
+		{Name: "apifox", Timeout: apifoxProbeTimeout, Check: h.checkApifox},
+	}
+	if h.runs != nil {
+		probes = append(probes, health.Probe{Name: "history", Critical: true, Check: func(ctx context.Context) (string, error) {
+			return h.cfg.History.Driver, h.runs.Ping(ctx)
+		}})
+	}
+	if h.storage != nil {
+		probes = append(probes, health.Probe{Name: "storage", Critical: true, Check: func(ctx context.Context) (string, error) {
+			return h.cfg.Storage.Provider, h.storage.Ping(ctx)
+		}})
+	}
+	return probes
+}
+
+// checkGit lists the refs of the configs repository when one is configured.
+// Repositories are cloned in-process, so there is no git binary to check.
+func (h *Handler) checkGit(ctx context.Context) (string, error) {
+	repoURL := h.cfg.Projects.ConfigRepoURL
+	if repoURL == "" {
+		return "in-process client, no configs repository", nil
+	}
+	if err := h.gitClient(nil, repoURL).CheckRemote(repoURL); err != nil {
+		return "", err
+	}
+	return "configs repository reachable", nil
+}
+
+// checkApifox checks that the Apifox API of the global settings answers.
+// Project base URLs are left out: they come from project configs, and
+// probing them would let anyone who can edit a config make the service
+// request arbitrary hosts.
+func (h *Handler) checkApifox(ctx context.Context) (string, error) {
+	if h.cfg.Apifox.BaseURL == "" {
+		return "not configured", nil
+	}
+	return health.Reachable(h.cfg.Apifox.BaseURL)(ctx)
+}
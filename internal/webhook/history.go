@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/metrics"
+	"api-doc-generator/internal/notify"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/sync"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxHistoryLimit = 500
+
+// ListHistory returns the recorded analysis runs of a project, newest first.
+// Query parameters:
+//
+//	status  success, failed, skipped or aborted
+//	since   RFC3339 time or YYYY-MM-DD date
+//	limit   number of runs (default 50, max 500)
+func (h *Handler) ListHistory(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	if h.runs == nil {
+		api.Error(c, api.CodeNotFound, "run history is disabled (HISTORY_DRIVER=none)", nil)
+		return
+	}
+
+	query := history.Query{Status: c.Query("status")}
+	var err error
+	if query.Since, err = parseLogTime(c.Query("since"), false); err != nil {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("invalid since: %v", err), nil)
+		return
+	}
+	query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || query.Limit < 1 || query.Limit > maxHistoryLimit {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("invalid limit %q: expected 1-%d", c.Query("limit"), maxHistoryLimit), nil)
+		return
+	}
+
+	runs, err := h.runs.List(project.ProjectName, query)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	c.JSON(200, gin.H{"project": project.ProjectName, "runs": runs})
+}
+
+// recordRun completes record with the measurements of run and stores it
+func (h *Handler) recordRun(record *history.Run, run metrics.SyncMetrics) {
+	record.Project = run.Project
+	record.Status = run.Status
+	record.Endpoints, record.Schemas = run.Endpoints, run.Schemas
+	record.Added, record.Removed, record.Changed, record.Breaking = run.Added, run.Removed, run.Changed, run.Breaking
+	record.DurationMS = time.Since(record.StartedAt).Milliseconds()
+	if h.runs == nil {
+		return
+	}
+	if err := h.runs.Record(record); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+}
+
+// maxHighlights is the number of changes listed in chat notifications
+const maxHighlights = 10
+
+// notifyRun sends the completed record to the project's callbacks and chat
+// channels. report holds the changes since the last sync, nil when the run
+// ended before comparing.
+func (h *Handler) notifyRun(project *config.ProjectConfig, record *history.Run, report *diff.Report) {
+	if project == nil {
+		return
+	}
+	if len(project.Callbacks) > 0 {
+		h.notifier.Notify(project.Callbacks, notify.Payload{Run: record})
+	}
+	// Chat channels announce synced docs; pull requests are reported on GitHub
+	if len(project.Notifications) > 0 && record.Trigger != history.TriggerPullRequest {
+		summary := notify.Summary{Run: record, Links: h.docLinks(project)}
+		if report != nil {
+			summary.Changes, summary.TotalChanges = report.Highlights(maxHighlights), len(report.Changes)
+		}
+		h.notifier.NotifyChat(project.Notifications, summary)
+	}
+}
+
+// docLinks returns where the project's synced documentation can be viewed
+func (h *Handler) docLinks(project *config.ProjectConfig) []notify.Link {
+	var links []notify.Link
+	if project.HasTarget(config.TargetApifox) && project.Apifox.BaseURL == "https://api.apifox.com" {
+		links = append(links, notify.Link{Title: "Apifox", URL: "https://app.apifox.com/project/" + project.Apifox.ProjectID})
+	}
+	if project.HasTarget(config.TargetHTML) {
+		links = append(links, notify.Link{Title: "HTML docs", URL: strings.TrimSuffix(h.cfg.Server.PublicURL, "/") + sync.PublicPath(filepath.Join(sync.ProjectDir(project.Team, project.ProjectName), "html")) + "/"})
+	}
+	return links
+}
+
+// specHash identifies a generated document; runs that produced the same
+// document have the same hash
+func specHash(spec *openapi.Spec) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSyncLogPageSize = 20
+	maxSyncLogPageSize     = 100
+)
+
+// ListSyncLogs returns the Apifox requests and responses recorded for a project,
+// newest first. Query parameters:
+//
+//	since, until  RFC3339 time or YYYY-MM-DD date
+//	kind          request or response
+//	branch        Apifox branch ID, or main for the main branch only
+//	page          1-based page number (default 1)
+//	page_size     entries per page (default 20, max 100)
+//	body          true to include the full payload sent to / received from Apifox
+func (h *Handler) ListSyncLogs(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+
+	query, page, pageSize, err := parseSyncLogQuery(c)
+	if err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	logs, total, err := sync.QuerySyncLogs(&project.Apifox, query)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	if logs == nil {
+		logs = []sync.SyncLog{}
+	}
+
+	c.JSON(200, gin.H{
+		"project":   project.ProjectName,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"logs":      logs,
+	})
+}
+
+func parseSyncLogQuery(c *gin.Context) (sync.SyncLogQuery, int, int, error) {
+	var query sync.SyncLogQuery
+	var err error
+
+	if query.Since, err = parseLogTime(c.Query("since"), false); err != nil {
+		return query, 0, 0, fmt.Errorf("invalid since: %w", err)
+	}
+	if query.Until, err = parseLogTime(c.Query("until"), true); err != nil {
+		return query, 0, 0, fmt.Errorf("invalid until: %w", err)
+	}
+
+	query.Kind = c.Query("kind")
+	if query.Kind != "" && query.Kind != sync.SyncLogRequest && query.Kind != sync.SyncLogResponse {
+		return query, 0, 0, fmt.Errorf("invalid kind %q: expected request or response", query.Kind)
+	}
+	query.Branch = c.Query("branch")
+	query.IncludeBody = c.Query("body") == "true"
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return query, 0, 0, fmt.Errorf("invalid page %q", c.Query("page"))
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultSyncLogPageSize)))
+	if err != nil || pageSize < 1 {
+		return query, 0, 0, fmt.Errorf("invalid page_size %q", c.Query("page_size"))
+	}
+	pageSize = min(pageSize, maxSyncLogPageSize)
+
+	query.Offset = (page - 1) * pageSize
+	query.Limit = pageSize
+	return query, page, pageSize, nil
+}
+
+// parseLogTime accepts RFC3339 or a date; a date used as an upper bound
+// covers the whole day
+func parseLogTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 time or YYYY-MM-DD date, got %q", value)
+	}
+	if endOfDay {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return day, nil
+}
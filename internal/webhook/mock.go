@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/sync"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeMock answers /mock/{project}/... requests from the project's last synced
+// document, so frontend work can start as soon as the backend routes land.
+// The response is the declared example of the lowest 2xx response, or a
+// placeholder generated from its schema. Send "Prefer: code=404" to get
+// another documented status instead. Projects of other teams are not
+// served; frontends calling the mock from the browser send their API key in
+// X-API-Key, with CORS handled by api.AllowCORS.
+func (h *Handler) ServeMock(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	spec, _, err := sync.LoadLastSyncedSpec(&project.Apifox)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	if spec == nil {
+		api.Error(c, api.CodeSpecNotSynced, "项目尚未同步过文档: "+project.ProjectName, nil)
+		return
+	}
+
+	path := c.Param("path")
+	template, _, ok := spec.Paths.Match(path)
+	if !ok {
+		api.Error(c, api.CodeNotFound, "", gin.H{"path": path})
+		return
+	}
+	pathItem := spec.Paths[template]
+	op := pathItem.Operation(c.Request.Method)
+	if op == nil {
+		if c.Request.Method == "OPTIONS" {
+			// CORS preflight of a documented route
+			c.Header("Access-Control-Allow-Methods", strings.Join(documentedMethods(pathItem), ", "))
+			c.Header("Access-Control-Allow-Headers", c.GetHeader("Access-Control-Request-Headers"))
+			c.Status(204)
+			return
+		}
+		api.Error(c, api.CodeMethodNotAllowed, "", gin.H{"method": c.Request.Method, "allowed": documentedMethods(pathItem)})
+		return
+	}
+
+	status, response, ok := pickMockResponse(op, c.GetHeader("Prefer"))
+	if !ok {
+		api.Error(c, api.CodeNotFound, "接口未声明该响应状态码", gin.H{"prefer": c.GetHeader("Prefer")})
+		return
+	}
+
+	for name, header := range response.Headers {
+		// Keep the real request ID, and don't set placeholder cookies
+		if strings.EqualFold(name, api.RequestIDHeader) || strings.EqualFold(name, "Set-Cookie") {
+			continue
+		}
+		if value := spec.Example(header.Schema); value != nil {
+			c.Header(name, fmt.Sprint(value))
+		}
+	}
+
+	contentType, media, ok := pickMockContent(response)
+	if !ok || c.Request.Method == "HEAD" {
+		c.Status(status)
+		return
+	}
+	body := media.Example
+	if body == nil {
+		body = spec.Example(media.Schema)
+	}
+	if strings.Contains(contentType, "json") {
+		c.JSON(status, body)
+		return
+	}
+	c.Data(status, contentType, []byte(fmt.Sprint(body)))
+}
+
+// pickMockResponse returns the response requested with "Prefer: code=xxx",
+// or else the lowest 2xx response, the default response, or the lowest status
+func pickMockResponse(op *openapi.Operation, prefer string) (int, openapi.Response, bool) {
+	if code := preferredCode(prefer); code != "" {
+		response, ok := op.Responses[code]
+		status, _ := strconv.Atoi(code)
+		return status, response, ok
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			status, _ := strconv.Atoi(code)
+			return status, op.Responses[code], true
+		}
+	}
+	if response, ok := op.Responses["default"]; ok {
+		return 200, response, true
+	}
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil {
+			return status, op.Responses[code], true
+		}
+	}
+	return 200, openapi.Response{}, true
+}
+
+// preferredCode parses the Prefer header, e.g. "code=404"
+func preferredCode(prefer string) string {
+	for _, part := range strings.Split(prefer, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found && strings.TrimSpace(name) == "code" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// pickMockContent prefers application/json over other media types
+func pickMockContent(response openapi.Response) (string, openapi.MediaType, bool) {
+	if media, ok := response.Content["application/json"]; ok {
+		return "application/json", media, true
+	}
+	types := make([]string, 0, len(response.Content))
+	for contentType := range response.Content {
+		types = append(types, contentType)
+	}
+	if len(types) == 0 {
+		return "", openapi.MediaType{}, false
+	}
+	sort.Strings(types)
+	return types[0], response.Content[types[0]], true
+}
+
+func documentedMethods(pathItem openapi.PathItem) []string {
+	var methods []string
+	for _, method := range openapi.Methods {
+		if pathItem.Operation(method) != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
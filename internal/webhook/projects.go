@@ -0,0 +1,374 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/config"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// configRepoName is the checkout directory of the configs repository inside the work dir
+const configRepoName = "_project-configs"
+
+// newProjectConfigManager points the manager at the configs repository
+// checkout when one is configured, otherwise at the local config directory
+func newProjectConfigManager(cfg *config.Config) *config.ProjectConfigManager {
+	configDir := cfg.Projects.ConfigDir
+	if cfg.Projects.ConfigRepoURL != "" {
+		configDir = filepath.Join(cfg.Git.WorkDir, configRepoName, cfg.Projects.ConfigRepoPath)
+	}
+	return config.NewProjectConfigManager(configDir)
+}
+
+// SyncProjectConfigs pulls the configs repository (if configured) and reloads
+// all project definitions. It is called on startup and by HandleConfigRepo.
+func (h *Handler) SyncProjectConfigs() error {
+	if h.cfg.Projects.ConfigRepoURL != "" {
+		gitClient := h.gitClient(nil, h.cfg.Projects.ConfigRepoURL)
+		if _, err := gitClient.CloneOrPull(h.cfg.Projects.ConfigRepoURL, configRepoName); err != nil {
+			return fmt.Errorf("failed to pull config repository: %w", err)
+		}
+	}
+
+	failures, err := h.projects.Reload()
+	if err != nil {
+		return err
+	}
+	for name, err := range failures {
+		log.Printf("⚠️  Skipping project config %s: %v", name, err)
+	}
+	log.Printf("📚 Loaded %d project config(s) from %s", len(h.projects.Loaded()), h.projects.ConfigDir)
+	return nil
+}
+
+// HandleConfigRepo receives push webhooks of the configs repository and reloads projects
+func (h *Handler) HandleConfigRepo(c *gin.Context) {
+	log.Println("📥 Received config repository webhook")
+
+	if h.cfg.Webhook.Secret != "" {
+		body, _ := io.ReadAll(c.Request.Body)
+		signature := c.GetHeader("X-Hub-Signature-256")
+		token := c.GetHeader("X-Gitlab-Token")
+		tokenValid := subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Webhook.Secret)) == 1
+		if !h.validateGitHubSignature(body, signature) && !tokenValid {
+			api.Error(c, api.CodeInvalidSignature, "", nil)
+			return
+		}
+		c.Request.Body = io.NopCloser(strings.NewReader(string(body)))
+	}
+
+	if err := h.SyncProjectConfigs(); err != nil {
+		log.Printf("❌ Project config reload failed: %v", err)
+		api.Error(c, api.CodeConfigReloadFailed, err.Error(), nil)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message":  "Project configs reloaded",
+		"projects": len(h.projects.Loaded()),
+	})
+}
+
+// ListProjectConfigs returns a summary of every registered project. Projects
+// whose config fails to load are listed with the error.
+func (h *Handler) ListProjectConfigs(c *gin.Context) {
+	projects := []map[string]interface{}{}
+	if _, err := os.Stat(h.projects.ConfigDir); os.IsNotExist(err) {
+		c.JSON(200, gin.H{"projects": projects})
+		return
+	}
+	names, err := h.projects.ListProjects()
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	tenant := api.TenantFrom(c)
+	for _, name := range names {
+		info, err := h.projects.GetProjectInfo(name)
+		if err != nil {
+			// The team of a broken config is unknown, so only admins see it
+			if tenant != nil && !tenant.Admin {
+				continue
+			}
+			info = map[string]interface{}{"project_name": name, "error": err.Error()}
+		} else if !tenant.CanAccess(info["team"].(string)) {
+			continue
+		}
+		projects = append(projects, info)
+	}
+	c.JSON(200, gin.H{"projects": projects})
+}
+
+// GetProjectConfig returns the config of a project with secrets masked
+func (h *Handler) GetProjectConfig(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	c.JSON(200, project.Redacted())
+}
+
+// CreateProjectConfig registers a new project. The body is a project config
+// file as stored under PROJECT_CONFIG_DIR.
+func (h *Handler) CreateProjectConfig(c *gin.Context) {
+	if !h.projectConfigsWritable(c) {
+		return
+	}
+	project, ok := bindProjectConfig(c, nil)
+	if !ok {
+		return
+	}
+	if err := config.ValidateProjectName(project.ProjectName); err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	if h.projects.Exists(project.ProjectName) {
+		api.Error(c, api.CodeProjectExists, fmt.Sprintf("project %s already exists", project.ProjectName), nil)
+		return
+	}
+	if !assignTeam(c, project) || !projectSourceAllowed(c, project) {
+		return
+	}
+	if !h.saveProjectConfig(c, project) {
+		return
+	}
+
+	log.Printf("🆕 Project %s registered", project.ProjectName)
+	c.JSON(201, project.Redacted())
+}
+
+// UpdateProjectConfig replaces the config of a project. Secrets sent back as
+// the mask returned by GetProjectConfig keep their stored value.
+func (h *Handler) UpdateProjectConfig(c *gin.Context) {
+	if !h.projectConfigsWritable(c) {
+		return
+	}
+	name := c.Param("name")
+	if !h.projects.Exists(name) || !h.projectAccessible(c, name) {
+		api.Error(c, api.CodeProjectNotFound, fmt.Sprintf("project %s is not registered", name), nil)
+		return
+	}
+	// YAML configs reference their secrets as ${ENV_VAR}; saving would write the values
+	if h.projects.IsYAML(name) {
+		api.Error(c, api.CodeConfigReadOnly, fmt.Sprintf("project %s is defined in %s; edit the file instead", name, h.projects.ConfigPath(name)), nil)
+		return
+	}
+	// A config that no longer loads can still be replaced, but its secrets are lost
+	previous, err := h.projects.LoadProjectConfig(name)
+	if err != nil {
+		previous = nil
+	}
+	project, ok := bindProjectConfig(c, previous)
+	if !ok {
+		return
+	}
+	if project.ProjectName != name {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("project_name %s does not match the project %s; projects cannot be renamed", project.ProjectName, name), nil)
+		return
+	}
+	if !assignTeam(c, project) || !projectSourceAllowed(c, project) {
+		return
+	}
+	if previous != nil {
+		project.KeepSecrets(previous)
+	}
+	if !h.saveProjectConfig(c, project) {
+		return
+	}
+
+	log.Printf("✏️  Project %s updated", name)
+	c.JSON(200, project.Redacted())
+}
+
+// DeleteProjectConfig removes the config of a project. Its generated documents are kept.
+func (h *Handler) DeleteProjectConfig(c *gin.Context) {
+	if !h.projectConfigsWritable(c) {
+		return
+	}
+	name := c.Param("name")
+	if !h.projects.Exists(name) || !h.projectAccessible(c, name) {
+		api.Error(c, api.CodeProjectNotFound, fmt.Sprintf("project %s is not registered", name), nil)
+		return
+	}
+	if err := h.projects.DeleteProjectConfig(name); err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+
+	log.Printf("🗑️  Project %s deleted", name)
+	c.JSON(200, gin.H{"message": "Project deleted", "project": name})
+}
+
+// projectConfigsWritable rejects changes when the configs come from the configs
+// repository, since the next pull would overwrite them
+func (h *Handler) projectConfigsWritable(c *gin.Context) bool {
+	if h.cfg.Projects.ConfigRepoURL != "" {
+		api.Error(c, api.CodeConfigReadOnly, fmt.Sprintf("project configs are managed in %s; commit the change there", h.cfg.Projects.ConfigRepoURL), nil)
+		return false
+	}
+	return true
+}
+
+// loadProject loads the project named in the path. Projects of other teams are
+// reported as not registered, so an API key does not reveal which projects
+// other teams have.
+func (h *Handler) loadProject(c *gin.Context) (*config.ProjectConfig, bool) {
+	name := c.Param("name")
+	if !h.projectAccessible(c, name) {
+		api.Error(c, api.CodeProjectNotFound, fmt.Sprintf("project %s is not registered", name), nil)
+		return nil, false
+	}
+	project, err := h.projects.LoadProjectConfig(name)
+	if err != nil {
+		api.Error(c, api.CodeProjectNotFound, err.Error(), nil)
+		return nil, false
+	}
+	return project, true
+}
+
+// projectAccessible reports whether the caller's team may access the project.
+// Configs that fail to load have no known team and are left to admins.
+func (h *Handler) projectAccessible(c *gin.Context, name string) bool {
+	tenant := api.TenantFrom(c)
+	if tenant == nil || tenant.Admin {
+		return true
+	}
+	project, err := h.projects.LoadProjectConfig(name)
+	return err == nil && tenant.CanAccess(project.Team)
+}
+
+// assignTeam puts projects created or updated with a team API key into that
+// team. Only admins may set another team or leave a project without one.
+func assignTeam(c *gin.Context, project *config.ProjectConfig) bool {
+	tenant := api.TenantFrom(c)
+	if tenant == nil || tenant.Admin {
+		return true
+	}
+	if project.Team == "" {
+		project.Team = tenant.Name
+	}
+	if project.Team != tenant.Name {
+		api.Error(c, api.CodeForbidden, fmt.Sprintf("team %s cannot assign projects to team %s", tenant.Name, project.Team), nil)
+		return false
+	}
+	return true
+}
+
+// projectSourceAllowed keeps projects of team API keys off the server's file
+// system. local_path and local repositories are analyzed in place, so they
+// could point at another team's checkout or at the project configs, and
+// export templates would render them. Team projects are cloned from a
+// remote repo_url instead; local_path is then only used by the CLI.
+func projectSourceAllowed(c *gin.Context, project *config.ProjectConfig) bool {
+	tenant := api.TenantFrom(c)
+	if tenant == nil || tenant.Admin || project.Type == config.ProjectTypeAggregate {
+		return true
+	}
+	if project.RepoURL == "" {
+		api.Error(c, api.CodeForbidden, "projects of team API keys need a remote repo_url; only admin API keys may register projects analyzed from local_path", nil)
+		return false
+	}
+	if !config.IsRemoteRepoURL(project.RepoURL) {
+		api.Error(c, api.CodeForbidden, fmt.Sprintf("repo_url %s is not a remote repository; only admin API keys may register local repositories", project.RepoURL), nil)
+		return false
+	}
+	return true
+}
+
+// repositoryAccessible limits manual runs with a team API key to the team's
+// registered projects; an unregistered repository would be synced with the
+// global Apifox settings
+func (h *Handler) repositoryAccessible(c *gin.Context, repoURL string) bool {
+	tenant := api.TenantFrom(c)
+	if tenant == nil || tenant.Admin {
+		return true
+	}
+	if project := h.projects.FindByRepoURL(repoURL); project != nil && project.Team == tenant.Name {
+		return true
+	}
+	api.Error(c, api.CodeProjectNotFound, fmt.Sprintf("%s is not a registered project of team %s", repoURL, tenant.Name), nil)
+	return false
+}
+
+// bindProjectConfig reads a project config from the request body, checked
+// against the same JSON Schema as config files. previous is the stored config
+// the body replaces, nil for new projects.
+func bindProjectConfig(c *gin.Context, previous *config.ProjectConfig) (*config.ProjectConfig, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return nil, false
+	}
+	if err := config.ValidateProjectConfigJSON(body); err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return nil, false
+	}
+	// ${VAR} is only expanded in YAML files on disk; a submitted config must
+	// not read the server's environment
+	envRefs, err := config.EnvRefPaths(body)
+	if err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return nil, false
+	}
+	if len(envRefs) > 0 {
+		api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("environment variable references are not allowed in submitted configs (%s)", strings.Join(envRefs, ", ")), nil)
+		return nil, false
+	}
+	// References are resolved with the server's environment and secret
+	// stores. Those of the stored config, shown by GetProjectConfig, may be
+	// sent back unchanged.
+	if tenant := api.TenantFrom(c); tenant == nil || !tenant.Admin {
+		refs, err := config.SecretRefPaths(body)
+		if err != nil {
+			api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+			return nil, false
+		}
+		var paths []string
+		for path, ref := range refs {
+			if previous == nil || !previous.UsesSecretRef(ref) {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			sort.Strings(paths)
+			api.Error(c, api.CodeForbidden, fmt.Sprintf("only admin API keys may submit secret references (%s)", strings.Join(paths, ", ")), nil)
+			return nil, false
+		}
+	}
+	var project config.ProjectConfig
+	if err := json.Unmarshal(body, &project); err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return nil, false
+	}
+	return &project, true
+}
+
+func (h *Handler) saveProjectConfig(c *gin.Context, project *config.ProjectConfig) bool {
+	if err := h.projects.Validate(project); err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return false
+	}
+	// Webhooks and manual runs find the project by its repository
+	if owner := h.projects.RepoURLOwner(project); owner != nil {
+		message := fmt.Sprintf("repository %s is already registered by another project", project.RepoURL)
+		if api.TenantFrom(c).CanAccess(owner.Team) {
+			message = fmt.Sprintf("repository %s is already registered by project %s", project.RepoURL, owner.ProjectName)
+		}
+		api.Error(c, api.CodeRepoRegistered, message, nil)
+		return false
+	}
+	if err := h.projects.SaveProjectConfig(project); err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return false
+	}
+	return true
+}
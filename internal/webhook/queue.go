@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/config"
+	"context"
+	"sync"
+	"time"
+)
+
+// syncJob is one requested run of processRepository
+type syncJob struct {
+	cloneURL string
+	repoName string
+	commits  interface{}
+	opts     processOptions
+}
+
+// jobQueue runs sync jobs with a global concurrency limit. Jobs of the same
+// repository share a checkout, so they run one at a time. A job waiting for
+// its repository is replaced by a newer push to the same branch, so a burst
+// of pushes results in one run of the latest commit.
+type jobQueue struct {
+	slots    chan struct{}
+	debounce time.Duration
+	run      func(job *syncJob)
+
+	mu       sync.Mutex
+	repos    map[string]*repoJobs
+	locks    map[string]*sync.Mutex
+	running  map[*syncJob]struct{}
+	stopping bool // set by Shutdown: no further jobs are started
+	active   sync.WaitGroup
+}
+
+// repoJobs holds the jobs of one repository, at most one per branch or pull request
+type repoJobs struct {
+	draining bool
+	pending  []*syncJob
+}
+
+func newJobQueue(maxConcurrent int, debounce time.Duration, run func(job *syncJob)) *jobQueue {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &jobQueue{
+		slots:    make(chan struct{}, maxConcurrent),
+		debounce: debounce,
+		run:      run,
+		repos:    make(map[string]*repoJobs),
+		locks:    make(map[string]*sync.Mutex),
+		running:  make(map[*syncJob]struct{}),
+	}
+}
+
+// Enqueue schedules job and reports whether it replaced a pending job
+func (q *jobQueue) Enqueue(job *syncJob) bool {
+	key := config.NormalizeRepoURL(job.cloneURL)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	repo := q.repos[key]
+	if repo == nil {
+		repo = &repoJobs{}
+		q.repos[key] = repo
+	}
+
+	superseded := false
+	for i, pending := range repo.pending {
+		if pending.opts.queueKey() == job.opts.queueKey() {
+			repo.pending[i] = mergeJobs(pending, job)
+			superseded = true
+			break
+		}
+	}
+	if !superseded {
+		repo.pending = append(repo.pending, job)
+	}
+	if !repo.draining && !q.stopping {
+		repo.draining = true
+		go q.drain(key, repo)
+	}
+	return superseded
+}
+
+// mergeJobs folds an older pending push into a newer one. The newer commit is
+// analyzed, but it must still cover the files and overrides of the older push.
+func mergeJobs(older, newer *syncJob) *syncJob {
+	merged := *newer
+	merged.opts.AllowBreaking = older.opts.AllowBreaking || newer.opts.AllowBreaking
+	merged.opts.FilterPaths = older.opts.FilterPaths && newer.opts.FilterPaths
+	if older.opts.ChangedFiles == nil || newer.opts.ChangedFiles == nil {
+		merged.opts.ChangedFiles = nil
+	} else {
+		merged.opts.ChangedFiles = append(append([]string{}, older.opts.ChangedFiles...), newer.opts.ChangedFiles...)
+	}
+	return &merged
+}
+
+// drain runs the pending jobs of one repository in order until none are left
+func (q *jobQueue) drain(key string, repo *repoJobs) {
+	for {
+		// Give follow-up pushes a moment to replace the pending job
+		time.Sleep(q.debounce)
+
+		q.mu.Lock()
+		if len(repo.pending) == 0 {
+			repo.draining = false
+			delete(q.repos, key)
+			q.mu.Unlock()
+			return
+		}
+		if q.stopping {
+			// Pending jobs are left for Shutdown to hand back
+			repo.draining = false
+			q.mu.Unlock()
+			return
+		}
+		job := repo.pending[0]
+		q.mu.Unlock()
+
+		q.slots <- struct{}{}
+		unlock := q.Lock(job.cloneURL)
+		q.mu.Lock()
+		if q.stopping {
+			repo.draining = false
+			q.mu.Unlock()
+			unlock()
+			<-q.slots
+			return
+		}
+		// The job may have been replaced by a newer push while waiting for a slot
+		job = repo.pending[0]
+		repo.pending = repo.pending[1:]
+		q.running[job] = struct{}{}
+		q.active.Add(1)
+		q.mu.Unlock()
+
+		q.run(job)
+
+		q.mu.Lock()
+		delete(q.running, job)
+		q.mu.Unlock()
+		q.active.Done()
+		unlock()
+		<-q.slots
+	}
+}
+
+// Shutdown stops starting jobs and waits until the running jobs finish or ctx
+// ends. It returns the jobs that did not run: those still pending, and those
+// interrupted because they were running when ctx ended.
+func (q *jobQueue) Shutdown(ctx context.Context) (pending, interrupted []*syncJob) {
+	q.mu.Lock()
+	q.stopping = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for job := range q.running {
+		interrupted = append(interrupted, job)
+	}
+	for _, repo := range q.repos {
+		pending = append(pending, repo.pending...)
+	}
+	return pending, interrupted
+}
+
+// Lock takes the checkout lock of a repository, for work outside the queue
+// that uses the same checkout. It returns the function that releases it.
+func (q *jobQueue) Lock(cloneURL string) func() {
+	key := config.NormalizeRepoURL(cloneURL)
+	q.mu.Lock()
+	lock := q.locks[key]
+	if lock == nil {
+		lock = &sync.Mutex{}
+		q.locks[key] = lock
+	}
+	q.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// projectConfigReloadDelay batches the file events of one change (editors and
+// git checkouts write several files) into a single reload
+const projectConfigReloadDelay = 500 * time.Millisecond
+
+// WatchProjectConfigs reloads the project configs whenever a file in the
+// config directory is created, changed or removed, so adding or editing a
+// project does not need a restart. Watching stops when stop is closed.
+func (h *Handler) WatchProjectConfigs(stop <-chan struct{}) error {
+	dir := h.projects.ConfigDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	log.Printf("👀 Watching %s for project config changes", dir)
+
+	go func() {
+		defer watcher.Close()
+		var reload <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				reload = time.After(projectConfigReloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("⚠️  Project config watch error: %v", err)
+			case <-reload:
+				reload = nil
+				h.reloadProjectConfigs()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadProjectConfigs reloads the config directory and logs the projects
+// that were added, removed or failed to load
+func (h *Handler) reloadProjectConfigs() {
+	before := make(map[string]bool)
+	for _, project := range h.projects.Loaded() {
+		before[project.ProjectName] = true
+	}
+
+	failures, err := h.projects.Reload()
+	if err != nil {
+		log.Printf("❌ Project config reload failed: %v", err)
+		return
+	}
+	for name, err := range failures {
+		log.Printf("⚠️  Skipping project config %s: %v", name, err)
+	}
+
+	loaded := h.projects.Loaded()
+	for _, project := range loaded {
+		if !before[project.ProjectName] {
+			log.Printf("🆕 Project %s loaded", project.ProjectName)
+		}
+		delete(before, project.ProjectName)
+	}
+	for name := range before {
+		if _, failed := failures[name]; !failed {
+			log.Printf("🗑️  Project %s removed", name)
+		}
+	}
+	log.Printf("🔄 Reloaded %d project config(s) from %s", len(loaded), h.projects.ConfigDir)
+}
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/config"
+	"api-doc-generator/internal/history"
+	"api-doc-generator/internal/metrics"
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/internal/openapi/diff"
+	"api-doc-generator/internal/openapi/lint"
+	"api-doc-generator/internal/parser"
+	"api-doc-generator/internal/sync"
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyzePrefix re-analyzes only the routes under ?prefix= and patches them
+// into the project's last synced document, which is then synced to the
+// project's targets. Unlike a push, it answers when the sync has finished.
+// Query parameters:
+//
+//	prefix          path prefix to refresh, e.g. /users (required)
+//	branch          git branch to analyze, default the current checkout
+//	allow_breaking  true to sync despite breaking changes
+func (h *Handler) AnalyzePrefix(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	prefix := c.Query("prefix")
+	if prefix == "" {
+		api.Error(c, api.CodeInvalidRequest, "prefix is required", nil)
+		return
+	}
+	prefix = openapi.NormalizePath(prefix)
+	if project.IsAggregate() {
+		api.Error(c, api.CodeInvalidRequest, "aggregate projects have no source code to analyze", nil)
+		return
+	}
+	branch := c.Query("branch")
+
+	apifoxCfg := project.Apifox.ForGitBranch(branch)
+	base, _, err := sync.LoadBaseline(apifoxCfg)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	if base == nil {
+		api.Error(c, api.CodeSpecNotSynced, "run a full sync of "+project.ProjectName+" first", nil)
+		return
+	}
+
+	// Pushes of the same repository use the same checkout
+	if project.RepoURL != "" {
+		unlock := h.jobs.Lock(project.RepoURL)
+		defer unlock()
+	}
+	ctx, cancel := h.analysisContext()
+	defer cancel()
+	repoPath, err := h.projectCheckout(ctx, project, branch)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	p, err := h.projectParser(project, repoPath)
+	if err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	if _, ok := p.(parser.PrefixAnalyzer); !ok {
+		api.Error(c, api.CodeInvalidRequest, p.Name()+" does not support scoped analysis", nil)
+		return
+	}
+
+	log.Printf("🎯 Re-analyzing %s%s", project.ProjectName, prefix)
+	started := time.Now()
+	run := metrics.SyncMetrics{Project: project.ProjectName, Status: "failed", Timestamp: started}
+	record := &history.Run{Repository: project.RepoURL, Branch: branch, Trigger: history.TriggerScoped, StartedAt: started}
+	if record.Repository == "" {
+		record.Repository = project.LocalPath
+	}
+	var report *diff.Report
+	defer func() {
+		h.recordRun(record, run)
+		h.notifyRun(project, record, report)
+	}()
+
+	partial, err := h.analyze(ctx, p, func(p parser.Parser) (*openapi.Spec, error) {
+		return p.(parser.PrefixAnalyzer).AnalyzePrefix(repoPath, prefix)
+	})
+	if err == nil {
+		// Only the new operations: those of base were described when synced
+		err = sync.DescribeOperations(partial, project, repoPath)
+	}
+	if err != nil {
+		record.Error = err.Error()
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	spec := base
+	removed := spec.PatchPrefix(prefix, partial)
+	if removed == nil {
+		removed = []string{}
+	}
+	overlayFile := openapi.DefaultOverlayFile
+	if project.Overlay != "" {
+		overlayFile = project.Overlay
+	}
+	if overlay, err := openapi.LoadOverlay(filepath.Join(repoPath, overlayFile)); err != nil {
+		log.Printf("⚠️  Ignoring overlay: %v", err)
+	} else if overlay != nil {
+		spec.ApplyOverlay(overlay)
+	}
+	spec.ApplyExtensions(project.Extensions)
+	if !project.Parser.KeepAllSchemas {
+		spec.PruneSchemas()
+	}
+
+	// The same breaking-change check as a full sync; base was patched in
+	// place, so compare against a fresh copy
+	previous, _, _ := sync.LoadBaseline(apifoxCfg)
+	report = diff.Compare(previous, spec)
+	log.Printf("📝 API changes under %s: %s", prefix, report.Summary())
+	run.Endpoints, run.Schemas = spec.OperationCount(), len(spec.Components.Schemas)
+	run.Added, run.Removed, run.Changed = report.Count(diff.Added), report.Count(diff.Removed), report.Count(diff.Changed)
+	run.Breaking = len(report.Breaking())
+	record.SpecHash, record.DiffSummary = specHash(spec), report.Summary()
+	if report.HasBreaking() && project.Sync.FailOnBreaking && c.Query("allow_breaking") != "true" {
+		var changes []string
+		for _, change := range report.Breaking() {
+			changes = append(changes, change.String())
+		}
+		run.Status = "aborted"
+		record.Error = fmt.Sprintf("%d breaking change(s) detected", len(changes))
+		api.Error(c, api.CodeBreakingChanges, "", gin.H{"breaking": changes})
+		return
+	}
+	if project.Lint != nil {
+		lintReport := lint.Run(spec, project.Lint)
+		log.Printf("📏 Lint: %s", lintReport.Summary())
+		if lintReport.HasErrors() {
+			run.Status = "aborted"
+			record.Error = fmt.Sprintf("lint: %s", lintReport.Summary())
+			api.Error(c, api.CodeLintFailed, "", gin.H{"violations": lintReport.Violations})
+			return
+		}
+	}
+
+	targets := project.Targets()
+	syncers, err := h.targets.Resolve(targets, &sync.Context{
+		Project:  project,
+		Apifox:   apifoxCfg,
+		Server:   &h.cfg.Server,
+		RepoPath: repoPath,
+		Storage:  h.storage,
+	})
+	if err != nil {
+		record.Error = err.Error()
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	results := make(map[string]string, len(syncers))
+	record.Results = results
+	for i, syncer := range syncers {
+		result, err := syncer.Sync(spec, "Refresh "+prefix)
+		if err != nil {
+			record.Error = targets[i] + ": " + err.Error()
+			results[targets[i]] = "failed"
+			api.Error(c, api.CodeSyncFailed, targets[i]+": "+err.Error(), gin.H{"synced": results})
+			return
+		}
+		results[targets[i]] = "ok"
+		if result != nil {
+			results[targets[i]] = result.String()
+		}
+	}
+	if err := sync.SaveBaseline(apifoxCfg, spec); err != nil {
+		log.Printf("⚠️  Could not save the spec for the next diff: %v", err)
+	}
+	run.Status = "success"
+	log.Printf("✅ Refreshed %s%s in %s", project.ProjectName, prefix, strings.Join(targets, ", "))
+
+	c.JSON(200, gin.H{
+		"project": project.ProjectName,
+		"prefix":  prefix,
+		"paths":   partial.Paths.Keys(),
+		"removed": removed,
+		"changes": report.Summary(),
+		"targets": results,
+	})
+}
+
+// projectCheckout returns the source of a project: a fresh pull of its
+// repository when repo_url is set, its local_path otherwise
+func (h *Handler) projectCheckout(ctx context.Context, project *config.ProjectConfig, branch string) (string, error) {
+	if project.RepoURL == "" {
+		return project.LocalPath, nil
+	}
+	parts := strings.Split(strings.TrimSuffix(project.RepoURL, ".git"), "/")
+	repoPath, err := h.sourceClient(project, project.RepoURL).WithContext(ctx).CloneOrPullBranch(project.RepoURL, parts[len(parts)-1], branch)
+	return repoPath, h.timeoutError(err)
+}
+
+// projectParser returns the project's configured parser, detecting the language when unset
+func (h *Handler) projectParser(project *config.ProjectConfig, repoPath string) (parser.Parser, error) {
+	language := project.Parser.Language
+	if language == "" {
+		detection, err := h.registry.Detect(repoPath, project.Parser.ExcludeDetectors)
+		if err != nil {
+			return nil, err
+		}
+		language = detection.Language
+	}
+	// Callers prune unused schemas after applying the overlay
+	parserCfg := project.Parser
+	parserCfg.KeepAllSchemas = true
+	p, err := h.registry.GetWithConfig(language, parserCfg)
+	if err != nil {
+		return nil, err
+	}
+	return h.withAnalysisCache(p, repoPath), nil
+}
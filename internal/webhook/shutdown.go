@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// pendingJobsFile in the data directory holds the jobs that did not run before
+// the last shutdown
+const pendingJobsFile = "pending-jobs.json"
+
+// savedJob is a sync job written to pendingJobsFile
+type savedJob struct {
+	CloneURL      string          `json:"clone_url"`
+	RepoName      string          `json:"repo_name"`
+	Commits       json.RawMessage `json:"commits,omitempty"`
+	Project       string          `json:"project,omitempty"` // project synced by name, instead of the one registered for the repository
+	AllowBreaking bool            `json:"allow_breaking,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	Branch        string          `json:"branch,omitempty"`
+	Trigger       string          `json:"trigger,omitempty"`
+	FilterPaths   bool            `json:"filter_paths,omitempty"`
+	ChangedFiles  []string        `json:"changed_files,omitempty"`
+	GitHub        *githubCommit   `json:"github,omitempty"`
+	// Interrupted is set for jobs that were running when the service stopped
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// Shutdown stops starting queued jobs and waits for the running ones until ctx
+// ends. Jobs still queued, and jobs cut off by the deadline, are saved and run
+// again by ResumeJobs after the next start.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	pending, interrupted := h.jobs.Shutdown(ctx)
+	if len(pending) == 0 && len(interrupted) == 0 {
+		return nil
+	}
+
+	saved := make([]savedJob, 0, len(pending)+len(interrupted))
+	for _, job := range interrupted {
+		log.Printf("⚠️  Interrupted the run of %s, it is retried after a restart", job.repoName)
+		saved = append(saved, saveJob(job, true))
+	}
+	for _, job := range pending {
+		saved = append(saved, saveJob(job, false))
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(h.cfg.DataDir, pendingJobsFile)
+	if err := os.MkdirAll(h.cfg.DataDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save %d pending job(s): %w", len(saved), err)
+	}
+	log.Printf("💾 Saved %d pending job(s) to %s", len(saved), path)
+	return nil
+}
+
+// ResumeJobs queues the jobs saved by the last Shutdown
+func (h *Handler) ResumeJobs() error {
+	path := filepath.Join(h.cfg.DataDir, pendingJobsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved []savedJob
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+	// Removed first, so a job that crashes the service is not retried forever
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	for _, s := range saved {
+		opts := processOptions{
+			AllowBreaking: s.AllowBreaking,
+			Language:      s.Language,
+			Branch:        s.Branch,
+			Trigger:       s.Trigger,
+			FilterPaths:   s.FilterPaths,
+			ChangedFiles:  s.ChangedFiles,
+			GitHub:        s.GitHub,
+		}
+		if s.Project != "" {
+			project, err := h.projects.LoadProjectConfig(s.Project)
+			if err != nil {
+				log.Printf("⚠️  Dropped the saved run of %s: %v", s.RepoName, err)
+				continue
+			}
+			opts.Project = project
+		}
+		var commits interface{}
+		if len(s.Commits) > 0 {
+			commits = s.Commits
+		}
+		if s.Interrupted {
+			log.Printf("🔁 Retrying the interrupted run of %s", s.RepoName)
+		}
+		h.enqueue(s.CloneURL, s.RepoName, commits, opts)
+	}
+	log.Printf("📋 Resumed %d job(s) saved at the last shutdown", len(saved))
+	return nil
+}
+
+func saveJob(job *syncJob, interrupted bool) savedJob {
+	s := savedJob{
+		CloneURL:      job.cloneURL,
+		RepoName:      job.repoName,
+		AllowBreaking: job.opts.AllowBreaking,
+		Language:      job.opts.Language,
+		Branch:        job.opts.Branch,
+		Trigger:       job.opts.Trigger,
+		FilterPaths:   job.opts.FilterPaths,
+		ChangedFiles:  job.opts.ChangedFiles,
+		GitHub:        job.opts.GitHub,
+		Interrupted:   interrupted,
+	}
+	if job.commits != nil {
+		s.Commits, _ = json.Marshal(job.commits)
+	}
+	if job.opts.Project != nil {
+		s.Project = job.opts.Project.ProjectName
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/sync"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectSpec returns the project's latest synced OpenAPI document at a
+// stable URL, as JSON (openapi.json) or YAML (openapi.yaml), so gateways and
+// developer portals can read it instead of the timestamped files under /docs.
+// Query parameters:
+//
+//	branch  git branch whose document is returned, for branches mapped in
+//	        apifox.git_branches (default: the main document)
+func (h *Handler) GetProjectSpec(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := h.loadProject(c)
+		if !ok {
+			return
+		}
+		apifoxCfg := &project.Apifox
+		if branch := c.Query("branch"); branch != "" {
+			if !apifoxCfg.MapsGitBranch(branch) {
+				api.Error(c, api.CodeInvalidRequest, fmt.Sprintf("branch %s is not mapped in apifox.git_branches", branch), nil)
+				return
+			}
+			apifoxCfg = apifoxCfg.ForGitBranch(branch)
+		}
+
+		spec, path, err := sync.LoadLastSyncedSpec(apifoxCfg)
+		if err != nil {
+			api.Error(c, api.CodeInternal, err.Error(), nil)
+			return
+		}
+		if spec == nil {
+			api.Error(c, api.CodeSpecNotSynced, "项目尚未同步过文档: "+project.ProjectName, nil)
+			return
+		}
+
+		// JSON is the synced file byte for byte, YAML is converted from it
+		info, err := os.Stat(path)
+		if err != nil {
+			api.Error(c, api.CodeInternal, err.Error(), nil)
+			return
+		}
+		data, err := os.ReadFile(path)
+		contentType := "application/json; charset=utf-8"
+		if err == nil && format == "yaml" {
+			data, err = spec.ToYAML()
+			contentType = "application/yaml; charset=utf-8"
+		}
+		if err != nil {
+			api.Error(c, api.CodeInternal, err.Error(), nil)
+			return
+		}
+		serveDocument(c, data, contentType, info.ModTime())
+	}
+}
+
+// serveDocument answers with data, or 304 Not Modified when the client's
+// If-None-Match or If-Modified-Since shows it already has this version
+func serveDocument(c *gin.Context, data []byte, contentType string, modified time.Time) {
+	sum := sha256.Sum256(data)
+	c.Header("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", contentType)
+	http.ServeContent(c.Writer, c.Request, "", modified, bytes.NewReader(data))
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tryItTimeout bounds the wait for the upstream's response headers
+const tryItTimeout = 60 * time.Second
+
+// tryItStripHeaders are not forwarded upstream: the API key of this service,
+// and the cookies and origin of the docs page
+var tryItStripHeaders = []string{api.APIKeyHeader, "Cookie", "Origin", "Referer"}
+
+var tryItTransport = func() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = tryItTimeout
+	return transport
+}()
+
+// TryIt forwards a "Try it out" request of the project's Swagger UI page to
+// html.try_it.upstream, so the page can call an environment on another origin
+// without CORS. The path after /try-it is appended to the upstream URL, and
+// the configured headers and bearer token are added. The route is behind the
+// API key check, so the page sends the key in X-API-Key, which is removed
+// before forwarding.
+//
+// Without API keys anyone could use the proxy and its credentials, so it is
+// disabled then. Upstreams come from project configs, which API users can
+// write, so only those under server.try_it_upstreams are forwarded to.
+func (h *Handler) TryIt(c *gin.Context) {
+	if len(h.cfg.Tenants) == 0 {
+		api.Error(c, api.CodeForbidden, "the try-it proxy needs API keys; configure tenants to enable it", nil)
+		return
+	}
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	tryIt := project.HTML.TryIt
+	if !tryIt.Enabled {
+		api.Error(c, api.CodeNotFound, fmt.Sprintf("html.try_it is not enabled for project %s", project.ProjectName), nil)
+		return
+	}
+	upstream, err := url.Parse(tryIt.Upstream)
+	if err != nil {
+		api.Error(c, api.CodeInternal, fmt.Sprintf("invalid html.try_it.upstream: %v", err), nil)
+		return
+	}
+	if !allowedUpstream(upstream, h.cfg.Server.TryItUpstreams) {
+		api.Error(c, api.CodeForbidden, fmt.Sprintf("html.try_it.upstream %s is not listed in server.try_it_upstreams", upstream.Redacted()), nil)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Transport: tryItTransport,
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.Out.URL.Path = strings.TrimSuffix(upstream.Path, "/") + c.Param("path")
+			r.Out.URL.RawPath = ""
+			for _, name := range tryItStripHeaders {
+				r.Out.Header.Del(name)
+			}
+			if tryIt.BearerToken != "" && r.Out.Header.Get("Authorization") == "" {
+				r.Out.Header.Set("Authorization", "Bearer "+tryIt.BearerToken)
+			}
+			for name, value := range tryIt.Headers {
+				r.Out.Header.Set(name, value)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			api.Error(c, api.CodeUpstreamFailed, fmt.Sprintf("%s %s: %v", r.Method, upstream.Host, err), nil)
+		},
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+// allowedUpstream reports whether upstream has the scheme and host of one of
+// the allowed URLs, and a path below its path
+func allowedUpstream(upstream *url.URL, allowed []string) bool {
+	for _, entry := range allowed {
+		base, err := url.Parse(entry)
+		if err != nil || base.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(upstream.Scheme, base.Scheme) || !strings.EqualFold(upstream.Host, base.Host) || upstream.User != nil {
+			continue
+		}
+		prefix := strings.TrimSuffix(base.Path, "/")
+		if prefix == "" || upstream.Path == prefix || strings.HasPrefix(upstream.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"api-doc-generator/internal/api"
+	"api-doc-generator/internal/contract"
+	"api-doc-generator/internal/sync"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyRequest optionally overrides the environment of the project's verify config
+type VerifyRequest struct {
+	BaseURL string `json:"base_url"`
+}
+
+// VerifyProject replays the operations of the project's last synced document
+// against verify.base_url and reports undocumented statuses and responses
+// that don't match their schema. The response status is 200 even when
+// operations fail; check "failed" in the report.
+func (h *Handler) VerifyProject(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+
+	var req VerifyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+			return
+		}
+	}
+	verifyCfg := project.Verify.WithBaseURL(req.BaseURL)
+	if verifyCfg.BaseURL == "" {
+		api.Error(c, api.CodeInvalidRequest, "verify.base_url is not configured", nil)
+		return
+	}
+
+	spec, _, err := sync.LoadLastSyncedSpec(&project.Apifox)
+	if err != nil {
+		api.Error(c, api.CodeInternal, err.Error(), nil)
+		return
+	}
+	if spec == nil {
+		api.Error(c, api.CodeSpecNotSynced, "项目尚未同步过文档: "+project.ProjectName, nil)
+		return
+	}
+
+	log.Printf("🔍 Verifying %s against %s", project.ProjectName, verifyCfg.BaseURL)
+	report, err := contract.New(verifyCfg).Verify(spec)
+	if err != nil {
+		api.Error(c, api.CodeInvalidRequest, err.Error(), nil)
+		return
+	}
+	log.Printf("🔍 Verification of %s: %d passed, %d failed, %d skipped", project.ProjectName, report.Passed, report.Failed, report.Skipped)
+	c.JSON(200, report)
+}
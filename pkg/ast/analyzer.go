@@ -9,20 +9,42 @@ import (
 )
 
 type RouteInfo struct {
-	Method      string
-	Path        string
-	Handler     string
-	HandlerFunc *ast.FuncDecl
-	HasBody     bool
-	HasParam    bool
-	GroupPrefix string
-	RequestType string
-	ResponseType string
+	Method          string
+	Path            string
+	Handler         string
+	HandlerFunc     *ast.FuncDecl
+	HasBody         bool
+	HasParam        bool
+	GroupPrefix     string
+	RequestType     string
+	ResponseType    string
+	ResponseSchema  *openapi.Schema // inline schema of a gin.H response, instead of ResponseType
+	RendersJSON     bool
+	RawResponses    []RawResponse
+	QueryParams     []QueryParam
+	Limits          RouteLimits
+	Middleware      []string // middleware of the route and its groups, in the order they run
+	Callbacks       []EventAnnotation
+	ResponseHeaders map[string]string // header name -> schema type
+	Cookies         []string
+	Idempotency     *Idempotency
+	Engine          string        // gin.Engine the route is served by (see engineKey), empty when unknown
+	Origin          *RouterOrigin // router parameter the route was registered on, until resolved by RouterMounts
+	InlineHandler   *HandlerInfo  // analysis of a handler written as a function literal, nil for named handlers
+	Kind            string        // RouteStatic, RouteNoRoute etc., empty for handler endpoints
+	HandlerFile     string        // file defining the handler, empty when the handler was not found
+	HandlerLine     int
+	Pagination      string // PaginationPage or PaginationOffset when the handler reads pagination parameters
 }
 
-// ExtractGinRoutes extracts Gin route definitions from AST
-func ExtractGinRoutes(node *ast.File) []RouteInfo {
+// ExtractGinRoutes extracts Gin route definitions from AST. Handlers written
+// as function literals are analyzed in place, see RouteInfo.InlineHandler.
+// Routes registered on a router parameter are returned relative to it, with
+// the calls passing routers to functions, see RouterMounts.
+func ExtractGinRoutes(fset *token.FileSet, node *ast.File) ([]RouteInfo, []RouterMount) {
 	var routes []RouteInfo
+	var mounts []RouterMount
+	imports := fileImports(node)
 
 	// Analyze each function declaration separately to maintain local scope
 	ast.Inspect(node, func(n ast.Node) bool {
@@ -34,27 +56,96 @@ func ExtractGinRoutes(node *ast.File) []RouteInfo {
 
 		// Extract routes from this function's body
 		if funcDecl.Body != nil {
-			funcRoutes := extractRoutesFromFunc(funcDecl)
+			funcRoutes, funcMounts := extractRoutesFromFunc(fset, funcDecl, localPackage, imports)
 			routes = append(routes, funcRoutes...)
+			mounts = append(mounts, funcMounts...)
 		}
 
 		return true
 	})
 
-	return routes
+	return routes, mounts
 }
 
 // extractRoutesFromFunc extracts routes from a single function's scope
-func extractRoutesFromFunc(funcDecl *ast.FuncDecl) []RouteInfo {
+func extractRoutesFromFunc(fset *token.FileSet, funcDecl *ast.FuncDecl, pkg string, imports map[string]string) ([]RouteInfo, []RouterMount) {
 	var routes []RouteInfo
-	groupPrefixes := make(map[string]string) // Local scope for this function
+	var mounts []RouterMount
+	groupPrefixes := make(map[string]string)     // Local scope for this function
+	groupLimits := make(map[string]RouteLimits)  // Limits declared on groups via middleware
+	groupMiddleware := make(map[string][]string) // Middleware of groups, including their parents'
+	engines := make(map[string]string)           // Router variable -> engine it belongs to
+	origins := routerParams(funcDecl)            // Router variable -> router parameter it derives from
+	varTypes := make(map[string]string)          // Variable -> type name, for calls of setup methods
+	for _, field := range funcDecl.Type.Params.List {
+		for _, name := range field.Names {
+			if typeName := namedType(field.Type); typeName != "" {
+				varTypes[name.Name] = typeName
+			}
+		}
+	}
+	self := funcKey(pkg, funcDecl)
+
+	// mountOf returns the router passed as a call argument: a router
+	// variable or a group created in place
+	mountOf := func(arg ast.Expr) (RouterMount, bool) {
+		mount := RouterMount{Caller: self, CallerParam: -1}
+		name := ""
+		if call, ok := arg.(*ast.CallExpr); ok {
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Group" || len(call.Args) == 0 {
+				return mount, false
+			}
+			ident, ok := sel.X.(*ast.Ident)
+			prefix, isLit := stringLiteral(call.Args[0])
+			if !ok || !isLit {
+				return mount, false
+			}
+			name = ident.Name
+			mount.Prefix = prefix
+			mount.Middleware = middlewareNames(call.Args[1:])
+			mount.Limits = extractLimits(call.Args[1:])
+		} else if ident, ok := arg.(*ast.Ident); ok {
+			name = ident.Name
+		}
+		_, isGroup := groupPrefixes[name]
+		_, isEngine := engines[name]
+		param, isParam := origins[name]
+		if !isGroup && !isEngine && !isParam {
+			return mount, false
+		}
+		mount.Prefix = groupPrefixes[name] + mount.Prefix
+		mount.Engine = engines[name]
+		mount.Middleware = append(append([]string(nil), groupMiddleware[name]...), mount.Middleware...)
+		mount.Limits = mount.Limits.merge(groupLimits[name])
+		if isParam {
+			mount.CallerParam = param
+		}
+		return mount, true
+	}
 
 	// Traverse the function body to find route groups and route definitions
 	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
-		// Track assignment statements for route groups
+		// Track engines declared with var admin = gin.New()
+		if spec, ok := n.(*ast.ValueSpec); ok {
+			for i, name := range spec.Names {
+				if i < len(spec.Values) && isEngineConstructor(spec.Values[i]) {
+					engines[name.Name] = engineKey(self, name.Name)
+				}
+			}
+		}
+
+		// Track assignment statements for engines and route groups
 		if assign, ok := n.(*ast.AssignStmt); ok {
 			for i, lhs := range assign.Lhs {
 				if ident, ok := lhs.(*ast.Ident); ok && i < len(assign.Rhs) {
+					if isEngineConstructor(assign.Rhs[i]) {
+						engines[ident.Name] = engineKey(self, ident.Name)
+						continue
+					}
+					if typeName := constructedType(assign.Rhs[i]); typeName != "" {
+						varTypes[ident.Name] = typeName
+					}
 					if call, ok := assign.Rhs[i].(*ast.CallExpr); ok {
 						if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
 							if sel.Sel.Name == "Group" && len(call.Args) > 0 {
@@ -66,9 +157,17 @@ func extractRoutesFromFunc(funcDecl *ast.FuncDecl) []RouteInfo {
 										parentPrefix := groupPrefixes[selIdent.Name]
 										// Concatenate parent prefix with new prefix
 										groupPrefixes[ident.Name] = parentPrefix + newPrefix
+										engines[ident.Name] = engines[selIdent.Name]
+										if param, ok := origins[selIdent.Name]; ok {
+											origins[ident.Name] = param
+										}
+										groupLimits[ident.Name] = extractLimits(call.Args[1:]).merge(groupLimits[selIdent.Name])
+										groupMiddleware[ident.Name] = append(append([]string(nil), groupMiddleware[selIdent.Name]...), middlewareNames(call.Args[1:])...)
 									} else {
 										// Direct engine.Group() call
 										groupPrefixes[ident.Name] = newPrefix
+										groupLimits[ident.Name] = extractLimits(call.Args[1:])
+										groupMiddleware[ident.Name] = middlewareNames(call.Args[1:])
 									}
 								}
 							}
@@ -84,41 +183,99 @@ func extractRoutesFromFunc(funcDecl *ast.FuncDecl) []RouteInfo {
 			return true
 		}
 
-		route := parseGinRouteCall(call, groupPrefixes)
-		if route != nil {
-			routes = append(routes, *route)
+		// Middleware attached with group.Use(...) applies to the group's routes
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Use" {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				groupLimits[ident.Name] = extractLimits(call.Args).merge(groupLimits[ident.Name])
+				groupMiddleware[ident.Name] = append(groupMiddleware[ident.Name], middlewareNames(call.Args)...)
+			}
+		}
+
+		callRoutes := parseGinRouteCall(call, groupPrefixes)
+		if callRoutes == nil {
+			if route := parseSpecialRoute(call, groupPrefixes); route != nil {
+				callRoutes = []RouteInfo{*route}
+			}
+		}
+		var inline *HandlerInfo
+		if len(callRoutes) > 0 && !callRoutes[0].IsFallback() {
+			if lit, ok := call.Args[len(call.Args)-1].(*ast.FuncLit); ok {
+				inline = newInlineHandlerInfo(fset, lit)
+			}
+		}
+		for _, route := range callRoutes {
+			route.InlineHandler = inline
+			route.Limits = extractLimits(call.Args[1:])
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					route.Limits = route.Limits.merge(groupLimits[ident.Name])
+					route.Middleware = append(append([]string(nil), groupMiddleware[ident.Name]...), route.Middleware...)
+					route.Engine = engines[ident.Name]
+					if param, ok := origins[ident.Name]; ok {
+						route.Origin = &RouterOrigin{Func: self, Param: param}
+					}
+				}
+			}
+			routes = append(routes, route)
+		}
+
+		// Routers passed to setup functions, like RegisterUserRoutes(v1)
+		if len(callRoutes) == 0 {
+			if callee := calleeKey(call, pkg, imports, varTypes); callee != "" {
+				for i, arg := range call.Args {
+					if mount, ok := mountOf(arg); ok {
+						mount.Callee, mount.Param = callee, i
+						mounts = append(mounts, mount)
+					}
+				}
+			}
 		}
 
 		return true
 	})
 
-	return routes
+	return routes, mounts
 }
 
-func parseGinRouteCall(call *ast.CallExpr, groupPrefixes map[string]string) *RouteInfo {
+func parseGinRouteCall(call *ast.CallExpr, groupPrefixes map[string]string) []RouteInfo {
 	// Match patterns like: r.GET("/path", handler) or router.POST("/users", CreateUser)
 	sel, ok := call.Fun.(*ast.SelectorExpr)
 	if !ok {
 		return nil
 	}
 
-	method := sel.Sel.Name
 	validMethods := map[string]bool{
 		"GET": true, "POST": true, "PUT": true,
 		"DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
 	}
 
-	if !validMethods[method] {
+	// r.Any registers every method, r.Handle("GET", path, handler) takes the
+	// method as its first argument
+	args := call.Args
+	var methods []string
+	switch {
+	case validMethods[sel.Sel.Name]:
+		methods = []string{sel.Sel.Name}
+	case sel.Sel.Name == "Any":
+		methods = openapi.Methods
+	case sel.Sel.Name == "Handle" && len(args) > 0:
+		method := handleMethod(args[0])
+		if !validMethods[method] {
+			return nil
+		}
+		methods = []string{method}
+		args = args[1:]
+	default:
 		return nil
 	}
 
 	// Need at least path and handler
-	if len(call.Args) < 2 {
+	if len(args) < 2 {
 		return nil
 	}
 
 	// Extract path (first argument)
-	pathLit, ok := call.Args[0].(*ast.BasicLit)
+	pathLit, ok := args[0].(*ast.BasicLit)
 	if !ok || pathLit.Kind != token.STRING {
 		return nil
 	}
@@ -138,25 +295,36 @@ func parseGinRouteCall(call *ast.CallExpr, groupPrefixes map[string]string) *Rou
 		fullPath = "/"
 	}
 
-	// Extract handler name (second argument)
-	var handler string
-	switch h := call.Args[1].(type) {
-	case *ast.Ident:
-		handler = h.Name
-	case *ast.SelectorExpr:
-		handler = h.Sel.Name
-	default:
-		handler = "handler"
+	// The handler is the last argument, the ones between path and handler
+	// are middleware
+	handler, middleware := routeHandler(args[1:])
+
+	routes := make([]RouteInfo, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, RouteInfo{
+			Method:      method,
+			Path:        convertGinPathToOpenAPI(fullPath),
+			Handler:     handler,
+			HasBody:     method == "POST" || method == "PUT" || method == "PATCH",
+			HasParam:    strings.Contains(path, ":"),
+			GroupPrefix: groupPrefix,
+			Middleware:  middleware,
+		})
 	}
+	return routes
+}
 
-	return &RouteInfo{
-		Method:      method,
-		Path:        convertGinPathToOpenAPI(fullPath),
-		Handler:     handler,
-		HasBody:     method == "POST" || method == "PUT" || method == "PATCH",
-		HasParam:    strings.Contains(path, ":"),
-		GroupPrefix: groupPrefix,
+// handleMethod returns the method passed to r.Handle, as a string literal
+// or a net/http constant like http.MethodGet
+func handleMethod(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok && x.Name == "http" && strings.HasPrefix(sel.Sel.Name, "Method") {
+			return strings.ToUpper(strings.TrimPrefix(sel.Sel.Name, "Method"))
+		}
+		return ""
 	}
+	method, _ := stringLiteral(expr)
+	return strings.ToUpper(method)
 }
 
 func convertGinPathToOpenAPI(ginPath string) string {
@@ -165,7 +333,27 @@ func convertGinPathToOpenAPI(ginPath string) string {
 	return re.ReplaceAllString(ginPath, `{$1}`)
 }
 
+// OperationStyle is how a project documents the responses of its operations
+type OperationStyle struct {
+	Envelope   *Envelope     // wraps JSON responses, nil leaves them unwrapped
+	Errors     *ErrorCatalog // error responses of every operation
+	Pagination *Pagination   // parameters and response of paginated routes, nil to leave them as found
+}
+
+// DefaultStyle wraps JSON responses in the DefaultEnvelope and documents the
+// DefaultErrorCatalog
+var DefaultStyle = OperationStyle{Envelope: DefaultEnvelope, Errors: DefaultErrorCatalog}
+
+// ToOperation documents the route in the DefaultStyle
 func (r *RouteInfo) ToOperation() *openapi.Operation {
+	return r.ToOperationWithStyle(DefaultStyle)
+}
+
+// ToOperationWithStyle documents the route in a project's style
+func (r *RouteInfo) ToOperationWithStyle(style OperationStyle) *openapi.Operation {
+	if r.IsStatic() {
+		return r.staticOperation()
+	}
 	op := &openapi.Operation{
 		Summary:   formatHandlerName(r.Handler),
 		Tags:      extractTags(r.Path),
@@ -176,6 +364,9 @@ func (r *RouteInfo) ToOperation() *openapi.Operation {
 	if r.HasParam {
 		op.Parameters = extractPathParameters(r.Path)
 	}
+	for _, param := range r.QueryParams {
+		op.Parameters = append(op.Parameters, param.Parameter())
+	}
 
 	// Add request body for methods that typically have one
 	if r.HasBody {
@@ -200,45 +391,79 @@ func (r *RouteInfo) ToOperation() *openapi.Operation {
 		}
 	}
 
-	// Success response - wrap in common response structure
+	// Success response - wrap in the response envelope
 	var dataSchema openapi.Schema
-	if r.ResponseType != "" {
+	if r.ResponseSchema != nil {
+		dataSchema = *r.ResponseSchema
+	} else if r.ResponseType != "" {
 		dataSchema = buildDataSchema(r.ResponseType)
 	} else {
 		// Fallback to generic object
 		dataSchema = openapi.Schema{Type: "object"}
 	}
+	if r.Pagination != "" && style.Pagination != nil {
+		dataSchema = style.Pagination.apply(op, r.Pagination, dataSchema)
+	}
 	
-	// Create wrapped response schema
-	responseSchema := openapi.Schema{
-		Type: "object",
-		Properties: map[string]openapi.Schema{
-			"code": {
-				Type:        "integer",
-				Description: "响应状态码，0表示成功",
-			},
-			"message": {
-				Type:        "string",
-				Description: "响应消息",
+	responseSchema := style.Envelope.Wrap(dataSchema)
+
+	// Success response; handlers writing only text, files or redirects have no JSON response
+	if len(r.RawResponses) == 0 || r.RendersJSON {
+		op.Responses["200"] = openapi.Response{
+			Description: "Successful response",
+			Content: map[string]openapi.MediaType{
+				"application/json": {
+					Schema: responseSchema,
+				},
 			},
-			"data": dataSchema,
-		},
+		}
 	}
 
-	// Success response
-	op.Responses["200"] = openapi.Response{
-		Description: "Successful response",
-		Content: map[string]openapi.MediaType{
-			"application/json": {
-				Schema: responseSchema,
-			},
-		},
+	// Response headers and cookies set by the handler
+	if len(r.ResponseHeaders) > 0 || len(r.Cookies) > 0 {
+		resp := op.Responses["200"]
+		resp.Headers = make(map[string]openapi.Header)
+		for name, typ := range r.ResponseHeaders {
+			resp.Headers[name] = openapi.Header{Schema: openapi.Schema{Type: typ}}
+		}
+		if len(r.Cookies) > 0 {
+			resp.Headers["Set-Cookie"] = openapi.Header{
+				Description: "Sets cookies: " + strings.Join(r.Cookies, ", "),
+				Schema:      openapi.Schema{Type: "string"},
+			}
+		}
+		op.Responses["200"] = resp
+	}
+
+	// Operational constraints declared through middleware
+	var constraints []string
+	if r.Limits.Timeout > 0 {
+		op.SetExtension("x-timeout", r.Limits.Timeout.String())
+		constraints = append(constraints, "Timeout: "+r.Limits.Timeout.String())
+	}
+	if r.Limits.MaxBodySize > 0 {
+		op.SetExtension("x-max-body-size", r.Limits.MaxBodySize)
+		constraints = append(constraints, "Max request body: "+formatSize(r.Limits.MaxBodySize))
+	}
+	if len(constraints) > 0 {
+		op.Description = strings.Join(constraints, "; ")
+	}
+	if r.Idempotency != nil {
+		op.MarkIdempotent(r.Idempotency.Key)
+	}
+
+	// Requests sent back to the caller
+	for _, cb := range r.Callbacks {
+		op.AddCallback(cb.Name, cb.Expression, cb.Method, cb.ToOperation())
 	}
 
 	// Error responses
-	op.Responses["400"] = openapi.Response{Description: "Bad request"}
-	op.Responses["404"] = openapi.Response{Description: "Not found"}
-	op.Responses["500"] = openapi.Response{Description: "Internal server error"}
+	style.Errors.addResponses(op)
+
+	// Text, XML, files and redirects written without c.JSON
+	addRawResponses(op, r.RawResponses)
+	r.addMiddleware(op)
+	style.Errors.attachSchema(op)
 
 	return op
 }
@@ -262,15 +487,10 @@ func extractTags(path string) []string {
 
 	// Skip common prefixes like api, v1, v2, imagine_hub, etc.
 	var resourcePart string
-	skipPrefixes := map[string]bool{
-		"api": true,
-		"imagine_hub": true,
-		"app": true,
-	}
 	
 	for _, part := range parts {
 		// Skip version patterns (v1, v2, etc.) and known prefixes
-		if skipPrefixes[part] || regexp.MustCompile(`^v\d+$`).MatchString(part) {
+		if tagSkipPrefixes[part] || versionSegment.MatchString(part) {
 			continue
 		}
 		// Skip path parameters
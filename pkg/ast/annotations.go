@@ -0,0 +1,147 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"strings"
+)
+
+// EventAnnotation describes an outbound request declared in a doc comment:
+//
+//	// @Webhook orderPaid POST OrderPaidEvent 订单支付成功通知
+//	// @Callback onStatus {$request.body#/callback_url} POST StatusEvent 状态变更回调
+//
+// @Webhook may appear on any declaration; @Callback belongs to a handler and
+// is attached to the operations the handler serves.
+type EventAnnotation struct {
+	Name       string
+	Expression string // callback URL expression, empty for webhooks
+	Method     string
+	Schema     string // payload schema name, "-" for no body
+	Summary    string
+}
+
+// ToOperation builds the OpenAPI operation for the event
+func (e EventAnnotation) ToOperation() *openapi.Operation {
+	schema := e.Schema
+	if schema == "-" {
+		schema = ""
+	}
+	summary := e.Summary
+	if summary == "" {
+		summary = formatHandlerName(e.Name)
+	}
+	return openapi.NewEventOperation(summary, "", schema)
+}
+
+// Idempotency marks a handler as safe to retry:
+//
+//	// @Idempotent
+//	// @Idempotent Idempotency-Key
+//
+// The optional argument names the request header that makes retries safe.
+// @RetrySafe is accepted as an alias.
+type Idempotency struct {
+	Key string
+}
+
+// parseIdempotentAnnotation returns the handler's @Idempotent declaration, or nil
+func parseIdempotentAnnotation(doc *ast.CommentGroup) *Idempotency {
+	if doc == nil {
+		return nil
+	}
+	for _, comment := range doc.List {
+		fields := strings.Fields(strings.TrimPrefix(comment.Text, "//"))
+		if len(fields) == 0 || (fields[0] != "@Idempotent" && fields[0] != "@RetrySafe") {
+			continue
+		}
+		idempotency := &Idempotency{}
+		if len(fields) > 1 {
+			idempotency.Key = fields[1]
+		}
+		return idempotency
+	}
+	return nil
+}
+
+// ExtractWebhookAnnotations collects @Webhook annotations from every comment in the file
+func ExtractWebhookAnnotations(node *ast.File) []EventAnnotation {
+	var events []EventAnnotation
+	for _, group := range node.Comments {
+		events = append(events, parseEventAnnotations(group, "@Webhook")...)
+	}
+	return events
+}
+
+// parseEventAnnotations parses "@Webhook name METHOD Schema [summary]" and
+// "@Callback name expression METHOD Schema [summary]" lines of a comment group
+func parseEventAnnotations(doc *ast.CommentGroup, tag string) []EventAnnotation {
+	if doc == nil {
+		return nil
+	}
+
+	var events []EventAnnotation
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(text, tag+" ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(text, tag))
+
+		var event EventAnnotation
+		if tag == "@Callback" {
+			if len(fields) < 4 {
+				continue
+			}
+			event = EventAnnotation{Name: fields[0], Expression: fields[1], Method: fields[2], Schema: fields[3]}
+			fields = fields[4:]
+		} else {
+			if len(fields) < 3 {
+				continue
+			}
+			event = EventAnnotation{Name: fields[0], Method: fields[1], Schema: fields[2]}
+			fields = fields[3:]
+		}
+		event.Method = strings.ToUpper(event.Method)
+		event.Summary = strings.Join(fields, " ")
+		events = append(events, event)
+	}
+	return events
+}
+
+// messageAnnotations maps the annotations declaring message channels to
+// their direction:
+//
+//	// @Publishes kafka user.created UserCreatedEvent 用户注册成功
+//	// @Consumes amqp order.paid events.OrderPaid 订单支付后发放积分
+var messageAnnotations = map[string]string{
+	"@Publishes": openapi.MessagePublish,
+	"@Consumes":  openapi.MessageConsume,
+}
+
+// ExtractMessageAnnotations collects @Publishes and @Consumes annotations
+// from every comment in the file
+func ExtractMessageAnnotations(node *ast.File) []openapi.Message {
+	var messages []openapi.Message
+	for _, group := range node.Comments {
+		for _, comment := range group.List {
+			fields := strings.Fields(strings.TrimPrefix(comment.Text, "//"))
+			if len(fields) < 4 {
+				continue
+			}
+			direction, ok := messageAnnotations[fields[0]]
+			if !ok {
+				continue
+			}
+			schema := fields[3]
+			messages = append(messages, openapi.Message{
+				Channel:   fields[2],
+				Direction: direction,
+				Protocol:  strings.ToLower(fields[1]),
+				Schema:    schema[strings.LastIndex(schema, ".")+1:],
+				Summary:   strings.Join(fields[4:], " "),
+			})
+		}
+	}
+	return messages
+}
@@ -0,0 +1,330 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"go/ast"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ExtEngine names the gin.Engine an operation is served by, when a project
+// runs more than one (e.g. a public API on :8080 and an admin API on :9090)
+const ExtEngine = "x-gin-engine"
+
+// ExtEngines maps the engines of the document to the addresses they listen
+// on, as far as they are known
+const ExtEngines = "x-gin-engines"
+
+// engineKey identifies an engine within its file by the variable holding it
+// and the function declaring it (variable@function). The same variable name,
+// such as r, is used by many functions and binaries; QualifyEngine adds the
+// file and EngineNames turns the keys back into readable names.
+func engineKey(function, variable string) string {
+	return variable + "@" + function
+}
+
+// QualifyEngine adds the declaring file, relative to the project, to an
+// engine key found in that file
+func QualifyEngine(key, file string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "@" + file
+}
+
+// EngineNames returns the names the engines are documented under, by
+// qualified key (see QualifyEngine): the variable name, or, when several
+// engines share it, the variable prefixed with the declaring directory
+// (admin.r, public.r), its full path (cmd.admin.r) or else the whole key.
+func EngineNames(keys []string) map[string]string {
+	type engine struct{ variable, function, dir string }
+	parsed := make(map[string]engine, len(keys))
+	byVariable := make(map[string][]string)
+	for _, key := range keys {
+		if _, ok := parsed[key]; ok || key == "" {
+			continue
+		}
+		parts := strings.SplitN(key, "@", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		e := engine{variable: parts[0], function: parts[1], dir: path.Dir(parts[2])}
+		parsed[key] = e
+		byVariable[e.variable] = append(byVariable[e.variable], key)
+	}
+
+	names := make(map[string]string, len(parsed))
+	for variable, shared := range byVariable {
+		if len(shared) == 1 {
+			names[shared[0]] = variable
+			continue
+		}
+		candidates := []func(e engine) string{
+			func(e engine) string { return path.Base(e.dir) + "." + e.variable },
+			func(e engine) string { return strings.ReplaceAll(e.dir, "/", ".") + "." + e.variable },
+		}
+		named := false
+		for _, candidate := range candidates {
+			seen := make(map[string]bool, len(shared))
+			for _, key := range shared {
+				seen[candidate(parsed[key])] = true
+			}
+			if len(seen) == len(shared) {
+				for _, key := range shared {
+					names[key] = candidate(parsed[key])
+				}
+				named = true
+				break
+			}
+		}
+		if !named {
+			for _, key := range shared {
+				names[key] = key
+			}
+		}
+	}
+	return names
+}
+
+// isEngineConstructor 判断是否为 gin.New() / gin.Default() 调用
+func isEngineConstructor(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "gin" && (sel.Sel.Name == "New" || sel.Sel.Name == "Default")
+}
+
+// DistinctEngines returns the names of the engines the routes were registered
+// on, sorted. Routes on engines that could not be identified (e.g. an engine
+// passed in as a parameter) are not counted.
+func DistinctEngines(routes []RouteInfo) []string {
+	seen := make(map[string]bool)
+	var engines []string
+	for _, route := range routes {
+		if route.Engine != "" && !seen[route.Engine] {
+			seen[route.Engine] = true
+			engines = append(engines, route.Engine)
+		}
+	}
+	sort.Strings(engines)
+	return engines
+}
+
+// MarkEngine records the engine on the operation. With sections set, the
+// operation's tags are nested under the engine name (admin/users), which
+// Apifox imports as one folder per engine.
+func MarkEngine(op *openapi.Operation, engine string, sections bool) {
+	if engine == "" {
+		return
+	}
+	op.SetExtension(ExtEngine, engine)
+	if !sections {
+		return
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{engine}
+		return
+	}
+	for i, tag := range op.Tags {
+		op.Tags[i] = engine + "/" + tag
+	}
+}
+
+// ExtractEngineAddrs returns the addresses the engines of a file listen on,
+// by engine key: admin.Run(":9090"), admin.RunTLS(":443", ...) or an
+// http.Server{Addr: ":9090", Handler: admin}. Only engines created in the
+// same function with gin.New() or gin.Default() and literal addresses are
+// found.
+func ExtractEngineAddrs(node *ast.File) map[string]string {
+	addrs := make(map[string]string)
+	for _, decl := range node.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		self := funcKey(localPackage, funcDecl)
+		engines := make(map[string]string) // variable -> engine key
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if i < len(n.Values) && isEngineConstructor(n.Values[i]) {
+						engines[name.Name] = engineKey(self, name.Name)
+					}
+				}
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && i < len(n.Rhs) && isEngineConstructor(n.Rhs[i]) {
+						engines[ident.Name] = engineKey(self, ident.Name)
+					}
+				}
+			case *ast.CallExpr:
+				sel, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || (sel.Sel.Name != "Run" && sel.Sel.Name != "RunTLS") || len(n.Args) == 0 {
+					return true
+				}
+				if ident, ok := sel.X.(*ast.Ident); ok && engines[ident.Name] != "" {
+					if addr, ok := stringLiteral(n.Args[0]); ok {
+						addrs[engines[ident.Name]] = addr
+					}
+				}
+			case *ast.CompositeLit:
+				if extractTypeFromExpr(n.Type) != "Server" {
+					return true
+				}
+				var addr, engine string
+				for _, elt := range n.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					key, _ := kv.Key.(*ast.Ident)
+					switch {
+					case key == nil:
+					case key.Name == "Addr":
+						addr, _ = stringLiteral(kv.Value)
+					case key.Name == "Handler":
+						if ident, ok := kv.Value.(*ast.Ident); ok && engines[ident.Name] != "" {
+							engine = engines[ident.Name]
+						}
+					}
+				}
+				if engine != "" && addr != "" {
+					addrs[engine] = addr
+				}
+			}
+			return true
+		})
+	}
+	return addrs
+}
+
+// SplitEngines splits a document whose operations were marked by MarkEngine
+// into one document per engine, named by names (engine -> document name,
+// several engines may share a document) or else after the engine. Operations
+// of no known engine, webhooks and message channels are kept in every
+// document. It returns nil when the operations don't come from several
+// engines. The document holds one operation per method and path, so an
+// operation served by several engines is only in the document of the engine
+// the analysis kept, which it warns about.
+func SplitEngines(spec *openapi.Spec, names map[string]string) map[string]*openapi.Spec {
+	nameOf := func(engine string) string {
+		if name := names[engine]; name != "" {
+			return name
+		}
+		return engine
+	}
+	engines := make(map[string]map[string]bool) // document name -> engines
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		if engine, ok := op.Extensions[ExtEngine].(string); ok {
+			name := nameOf(engine)
+			if engines[name] == nil {
+				engines[name] = make(map[string]bool)
+			}
+			engines[name][engine] = true
+		}
+	})
+	if len(engines) < 2 {
+		return nil
+	}
+
+	addrs := engineAddrs(spec)
+	specs := make(map[string]*openapi.Spec, len(engines))
+	for name, set := range engines {
+		var servedBy []string
+		for engine := range set {
+			if addr := addrs[engine]; addr != "" {
+				engine += " (" + addr + ")"
+			}
+			servedBy = append(servedBy, engine)
+		}
+		sort.Strings(servedBy)
+		doc := openapi.NewSpec()
+		doc.OpenAPI = spec.OpenAPI
+		doc.Info = spec.Info
+		doc.Info.Title = fmt.Sprintf("%s (%s)", spec.Info.Title, name)
+		served := "Operations served by the gin engine " + strings.Join(servedBy, ", ") + "."
+		if doc.Info.Description == "" {
+			doc.Info.Description = served
+		} else {
+			doc.Info.Description += "\n\n" + served
+		}
+		for key, value := range spec.Extensions {
+			if key != ExtEngines {
+				doc.SetExtension(key, value)
+			}
+		}
+		doc.EnvelopeData = spec.EnvelopeData
+		specs[name] = doc
+	}
+
+	tags := make(map[string]map[string]bool) // document name -> tags used
+	spec.ForEachOperation(func(path, method string, op *openapi.Operation) {
+		for name, doc := range specs {
+			engine, ok := op.Extensions[ExtEngine].(string)
+			if ok && nameOf(engine) != name {
+				continue
+			}
+			doc.AddPath(path, method, op)
+			if tags[name] == nil {
+				tags[name] = make(map[string]bool)
+			}
+			for _, tag := range op.Tags {
+				tags[name][tag] = true
+			}
+		}
+	})
+	for name, doc := range specs {
+		for webhook, pathItem := range spec.Webhooks {
+			for _, method := range openapi.Methods {
+				if op := pathItem.Operation(method); op != nil {
+					doc.AddWebhook(webhook, method, op)
+					if tags[name] == nil {
+						tags[name] = make(map[string]bool)
+					}
+					for _, tag := range op.Tags {
+						tags[name][tag] = true
+					}
+				}
+			}
+		}
+		doc.Messages = append([]openapi.Message(nil), spec.Messages...)
+		for _, tag := range spec.Tags {
+			if tags[name][tag.Name] {
+				doc.Tags = append(doc.Tags, tag)
+			}
+		}
+		if spec.Components != nil {
+			for schemaName, schema := range spec.Components.Schemas {
+				doc.AddSchema(schemaName, schema)
+			}
+		}
+		doc.PruneSchemas()
+	}
+	return specs
+}
+
+// engineAddrs reads the ExtEngines extension, also after a JSON round trip
+func engineAddrs(spec *openapi.Spec) map[string]string {
+	switch addrs := spec.Extensions[ExtEngines].(type) {
+	case map[string]string:
+		return addrs
+	case map[string]interface{}:
+		result := make(map[string]string, len(addrs))
+		for engine, addr := range addrs {
+			if s, ok := addr.(string); ok {
+				result[engine] = s
+			}
+		}
+		return result
+	}
+	return nil
+}
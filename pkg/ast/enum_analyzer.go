@@ -0,0 +1,225 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// EnumValue 记录枚举类型的一个常量取值及其注释说明
+type EnumValue struct {
+	Name    string
+	Value   interface{}
+	Comment string
+}
+
+// collectEnums records named basic types (type Status int) and the typed
+// constants declared for them, so fields of those types can document values
+func (sa *StructAnalyzer) collectEnums(node *ast.File) {
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		switch genDecl.Tok {
+		case token.TYPE:
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if ident, ok := typeSpec.Type.(*ast.Ident); ok && isBasicType(ident.Name) {
+					sa.namedTypes[typeSpec.Name.Name] = ident.Name
+				}
+			}
+
+		case token.CONST:
+			sa.collectConstBlock(genDecl)
+		}
+	}
+}
+
+// collectConstBlock evaluates a const block, following iota and implicit
+// repetition of the previous type/expression
+func (sa *StructAnalyzer) collectConstBlock(genDecl *ast.GenDecl) {
+	var lastType string
+	var lastValues []ast.Expr
+
+	for iota, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			lastType = ""
+			if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+				lastType = ident.Name
+			} else if len(valueSpec.Values) > 0 {
+				// StatusActive = Status(1)
+				if call, ok := valueSpec.Values[0].(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok {
+						lastType = ident.Name
+					}
+				}
+			}
+			lastValues = valueSpec.Values
+		}
+		if lastType == "" || isBasicType(lastType) {
+			continue
+		}
+
+		comment := commentText(valueSpec.Comment)
+		if comment == "" {
+			comment = commentText(valueSpec.Doc)
+		}
+
+		for i, name := range valueSpec.Names {
+			if name.Name == "_" || i >= len(lastValues) {
+				continue
+			}
+			value, ok := evalConst(lastValues[i], int64(iota))
+			if !ok {
+				continue
+			}
+			sa.enumValues[lastType] = append(sa.enumValues[lastType], EnumValue{
+				Name:    name.Name,
+				Value:   value,
+				Comment: comment,
+			})
+		}
+	}
+}
+
+// ResolveEnums inlines references to named basic types. Types with constants
+// get an enum list and a value/meaning table appended to the description.
+func (sa *StructAnalyzer) ResolveEnums() {
+	for name, schema := range sa.structs {
+		rewritten := openapi.MapSchema(*schema, func(s openapi.Schema) openapi.Schema {
+			typeName := openapi.RefName(s.Ref)
+			underlying, ok := sa.namedTypes[typeName]
+			if !ok {
+				return s
+			}
+
+			inline := sa.identToSchema(underlying)
+			inline.Description = s.Description
+			return withEnumValues(inline, sa.enumValues[typeName])
+		})
+		sa.structs[name] = &rewritten
+	}
+}
+
+// withEnumValues adds the enum list and the value/meaning table of a named type
+func withEnumValues(inline openapi.Schema, values []EnumValue) openapi.Schema {
+	if len(values) == 0 {
+		return inline
+	}
+
+	var rows []string
+	for _, v := range values {
+		inline.Enum = append(inline.Enum, v.Value)
+		meaning := v.Comment
+		if meaning == "" {
+			meaning = v.Name
+		}
+		rows = append(rows, fmt.Sprintf("| %v | %s |", v.Value, strings.ReplaceAll(meaning, "|", "\\|")))
+	}
+	table := "| 值 | 含义 |\n|----|------|\n" + strings.Join(rows, "\n")
+	if inline.Description != "" {
+		inline.Description += "\n\n" + table
+	} else {
+		inline.Description = table
+	}
+	return inline
+}
+
+// evalConst evaluates the constant expressions commonly used for enums:
+// literals, iota, iota arithmetic and shifts
+func evalConst(expr ast.Expr, iota int64) (interface{}, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			v, err := strconv.ParseInt(e.Value, 0, 64)
+			return v, err == nil
+		case token.FLOAT:
+			v, err := strconv.ParseFloat(e.Value, 64)
+			return v, err == nil
+		case token.STRING:
+			v, err := strconv.Unquote(e.Value)
+			return v, err == nil
+		}
+	case *ast.Ident:
+		if e.Name == "iota" {
+			return iota, true
+		}
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota)
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			if v, ok := evalConst(e.X, iota); ok {
+				if n, ok := v.(int64); ok {
+					return -n, true
+				}
+			}
+		}
+	case *ast.CallExpr:
+		// Status(1) style conversions
+		if len(e.Args) == 1 {
+			return evalConst(e.Args[0], iota)
+		}
+	case *ast.BinaryExpr:
+		x, okX := evalConst(e.X, iota)
+		y, okY := evalConst(e.Y, iota)
+		if !okX || !okY {
+			return nil, false
+		}
+		a, okA := x.(int64)
+		b, okB := y.(int64)
+		if !okA || !okB {
+			return nil, false
+		}
+		switch e.Op {
+		case token.ADD:
+			return a + b, true
+		case token.SUB:
+			return a - b, true
+		case token.MUL:
+			return a * b, true
+		case token.SHL:
+			return a << uint(b), true
+		}
+	}
+	return nil, false
+}
+
+// isBasicType reports whether a type name is a Go builtin usable for enums
+func isBasicType(name string) bool {
+	switch name {
+	case "string", "bool", "float32", "float64",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// commentText joins the lines of a comment group
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	var lines []string
+	for _, c := range group.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, " ")
+}
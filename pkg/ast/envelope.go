@@ -0,0 +1,65 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Envelope is the structure the service's middleware wraps JSON responses
+// in, e.g. {code, message, data}
+type Envelope struct {
+	DataField  string                    // property holding the handler's payload
+	Properties map[string]openapi.Schema // the other properties
+	Required   []string
+}
+
+// DefaultEnvelope is the {code, message, data} wrapper used unless a
+// project declares its own
+var DefaultEnvelope = &Envelope{
+	DataField: "data",
+	Properties: map[string]openapi.Schema{
+		"code":    {Type: "integer", Description: "响应状态码，0表示成功"},
+		"message": {Type: "string", Description: "响应消息"},
+	},
+}
+
+// NewEnvelope creates an envelope from the OpenAPI schemas of its
+// properties, given as decoded JSON or YAML
+func NewEnvelope(dataField string, properties map[string]map[string]interface{}, required []string) (*Envelope, error) {
+	if dataField == "" {
+		dataField = DefaultEnvelope.DataField
+	}
+	envelope := &Envelope{DataField: dataField, Properties: make(map[string]openapi.Schema), Required: required}
+	for name, raw := range properties {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("envelope property %s: %w", name, err)
+		}
+		var schema openapi.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("envelope property %s: %w", name, err)
+		}
+		envelope.Properties[name] = schema
+	}
+	return envelope, nil
+}
+
+// Wrap returns the envelope schema around a payload. A nil envelope leaves
+// the payload unwrapped.
+func (e *Envelope) Wrap(data openapi.Schema) openapi.Schema {
+	if e == nil {
+		return data
+	}
+	schema := openapi.Schema{Type: "object", Properties: make(map[string]openapi.Schema, len(e.Properties)+1)}
+	for name, property := range e.Properties {
+		schema.Properties[name] = property
+	}
+	schema.Properties[e.DataField] = data
+	if len(e.Required) > 0 {
+		schema.Required = append([]string(nil), e.Required...)
+		sort.Strings(schema.Required)
+	}
+	return schema
+}
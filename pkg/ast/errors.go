@@ -0,0 +1,125 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ErrorSchemaName is the component error responses refer to, unless the
+// project declares a type of that name (see ErrorCatalog.WithSchemaName)
+const ErrorSchemaName = "ErrorResponse"
+
+// ErrorCatalog is the set of error responses every operation documents
+type ErrorCatalog struct {
+	Responses  map[string]string // status code -> description
+	Schema     *openapi.Schema   // body of error responses, nil for none
+	SchemaName string            // component of Schema, ErrorSchemaName when empty
+	defaulted  bool              // Schema is defaultErrorSchema, not configured
+}
+
+// DefaultErrorCatalog documents 400, 404 and 500 without a body unless a
+// project declares its own errors
+var DefaultErrorCatalog = &ErrorCatalog{
+	Responses: map[string]string{
+		"400": "Bad request",
+		"404": "Not found",
+		"500": "Internal server error",
+	},
+}
+
+// defaultErrorSchema is the body of a project's errors when it declares
+// responses but no schema
+var defaultErrorSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"code":    map[string]interface{}{"type": "integer"},
+		"message": map[string]interface{}{"type": "string"},
+	},
+}
+
+// NewErrorCatalog creates a catalog from the OpenAPI schema of error
+// bodies, given as decoded JSON or YAML, and the descriptions of the status
+// codes
+func NewErrorCatalog(schema map[string]interface{}, responses map[string]string) (*ErrorCatalog, error) {
+	defaulted := schema == nil
+	if defaulted {
+		schema = defaultErrorSchema
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("errors.schema: %w", err)
+	}
+	catalog := &ErrorCatalog{Responses: responses, Schema: &openapi.Schema{}, defaulted: defaulted}
+	if err := json.Unmarshal(data, catalog.Schema); err != nil {
+		return nil, fmt.Errorf("errors.schema: %w", err)
+	}
+	return catalog, nil
+}
+
+// WithSchemaName places the catalog's schema among the components of a
+// project, so that it doesn't replace a type of the project. A project's own
+// ErrorResponse is reused as the body when no schema is configured; a
+// configured schema gets the first free name of ErrorResponse2,
+// ErrorResponse3 and so on. It returns whether the schema must be added
+// to the components.
+func (c *ErrorCatalog) WithSchemaName(components map[string]openapi.Schema) (*ErrorCatalog, bool) {
+	if c == nil || c.Schema == nil {
+		return c, false
+	}
+	if _, taken := components[ErrorSchemaName]; !taken {
+		return c, true
+	}
+	if c.defaulted {
+		return c, false
+	}
+	named := *c
+	for i := 2; ; i++ {
+		name := ErrorSchemaName + strconv.Itoa(i)
+		if _, taken := components[name]; !taken {
+			named.SchemaName = name
+			return &named, true
+		}
+	}
+}
+
+// ComponentName returns the component of the catalog's schema
+func (c *ErrorCatalog) ComponentName() string {
+	if c.SchemaName != "" {
+		return c.SchemaName
+	}
+	return ErrorSchemaName
+}
+
+// addResponses adds the catalog's responses to an operation
+func (c *ErrorCatalog) addResponses(op *openapi.Operation) {
+	if c == nil {
+		return
+	}
+	for code, description := range c.Responses {
+		resp := op.Responses[code]
+		if resp.Description == "" {
+			resp.Description = description
+		}
+		op.Responses[code] = resp
+	}
+}
+
+// attachSchema gives the error responses of an operation without a body,
+// including those added by handlers and middleware, the shared error schema
+func (c *ErrorCatalog) attachSchema(op *openapi.Operation) {
+	if c == nil || c.Schema == nil {
+		return
+	}
+	for code, resp := range op.Responses {
+		status, err := strconv.Atoi(code)
+		if (err != nil && code != "default") || (err == nil && status < 400) || len(resp.Content) > 0 {
+			continue
+		}
+		resp.Content = map[string]openapi.MediaType{
+			"application/json": {Schema: openapi.Schema{Ref: openapi.SchemaRefPrefix + c.ComponentName()}},
+		}
+		op.Responses[code] = resp
+	}
+}
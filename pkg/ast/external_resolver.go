@@ -0,0 +1,138 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ExternalResolver 解析定义在项目之外（如公司公共模块 common.Money）的类型。
+// 通过在项目目录下执行 go list 下载依赖模块，因此会遵循 go.mod 中锁定的版本
+// 以及 GOPROXY / GOPRIVATE 设置
+type ExternalResolver struct {
+	ProjectPath string
+	GoProxy     string // 为空时沿用进程环境变量
+	GoPrivate   string // 为空时沿用进程环境变量
+
+	packages    map[string]*StructAnalyzer // import 路径 -> 该包的结构体分析结果
+	typeMappers []TypeMapper               // 沿用项目分析器的自定义类型映射
+}
+
+func NewExternalResolver(projectPath, goProxy, goPrivate string) *ExternalResolver {
+	return &ExternalResolver{
+		ProjectPath: projectPath,
+		GoProxy:     goProxy,
+		GoPrivate:   goPrivate,
+		packages:    make(map[string]*StructAnalyzer),
+	}
+}
+
+// Resolve 为 StructAnalyzer 中悬空的外部引用补全 schema，
+// 被引用类型自身依赖的其他类型会被一并解析。返回无法解析的类型及原因
+func (r *ExternalResolver) Resolve(sa *StructAnalyzer) map[string]error {
+	failures := make(map[string]error)
+	pending := sa.DanglingRefs()
+	r.typeMappers = sa.typeMappers
+
+	for len(pending) > 0 {
+		next := make(map[string]string)
+		for typeName, importPath := range pending {
+			pkg, err := r.loadPackage(importPath)
+			if err != nil {
+				failures[typeName] = err
+				continue
+			}
+			schema := pkg.GetSchema(typeName)
+			if schema == nil {
+				failures[typeName] = fmt.Errorf("type %s not found in %s", typeName, importPath)
+				continue
+			}
+			sa.AddExternalSchema(typeName, schema)
+
+			// 同包内引用的类型与该包再引用的外部类型，进入下一轮解析
+			refs := make(map[string]bool)
+			openapi.CollectRefs(*schema, refs)
+			for ref := range refs {
+				if sa.GetSchema(ref) != nil || failures[ref] != nil {
+					continue
+				}
+				if refPath, ok := pkg.externalRefs[ref]; ok {
+					next[ref] = refPath
+				} else if pkg.GetSchema(ref) != nil {
+					next[ref] = importPath
+				}
+			}
+		}
+		pending = next
+	}
+
+	return failures
+}
+
+// loadPackage 定位并分析某个 import 路径对应的包
+func (r *ExternalResolver) loadPackage(importPath string) (*StructAnalyzer, error) {
+	if pkg, ok := r.packages[importPath]; ok {
+		return pkg, nil
+	}
+
+	dir, err := r.packageDir(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package dir: %w", err)
+	}
+
+	pkg := NewStructAnalyzer()
+	pkg.typeMappers = r.typeMappers
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		fset := token.NewFileSet()
+		node, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		pkg.AnalyzeFile(node)
+	}
+	pkg.ExpandEmbeddedFields()
+
+	r.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// packageDir 通过 go list 获取包源码目录，必要时会下载所在模块
+func (r *ExternalResolver) packageDir(importPath string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.Dir}}", importPath)
+	cmd.Dir = r.ProjectPath
+	cmd.Env = os.Environ()
+	if r.GoProxy != "" {
+		cmd.Env = append(cmd.Env, "GOPROXY="+r.GoProxy)
+	}
+	if r.GoPrivate != "" {
+		cmd.Env = append(cmd.Env, "GOPRIVATE="+r.GoPrivate)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("go list %s failed: %s", importPath, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("go list %s failed: %w", importPath, err)
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if dir == "" {
+		return "", fmt.Errorf("package %s has no source directory", importPath)
+	}
+	return dir, nil
+}
@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// localRefPrefix marks, in StructFacts, references to project types that are
+// inlined or turned into component references when the facts are added
+const localRefPrefix = "#/local/"
+
+// localRefName returns the type name of a localRefPrefix reference
+func localRefName(ref string) string {
+	if strings.HasPrefix(ref, localRefPrefix) {
+		return strings.TrimPrefix(ref, localRefPrefix)
+	}
+	return ""
+}
+
+// StructFacts is what a StructAnalyzer extracts from one file. Facts depend
+// only on the file, its package name and the type mappers, never on other
+// files, so they can be cached by file content. Adding the facts of every
+// file in order gives the same schemas as analyzing the files in order.
+type StructFacts struct {
+	Package      string
+	Structs      []StructFact        // in declaration order
+	Embedded     map[string][]string // struct name -> embedded type names
+	ExternalRefs []ExternalRef
+	NamedTypes   map[string]string
+	EnumValues   map[string][]EnumValue
+}
+
+// StructFact is one struct declaration
+type StructFact struct {
+	Name   string
+	Schema openapi.Schema
+	// Deferred holds the fields (by JSON name) whose type is a project type;
+	// their schema is decorated once the type is resolved
+	Deferred map[string]FieldSource
+}
+
+// ExternalRef is a type referenced as pkg.Type, with the import path of pkg
+type ExternalRef struct {
+	Name       string
+	ImportPath string
+}
+
+// ExtractFacts extracts the struct definitions of a file without changing
+// the analyzer
+func (sa *StructAnalyzer) ExtractFacts(node *ast.File, packageName string) *StructFacts {
+	extractor := &StructAnalyzer{
+		currentPackage: packageName,
+		currentImports: fileImports(node),
+		embeddedFields: make(map[string][]string),
+		externalRefs:   make(map[string]string),
+		namedTypes:     make(map[string]string),
+		enumValues:     make(map[string][]EnumValue),
+		typeMappers:    sa.typeMappers,
+		deferRefs:      true,
+	}
+	if facts, ok := extractor.protoFacts(node, packageName); ok {
+		return facts
+	}
+	extractor.collectEnums(node)
+
+	facts := &StructFacts{Package: packageName}
+	ast.Inspect(node, func(n ast.Node) bool {
+		// Look for type declarations
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+
+		// Only process struct types
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		schema, deferred := extractor.extractStructSchemaWithName(structType, typeSpec.Name.Name)
+		facts.Structs = append(facts.Structs, StructFact{Name: typeSpec.Name.Name, Schema: *schema, Deferred: deferred})
+		return true
+	})
+
+	facts.Embedded = extractor.embeddedFields
+	facts.NamedTypes = extractor.namedTypes
+	facts.EnumValues = extractor.enumValues
+	for name, importPath := range extractor.externalRefs {
+		facts.ExternalRefs = append(facts.ExternalRefs, ExternalRef{Name: name, ImportPath: importPath})
+	}
+	sort.Slice(facts.ExternalRefs, func(i, j int) bool {
+		return facts.ExternalRefs[i].Name < facts.ExternalRefs[j].Name
+	})
+	return facts
+}
+
+// AddFacts adds the structs of one file. References to project types are
+// inlined when the type is already known, as in a plain analysis.
+func (sa *StructAnalyzer) AddFacts(facts *StructFacts) {
+	for name, underlying := range facts.NamedTypes {
+		sa.namedTypes[name] = underlying
+	}
+	for typeName, values := range facts.EnumValues {
+		sa.enumValues[typeName] = append(sa.enumValues[typeName], values...)
+	}
+	for _, ref := range facts.ExternalRefs {
+		if _, exists := sa.externalRefs[ref.Name]; !exists {
+			sa.externalRefs[ref.Name] = ref.ImportPath
+		}
+	}
+	for name, embedded := range facts.Embedded {
+		sa.embeddedFields[name] = append(sa.embeddedFields[name], embedded...)
+	}
+
+	// 计算优先级：dao/model > service > controller
+	priority := calculatePriority(facts.Package)
+	for _, fact := range facts.Structs {
+		if fact.Schema.Properties == nil {
+			// Cached facts lose empty maps; embedded fields are added to it later
+			fact.Schema.Properties = make(map[string]openapi.Schema)
+		}
+		schema := openapi.MapSchema(fact.Schema, func(s openapi.Schema) openapi.Schema {
+			if name := localRefName(s.Ref); name != "" {
+				return sa.resolveLocalRef(name)
+			}
+			return s
+		})
+		for field, source := range fact.Deferred {
+			schema.Properties[field] = decorateField(sa.resolveLocalRef(localRefName(fact.Schema.Properties[field].Ref)), source)
+		}
+
+		// 只有优先级更高或相同时才覆盖
+		if existingPriority, exists := sa.structPriority[fact.Name]; !exists || priority >= existingPriority {
+			sa.structs[fact.Name] = &schema
+			sa.structPriority[fact.Name] = priority
+		}
+	}
+}
+
+// resolveLocalRef inlines a known struct and references any other type
+func (sa *StructAnalyzer) resolveLocalRef(typeName string) openapi.Schema {
+	if schema, exists := sa.structs[typeName]; exists {
+		return *schema
+	}
+	return openapi.Schema{Ref: openapi.SchemaRefPrefix + typeName}
+}
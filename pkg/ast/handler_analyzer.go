@@ -1,8 +1,10 @@
 package ast
 
 import (
+	"api-doc-generator/internal/openapi"
 	"go/ast"
 	"go/token"
+	"strconv"
 	"strings"
 )
 
@@ -11,9 +13,59 @@ type HandlerInfo struct {
 	Name            string
 	RequestType     string
 	ResponseType    string
-	QueryParams     []string
+	ResponseSchema  *openapi.Schema // inline schema of a gin.H response, instead of ResponseType
+	RendersJSON     bool            // c.JSON or a response wrapper is called
+	RawResponses    []RawResponse   // responses written with c.String, c.XML, c.File, c.Redirect etc.
+	QueryParams     []QueryParam
 	PathParams      []string
-	ServiceCalls    []ServiceCall // 记录 service 函数调用
+	ServiceCalls    []ServiceCall     // 记录 service 函数调用
+	Callbacks       []EventAnnotation // 文档注释中声明的 @Callback
+	ResponseHeaders map[string]string // c.Header 设置的响应头 -> schema 类型
+	Cookies         []string          // c.SetCookie 设置的 cookie 名称
+	Idempotency     *Idempotency      // 文档注释中的 @Idempotent，nil 表示未声明
+	TypedResponse   bool              // ResponseType 由类型信息确定（deep 模式），不再从 service 调用推断
+	File            string            // 函数定义所在的文件，即解析时传入的路径
+	Line            int               // 函数定义所在的行
+}
+
+// QueryParam is a query parameter read with c.Query or c.DefaultQuery
+type QueryParam struct {
+	Name    string
+	Type    string // string, or integer, number or boolean when the value is parsed with strconv
+	Default string // second argument of c.DefaultQuery
+}
+
+// queryConversions are the strconv functions parsing a query value, and the
+// schema type they produce
+var queryConversions = map[string]string{
+	"strconv.Atoi":       "integer",
+	"strconv.ParseInt":   "integer",
+	"strconv.ParseUint":  "integer",
+	"strconv.ParseFloat": "number",
+	"strconv.ParseBool":  "boolean",
+}
+
+// Parameter returns the query parameter of an operation. The default is
+// converted to the parameter's type and dropped when it doesn't parse.
+func (q QueryParam) Parameter() openapi.Parameter {
+	schema := openapi.Schema{Type: q.Type}
+	if q.Default != "" {
+		var err error
+		switch q.Type {
+		case "integer":
+			schema.Default, err = strconv.ParseInt(q.Default, 10, 64)
+		case "number":
+			schema.Default, err = strconv.ParseFloat(q.Default, 64)
+		case "boolean":
+			schema.Default, err = strconv.ParseBool(q.Default)
+		default:
+			schema.Default = q.Default
+		}
+		if err != nil {
+			schema.Default = nil
+		}
+	}
+	return openapi.Parameter{Name: q.Name, In: "query", Schema: schema}
 }
 
 // ServiceCall 记录 service 函数调用信息
@@ -24,7 +76,7 @@ type ServiceCall struct {
 }
 
 // AnalyzeHandlers extracts handler function information from a Go file
-func AnalyzeHandlers(node *ast.File) map[string]*HandlerInfo {
+func AnalyzeHandlers(fset *token.FileSet, node *ast.File) map[string]*HandlerInfo {
 	handlers := make(map[string]*HandlerInfo)
 
 	ast.Inspect(node, func(n ast.Node) bool {
@@ -38,18 +90,7 @@ func AnalyzeHandlers(node *ast.File) map[string]*HandlerInfo {
 			return true
 		}
 
-		info := &HandlerInfo{
-			Name:        funcDecl.Name.Name,
-			QueryParams: []string{},
-			PathParams:  []string{},
-		}
-
-		// Analyze function body to find request/response types
-		if funcDecl.Body != nil {
-			analyzeHandlerBody(funcDecl.Body, info)
-		}
-
-		handlers[funcDecl.Name.Name] = info
+		handlers[funcDecl.Name.Name] = newHandlerInfo(fset, funcDecl)
 
 		return true
 	})
@@ -57,6 +98,71 @@ func AnalyzeHandlers(node *ast.File) map[string]*HandlerInfo {
 	return handlers
 }
 
+// newHandlerInfo analyzes the declaration of a handler function
+func newHandlerInfo(fset *token.FileSet, funcDecl *ast.FuncDecl) *HandlerInfo {
+	position := fset.Position(funcDecl.Pos())
+	info := &HandlerInfo{
+		File:            position.Filename,
+		Line:            position.Line,
+		Name:            funcDecl.Name.Name,
+		QueryParams:     []QueryParam{},
+		PathParams:      []string{},
+		Callbacks:       parseEventAnnotations(funcDecl.Doc, "@Callback"),
+		ResponseHeaders: make(map[string]string),
+		Idempotency:     parseIdempotentAnnotation(funcDecl.Doc),
+	}
+
+	// Analyze function body to find request/response types
+	if funcDecl.Body != nil {
+		analyzeHandlerBody(funcDecl.Body, info, contextParams(funcDecl.Type))
+	}
+	return info
+}
+
+// newInlineHandlerInfo analyzes a handler written as a function literal in
+// a route registration, or returns nil when it takes no *gin.Context
+func newInlineHandlerInfo(fset *token.FileSet, lit *ast.FuncLit) *HandlerInfo {
+	contexts := contextParams(lit.Type)
+	if len(contexts) == 0 {
+		return nil
+	}
+	position := fset.Position(lit.Pos())
+	info := &HandlerInfo{
+		File:            position.Filename,
+		Line:            position.Line,
+		Name:            "handler",
+		QueryParams:     []QueryParam{},
+		PathParams:      []string{},
+		ResponseHeaders: make(map[string]string),
+	}
+	analyzeHandlerBody(lit.Body, info, contexts)
+	return info
+}
+
+// contextParams returns the names of the *gin.Context parameters of a handler
+func contextParams(funcType *ast.FuncType) map[string]bool {
+	names := make(map[string]bool)
+	if funcType.Params == nil {
+		return names
+	}
+	for _, param := range funcType.Params.List {
+		star, ok := param.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := star.X.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "gin" {
+			for _, name := range param.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	return names
+}
+
 // isGinHandler checks if a function is a Gin handler
 func isGinHandler(funcDecl *ast.FuncDecl) bool {
 	if funcDecl.Type == nil || funcDecl.Type.Params == nil {
@@ -80,9 +186,13 @@ func isGinHandler(funcDecl *ast.FuncDecl) bool {
 }
 
 // analyzeHandlerBody extracts request/response type information from handler body
-func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
+func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo, contexts map[string]bool) {
 	// Track variable types within the function
 	varTypes := make(map[string]string)
+	queryVars := make(map[string]string)       // variable -> query parameter it holds
+	queryTypes := make(map[string]string)      // query parameter -> type of its strconv conversion
+	protojsonVars := make(map[string]ast.Expr) // variable -> message it holds marshaled by protojson
+	values := newValueInferrer(body)
 	
 	// First pass: collect variable declarations and assignments
 	ast.Inspect(body, func(n ast.Node) bool {
@@ -91,6 +201,13 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 			// Handle var := value or var, err := func()
 			for i, lhs := range node.Lhs {
 				if ident, ok := lhs.(*ast.Ident); ok && i < len(node.Rhs) {
+					if name, _, ok := queryCall(node.Rhs[i], contexts); ok {
+						queryVars[ident.Name] = name
+					}
+					if message, ok := protojsonValue(node.Rhs[i]); ok {
+						protojsonVars[ident.Name] = message
+					}
+
 					// Try to extract type from RHS
 					typeName := extractTypeFromExpr(node.Rhs[i])
 					if typeName != "" {
@@ -148,8 +265,35 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 		if !ok {
 			return true
 		}
+		if jsonRenderers[sel.Sel.Name] {
+			info.RendersJSON = true
+		}
 
 		switch sel.Sel.Name {
+		case "String", "HTML", "XML", "YAML", "Data", "DataFromReader", "File", "FileAttachment", "FileFromFS", "Redirect":
+			// Non-JSON responses written through the handler's gin.Context
+			if ident, ok := sel.X.(*ast.Ident); ok && contexts[ident.Name] {
+				// c.Data(200, "application/json", data) with data from
+				// protojson.Marshal(resp) renders resp as JSON
+				if sel.Sel.Name == "Data" && isJSONData(call.Args) {
+					if data, ok := call.Args[2].(*ast.Ident); ok && protojsonVars[data.Name] != nil {
+						info.RendersJSON = true
+						message := protojsonVars[data.Name]
+						respType := extractTypeFromExpr(message)
+						if ident, ok := message.(*ast.Ident); ok {
+							respType = varTypes[ident.Name]
+						}
+						if respType != "" && info.ResponseType == "" && info.ResponseSchema == nil {
+							info.ResponseType = respType
+						}
+						break
+					}
+				}
+				if raw, ok := rawResponse(sel.Sel.Name, call.Args); ok {
+					info.RawResponses = append(info.RawResponses, raw)
+				}
+			}
+
 		case "ShouldBindJSON", "BindJSON", "ShouldBind", "Bind", "ShouldBindQuery":
 			// Extract request type from c.ShouldBindJSON(&req)
 			if len(call.Args) > 0 && info.RequestType == "" {
@@ -174,6 +318,17 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 			}
 
 		case "JSON":
+			// Ad-hoc responses c.JSON(200, gin.H{"id": id}) get an inline schema;
+			// gin.H error responses are skipped
+			if len(call.Args) > 1 {
+				if lit, ok := mapLiteral(call.Args[1]); ok {
+					if isSuccessStatusExpr(call.Args[0]) && info.ResponseType == "" && info.ResponseSchema == nil {
+						schema := objectSchema(lit, values.schema)
+						info.ResponseSchema = &schema
+					}
+					break
+				}
+			}
 			// Extract response type from c.JSON(200, response)
 			if len(call.Args) > 1 {
 				respType := extractTypeFromExpr(call.Args[1])
@@ -185,7 +340,7 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 						}
 					}
 				}
-				if respType != "" && info.ResponseType == "" {
+				if respType != "" && info.ResponseType == "" && info.ResponseSchema == nil {
 					info.ResponseType = respType
 				}
 			}
@@ -193,6 +348,12 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 		case "SetResponseOK", "Success", "OK":
 			// Handle custom response wrappers like tool.SetResponseOK(c, data)
 			if len(call.Args) > 1 {
+				if lit, ok := mapLiteral(call.Args[1]); ok {
+					schema := objectSchema(lit, values.schema)
+					info.ResponseType, info.ResponseSchema = "", &schema
+					break
+				}
+				info.ResponseSchema = nil
 				// 先尝试从变量类型映射获取
 				if ident, ok := call.Args[1].(*ast.Ident); ok {
 					if varType, exists := varTypes[ident.Name]; exists {
@@ -216,11 +377,39 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 			}
 
 		case "Query", "DefaultQuery":
-			// Extract query parameter from c.Query("name")
+			// Extract query parameter from c.Query("name") or c.DefaultQuery("name", "default")
+			if name, def, ok := queryCall(call, contexts); ok {
+				info.QueryParams = appendQueryParam(info.QueryParams, QueryParam{Name: name, Type: "string", Default: def})
+			}
+
+		case "Atoi", "ParseInt", "ParseUint", "ParseFloat", "ParseBool":
+			// strconv.Atoi(page) or strconv.Atoi(c.Query("page")) types the query parameter
+			typ, ok := queryConversions[callName(call)]
+			if !ok || len(call.Args) == 0 {
+				break
+			}
+			if ident, ok := call.Args[0].(*ast.Ident); ok && queryVars[ident.Name] != "" {
+				queryTypes[queryVars[ident.Name]] = typ
+			} else if name, _, ok := queryCall(call.Args[0], contexts); ok {
+				queryTypes[name] = typ
+			}
+
+		case "Header", "Set", "Add":
+			// Response headers: c.Header("X-Total-Count", v) or c.Writer.Header().Set("X-Total-Count", v)
+			if sel.Sel.Name != "Header" && !isWriterHeaderCall(sel.X) {
+				break
+			}
+			if len(call.Args) == 2 {
+				if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					info.ResponseHeaders[strings.Trim(lit.Value, `"`)] = headerValueType(call.Args[1])
+				}
+			}
+
+		case "SetCookie":
+			// c.SetCookie("session", value, maxAge, path, domain, secure, httpOnly)
 			if len(call.Args) > 0 {
 				if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
-					paramName := strings.Trim(lit.Value, `"`)
-					info.QueryParams = appendUnique(info.QueryParams, paramName)
+					info.Cookies = appendUnique(info.Cookies, strings.Trim(lit.Value, `"`))
 				}
 			}
 
@@ -236,6 +425,91 @@ func analyzeHandlerBody(body *ast.BlockStmt, info *HandlerInfo) {
 
 		return true
 	})
+
+	for i, param := range info.QueryParams {
+		if typ, ok := queryTypes[param.Name]; ok {
+			info.QueryParams[i].Type = typ
+		}
+	}
+}
+
+// queryCall returns the parameter name and default value of c.Query("name")
+// or c.DefaultQuery("name", "default"), where c is one of the handler's
+// *gin.Context parameters, so db.Query("SELECT ...") is not a parameter
+func queryCall(expr ast.Expr, contexts map[string]bool) (name, def string, ok bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "Query" && sel.Sel.Name != "DefaultQuery") {
+		return "", "", false
+	}
+	if receiver, ok := sel.X.(*ast.Ident); !ok || !contexts[receiver.Name] {
+		return "", "", false
+	}
+	name, ok = stringLiteral(call.Args[0])
+	if !ok {
+		return "", "", false
+	}
+	if sel.Sel.Name == "DefaultQuery" && len(call.Args) > 1 {
+		def, _ = stringLiteral(call.Args[1])
+	}
+	return name, def, true
+}
+
+// stringLiteral returns the value of a string literal
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// appendQueryParam adds a query parameter read for the first time; a default
+// found in a later read of the same parameter is kept
+func appendQueryParam(params []QueryParam, param QueryParam) []QueryParam {
+	for i := range params {
+		if params[i].Name == param.Name {
+			if params[i].Default == "" {
+				params[i].Default = param.Default
+			}
+			return params
+		}
+	}
+	return append(params, param)
+}
+
+// isWriterHeaderCall reports whether expr is c.Writer.Header()
+func isWriterHeaderCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Header" {
+		return false
+	}
+	writer, ok := sel.X.(*ast.SelectorExpr)
+	return ok && writer.Sel.Name == "Writer"
+}
+
+// headerValueType guesses the schema type of a header value: strconv.Itoa(n)
+// and friends produce integers, everything else is documented as a string
+func headerValueType(expr ast.Expr) string {
+	if call, ok := expr.(*ast.CallExpr); ok {
+		switch callName(call) {
+		case "strconv.Itoa", "strconv.FormatInt", "strconv.FormatUint":
+			return "integer"
+		case "strconv.FormatFloat":
+			return "number"
+		case "strconv.FormatBool":
+			return "boolean"
+		}
+	}
+	return "string"
 }
 
 // appendUnique appends a string to a slice if it doesn't already exist
@@ -0,0 +1,109 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// SummaryData is what a summary template is executed with, for the handler
+// GetUserProfile registered as GET /users/{id}/profile
+type SummaryData struct {
+	Handler  string // GetUserProfile
+	Words    string // Get User Profile, the default summary
+	Sentence string // Get user profile
+	Verb     string // Get
+	Object   string // User Profile
+	Method   string // GET
+	Path     string // /users/{id}/profile
+	Tag      string // Users, translated
+}
+
+// Labeler rewrites the summaries and tags of generated operations with a
+// summary template and a translation map, e.g. for documents maintained in
+// Chinese:
+//
+//	summary_template: '{{tr .Verb}}{{tr .Object}}'
+//	translations: {"Users": "用户", "Get": "获取", "User Profile": "用户资料"}
+type Labeler struct {
+	summary      *template.Template // nil keeps the default summary
+	translations map[string]string
+}
+
+// NewLabeler creates a labeler. The template is tried on a sample handler,
+// so references to unknown fields are reported here rather than mid-analysis.
+func NewLabeler(summaryTemplate string, translations map[string]string) (*Labeler, error) {
+	l := &Labeler{translations: translations}
+	if summaryTemplate == "" {
+		return l, nil
+	}
+	tmpl, err := template.New("summary").Funcs(template.FuncMap{"tr": l.translate}).Parse(summaryTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("summary_template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, summaryData("GetUser", "GET", "/users/{id}", "Users")); err != nil {
+		return nil, fmt.Errorf("summary_template: %w", err)
+	}
+	l.summary = tmpl
+	return l, nil
+}
+
+// translate returns the translation of text, or text itself
+func (l *Labeler) translate(text string) string {
+	if translated, ok := l.translations[text]; ok {
+		return translated
+	}
+	return text
+}
+
+// translateTag translates a tag, keeping the version suffix MarkVersion
+// adds: "Users v2" becomes "用户 v2"
+func (l *Labeler) translateTag(tag string) string {
+	if translated, ok := l.translations[tag]; ok {
+		return translated
+	}
+	if i := strings.LastIndex(tag, " "); i > 0 && versionSegment.MatchString(tag[i+1:]) {
+		return l.translate(tag[:i]) + tag[i:]
+	}
+	return tag
+}
+
+// Apply labels the operation of route. The summary of static file routes is
+// translated but not templated, they have no handler.
+func (l *Labeler) Apply(op *openapi.Operation, route RouteInfo) {
+	for i, tag := range op.Tags {
+		op.Tags[i] = l.translateTag(tag)
+	}
+	if l.summary != nil && !route.IsStatic() {
+		var tag string
+		if len(op.Tags) > 0 {
+			tag = op.Tags[0]
+		}
+		var buf bytes.Buffer
+		if err := l.summary.Execute(&buf, summaryData(route.Handler, route.Method, route.Path, tag)); err == nil {
+			op.Summary = strings.TrimSpace(buf.String())
+		}
+	}
+	op.Summary = l.translate(op.Summary)
+}
+
+func summaryData(handler, method, path, tag string) SummaryData {
+	words := formatHandlerName(handler)
+	verb, object, _ := strings.Cut(words, " ")
+	sentence := verb
+	if object != "" {
+		sentence += " " + strings.ToLower(object)
+	}
+	return SummaryData{
+		Handler:  handler,
+		Words:    words,
+		Sentence: sentence,
+		Verb:     verb,
+		Object:   object,
+		Method:   method,
+		Path:     path,
+		Tag:      tag,
+	}
+}
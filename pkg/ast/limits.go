@@ -0,0 +1,201 @@
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteLimits holds operational constraints declared through middleware,
+// e.g. timeout.New(5*time.Second) or limits.RequestSizeLimiter(10<<20)
+type RouteLimits struct {
+	Timeout     time.Duration
+	MaxBodySize int64
+}
+
+// merge fills limits that are not set yet from other
+func (l RouteLimits) merge(other RouteLimits) RouteLimits {
+	if l.Timeout == 0 {
+		l.Timeout = other.Timeout
+	}
+	if l.MaxBodySize == 0 {
+		l.MaxBodySize = other.MaxBodySize
+	}
+	return l
+}
+
+// extractLimits inspects middleware arguments for timeout and body size limits
+func extractLimits(args []ast.Expr) RouteLimits {
+	var limits RouteLimits
+	for _, arg := range args {
+		call, ok := arg.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		name := strings.ToLower(callName(call))
+
+		switch {
+		case strings.Contains(name, "timeout"):
+			if d, ok := findDuration(call); ok && limits.Timeout == 0 {
+				limits.Timeout = d
+			}
+		case strings.Contains(name, "sizelimit") || strings.Contains(name, "bodylimit") ||
+			strings.HasPrefix(name, "limits."):
+			if size, ok := findSize(call); ok && limits.MaxBodySize == 0 {
+				limits.MaxBodySize = size
+			}
+		}
+	}
+	return limits
+}
+
+// callName returns "pkg.Func" or "Func" for a call expression
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		if x, ok := fn.X.(*ast.Ident); ok {
+			return x.Name + "." + fn.Sel.Name
+		}
+		return fn.Sel.Name
+	}
+	return ""
+}
+
+// findDuration searches the call arguments (recursively, to cover option
+// helpers like timeout.WithTimeout(...)) for a duration expression
+func findDuration(call *ast.CallExpr) (time.Duration, bool) {
+	for _, arg := range call.Args {
+		if d, ok := evalDuration(arg); ok {
+			return d, true
+		}
+		if nested, ok := arg.(*ast.CallExpr); ok {
+			if d, ok := findDuration(nested); ok {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// evalDuration evaluates 5*time.Second, time.Duration(5)*time.Second or "5s"
+func evalDuration(expr ast.Expr) (time.Duration, bool) {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "time" {
+			units := map[string]time.Duration{
+				"Nanosecond": time.Nanosecond, "Microsecond": time.Microsecond,
+				"Millisecond": time.Millisecond, "Second": time.Second,
+				"Minute": time.Minute, "Hour": time.Hour,
+			}
+			d, ok := units[e.Sel.Name]
+			return d, ok
+		}
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			if s, err := strconv.Unquote(e.Value); err == nil {
+				d, err := time.ParseDuration(s)
+				return d, err == nil
+			}
+		}
+	case *ast.ParenExpr:
+		return evalDuration(e.X)
+	case *ast.CallExpr:
+		// time.Duration(n)
+		if callName(e) == "time.Duration" && len(e.Args) == 1 {
+			if n, ok := evalConst(e.Args[0], 0); ok {
+				if v, ok := n.(int64); ok {
+					return time.Duration(v), true
+				}
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op != token.MUL {
+			return 0, false
+		}
+		if unit, ok := evalDuration(e.Y); ok {
+			if n, ok := evalScalar(e.X); ok {
+				return time.Duration(n) * unit, true
+			}
+		}
+		if unit, ok := evalDuration(e.X); ok {
+			if n, ok := evalScalar(e.Y); ok {
+				return time.Duration(n) * unit, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// evalScalar evaluates an integer constant, unwrapping time.Duration(n)
+func evalScalar(expr ast.Expr) (int64, bool) {
+	if call, ok := expr.(*ast.CallExpr); ok && callName(call) == "time.Duration" && len(call.Args) == 1 {
+		expr = call.Args[0]
+	}
+	v, ok := evalConst(expr, 0)
+	if !ok {
+		return 0, false
+	}
+	n, ok := v.(int64)
+	return n, ok
+}
+
+// findSize searches the call arguments for a byte size: 10<<20, 10*1024*1024 or "10MB"
+func findSize(call *ast.CallExpr) (int64, bool) {
+	for _, arg := range call.Args {
+		if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				if size, ok := parseSize(s); ok {
+					return size, true
+				}
+			}
+			continue
+		}
+		if n, ok := evalScalar(arg); ok && n > 0 {
+			return n, true
+		}
+		if nested, ok := arg.(*ast.CallExpr); ok {
+			if size, ok := findSize(nested); ok {
+				return size, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseSize parses human readable sizes like "10MB", "512K" or "1G"
+func parseSize(s string) (int64, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	s = strings.TrimRight(s, "KMG")
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n * multiplier, true
+}
+
+// formatSize renders a byte count for descriptions
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30 && size%(1<<30) == 0:
+		return strconv.FormatInt(size>>30, 10) + " GB"
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return strconv.FormatInt(size>>20, 10) + " MB"
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return strconv.FormatInt(size>>10, 10) + " KB"
+	}
+	return strconv.FormatInt(size, 10) + " bytes"
+}
@@ -0,0 +1,253 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// maxValueDepth bounds how many variable assignments are followed when
+// inferring the schema of a value
+const maxValueDepth = 8
+
+// mapLiteral returns the literal of an ad-hoc JSON response:
+// gin.H{...} or map[string]interface{}{...}
+func mapLiteral(expr ast.Expr) (*ast.CompositeLit, bool) {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	switch t := lit.Type.(type) {
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "gin" && t.Sel.Name == "H" {
+			return lit, true
+		}
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); ok && key.Name == "string" {
+			return lit, true
+		}
+	}
+	return nil, false
+}
+
+// mapLiteralKeys returns the string keys of a map literal with their values
+func mapLiteralKeys(lit *ast.CompositeLit) ([]string, []ast.Expr) {
+	var keys []string
+	var values []ast.Expr
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := stringLiteral(kv.Key)
+		if !ok {
+			continue
+		}
+		keys = append(keys, key)
+		values = append(values, kv.Value)
+	}
+	return keys, values
+}
+
+// objectSchema builds the object schema of a map literal. Every key is
+// written by encoding/json, so all of them are required.
+func objectSchema(lit *ast.CompositeLit, valueSchema func(ast.Expr) openapi.Schema) openapi.Schema {
+	schema := openapi.Schema{Type: "object", Properties: make(map[string]openapi.Schema)}
+	keys, values := mapLiteralKeys(lit)
+	for i, key := range keys {
+		schema.Properties[key] = valueSchema(values[i])
+		schema.Required = append(schema.Required, key)
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// valueInferrer infers the schemas of the values of gin.H responses from the
+// handler body: literals, variables assigned or declared in the handler, and
+// the results of well-known functions. Values it can't infer get an empty
+// schema, which allows any value.
+type valueInferrer struct {
+	values map[string]ast.Expr // variable -> expression assigned to it
+	types  map[string]ast.Expr // variable -> declared type
+}
+
+func newValueInferrer(body *ast.BlockStmt) *valueInferrer {
+	v := &valueInferrer{values: make(map[string]ast.Expr), types: make(map[string]ast.Expr)}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Name == "_" {
+					continue
+				}
+				// In a, err := f() only the first variable holds the result
+				if len(node.Lhs) == len(node.Rhs) {
+					v.values[ident.Name] = node.Rhs[i]
+				} else if i == 0 && len(node.Rhs) == 1 {
+					v.values[ident.Name] = node.Rhs[0]
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if node.Type != nil {
+					v.types[name.Name] = node.Type
+				} else if i < len(node.Values) {
+					v.values[name.Name] = node.Values[i]
+				}
+			}
+		}
+		return true
+	})
+	return v
+}
+
+// stringFuncs are functions returning a string
+var stringFuncs = map[string]bool{
+	"strconv.Itoa": true, "strconv.FormatInt": true, "strconv.FormatUint": true,
+	"strconv.FormatFloat": true, "strconv.FormatBool": true, "strconv.Quote": true,
+	"fmt.Sprintf": true, "fmt.Sprint": true, "strings.Join": true, "strings.ToLower": true,
+	"strings.ToUpper": true, "strings.TrimSpace": true, "strings.Replace": true, "strings.ReplaceAll": true,
+}
+
+// contextStringMethods are gin.Context methods returning a request value as a string
+var contextStringMethods = map[string]bool{
+	"Param": true, "Query": true, "DefaultQuery": true, "PostForm": true, "DefaultPostForm": true,
+	"GetHeader": true, "ClientIP": true, "FullPath": true, "ContentType": true,
+}
+
+func (v *valueInferrer) schema(expr ast.Expr) openapi.Schema {
+	return v.infer(expr, 0)
+}
+
+func (v *valueInferrer) infer(expr ast.Expr, depth int) openapi.Schema {
+	if depth > maxValueDepth {
+		return openapi.Schema{}
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return openapi.Schema{Type: "string"}
+		case token.INT, token.CHAR:
+			return openapi.Schema{Type: "integer"}
+		case token.FLOAT:
+			return openapi.Schema{Type: "number"}
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return openapi.Schema{Type: "boolean"}
+		case "nil":
+			return openapi.Schema{}
+		}
+		if typ, ok := v.types[e.Name]; ok {
+			return typeSchema(typ)
+		}
+		if value, ok := v.values[e.Name]; ok {
+			return v.infer(value, depth+1)
+		}
+	case *ast.ParenExpr:
+		return v.infer(e.X, depth)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return openapi.Schema{Type: "boolean"}
+		}
+		return v.infer(e.X, depth)
+	case *ast.StarExpr:
+		return v.infer(e.X, depth)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ, token.LAND, token.LOR:
+			return openapi.Schema{Type: "boolean"}
+		}
+		if left := v.infer(e.X, depth+1); left.Type != "" {
+			return left
+		}
+		return v.infer(e.Y, depth+1)
+	case *ast.CompositeLit:
+		if lit, ok := mapLiteral(e); ok {
+			return objectSchema(lit, func(value ast.Expr) openapi.Schema { return v.infer(value, depth+1) })
+		}
+		if e.Type != nil {
+			return typeSchema(e.Type)
+		}
+	case *ast.CallExpr:
+		return v.callSchema(e)
+	}
+	return openapi.Schema{}
+}
+
+// callSchema returns the schema of the result of well-known functions
+func (v *valueInferrer) callSchema(call *ast.CallExpr) openapi.Schema {
+	name := callName(call)
+	switch {
+	case stringFuncs[name]:
+		return openapi.Schema{Type: "string"}
+	case queryConversions[name] != "":
+		return openapi.Schema{Type: queryConversions[name]}
+	case name == "len" || name == "cap":
+		return openapi.Schema{Type: "integer"}
+	case name == "time.Now":
+		return openapi.Schema{Type: "string", Format: "date-time"}
+	case name == "time.Since":
+		return openapi.Schema{Type: "integer"} // time.Duration is written in nanoseconds
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if contextStringMethods[sel.Sel.Name] || sel.Sel.Name == "Format" || sel.Sel.Name == "String" || sel.Sel.Name == "Error" {
+			return openapi.Schema{Type: "string"}
+		}
+		if sel.Sel.Name == "Unix" || sel.Sel.Name == "UnixMilli" {
+			return openapi.Schema{Type: "integer"}
+		}
+	}
+	return openapi.Schema{}
+}
+
+// typeSchema returns the schema of a type expression: basic types are
+// inlined, named types become references
+func typeSchema(expr ast.Expr) openapi.Schema {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return openapi.Schema{Type: "string"}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "rune", "byte":
+			return openapi.Schema{Type: "integer"}
+		case "float32", "float64":
+			return openapi.Schema{Type: "number"}
+		case "bool":
+			return openapi.Schema{Type: "boolean"}
+		case "any":
+			return openapi.Schema{}
+		}
+		return openapi.Schema{Ref: openapi.SchemaRefPrefix + t.Name}
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" {
+			return openapi.Schema{Type: "string", Format: "date-time"}
+		}
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "gin" && t.Sel.Name == "H" {
+			return openapi.Schema{Type: "object"}
+		}
+		return openapi.Schema{Ref: openapi.SchemaRefPrefix + t.Sel.Name}
+	case *ast.StarExpr:
+		return typeSchema(t.X)
+	case *ast.ArrayType:
+		items := typeSchema(t.Elt)
+		return openapi.Schema{Type: "array", Items: &items}
+	case *ast.MapType:
+		return openapi.Schema{Type: "object"}
+	}
+	return openapi.Schema{}
+}
+
+// isSuccessStatusExpr reports whether a status argument is a 2xx literal or
+// net/http constant
+func isSuccessStatusExpr(expr ast.Expr) bool {
+	code := statusCode(expr)
+	return code >= 200 && code < 300
+}
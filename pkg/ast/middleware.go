@@ -0,0 +1,91 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"strings"
+)
+
+// authMiddlewareWords mark middleware that authenticates the caller, e.g.
+// middleware.JWTAuth() or RequireLogin
+var authMiddlewareWords = []string{"auth", "jwt", "token", "login", "session", "apikey"}
+
+// routeHandler splits the handler arguments of a route registration: the
+// last one is the handler, the ones before it are middleware, like
+// r.GET("/users", authMiddleware(), rateLimit(), GetUser)
+func routeHandler(args []ast.Expr) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	return handlerName(args[len(args)-1]), middlewareNames(args[:len(args)-1])
+}
+
+// handlerName returns the name of a handler argument. Handlers built by a
+// factory like handler.List(db) are named after the factory, inline
+// functions are named "handler".
+func handlerName(expr ast.Expr) string {
+	switch h := expr.(type) {
+	case *ast.Ident:
+		return h.Name
+	case *ast.SelectorExpr:
+		return h.Sel.Name
+	case *ast.CallExpr:
+		if name := callName(h); name != "" {
+			return name[strings.LastIndex(name, ".")+1:]
+		}
+	}
+	return "handler"
+}
+
+// middlewareNames returns the names of middleware arguments: "pkg.Func" for
+// calls and references, in the order they run
+func middlewareNames(args []ast.Expr) []string {
+	var names []string
+	for _, arg := range args {
+		var name string
+		switch m := arg.(type) {
+		case *ast.CallExpr:
+			name = callName(m)
+		case *ast.Ident:
+			name = m.Name
+		case *ast.SelectorExpr:
+			if x, ok := m.X.(*ast.Ident); ok {
+				name = x.Name + "." + m.Sel.Name
+			} else {
+				name = m.Sel.Name
+			}
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RequiresAuth reports whether one of the route's middleware looks like
+// authentication
+func (r *RouteInfo) RequiresAuth() bool {
+	for _, name := range r.Middleware {
+		lower := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+		for _, word := range authMiddlewareWords {
+			if strings.Contains(lower, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addMiddleware records the middleware of an operation in x-middleware, and
+// documents 401 for routes behind authentication middleware
+func (r *RouteInfo) addMiddleware(op *openapi.Operation) {
+	if len(r.Middleware) == 0 {
+		return
+	}
+	op.SetExtension("x-middleware", r.Middleware)
+	if r.RequiresAuth() {
+		if _, ok := op.Responses["401"]; !ok {
+			op.Responses["401"] = openapi.Response{Description: "Unauthorized"}
+		}
+	}
+}
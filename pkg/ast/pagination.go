@@ -0,0 +1,123 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"strings"
+)
+
+// Pagination styles of a list endpoint
+const (
+	PaginationPage   = "page"   // page number and page size
+	PaginationOffset = "offset" // offset and limit
+)
+
+// Pagination is a project's pagination convention: the query parameters its
+// list endpoints read and the fields of the paginated response, e.g.
+//
+//	GET /users?page=2&page_size=20 -> {"list": [...], "total": 135}
+type Pagination struct {
+	PageParam   string
+	SizeParam   string
+	OffsetParam string
+	LimitParam  string
+	DefaultSize int
+	MaxSize     int
+	ItemsField  string
+	TotalField  string
+}
+
+// normalizeName folds page_size, pageSize and PageSize together
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// Detect returns the pagination style of a route from the query parameters
+// its handler reads and the fields of its bound request struct, or "" for
+// routes that aren't paginated
+func (p *Pagination) Detect(route RouteInfo, requestFields map[string]openapi.Schema) string {
+	if p == nil || route.Method != "GET" {
+		return ""
+	}
+	names := make(map[string]bool, len(route.QueryParams)+len(requestFields))
+	for _, param := range route.QueryParams {
+		names[normalizeName(param.Name)] = true
+	}
+	for field := range requestFields {
+		names[normalizeName(field)] = true
+	}
+	switch {
+	case names[normalizeName(p.OffsetParam)]:
+		return PaginationOffset
+	case names[normalizeName(p.PageParam)]:
+		return PaginationPage
+	case names[normalizeName(p.LimitParam)]:
+		return PaginationOffset
+	case names[normalizeName(p.SizeParam)]:
+		return PaginationPage
+	}
+	return ""
+}
+
+// parameters returns the standard query parameters of a pagination style
+func (p *Pagination) parameters(style string) []openapi.Parameter {
+	one, zero := 1.0, 0.0
+	size := openapi.Schema{Type: "integer", Minimum: &one}
+	if p.DefaultSize > 0 {
+		size.Default = p.DefaultSize
+	}
+	if p.MaxSize > 0 {
+		max := float64(p.MaxSize)
+		size.Maximum = &max
+	}
+	if style == PaginationOffset {
+		return []openapi.Parameter{
+			{Name: p.OffsetParam, In: "query", Description: "Number of items to skip", Schema: openapi.Schema{Type: "integer", Minimum: &zero, Default: 0}},
+			{Name: p.LimitParam, In: "query", Description: "Maximum number of items to return", Schema: size},
+		}
+	}
+	return []openapi.Parameter{
+		{Name: p.PageParam, In: "query", Description: "Page number, starting from 1", Schema: openapi.Schema{Type: "integer", Minimum: &one, Default: 1}},
+		{Name: p.SizeParam, In: "query", Description: "Number of items per page", Schema: size},
+	}
+}
+
+// apply documents an operation as paginated: the pagination parameters the
+// handler reads are replaced by the standard ones, keeping the spelling the
+// handler uses (pageSize for page_size), and a list payload is wrapped in the
+// paginated response. Payloads that are objects already carry their own
+// pagination fields and are left as is.
+func (p *Pagination) apply(op *openapi.Operation, style string, data openapi.Schema) openapi.Schema {
+	replaced := make(map[string]bool)
+	for _, name := range []string{p.PageParam, p.SizeParam, p.OffsetParam, p.LimitParam} {
+		replaced[normalizeName(name)] = true
+	}
+	read := make(map[string]string)
+	params := make([]openapi.Parameter, 0, len(op.Parameters)+2)
+	for _, param := range op.Parameters {
+		if param.In == "query" && replaced[normalizeName(param.Name)] {
+			read[normalizeName(param.Name)] = param.Name
+			continue
+		}
+		params = append(params, param)
+	}
+	for _, param := range p.parameters(style) {
+		if name, ok := read[normalizeName(param.Name)]; ok {
+			param.Name = name
+		}
+		params = append(params, param)
+	}
+	op.Parameters = params
+	op.SetExtension("x-pagination", style)
+
+	if data.Type != "array" {
+		return data
+	}
+	return openapi.Schema{
+		Type: "object",
+		Properties: map[string]openapi.Schema{
+			p.ItemsField: data,
+			p.TotalField: {Type: "integer", Format: "int64", Description: "Total number of items"},
+		},
+		Required: []string{p.ItemsField, p.TotalField},
+	}
+}
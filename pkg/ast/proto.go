@@ -0,0 +1,110 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"api-doc-generator/pkg/protoconv"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+const protoreflectPath = "google.golang.org/protobuf/reflect/protoreflect"
+
+// protoFacts returns the facts of a Go file generated by protoc-gen-go: the
+// messages and enums of its .proto file, documented as protojson writes them
+// rather than from the generated structs. Of the external types referenced by
+// the structs, only those the messages still refer to are kept, i.e. messages
+// of other proto packages but not well-known types. ok is false for other
+// files and for descriptors that can't be read, which are analyzed as usual.
+// Called on the extractor of ExtractFacts.
+func (sa *StructAnalyzer) protoFacts(node *ast.File, packageName string) (facts *StructFacts, ok bool) {
+	file, err := protoconv.FileFromGoSource(node)
+	if err != nil || file == nil {
+		return nil, false
+	}
+	converter := protoconv.NewConverter()
+	converter.AddFile(file)
+
+	facts = &StructFacts{Package: packageName}
+	refs := make(map[string]bool)
+	names := make([]string, 0, len(converter.Schemas()))
+	for name := range converter.Schemas() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := *converter.Schemas()[name]
+		facts.Structs = append(facts.Structs, StructFact{Name: name, Schema: schema})
+		openapi.CollectRefs(schema, refs)
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if structType, ok := n.(*ast.StructType); ok {
+			sa.extractStructSchemaWithName(structType, "")
+		}
+		return true
+	})
+	for name, importPath := range sa.externalRefs {
+		if refs[name] && converter.Schemas()[name] == nil {
+			facts.ExternalRefs = append(facts.ExternalRefs, ExternalRef{Name: name, ImportPath: importPath})
+		}
+	}
+	sort.Slice(facts.ExternalRefs, func(i, j int) bool {
+		return facts.ExternalRefs[i].Name < facts.ExternalRefs[j].Name
+	})
+	return facts, true
+}
+
+// protojsonValue returns the message marshaled by protojson.Marshal(m) or
+// protojson.MarshalOptions{...}.Marshal(m)
+func protojsonValue(expr ast.Expr) (ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Marshal" {
+		return nil, false
+	}
+	switch x := sel.X.(type) {
+	case *ast.Ident:
+		ok = x.Name == "protojson"
+	case *ast.CompositeLit:
+		ok = extractTypeFromExpr(x.Type) == "MarshalOptions"
+	default:
+		ok = false
+	}
+	return call.Args[0], ok
+}
+
+// isJSONData reports whether c.Data(status, contentType, data) writes JSON
+func isJSONData(args []ast.Expr) bool {
+	if len(args) < 3 {
+		return false
+	}
+	contentType, ok := stringLiteral(args[1])
+	return ok && strings.Contains(contentType, "json")
+}
+
+// isProtoType reports whether a named type is a message or enum generated by
+// protoc-gen-go, whose methods return protoreflect values
+func isProtoType(named *types.Named) bool {
+	methods := types.NewMethodSet(types.NewPointer(named))
+	for _, name := range []string{"ProtoReflect", "Number"} {
+		selection := methods.Lookup(nil, name)
+		if selection == nil {
+			continue
+		}
+		results := selection.Type().(*types.Signature).Results()
+		if results.Len() != 1 {
+			continue
+		}
+		if result, ok := types.Unalias(results.At(0).Type()).(*types.Named); ok {
+			if pkg := result.Obj().Pkg(); pkg != nil && pkg.Path() == protoreflectPath {
+				return true
+			}
+		}
+	}
+	return false
+}
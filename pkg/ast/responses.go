@@ -0,0 +1,142 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RawResponse is a response a handler writes without c.JSON: text, XML,
+// HTML, YAML, raw data, files and redirects
+type RawResponse struct {
+	Status      int    // 0 when the status is not a constant
+	ContentType string // empty for redirects
+	Schema      openapi.Schema
+}
+
+// jsonRenderers are the gin.Context methods and response wrappers writing JSON
+var jsonRenderers = map[string]bool{
+	"JSON": true, "IndentedJSON": true, "PureJSON": true, "SecureJSON": true, "AsciiJSON": true, "JSONP": true,
+	"SetResponseOK": true, "Success": true, "OK": true,
+}
+
+// statusNames are the net/http constants of the status codes handlers commonly use
+var statusNames = map[string]int{
+	"StatusOK": http.StatusOK, "StatusCreated": http.StatusCreated, "StatusAccepted": http.StatusAccepted,
+	"StatusNonAuthoritativeInfo": http.StatusNonAuthoritativeInfo, "StatusNoContent": http.StatusNoContent,
+	"StatusResetContent": http.StatusResetContent, "StatusPartialContent": http.StatusPartialContent,
+	"StatusMultipleChoices": http.StatusMultipleChoices, "StatusMovedPermanently": http.StatusMovedPermanently,
+	"StatusFound": http.StatusFound, "StatusSeeOther": http.StatusSeeOther, "StatusNotModified": http.StatusNotModified,
+	"StatusTemporaryRedirect": http.StatusTemporaryRedirect, "StatusPermanentRedirect": http.StatusPermanentRedirect,
+	"StatusBadRequest": http.StatusBadRequest, "StatusUnauthorized": http.StatusUnauthorized,
+	"StatusForbidden": http.StatusForbidden, "StatusNotFound": http.StatusNotFound,
+	"StatusMethodNotAllowed": http.StatusMethodNotAllowed, "StatusConflict": http.StatusConflict,
+	"StatusUnprocessableEntity": http.StatusUnprocessableEntity, "StatusTooManyRequests": http.StatusTooManyRequests,
+	"StatusInternalServerError": http.StatusInternalServerError, "StatusServiceUnavailable": http.StatusServiceUnavailable,
+}
+
+// statusCode returns the code of a status argument written as a literal or a
+// net/http constant, or 0
+func statusCode(expr ast.Expr) int {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		code, _ := strconv.Atoi(e.Value)
+		return code
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "http" {
+			return statusNames[e.Sel.Name]
+		}
+	}
+	return 0
+}
+
+var binarySchema = openapi.Schema{Type: "string", Format: "binary"}
+
+// rawResponse returns the response written by a gin.Context call that does
+// not render JSON:
+//
+//	c.String(200, "ok")                  text/plain
+//	c.XML(200, obj), c.YAML(200, obj)    application/xml, application/yaml
+//	c.HTML(200, "index.tmpl", data)      text/html
+//	c.Data(200, "image/png", data)       the given content type
+//	c.File(path), c.FileAttachment(...)  application/octet-stream
+//	c.Redirect(302, url)                 the redirect status with Location
+func rawResponse(method string, args []ast.Expr) (RawResponse, bool) {
+	status := func() int {
+		if len(args) == 0 {
+			return 0
+		}
+		return statusCode(args[0])
+	}
+	switch method {
+	case "String":
+		return RawResponse{Status: status(), ContentType: "text/plain", Schema: openapi.Schema{Type: "string"}}, true
+	case "HTML":
+		return RawResponse{Status: status(), ContentType: "text/html", Schema: openapi.Schema{Type: "string"}}, true
+	case "XML", "YAML":
+		schema := openapi.Schema{Type: "object"}
+		if len(args) > 1 {
+			if typeName := extractTypeFromExpr(args[1]); typeName != "" && typeName != "H" && !strings.HasPrefix(typeName, "map[") {
+				schema = openapi.Schema{Ref: openapi.SchemaRefPrefix + typeName}
+			}
+		}
+		return RawResponse{Status: status(), ContentType: "application/" + strings.ToLower(method), Schema: schema}, true
+	case "Data", "DataFromReader":
+		contentType := "application/octet-stream"
+		index := 1
+		if method == "DataFromReader" {
+			index = 2
+		}
+		if len(args) > index {
+			if value, ok := stringLiteral(args[index]); ok && value != "" {
+				contentType = value
+			}
+		}
+		schema := binarySchema
+		if strings.HasPrefix(contentType, "text/") {
+			schema = openapi.Schema{Type: "string"}
+		}
+		return RawResponse{Status: status(), ContentType: contentType, Schema: schema}, true
+	case "File", "FileAttachment", "FileFromFS":
+		return RawResponse{Status: http.StatusOK, ContentType: "application/octet-stream", Schema: binarySchema}, true
+	case "Redirect":
+		code := status()
+		if code == 0 {
+			code = http.StatusFound
+		}
+		return RawResponse{Status: code}, true
+	}
+	return RawResponse{}, false
+}
+
+// addRawResponses documents the raw responses of an operation. Responses
+// without a constant status are documented as 200.
+func addRawResponses(op *openapi.Operation, responses []RawResponse) {
+	for _, raw := range responses {
+		status := raw.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+		resp := op.Responses[code]
+		if resp.Description == "" {
+			resp.Description = http.StatusText(status)
+		}
+		if raw.ContentType == "" {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]openapi.Header)
+			}
+			resp.Headers["Location"] = openapi.Header{Description: "Redirect target", Schema: openapi.Schema{Type: "string"}}
+		} else {
+			if resp.Content == nil {
+				resp.Content = make(map[string]openapi.MediaType)
+			}
+			if _, ok := resp.Content[raw.ContentType]; !ok {
+				resp.Content[raw.ContentType] = openapi.MediaType{Schema: raw.Schema}
+			}
+		}
+		op.Responses[code] = resp
+	}
+}
@@ -0,0 +1,338 @@
+package ast
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// maxMountDepth bounds how many setup functions a router is followed through
+const maxMountDepth = 8
+
+// RouterOrigin ties a route to the router parameter of the setup function
+// registering it, e.g. rg in RegisterUserRoutes(rg *gin.RouterGroup)
+type RouterOrigin struct {
+	Func  string // key of the setup function, see funcKey
+	Param int    // index of the router parameter
+}
+
+// RouterMount is a call passing a router to a setup function, like
+// RegisterUserRoutes(v1) or user.RegisterRoutes(api.Group("/users"))
+type RouterMount struct {
+	Callee      string // key of the called function, see funcKey
+	Param       int    // index of the argument holding the router
+	Prefix      string // path prefix of the router in the calling function
+	Engine      string
+	Middleware  []string
+	Limits      RouteLimits
+	Caller      string // key of the calling function
+	CallerParam int    // router parameter of the caller the router derives from, -1 for none
+}
+
+// localPackage stands for the package of the analyzed file in function keys,
+// until QualifyFuncKey replaces it with the package's import path
+const localPackage = "~"
+
+// unresolvedPackage marks calls through a name that is not an import alias
+// nor a typed variable, until ResolvePackageKey resolves them
+const unresolvedPackage = "?"
+
+// funcKey identifies a function across files: "importpath.Func" for
+// functions, "(Type).Method" for methods. Files only know their package
+// as localPackage (see QualifyFuncKey).
+func funcKey(pkg string, funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		return "(" + receiverTypeName(funcDecl.Recv.List[0].Type) + ")." + funcDecl.Name.Name
+	}
+	return pkg + "." + funcDecl.Name.Name
+}
+
+// receiverTypeName returns T for receivers T, *T and T[K]
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "*"
+}
+
+// isRouterType reports whether a parameter type is a gin router:
+// *gin.RouterGroup, *gin.Engine, gin.IRouter or gin.IRoutes
+func isRouterType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || pkg.Name != "gin" {
+		return false
+	}
+	switch sel.Sel.Name {
+	case "RouterGroup", "Engine", "IRouter", "IRoutes":
+		return true
+	}
+	return false
+}
+
+// routerParams returns the router parameters of a function by name
+func routerParams(funcDecl *ast.FuncDecl) map[string]int {
+	params := make(map[string]int)
+	index := 0
+	for _, field := range funcDecl.Type.Params.List {
+		if len(field.Names) == 0 {
+			index++
+			continue
+		}
+		for _, name := range field.Names {
+			if isRouterType(field.Type) {
+				params[name.Name] = index
+			}
+			index++
+		}
+	}
+	return params
+}
+
+// QualifyFuncKey replaces the placeholder of the file's own package in a
+// function key with the import path of the package, so keys match those of
+// calls from other packages, which import it by that path
+func QualifyFuncKey(key, importPath string) string {
+	if strings.HasPrefix(key, localPackage+".") {
+		return importPath + strings.TrimPrefix(key, localPackage)
+	}
+	return key
+}
+
+// ResolvePackageKey resolves the key of a call through a name that is
+// neither an import alias nor a typed variable, "?name.Func" (see
+// calleeKey): a package imported without alias whose name differs from its
+// directory, e.g. package api in .../apiv1. It returns the function of the
+// imported package declaring that name, or else a method of an unknown type,
+// "(*).Func". packageOf returns the package name declared at an import
+// path, "" for packages outside the project.
+func ResolvePackageKey(key string, imports []string, packageOf func(importPath string) string) string {
+	name, fn, ok := strings.Cut(strings.TrimPrefix(key, unresolvedPackage), ".")
+	if !strings.HasPrefix(key, unresolvedPackage) || !ok {
+		return key
+	}
+	for _, importPath := range imports {
+		if packageOf(importPath) == name {
+			return importPath + "." + fn
+		}
+	}
+	return "(*)." + fn
+}
+
+// ImportPaths returns the paths a file imports without alias, which it
+// refers to by the declared package name
+func ImportPaths(node *ast.File) []string {
+	var paths []string
+	for _, imp := range node.Imports {
+		if imp.Name == nil {
+			paths = append(paths, strings.Trim(imp.Path.Value, `"`))
+		}
+	}
+	return paths
+}
+
+// PackageNameKey reduces the import path of a function key to the package
+// name guessed from it, for projects without go.mod, whose import paths
+// can't be mapped to directories
+func PackageNameKey(key string) string {
+	i := strings.LastIndex(key, ".")
+	if strings.HasPrefix(key, "(") || i < 0 {
+		return key
+	}
+	return defaultImportName(key[:i]) + key[i:]
+}
+
+// calleeKey returns the key of the function a call refers to, with the
+// package of imported functions given by its full import path (imports maps
+// the names the file refers to its imports by, see fileImports). The
+// receiver type of a method call is taken from varTypes or from a
+// NewType(...) constructor; "(*).Method" stands for a method of an unknown
+// type. Other names are left to ResolvePackageKey.
+func calleeKey(call *ast.CallExpr, pkg string, imports, varTypes map[string]string) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return pkg + "." + fn.Name
+	case *ast.SelectorExpr:
+		switch x := fn.X.(type) {
+		case *ast.Ident:
+			if importPath, ok := imports[x.Name]; ok {
+				return importPath + "." + fn.Sel.Name
+			}
+			if typeName, ok := varTypes[x.Name]; ok {
+				return "(" + typeName + ")." + fn.Sel.Name
+			}
+			return unresolvedPackage + x.Name + "." + fn.Sel.Name
+		case *ast.CallExpr:
+			if typeName := constructedType(x); typeName != "" {
+				return "(" + typeName + ")." + fn.Sel.Name
+			}
+		}
+		return "(*)." + fn.Sel.Name
+	}
+	return ""
+}
+
+// constructedType returns the type built by an expression: T for &T{},
+// pkg.T{} and NewT(...) calls, or ""
+func constructedType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		return constructedType(e.X)
+	case *ast.CompositeLit:
+		return receiverTypeName(e.Type)
+	case *ast.CallExpr:
+		name := callName(e)
+		name = name[strings.LastIndex(name, ".")+1:]
+		if strings.HasPrefix(name, "New") && len(name) > len("New") {
+			return strings.TrimPrefix(name, "New")
+		}
+	}
+	return ""
+}
+
+// namedType returns T for parameter types T, *T and pkg.T, or ""
+func namedType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return namedType(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// mountContext is what a router carries into a setup function
+type mountContext struct {
+	prefix     string
+	engine     string
+	middleware []string
+	limits     RouteLimits
+}
+
+// RouterMounts resolves routes registered on router parameters against the
+// calls passing routers to their setup functions
+type RouterMounts struct {
+	byCallee map[string][]RouterMount
+	methods  map[string]map[string]bool // method name -> receiver types of the setup methods declaring it
+}
+
+// NewRouterMounts indexes the router mounts of a project, with the routes
+// registered on router parameters, which tell the setup methods declared
+func NewRouterMounts(mounts []RouterMount, routes []RouteInfo) *RouterMounts {
+	m := &RouterMounts{byCallee: make(map[string][]RouterMount), methods: make(map[string]map[string]bool)}
+	for _, mount := range mounts {
+		m.byCallee[mount.Callee] = append(m.byCallee[mount.Callee], mount)
+		if mount.CallerParam >= 0 {
+			m.declare(mount.Caller)
+		}
+	}
+	for _, route := range routes {
+		if route.Origin != nil {
+			m.declare(route.Origin.Func)
+		}
+	}
+	return m
+}
+
+// declare records a setup function that is a method
+func (m *RouterMounts) declare(key string) {
+	end := strings.Index(key, ").")
+	if !strings.HasPrefix(key, "(") || end < 0 {
+		return
+	}
+	name := key[end+2:]
+	if m.methods[name] == nil {
+		m.methods[name] = make(map[string]bool)
+	}
+	m.methods[name][key[1:end]] = true
+}
+
+// Expand returns the route once for every router its setup function is
+// called with, with the router's prefix, engine, middleware and limits.
+// Routes not registered on a router parameter, or whose setup function is
+// never called with a known router, are returned as is.
+func (m *RouterMounts) Expand(route RouteInfo) []RouteInfo {
+	if route.Origin == nil {
+		return []RouteInfo{route}
+	}
+	contexts := m.resolve(route.Origin.Func, route.Origin.Param, 0)
+	if len(contexts) == 0 {
+		return []RouteInfo{route}
+	}
+	routes := make([]RouteInfo, 0, len(contexts))
+	for _, ctx := range contexts {
+		mounted := route
+		mounted.Origin = nil
+		if ctx.prefix != "" {
+			if mounted.Path == "/" {
+				mounted.Path = convertGinPathToOpenAPI(ctx.prefix)
+			} else {
+				mounted.Path = convertGinPathToOpenAPI(ctx.prefix) + mounted.Path
+			}
+			mounted.GroupPrefix = ctx.prefix + mounted.GroupPrefix
+			mounted.HasParam = mounted.HasParam || strings.Contains(ctx.prefix, ":")
+		}
+		if mounted.Engine == "" {
+			mounted.Engine = ctx.engine
+		}
+		mounted.Middleware = append(append([]string(nil), ctx.middleware...), route.Middleware...)
+		mounted.Limits = mounted.Limits.merge(ctx.limits)
+		routes = append(routes, mounted)
+	}
+	return routes
+}
+
+// resolve returns the routers the param-th parameter of a function is
+// called with, following routers passed on from the caller's own parameters
+func (m *RouterMounts) resolve(fn string, param, depth int) []mountContext {
+	if depth > maxMountDepth {
+		return nil
+	}
+	mounts := m.byCallee[fn]
+	if end := strings.Index(fn, ")."); strings.HasPrefix(fn, "(") && end >= 0 {
+		// Method calls on values of unknown type can only be this method
+		// when no other type declares a setup method of that name
+		if name := fn[end+2:]; len(m.methods[name]) == 1 {
+			mounts = append(append([]RouterMount(nil), mounts...), m.byCallee["(*)."+name]...)
+		}
+	}
+	var contexts []mountContext
+	for _, mount := range mounts {
+		if mount.Param != param {
+			continue
+		}
+		parents := []mountContext{{}}
+		if mount.CallerParam >= 0 {
+			if resolved := m.resolve(mount.Caller, mount.CallerParam, depth+1); len(resolved) > 0 {
+				parents = resolved
+			}
+		}
+		for _, parent := range parents {
+			ctx := mountContext{
+				prefix:     parent.prefix + mount.Prefix,
+				engine:     mount.Engine,
+				middleware: append(append([]string(nil), parent.middleware...), mount.Middleware...),
+				limits:     mount.Limits.merge(parent.limits),
+			}
+			if ctx.engine == "" {
+				ctx.engine = parent.engine
+			}
+			contexts = append(contexts, ctx)
+		}
+	}
+	return contexts
+}
@@ -26,6 +26,12 @@ func NewServiceAnalyzer() *ServiceAnalyzer {
 
 // AnalyzeFile 分析文件中的 service 函数
 func (sa *ServiceAnalyzer) AnalyzeFile(node *ast.File, packageName string) {
+	sa.AddFunctions(sa.ExtractFunctions(node, packageName))
+}
+
+// ExtractFunctions 提取文件中导出的 service 函数，不修改分析器
+func (sa *ServiceAnalyzer) ExtractFunctions(node *ast.File, packageName string) []*ServiceFuncInfo {
+	var functions []*ServiceFuncInfo
 	ast.Inspect(node, func(n ast.Node) bool {
 		funcDecl, ok := n.(*ast.FuncDecl)
 		if !ok {
@@ -40,12 +46,19 @@ func (sa *ServiceAnalyzer) AnalyzeFile(node *ast.File, packageName string) {
 		// 分析函数签名和函数体
 		info := sa.analyzServiceFunc(funcDecl, packageName)
 		if info != nil {
-			key := packageName + "." + funcDecl.Name.Name
-			sa.functions[key] = info
+			functions = append(functions, info)
 		}
 
 		return true
 	})
+	return functions
+}
+
+// AddFunctions 登记 ExtractFunctions 提取的函数
+func (sa *ServiceAnalyzer) AddFunctions(functions []*ServiceFuncInfo) {
+	for _, info := range functions {
+		sa.functions[info.Package+"."+info.Name] = info
+	}
 }
 
 // analyzServiceFunc 分析单个 service 函数
@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Kinds of registrations that are not handler endpoints. Plain routes have
+// an empty Kind.
+const (
+	RouteStatic     = "static"      // r.Static / r.StaticFS: a directory served under a prefix
+	RouteStaticFile = "static_file" // r.StaticFile / r.StaticFileFS: a single file
+	RouteNoRoute    = "no_route"    // r.NoRoute: handler of unmatched paths, never documented
+	RouteNoMethod   = "no_method"   // r.NoMethod: handler of unmatched methods, never documented
+)
+
+// staticPathParam is the wildcard gin appends to directory mounts
+const staticPathParam = "filepath"
+
+// IsStatic reports whether the route serves files from r.Static and friends
+func (r *RouteInfo) IsStatic() bool {
+	return r.Kind == RouteStatic || r.Kind == RouteStaticFile
+}
+
+// IsFallback reports whether the route is a NoRoute or NoMethod handler
+func (r *RouteInfo) IsFallback() bool {
+	return r.Kind == RouteNoRoute || r.Kind == RouteNoMethod
+}
+
+// parseSpecialRoute recognizes static file mounts and NoRoute/NoMethod
+// handlers, which gin registers without an HTTP method
+func parseSpecialRoute(call *ast.CallExpr, groupPrefixes map[string]string) *RouteInfo {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	var groupPrefix string
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		groupPrefix = groupPrefixes[ident.Name]
+	}
+
+	switch sel.Sel.Name {
+	case "NoRoute", "NoMethod":
+		if len(call.Args) == 0 {
+			return nil
+		}
+		kind := RouteNoRoute
+		if sel.Sel.Name == "NoMethod" {
+			kind = RouteNoMethod
+		}
+		return &RouteInfo{Kind: kind, Handler: handlerName(call.Args[len(call.Args)-1]), GroupPrefix: groupPrefix}
+
+	case "Static", "StaticFS", "StaticFile", "StaticFileFS":
+		if len(call.Args) < 2 {
+			return nil
+		}
+		pathLit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || pathLit.Kind != token.STRING {
+			return nil
+		}
+		path := groupPrefix + strings.Trim(pathLit.Value, `"`)
+		route := &RouteInfo{Method: "GET", Kind: RouteStaticFile, GroupPrefix: groupPrefix}
+		if sel.Sel.Name == "Static" || sel.Sel.Name == "StaticFS" {
+			route.Kind = RouteStatic
+			route.HasParam = true
+			path = strings.TrimSuffix(path, "/") + "/{" + staticPathParam + "}"
+		}
+		route.Path = convertGinPathToOpenAPI(path)
+		return route
+	}
+	return nil
+}
+
+// staticOperation documents a static file mount
+func (r *RouteInfo) staticOperation() *openapi.Operation {
+	op := &openapi.Operation{
+		Summary:   "Static files",
+		Tags:      []string{"Static"},
+		Responses: make(map[string]openapi.Response),
+	}
+	if r.Kind == RouteStaticFile {
+		op.Summary = "Static file"
+	}
+	if r.Kind == RouteStatic {
+		op.Parameters = []openapi.Parameter{{
+			Name:        staticPathParam,
+			In:          "path",
+			Description: "Path of the file under the mount",
+			Required:    true,
+			Schema:      openapi.Schema{Type: "string"},
+		}}
+	}
+	op.Responses["200"] = openapi.Response{
+		Description: "File content",
+		Content: map[string]openapi.MediaType{
+			"application/octet-stream": {Schema: binarySchema},
+		},
+	}
+	op.Responses["404"] = openapi.Response{Description: "Not found"}
+	return op
+}
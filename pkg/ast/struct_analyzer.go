@@ -4,15 +4,22 @@ import (
 	"api-doc-generator/internal/openapi"
 	"go/ast"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 // StructAnalyzer extracts schema information from Go struct definitions
 type StructAnalyzer struct {
-	structs          map[string]*openapi.Schema // TypeName -> Schema
-	structPriority   map[string]int             // TypeName -> Priority (higher is better)
-	currentPackage   string                     // 当前解析的包名
-	embeddedFields   map[string][]string        // StructName -> []EmbeddedTypeName
+	structs        map[string]*openapi.Schema // TypeName -> Schema
+	structPriority map[string]int             // TypeName -> Priority (higher is better)
+	currentPackage string                     // 当前解析的包名
+	currentImports map[string]string          // 当前文件的 import 别名 -> import 路径
+	embeddedFields map[string][]string        // StructName -> []EmbeddedTypeName
+	externalRefs   map[string]string          // 以 pkg.Type 形式引用的 TypeName -> import 路径
+	namedTypes     map[string]string          // 基础类型别名 TypeName -> 底层类型，如 Status -> int
+	enumValues     map[string][]EnumValue     // TypeName -> 该类型的常量取值
+	typeMappers    []TypeMapper               // 自定义类型映射，优先于 $ref/object
+	deferRefs      bool                       // 提取 StructFacts 时不内联已知结构体，改为 localRefPrefix 占位
 }
 
 func NewStructAnalyzer() *StructAnalyzer {
@@ -20,6 +27,9 @@ func NewStructAnalyzer() *StructAnalyzer {
 		structs:        make(map[string]*openapi.Schema),
 		structPriority: make(map[string]int),
 		embeddedFields: make(map[string][]string),
+		externalRefs:   make(map[string]string),
+		namedTypes:     make(map[string]string),
+		enumValues:     make(map[string][]EnumValue),
 	}
 }
 
@@ -35,36 +45,7 @@ func (sa *StructAnalyzer) AnalyzeFile(node *ast.File) {
 
 // AnalyzeFileWithPackage extracts all struct definitions from a Go file with package context
 func (sa *StructAnalyzer) AnalyzeFileWithPackage(node *ast.File, packageName string) {
-	sa.currentPackage = packageName
-	
-	ast.Inspect(node, func(n ast.Node) bool {
-		// Look for type declarations
-		typeSpec, ok := n.(*ast.TypeSpec)
-		if !ok {
-			return true
-		}
-
-		// Only process struct types
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			return true
-		}
-
-		typeName := typeSpec.Name.Name
-		schema := sa.extractStructSchemaWithName(structType, typeName)
-		if schema != nil {
-			// 计算优先级：dao/model > service > controller
-			priority := calculatePriority(packageName)
-			
-			// 只有优先级更高或相同时才覆盖
-			if existingPriority, exists := sa.structPriority[typeName]; !exists || priority >= existingPriority {
-				sa.structs[typeName] = schema
-				sa.structPriority[typeName] = priority
-			}
-		}
-
-		return true
-	})
+	sa.AddFacts(sa.ExtractFacts(node, packageName))
 }
 
 // calculatePriority 计算包的优先级
@@ -142,14 +123,15 @@ func (sa *StructAnalyzer) ExpandEmbeddedFields() {
 }
 
 // extractStructSchemaWithName converts a Go struct to an OpenAPI schema with struct name context
-func (sa *StructAnalyzer) extractStructSchemaWithName(structType *ast.StructType, structName string) *openapi.Schema {
-	schema := &openapi.Schema{
+// and the fields whose schema refers to a type resolved later (see deferRefs)
+func (sa *StructAnalyzer) extractStructSchemaWithName(structType *ast.StructType, structName string) (schema *openapi.Schema, deferred map[string]FieldSource) {
+	schema = &openapi.Schema{
 		Type:       "object",
 		Properties: make(map[string]openapi.Schema),
 	}
 
 	if structType.Fields == nil {
-		return schema
+		return schema, nil
 	}
 
 	for _, field := range structType.Fields.List {
@@ -182,56 +164,18 @@ func (sa *StructAnalyzer) extractStructSchemaWithName(structType *ast.StructType
 
 		// Extract field schema
 		fieldSchema := sa.extractFieldSchema(field.Type)
-
-		// Extract field comment/description
-		if field.Comment != nil && len(field.Comment.List) > 0 {
-			// Combine all comment lines
-			var comments []string
-			for _, c := range field.Comment.List {
-				text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
-				if text != "" {
-					comments = append(comments, text)
-				}
-			}
-			if len(comments) > 0 {
-				fieldSchema.Description = strings.Join(comments, " ")
-			}
-		}
-		
-		// Also try doc comments (for fields with doc above them)
-		if field.Doc != nil && len(field.Doc.List) > 0 && fieldSchema.Description == "" {
-			var comments []string
-			for _, c := range field.Doc.List {
-				text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
-				if text != "" {
-					comments = append(comments, text)
-				}
-			}
-			if len(comments) > 0 {
-				fieldSchema.Description = strings.Join(comments, " ")
-			}
-		}
-
-		// Handle validation tags
-		sa.applyValidationTags(field.Tag, &fieldSchema)
-		
-		// Extract gorm tag for additional description
+		source := FieldSource{Comment: joinComments(field.Comment), Doc: joinComments(field.Doc)}
 		if field.Tag != nil {
-			gormTag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("gorm")
-			if gormTag != "" {
-				// Extract comment from gorm tag
-				if strings.Contains(gormTag, "comment:") {
-					parts := strings.Split(gormTag, "comment:")
-					if len(parts) > 1 {
-						comment := strings.Split(parts[1], ";")[0]
-						comment = strings.Trim(comment, `"'`)
-						if comment != "" && fieldSchema.Description == "" {
-							fieldSchema.Description = comment
-						}
-					}
-				}
+			source.Tag = strings.Trim(field.Tag.Value, "`")
+		}
+		if localRefName(fieldSchema.Ref) != "" {
+			// The schema is decorated again once the type is resolved
+			if deferred == nil {
+				deferred = make(map[string]FieldSource)
 			}
+			deferred[jsonName] = source
 		}
+		fieldSchema = decorateField(fieldSchema, source)
 
 		// Mark as required if not omitempty
 		if !omitempty {
@@ -244,7 +188,64 @@ func (sa *StructAnalyzer) extractStructSchemaWithName(structType *ast.StructType
 		schema.Properties[jsonName] = fieldSchema
 	}
 
-	return schema
+	return schema, deferred
+}
+
+// FieldSource holds the parts of a struct field declaration that decorate
+// the schema of its type: comments and the raw struct tag
+type FieldSource struct {
+	Comment string
+	Doc     string
+	Tag     string
+}
+
+// decorateField applies the description and validation rules of a field
+// declaration to the schema of its type
+func decorateField(fieldSchema openapi.Schema, source FieldSource) openapi.Schema {
+	// Extract field comment/description
+	if source.Comment != "" {
+		fieldSchema.Description = source.Comment
+	}
+
+	// Also try doc comments (for fields with doc above them)
+	if source.Doc != "" && fieldSchema.Description == "" {
+		fieldSchema.Description = source.Doc
+	}
+
+	// Handle validation tags
+	applyValidationTags(source.Tag, &fieldSchema)
+
+	// Extract gorm tag for additional description
+	gormTag := reflect.StructTag(source.Tag).Get("gorm")
+	if gormTag != "" {
+		// Extract comment from gorm tag
+		if strings.Contains(gormTag, "comment:") {
+			parts := strings.Split(gormTag, "comment:")
+			if len(parts) > 1 {
+				comment := strings.Split(parts[1], ";")[0]
+				comment = strings.Trim(comment, `"'`)
+				if comment != "" && fieldSchema.Description == "" {
+					fieldSchema.Description = comment
+				}
+			}
+		}
+	}
+	return fieldSchema
+}
+
+// joinComments combines the lines of a field comment into one description
+func joinComments(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	var comments []string
+	for _, c := range group.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if text != "" {
+			comments = append(comments, text)
+		}
+	}
+	return strings.Join(comments, " ")
 }
 
 // extractFieldSchema determines the OpenAPI schema for a field type
@@ -269,8 +270,7 @@ func (sa *StructAnalyzer) extractFieldSchema(expr ast.Expr) openapi.Schema {
 	case *ast.SelectorExpr:
 		// Qualified type (e.g., time.Time)
 		if ident, ok := t.X.(*ast.Ident); ok {
-			qualifiedName := ident.Name + "." + t.Sel.Name
-			return sa.qualifiedTypeToSchema(qualifiedName)
+			return sa.qualifiedTypeToSchema(ident.Name, t.Sel.Name)
 		}
 
 	case *ast.MapType:
@@ -314,7 +314,14 @@ func (sa *StructAnalyzer) identToSchema(typeName string) openapi.Schema {
 	case "bool":
 		return openapi.Schema{Type: "boolean"}
 	default:
+		// Custom type mapped by a plugin or the parser config
+		if schema, ok := sa.mapType(TypeRef{Package: sa.currentPackage, Name: typeName}); ok {
+			return schema
+		}
 		// Custom type - create a reference
+		if sa.deferRefs {
+			return openapi.Schema{Ref: localRefPrefix + typeName}
+		}
 		if schema, exists := sa.structs[typeName]; exists {
 			return *schema
 		}
@@ -324,15 +331,102 @@ func (sa *StructAnalyzer) identToSchema(typeName string) openapi.Schema {
 }
 
 // qualifiedTypeToSchema handles types like time.Time
-func (sa *StructAnalyzer) qualifiedTypeToSchema(qualifiedName string) openapi.Schema {
-	switch qualifiedName {
+func (sa *StructAnalyzer) qualifiedTypeToSchema(pkgName, typeName string) openapi.Schema {
+	ref := TypeRef{ImportPath: sa.currentImports[pkgName], Package: pkgName, Name: typeName}
+	if schema, ok := sa.mapType(ref); ok {
+		return schema
+	}
+
+	switch pkgName + "." + typeName {
 	case "time.Time":
 		return openapi.Schema{
 			Type:   "string",
 			Format: "date-time",
 		}
 	default:
-		return openapi.Schema{Type: "object"}
+		// Types from other packages become references; the import path is
+		// remembered so that types living outside the project can be resolved later
+		importPath, ok := sa.currentImports[pkgName]
+		if !ok {
+			return openapi.Schema{Type: "object"}
+		}
+		if _, exists := sa.externalRefs[typeName]; !exists {
+			sa.externalRefs[typeName] = importPath
+		}
+		return openapi.Schema{Ref: openapi.SchemaRefPrefix + typeName}
+	}
+}
+
+// fileImports maps the local name of every import in a file to its import path
+func fileImports(node *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, imp := range node.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+		if imp.Name != nil {
+			if imp.Name.Name != "_" && imp.Name.Name != "." {
+				imports[imp.Name.Name] = path
+			}
+			continue
+		}
+		imports[defaultImportName(path)] = path
+	}
+	return imports
+}
+
+// defaultImportName guesses the package name of an import path:
+// "github.com/acme/common" -> common, "gopkg.in/yaml.v3" -> yaml, "example.com/api/v2" -> api
+func defaultImportName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if idx := strings.Index(name, ".v"); idx > 0 {
+		name = name[:idx]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// DanglingRefs returns the referenced external types that were not found
+// among the analyzed structs, keyed by type name with their import path
+func (sa *StructAnalyzer) DanglingRefs() map[string]string {
+	dangling := make(map[string]string)
+	for typeName, importPath := range sa.externalRefs {
+		if _, isNamed := sa.namedTypes[typeName]; isNamed {
+			continue
+		}
+		if _, exists := sa.structs[typeName]; !exists {
+			dangling[typeName] = importPath
+		}
+	}
+	return dangling
+}
+
+// AddExternalSchema registers a schema resolved from outside the project.
+// Existing schemas always win over external ones.
+func (sa *StructAnalyzer) AddExternalSchema(typeName string, schema *openapi.Schema) {
+	if _, exists := sa.structs[typeName]; exists {
+		return
+	}
+	sa.structs[typeName] = schema
+	sa.structPriority[typeName] = 0
+}
+
+// ReplaceDanglingRefs rewrites references to types that could not be resolved
+// into generic objects so the document never contains broken $refs
+func (sa *StructAnalyzer) ReplaceDanglingRefs() {
+	dangling := sa.DanglingRefs()
+	if len(dangling) == 0 {
+		return
+	}
+	for name, schema := range sa.structs {
+		rewritten := openapi.MapSchema(*schema, func(s openapi.Schema) openapi.Schema {
+			if _, missing := dangling[openapi.RefName(s.Ref)]; missing {
+				return openapi.Schema{Type: "object", Description: s.Description}
+			}
+			return s
+		})
+		sa.structs[name] = &rewritten
 	}
 }
 
@@ -366,13 +460,8 @@ func parseJSONTag(tag *ast.BasicLit) (name string, omitempty bool, skip bool) {
 }
 
 // applyValidationTags applies Gin binding validation tags to schema
-func (sa *StructAnalyzer) applyValidationTags(tag *ast.BasicLit, schema *openapi.Schema) {
-	if tag == nil {
-		return
-	}
-
-	tagStr := strings.Trim(tag.Value, "`")
-	bindingTag := reflect.StructTag(tagStr).Get("binding")
+func applyValidationTags(tag string, schema *openapi.Schema) {
+	bindingTag := reflect.StructTag(tag).Get("binding")
 
 	if bindingTag == "" {
 		return
@@ -386,9 +475,12 @@ func (sa *StructAnalyzer) applyValidationTags(tag *ast.BasicLit, schema *openapi
 		case rule == "required":
 			// Handled separately
 		case strings.HasPrefix(rule, "min="):
-			// Could add minimum constraint
+			applyBound(schema, strings.TrimPrefix(rule, "min="), false)
 		case strings.HasPrefix(rule, "max="):
-			// Could add maximum constraint
+			applyBound(schema, strings.TrimPrefix(rule, "max="), true)
+		case strings.HasPrefix(rule, "len="):
+			applyBound(schema, strings.TrimPrefix(rule, "len="), false)
+			applyBound(schema, strings.TrimPrefix(rule, "len="), true)
 		case rule == "email":
 			schema.Format = "email"
 		case rule == "url":
@@ -407,6 +499,38 @@ func (sa *StructAnalyzer) applyValidationTags(tag *ast.BasicLit, schema *openapi
 	}
 }
 
+// applyBound maps a min=/max=/len= rule to the constraint matching the field
+// type: string length, array size or numeric range
+func applyBound(schema *openapi.Schema, value string, upper bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string", "array":
+		if n < 0 || n != float64(int(n)) {
+			return
+		}
+		count := int(n)
+		switch {
+		case schema.Type == "string" && upper:
+			schema.MaxLength = &count
+		case schema.Type == "string":
+			schema.MinLength = &count
+		case upper:
+			schema.MaxItems = &count
+		default:
+			schema.MinItems = &count
+		}
+	case "integer", "number":
+		if upper {
+			schema.Maximum = &n
+		} else {
+			schema.Minimum = &n
+		}
+	}
+}
+
 // isExported checks if a field name is exported
 func isExported(name string) bool {
 	if name == "" {
@@ -0,0 +1,107 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// TypeRef identifies a Go type referenced by a struct field
+type TypeRef struct {
+	ImportPath string // e.g. github.com/acme/types, empty for types of the analyzed package
+	Package    string // package name as written in the source, e.g. types
+	Name       string // type name, e.g. Money
+}
+
+// TypeMapper lets organizations document their own types, e.g. types.Money as
+// an object with amount and currency instead of a $ref to the struct.
+// StructAnalyzer asks mappers before falling back to $ref or object schemas.
+type TypeMapper interface {
+	MapType(ref TypeRef) (openapi.Schema, bool)
+}
+
+// TypeMapperFunc adapts a function to TypeMapper
+type TypeMapperFunc func(ref TypeRef) (openapi.Schema, bool)
+
+func (f TypeMapperFunc) MapType(ref TypeRef) (openapi.Schema, bool) {
+	return f(ref)
+}
+
+// StaticTypeMapper maps type names to fixed schemas. Keys are matched from the
+// most to the least specific: "github.com/acme/types.Money", "types.Money", "Money".
+type StaticTypeMapper map[string]openapi.Schema
+
+func (m StaticTypeMapper) MapType(ref TypeRef) (openapi.Schema, bool) {
+	var keys []string
+	if ref.ImportPath != "" {
+		keys = append(keys, ref.ImportPath+"."+ref.Name)
+	}
+	if ref.Package != "" {
+		keys = append(keys, ref.Package+"."+ref.Name)
+	}
+	keys = append(keys, ref.Name)
+
+	for _, key := range keys {
+		if schema, ok := m[key]; ok {
+			return schema, true
+		}
+	}
+	return openapi.Schema{}, false
+}
+
+// NewStaticTypeMapper builds a StaticTypeMapper from the type_mappings of a
+// parser config, where every value is an OpenAPI schema object
+func NewStaticTypeMapper(mappings map[string]map[string]interface{}) (StaticTypeMapper, error) {
+	mapper := make(StaticTypeMapper, len(mappings))
+	for name, raw := range mappings {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("type mapping %s: %w", name, err)
+		}
+		var schema openapi.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("type mapping %s: %w", name, err)
+		}
+		mapper[name] = schema
+	}
+	return mapper, nil
+}
+
+var (
+	typeMappersMu sync.RWMutex
+	typeMappers   []TypeMapper
+)
+
+// RegisterTypeMapper adds a mapper used by every StructAnalyzer, after the
+// analyzer's own mappers. Call it from an init function of a plugin package
+// linked into the service binary.
+func RegisterTypeMapper(mapper TypeMapper) {
+	typeMappersMu.Lock()
+	defer typeMappersMu.Unlock()
+	typeMappers = append(typeMappers, mapper)
+}
+
+// AddTypeMapper adds a mapper used only by this analyzer, ahead of the
+// globally registered ones
+func (sa *StructAnalyzer) AddTypeMapper(mapper TypeMapper) {
+	sa.typeMappers = append(sa.typeMappers, mapper)
+}
+
+// mapType asks the analyzer's mappers, then the registered ones
+func (sa *StructAnalyzer) mapType(ref TypeRef) (openapi.Schema, bool) {
+	for _, mapper := range sa.typeMappers {
+		if schema, ok := mapper.MapType(ref); ok {
+			return schema, true
+		}
+	}
+
+	typeMappersMu.RLock()
+	defer typeMappersMu.RUnlock()
+	for _, mapper := range typeMappers {
+		if schema, ok := mapper.MapType(ref); ok {
+			return schema, true
+		}
+	}
+	return openapi.Schema{}, false
+}
@@ -0,0 +1,654 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const ginPackagePath = "github.com/gin-gonic/gin"
+
+// TypedResolver resolves handler request/response types and their schemas
+// with full type information, as loaded by golang.org/x/tools/go/packages.
+// Where the AST analysis guesses from names, the type checker knows: aliases
+// are followed, types of other packages and generic instantiations are
+// resolved, and an interface implemented by a single project type is
+// documented as that type.
+type TypedResolver struct {
+	sa       *StructAnalyzer // supplies the type mappers
+	packages []*packages.Package
+
+	fields map[token.Pos]*ast.Field // struct field declarations by name position
+	consts map[token.Pos]string     // comments of constant declarations
+	named  []*types.Named           // concrete named types of the project
+	shared map[string]bool          // type names declared by several project packages
+
+	names   map[string]string // schema name -> type key
+	keys    map[string]string // type key -> schema name
+	schemas map[string]*openapi.Schema
+}
+
+// LoadTypes type-checks the packages of the Go module at projectPath.
+// Dependencies are loaded by the go command, which downloads missing modules
+// with the given GOPROXY / GOPRIVATE (empty values keep the environment's).
+// Packages with errors are kept as far as they could be checked; an error is
+// returned only when nothing could be loaded. Cancelling ctx stops the go command.
+func LoadTypes(ctx context.Context, projectPath, goProxy, goPrivate string, sa *StructAnalyzer) (*TypedResolver, error) {
+	env := os.Environ()
+	if goProxy != "" {
+		env = append(env, "GOPROXY="+goProxy)
+	}
+	if goPrivate != "" {
+		env = append(env, "GOPRIVATE="+goPrivate)
+	}
+	cfg := &packages.Config{
+		// Dependencies are type-checked from source too, which does not depend
+		// on the export data format of the installed go toolchain
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Context: ctx,
+		Dir:     projectPath,
+		Env:     env,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	r := &TypedResolver{
+		sa:      sa,
+		fields:  make(map[token.Pos]*ast.Field),
+		consts:  make(map[token.Pos]string),
+		shared:  make(map[string]bool),
+		names:   make(map[string]string),
+		keys:    make(map[string]string),
+		schemas: make(map[string]*openapi.Schema),
+	}
+	var firstErr error
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 && firstErr == nil {
+			firstErr = pkg.Errors[0]
+		}
+		if pkg.Types == nil || pkg.TypesInfo == nil || len(pkg.Syntax) == 0 {
+			continue
+		}
+		r.packages = append(r.packages, pkg)
+	}
+	if len(r.packages) == 0 {
+		if firstErr != nil {
+			return nil, fmt.Errorf("no package could be type-checked: %w", firstErr)
+		}
+		return nil, fmt.Errorf("no Go packages found in %s", projectPath)
+	}
+	sort.Slice(r.packages, func(i, j int) bool { return r.packages[i].PkgPath < r.packages[j].PkgPath })
+
+	declared := make(map[string]int)
+	for _, pkg := range r.packages {
+		r.indexDeclarations(pkg, declared)
+	}
+	for name, count := range declared {
+		r.shared[name] = count > 1
+	}
+	return r, nil
+}
+
+// indexDeclarations records what types.Object does not carry: the comments of
+// struct fields and constants, the named types that may implement interfaces,
+// and how many packages declare each type name
+func (r *TypedResolver) indexDeclarations(pkg *packages.Package, declared map[string]int) {
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.StructType:
+				if node.Fields == nil {
+					break
+				}
+				for _, field := range node.Fields.List {
+					for _, name := range field.Names {
+						r.fields[name.Pos()] = field
+					}
+				}
+			case *ast.GenDecl:
+				if node.Tok != token.CONST {
+					break
+				}
+				for _, spec := range node.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+					comment := commentText(valueSpec.Comment)
+					if comment == "" {
+						comment = commentText(valueSpec.Doc)
+					}
+					for _, name := range valueSpec.Names {
+						r.consts[name.Pos()] = comment
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		declared[name]++
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		if _, isInterface := named.Underlying().(*types.Interface); !isInterface {
+			r.named = append(r.named, named)
+		}
+	}
+}
+
+// ResolveHandlers refines the request and response types of the handlers
+// with the types of the values actually bound and rendered. Handlers missed
+// by the AST analysis, e.g. because gin is imported under another name, are
+// added.
+func (r *TypedResolver) ResolveHandlers(handlers map[string]*HandlerInfo) {
+	for _, pkg := range r.packages {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+				fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+				if !ok || !takesGinContext(fn.Type().(*types.Signature)) {
+					continue
+				}
+				info, exists := handlers[funcDecl.Name.Name]
+				if !exists {
+					info = newHandlerInfo(pkg.Fset, funcDecl)
+					handlers[funcDecl.Name.Name] = info
+				}
+				r.resolveHandler(pkg.TypesInfo, funcDecl.Body, info)
+			}
+		}
+	}
+}
+
+// Methods of gin.Context binding the request body into their first argument
+var bindMethods = map[string]bool{
+	"ShouldBindJSON": true, "BindJSON": true, "ShouldBind": true, "Bind": true, "ShouldBindQuery": true,
+	"ShouldBindXML": true, "BindXML": true, "ShouldBindYAML": true, "BindYAML": true,
+	"ShouldBindWith": true, "ShouldBindBodyWith": true,
+}
+
+// Methods of gin.Context rendering their second argument as JSON
+var jsonMethods = map[string]bool{
+	"JSON": true, "IndentedJSON": true, "PureJSON": true, "SecureJSON": true, "AsciiJSON": true, "JSONP": true,
+}
+
+// resolveHandler determines the request and response types of one handler.
+// Custom response wrappers (SetResponseOK, Success, OK) take precedence like
+// in the AST analysis; among c.JSON calls one with a constant 2xx status is
+// preferred over error responses. A gin.H literal response gets an inline
+// schema with the types of its values. Messages marshaled with protojson and
+// written with c.Data count as rendered JSON.
+func (r *TypedResolver) resolveHandler(info *types.Info, body *ast.BlockStmt, handler *HandlerInfo) {
+	var request, wrapped, success, rendered string
+	var wrappedExpr, successExpr, renderedExpr ast.Expr
+	render := func(status, value ast.Expr) {
+		typeName := r.typeName(info.TypeOf(value))
+		if typeName == "" {
+			return
+		}
+		if rendered == "" {
+			rendered, renderedExpr = typeName, value
+		}
+		if success == "" && isSuccessStatus(info, status) {
+			success, successExpr = typeName, value
+		}
+	}
+	protojsonVars := make(map[types.Object]ast.Expr)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && len(assign.Rhs) == 1 {
+			if message, ok := protojsonValue(assign.Rhs[0]); ok {
+				if ident, ok := assign.Lhs[0].(*ast.Ident); ok && info.ObjectOf(ident) != nil {
+					protojsonVars[info.ObjectOf(ident)] = message
+				}
+			}
+			return true
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		onContext := isGinContext(info.TypeOf(sel.X))
+		name := sel.Sel.Name
+
+		switch {
+		case onContext && bindMethods[name] && len(call.Args) > 0:
+			if request == "" {
+				request = r.typeName(info.TypeOf(call.Args[0]))
+			}
+		case onContext && jsonMethods[name] && len(call.Args) > 1:
+			render(call.Args[0], call.Args[1])
+		case onContext && name == "Data" && isJSONData(call.Args):
+			if data, ok := call.Args[2].(*ast.Ident); ok && protojsonVars[info.ObjectOf(data)] != nil {
+				render(call.Args[0], protojsonVars[info.ObjectOf(data)])
+			}
+		case !onContext && (name == "SetResponseOK" || name == "Success" || name == "OK") && len(call.Args) > 1:
+			if wrapped == "" {
+				wrapped, wrappedExpr = r.typeName(info.TypeOf(call.Args[1])), call.Args[1]
+			}
+		}
+		return true
+	})
+
+	if request != "" {
+		handler.RequestType = request
+	}
+	responses := []string{wrapped, success, rendered}
+	for i, expr := range []ast.Expr{wrappedExpr, successExpr, renderedExpr} {
+		if responses[i] == "" {
+			continue
+		}
+		handler.ResponseType = responses[i]
+		handler.TypedResponse = true
+		handler.ResponseSchema = nil
+		if lit, ok := mapLiteral(expr); ok {
+			schema := r.literalSchema(info, lit)
+			handler.ResponseSchema = &schema
+		}
+		break
+	}
+}
+
+// literalSchema builds the schema of a gin.H literal from the types of its
+// values; values of interface type, such as nil, allow any value
+func (r *TypedResolver) literalSchema(info *types.Info, lit *ast.CompositeLit) openapi.Schema {
+	return objectSchema(lit, func(value ast.Expr) openapi.Schema {
+		if nested, ok := mapLiteral(value); ok {
+			return r.literalSchema(info, nested)
+		}
+		t := info.TypeOf(value)
+		if t == nil {
+			return openapi.Schema{}
+		}
+		if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+			return openapi.Schema{}
+		}
+		if _, ok := types.Unalias(t).(*types.Interface); ok {
+			return openapi.Schema{}
+		}
+		return r.schemaFor(t)
+	})
+}
+
+// Schemas returns the schemas of the types resolved so far. They replace the
+// AST schemas of the same name.
+func (r *TypedResolver) Schemas() map[string]*openapi.Schema {
+	return r.schemas
+}
+
+// typeName returns the name a handler's RequestType/ResponseType uses for t,
+// registering the schemas involved, or "" when t has no useful schema
+func (r *TypedResolver) typeName(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	switch u := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return r.typeName(u.Elem())
+	case *types.Slice:
+		return r.elementTypeName(u.Elem())
+	case *types.Array:
+		return r.elementTypeName(u.Elem())
+	case *types.Map:
+		return "map[string]interface{}"
+	case *types.Interface:
+		if impl := r.implementation(u); impl != nil {
+			return r.typeName(impl)
+		}
+		return "interface{}"
+	case *types.Named:
+		switch underlying := u.Underlying().(type) {
+		case *types.Interface:
+			return r.typeName(underlying)
+		case *types.Struct, *types.Basic:
+			return r.schemaName(u)
+		default:
+			// e.g. gin.H
+			return r.typeName(underlying)
+		}
+	}
+	return ""
+}
+
+func (r *TypedResolver) elementTypeName(elem types.Type) string {
+	name := r.typeName(elem)
+	if name == "" || name == "interface{}" || strings.HasPrefix(name, "[]") || strings.HasPrefix(name, "map[") {
+		return ""
+	}
+	return "[]" + name
+}
+
+// schemaName returns the component name of a named type, generating its
+// schema on first use. Instantiations of generic types are named after their
+// type arguments (Page[User] -> Page_User); names declared by several project
+// packages are qualified with the package name (model.User). Generated proto
+// messages and enums keep the name of their protojson schema (see protoName).
+func (r *TypedResolver) schemaName(named *types.Named) string {
+	if name, ok := r.protoName(named); ok {
+		return name
+	}
+	key := types.TypeString(named, nil)
+	if name, ok := r.keys[key]; ok {
+		return name
+	}
+
+	obj := named.Obj()
+	name := obj.Name()
+	if r.shared[name] && obj.Pkg() != nil {
+		name = obj.Pkg().Name() + "." + name
+	}
+	if args := named.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			name += "_" + r.argName(args.At(i))
+		}
+	}
+	for i, base := 2, name; ; i++ {
+		if _, taken := r.names[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	// Registered before the schema is built, for recursive types
+	r.keys[key] = name
+	r.names[name] = key
+	r.schemas[name] = &openapi.Schema{Type: "object"}
+	schema := r.namedSchema(named)
+	r.schemas[name] = &schema
+	return name
+}
+
+// argName names a type argument inside a generic instantiation's name
+func (r *TypedResolver) argName(t types.Type) string {
+	switch u := types.Unalias(t).(type) {
+	case *types.Named:
+		return r.schemaName(u)
+	case *types.Basic:
+		return u.Name()
+	case *types.Pointer:
+		return r.argName(u.Elem())
+	case *types.Slice:
+		return r.argName(u.Elem()) + "List"
+	case *types.Array:
+		return r.argName(u.Elem()) + "List"
+	case *types.Map:
+		return r.argName(u.Elem()) + "Map"
+	}
+	return "Any"
+}
+
+// namedSchema builds the component schema of a named type
+func (r *TypedResolver) namedSchema(named *types.Named) openapi.Schema {
+	if schema, ok := r.wellKnown(named); ok {
+		return schema
+	}
+	switch underlying := named.Underlying().(type) {
+	case *types.Struct:
+		return r.structSchema(underlying)
+	case *types.Basic:
+		return withEnumValues(basicSchema(underlying), r.enumValues(named))
+	default:
+		return r.schemaFor(underlying)
+	}
+}
+
+// wellKnown returns the schema of types with a custom mapping and of time.Time
+func (r *TypedResolver) wellKnown(named *types.Named) (openapi.Schema, bool) {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return openapi.Schema{}, false
+	}
+	if schema, ok := r.sa.mapType(TypeRef{ImportPath: obj.Pkg().Path(), Package: obj.Pkg().Name(), Name: obj.Name()}); ok {
+		return schema, true
+	}
+	if obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+		return openapi.Schema{Type: "string", Format: "date-time"}, true
+	}
+	return openapi.Schema{}, false
+}
+
+// protoName returns the name of a message or enum type generated by
+// protoc-gen-go when the AST analysis documented it from its descriptor, as
+// protojson writes it; the generated struct doesn't describe that JSON
+func (r *TypedResolver) protoName(named *types.Named) (string, bool) {
+	name := named.Obj().Name()
+	if r.sa.GetSchema(name) == nil || !isProtoType(named) {
+		return "", false
+	}
+	return name, true
+}
+
+// schemaFor returns the schema of a field type. Like in the AST analysis,
+// structs become references and named basic types are inlined with their
+// enum values.
+func (r *TypedResolver) schemaFor(t types.Type) openapi.Schema {
+	switch u := types.Unalias(t).(type) {
+	case *types.Basic:
+		return basicSchema(u)
+	case *types.Pointer:
+		return r.schemaFor(u.Elem())
+	case *types.Slice:
+		if elem, ok := types.Unalias(u.Elem()).(*types.Basic); ok && elem.Kind() == types.Byte {
+			// encoding/json writes []byte as base64
+			return openapi.Schema{Type: "string", Format: "byte"}
+		}
+		items := r.schemaFor(u.Elem())
+		return openapi.Schema{Type: "array", Items: &items}
+	case *types.Array:
+		items := r.schemaFor(u.Elem())
+		return openapi.Schema{Type: "array", Items: &items}
+	case *types.Map:
+		value := r.schemaFor(u.Elem())
+		return openapi.Schema{Type: "object", AdditionalProperties: &value}
+	case *types.Interface:
+		if impl := r.implementation(u); impl != nil {
+			return r.schemaFor(impl)
+		}
+	case *types.Named:
+		if schema, ok := r.wellKnown(u); ok {
+			return schema
+		}
+		if name, ok := r.protoName(u); ok {
+			return openapi.Schema{Ref: openapi.SchemaRefPrefix + name}
+		}
+		switch underlying := u.Underlying().(type) {
+		case *types.Struct:
+			return openapi.Schema{Ref: openapi.SchemaRefPrefix + r.schemaName(u)}
+		case *types.Basic:
+			return withEnumValues(basicSchema(underlying), r.enumValues(u))
+		default:
+			return r.schemaFor(underlying)
+		}
+	}
+	return openapi.Schema{Type: "object"}
+}
+
+func basicSchema(basic *types.Basic) openapi.Schema {
+	info := basic.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return openapi.Schema{Type: "boolean"}
+	case info&types.IsInteger != 0:
+		return openapi.Schema{Type: "integer"}
+	case info&types.IsFloat != 0:
+		return openapi.Schema{Type: "number"}
+	case info&types.IsString != 0:
+		return openapi.Schema{Type: "string"}
+	}
+	return openapi.Schema{Type: "object"}
+}
+
+// structSchema follows encoding/json: fields of embedded structs without a
+// json name are promoted unless a shallower field has the same name
+func (r *TypedResolver) structSchema(st *types.Struct) openapi.Schema {
+	schema := openapi.Schema{Type: "object", Properties: make(map[string]openapi.Schema)}
+	r.addFields(&schema, st, map[*types.Struct]bool{st: true})
+	return schema
+}
+
+func (r *TypedResolver) addFields(schema *openapi.Schema, st *types.Struct, visiting map[*types.Struct]bool) {
+	var embedded []*types.Struct
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := st.Tag(i)
+		jsonTag := reflect.StructTag(tag).Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(jsonTag, ",")
+
+		if field.Embedded() && name == "" {
+			fieldType := types.Unalias(field.Type())
+			if ptr, ok := fieldType.(*types.Pointer); ok {
+				fieldType = types.Unalias(ptr.Elem())
+			}
+			if inner, ok := fieldType.Underlying().(*types.Struct); ok {
+				if !visiting[inner] {
+					embedded = append(embedded, inner)
+				}
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = toLowerCamelCase(field.Name())
+		}
+		if _, exists := schema.Properties[name]; exists {
+			continue
+		}
+
+		source := FieldSource{Tag: tag}
+		if decl := r.fields[field.Pos()]; decl != nil {
+			source.Comment = joinComments(decl.Comment)
+			source.Doc = joinComments(decl.Doc)
+		}
+		schema.Properties[name] = decorateField(r.schemaFor(field.Type()), source)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, inner := range embedded {
+		visiting[inner] = true
+		r.addFields(schema, inner, visiting)
+		delete(visiting, inner)
+	}
+}
+
+// enumValues returns the constants of a named basic type declared in its
+// package, in declaration order
+func (r *TypedResolver) enumValues(named *types.Named) []EnumValue {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return nil
+	}
+	var consts []*types.Const
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	sort.SliceStable(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+
+	var values []EnumValue
+	for _, c := range consts {
+		var value interface{}
+		switch v := constant.Val(c.Val()).(type) {
+		case int64, string, bool:
+			value = v
+		default:
+			if f, ok := constant.Float64Val(c.Val()); ok {
+				value = f
+			}
+		}
+		if value == nil {
+			continue
+		}
+		values = append(values, EnumValue{Name: c.Name(), Value: value, Comment: r.consts[c.Pos()]})
+	}
+	return values
+}
+
+// implementation returns the only named type of the project implementing
+// iface (directly or through its pointer), nil when there are none or several
+func (r *TypedResolver) implementation(iface *types.Interface) types.Type {
+	if iface.NumMethods() == 0 {
+		return nil
+	}
+	var found types.Type
+	for _, named := range r.named {
+		if !types.Implements(named, iface) && !types.Implements(types.NewPointer(named), iface) {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = named
+	}
+	return found
+}
+
+// takesGinContext reports whether a function has a *gin.Context parameter
+func takesGinContext(sig *types.Signature) bool {
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if isGinContext(params.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGinContext reports whether t is *gin.Context, however gin is imported
+func isGinContext(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Context" && obj.Pkg() != nil && obj.Pkg().Path() == ginPackagePath
+}
+
+// isSuccessStatus reports whether a status argument is a constant 2xx code
+func isSuccessStatus(info *types.Info, expr ast.Expr) bool {
+	value := info.Types[expr].Value
+	if value == nil || value.Kind() != constant.Int {
+		return false
+	}
+	code, ok := constant.Int64Val(value)
+	return ok && code >= 200 && code < 300
+}
@@ -0,0 +1,70 @@
+package ast
+
+import (
+	"api-doc-generator/internal/openapi"
+	"regexp"
+	"strings"
+)
+
+// versionSegment matches the version segments extractTags skips: v1, v2...
+var versionSegment = regexp.MustCompile(`^v\d+$`)
+
+// tagSkipPrefixes are the path prefixes extractTags skips besides versions
+var tagSkipPrefixes = map[string]bool{
+	"api":         true,
+	"imagine_hub": true,
+	"app":         true,
+}
+
+// PathVersion returns the version segment preceding the resource of a path,
+// "v2" for /api/v2/users/{id}, or "" for unversioned paths
+func PathVersion(path string) string {
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if versionSegment.MatchString(part) {
+			return part
+		}
+		if !tagSkipPrefixes[part] && !strings.HasPrefix(part, "{") {
+			return ""
+		}
+	}
+	return ""
+}
+
+// VersionedTags returns the tags of resources registered under more than
+// one API version, like Users under /api/v1/users and /api/v2/users
+func VersionedTags(routes []RouteInfo) map[string]bool {
+	versions := make(map[string]map[string]bool)
+	for _, route := range routes {
+		version := PathVersion(route.Path)
+		if version == "" || route.IsStatic() {
+			continue
+		}
+		for _, tag := range extractTags(route.Path) {
+			if versions[tag] == nil {
+				versions[tag] = make(map[string]bool)
+			}
+			versions[tag][version] = true
+		}
+	}
+	versioned := make(map[string]bool)
+	for tag, seen := range versions {
+		if len(seen) > 1 {
+			versioned[tag] = true
+		}
+	}
+	return versioned
+}
+
+// MarkVersion appends the version of path to the operation's tags that are
+// versioned: Users becomes "Users v2" under /api/v2
+func MarkVersion(op *openapi.Operation, path string, versioned map[string]bool) {
+	version := PathVersion(path)
+	if version == "" {
+		return
+	}
+	for i, tag := range op.Tags {
+		if versioned[tag] {
+			op.Tags[i] = tag + " " + version
+		}
+	}
+}
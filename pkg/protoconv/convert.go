@@ -0,0 +1,313 @@
+// Package protoconv converts protobuf messages and enums to OpenAPI schemas
+// describing their JSON form, as written by protojson: lowerCamelCase field
+// names, 64-bit integers as strings, enums by value name and the special
+// mappings of the well-known types.
+//
+// Descriptors come from compiled .proto files, e.g. for a gRPC parser, or from
+// the Go code generated by protoc-gen-go (see FileFromGoSource), for Gin
+// handlers rendering proto messages.
+package protoconv
+
+import (
+	"api-doc-generator/internal/openapi"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Converter converts messages and enums to component schemas named after
+// their generated Go types (see SchemaName). Message fields refer to other
+// messages and enums by $ref; well-known types are inlined.
+type Converter struct {
+	// UseProtoNames names properties as declared in the .proto file (user_id)
+	// instead of by their JSON name (userId), like the protojson option
+	UseProtoNames bool
+
+	schemas map[string]*openapi.Schema
+}
+
+func NewConverter() *Converter {
+	return &Converter{schemas: make(map[string]*openapi.Schema)}
+}
+
+// Schemas returns the component schemas converted so far, by SchemaName
+func (c *Converter) Schemas() map[string]*openapi.Schema {
+	return c.schemas
+}
+
+// AddFile converts every message and enum declared in a file, nested ones
+// included
+func (c *Converter) AddFile(file protoreflect.FileDescriptor) {
+	c.addEnums(file.Enums())
+	c.addMessages(file.Messages())
+}
+
+func (c *Converter) addMessages(messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		if message.IsMapEntry() {
+			continue
+		}
+		c.Message(message)
+		c.addEnums(message.Enums())
+		c.addMessages(message.Messages())
+	}
+}
+
+func (c *Converter) addEnums(enums protoreflect.EnumDescriptors) {
+	for i := 0; i < enums.Len(); i++ {
+		c.Enum(enums.Get(i))
+	}
+}
+
+// Message returns the schema of a message: a reference to its component,
+// converted on first use, or the inline schema of a well-known type.
+// Unresolved messages are referred to without a component.
+func (c *Converter) Message(message protoreflect.MessageDescriptor) openapi.Schema {
+	if schema, ok := wellKnownSchema(message.FullName()); ok {
+		return schema
+	}
+	name := SchemaName(message)
+	if _, done := c.schemas[name]; !done && !message.IsPlaceholder() {
+		// Registered before the fields are converted, for recursive messages
+		c.schemas[name] = &openapi.Schema{Type: "object"}
+		schema := c.messageSchema(message)
+		c.schemas[name] = &schema
+	}
+	return openapi.Schema{Ref: openapi.SchemaRefPrefix + name}
+}
+
+// Enum returns a reference to the component of an enum, converted on first
+// use. protojson writes enum values by name.
+func (c *Converter) Enum(enum protoreflect.EnumDescriptor) openapi.Schema {
+	if enum.FullName() == "google.protobuf.NullValue" {
+		return openapi.Schema{}
+	}
+	name := SchemaName(enum)
+	if _, done := c.schemas[name]; !done && !enum.IsPlaceholder() {
+		schema := openapi.Schema{Type: "string", Description: comments(enum)}
+		var values []string
+		for i := 0; i < enum.Values().Len(); i++ {
+			value := enum.Values().Get(i)
+			schema.Enum = append(schema.Enum, string(value.Name()))
+			if comment := comments(value); comment != "" {
+				values = append(values, string(value.Name())+": "+comment)
+			}
+		}
+		if len(values) > 0 {
+			schema.Description = joinSentences(schema.Description, strings.Join(values, "; "))
+		}
+		c.schemas[name] = &schema
+	}
+	return openapi.Schema{Ref: openapi.SchemaRefPrefix + name}
+}
+
+// messageSchema converts the fields of a message. proto3 fields are omitted
+// from the JSON when unset, so only proto2 required fields are required.
+func (c *Converter) messageSchema(message protoreflect.MessageDescriptor) openapi.Schema {
+	schema := openapi.Schema{
+		Type:        "object",
+		Description: comments(message),
+		Properties:  make(map[string]openapi.Schema),
+	}
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := c.propertyName(field)
+		property := c.field(field)
+
+		description := comments(field)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			description = joinSentences(description, "Only one of "+c.oneofNames(oneof)+" is set.")
+		}
+		if options, ok := field.Options().(interface{ GetDeprecated() bool }); ok && options.GetDeprecated() {
+			description = joinSentences(description, "Deprecated.")
+		}
+		if description != "" {
+			property.Description = description
+		}
+		schema.Properties[name] = property
+		if field.Cardinality() == protoreflect.Required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// field returns the schema of a field, with its cardinality
+func (c *Converter) field(field protoreflect.FieldDescriptor) openapi.Schema {
+	switch {
+	case field.IsMap():
+		value := c.singular(field.MapValue())
+		return openapi.Schema{Type: "object", AdditionalProperties: &value}
+	case field.IsList():
+		items := c.singular(field)
+		return openapi.Schema{Type: "array", Items: &items}
+	}
+	return c.singular(field)
+}
+
+// singular returns the schema of one value of a field
+func (c *Converter) singular(field protoreflect.FieldDescriptor) openapi.Schema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return c.Message(field.Message())
+	case protoreflect.EnumKind:
+		return c.Enum(field.Enum())
+	}
+	return scalarSchema(field.Kind())
+}
+
+// scalarSchema follows protojson, which writes 64-bit integers as strings
+// so that JavaScript clients don't lose precision
+func scalarSchema(kind protoreflect.Kind) openapi.Schema {
+	switch kind {
+	case protoreflect.BoolKind:
+		return openapi.Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return openapi.Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return openapi.Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return openapi.Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return openapi.Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return openapi.Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return openapi.Schema{Type: "number", Format: "double"}
+	case protoreflect.BytesKind:
+		return openapi.Schema{Type: "string", Format: "byte"}
+	}
+	return openapi.Schema{Type: "string"}
+}
+
+// wellKnownSchema returns the JSON mapping of the well-known types
+func wellKnownSchema(name protoreflect.FullName) (openapi.Schema, bool) {
+	switch name {
+	case "google.protobuf.Timestamp":
+		return openapi.Schema{Type: "string", Format: "date-time"}, true
+	case "google.protobuf.Duration":
+		return openapi.Schema{Type: "string", Example: "1.5s"}, true
+	case "google.protobuf.FieldMask":
+		return openapi.Schema{Type: "string", Example: "user.displayName,photo"}, true
+	case "google.protobuf.Empty", "google.protobuf.Struct":
+		return openapi.Schema{Type: "object"}, true
+	case "google.protobuf.Value":
+		return openapi.Schema{}, true
+	case "google.protobuf.ListValue":
+		return openapi.Schema{Type: "array", Items: &openapi.Schema{}}, true
+	case "google.protobuf.Any":
+		return openapi.Schema{
+			Type:       "object",
+			Properties: map[string]openapi.Schema{"@type": {Type: "string", Description: "URL identifying the message type"}},
+			Required:   []string{"@type"},
+		}, true
+	case "google.protobuf.BoolValue":
+		return scalarSchema(protoreflect.BoolKind), true
+	case "google.protobuf.Int32Value":
+		return scalarSchema(protoreflect.Int32Kind), true
+	case "google.protobuf.UInt32Value":
+		return scalarSchema(protoreflect.Uint32Kind), true
+	case "google.protobuf.Int64Value":
+		return scalarSchema(protoreflect.Int64Kind), true
+	case "google.protobuf.UInt64Value":
+		return scalarSchema(protoreflect.Uint64Kind), true
+	case "google.protobuf.FloatValue":
+		return scalarSchema(protoreflect.FloatKind), true
+	case "google.protobuf.DoubleValue":
+		return scalarSchema(protoreflect.DoubleKind), true
+	case "google.protobuf.StringValue":
+		return scalarSchema(protoreflect.StringKind), true
+	case "google.protobuf.BytesValue":
+		return scalarSchema(protoreflect.BytesKind), true
+	}
+	return openapi.Schema{}, false
+}
+
+// propertyName returns the JSON name of a field
+func (c *Converter) propertyName(field protoreflect.FieldDescriptor) string {
+	if c.UseProtoNames {
+		return string(field.Name())
+	}
+	return field.JSONName()
+}
+
+// oneofNames lists the properties of a oneof
+func (c *Converter) oneofNames(oneof protoreflect.OneofDescriptor) string {
+	names := make([]string, oneof.Fields().Len())
+	for i := range names {
+		names[i] = c.propertyName(oneof.Fields().Get(i))
+	}
+	return strings.Join(names, ", ")
+}
+
+// SchemaName names the component of a message or enum after its Go type, as
+// generated by protoc-gen-go: user.v1.User.Address becomes User_Address.
+// The package of an unresolved descriptor is unknown; its lowercase leading
+// components are taken for the package.
+func SchemaName(desc protoreflect.Descriptor) string {
+	name := string(desc.FullName())
+	if file := desc.ParentFile(); file != nil {
+		name = strings.TrimPrefix(name, string(file.Package())+".")
+	} else {
+		parts := strings.Split(name, ".")
+		for len(parts) > 1 && !isASCIIUpper(parts[0][0]) {
+			parts = parts[1:]
+		}
+		name = strings.Join(parts, ".")
+	}
+	return goCamelCase(name)
+}
+
+// goCamelCase converts a proto identifier to a Go identifier like
+// protoc-gen-go: the dots of nested names become underscores, an underscore
+// followed by a lowercase letter is dropped and the letter capitalized, and
+// the first letter is capitalized
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skipped, the next letter is capitalized
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skipped, the next letter is capitalized
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isASCIIUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// comments returns the leading comment of a declaration, available when the
+// descriptor keeps source information, i.e. when compiled from .proto files
+func comments(desc protoreflect.Descriptor) string {
+	location := desc.ParentFile().SourceLocations().ByDescriptor(desc)
+	return strings.Join(strings.Fields(location.LeadingComments), " ")
+}
+
+func joinSentences(first, second string) string {
+	if first == "" {
+		return second
+	}
+	return first + " " + second
+}
@@ -0,0 +1,115 @@
+package protoconv
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// errNoDescriptor is returned for raw descriptors written in a form not known
+var errNoDescriptor = errors.New("raw descriptor is not a literal")
+
+// FileFromGoSource returns the descriptor of the .proto file a Go file was
+// generated from by protoc-gen-go, read from the raw descriptor the generated
+// code embeds (file_<name>_proto_rawDesc). It returns nil for other files.
+// Imported .proto files are not needed: messages and enums they declare are
+// unresolved placeholders, which Converter refers to by name.
+func FileFromGoSource(node *ast.File) (protoreflect.FileDescriptor, error) {
+	expr := rawDescriptor(node)
+	if expr == nil {
+		return nil, nil
+	}
+	raw, err := descriptorBytes(expr)
+	if err != nil {
+		return nil, err
+	}
+	fileProto := new(descriptorpb.FileDescriptorProto)
+	if err := proto.Unmarshal(raw, fileProto); err != nil {
+		return nil, fmt.Errorf("invalid raw descriptor: %w", err)
+	}
+	file, err := protodesc.FileOptions{AllowUnresolvable: true}.New(fileProto, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor of %s: %w", fileProto.GetName(), err)
+	}
+	return file, nil
+}
+
+// rawDescriptor finds the value of the raw descriptor variable or constant
+func rawDescriptor(node *ast.File) ast.Expr {
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Names) != 1 || len(valueSpec.Values) != 1 {
+				continue
+			}
+			name := valueSpec.Names[0].Name
+			if strings.HasPrefix(name, "file_") && strings.HasSuffix(name, "_rawDesc") {
+				return valueSpec.Values[0]
+			}
+		}
+	}
+	return nil
+}
+
+// descriptorBytes evaluates a raw descriptor, written by protoc-gen-go as a
+// []byte literal, string([]byte{...}), or a concatenation of string literals
+// depending on its version
+func descriptorBytes(expr ast.Expr) ([]byte, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return descriptorBytes(e.X)
+	case *ast.CallExpr:
+		// string([]byte{...}) or []byte("...")
+		if len(e.Args) == 1 {
+			return descriptorBytes(e.Args[0])
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			left, err := descriptorBytes(e.X)
+			if err != nil {
+				return nil, err
+			}
+			right, err := descriptorBytes(e.Y)
+			if err != nil {
+				return nil, err
+			}
+			return append(left, right...), nil
+		}
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			value, err := strconv.Unquote(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(value), nil
+		}
+	case *ast.CompositeLit:
+		raw := make([]byte, 0, len(e.Elts))
+		for _, elt := range e.Elts {
+			lit, ok := elt.(*ast.BasicLit)
+			if !ok || lit.Kind != token.INT {
+				return nil, errNoDescriptor
+			}
+			value, err := strconv.ParseUint(lit.Value, 0, 8)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, byte(value))
+		}
+		return raw, nil
+	}
+	return nil, errNoDescriptor
+}